@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/spf13/cobra"
+
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+	"github.com/go-go-golems/vm-system/pkg/vmstore"
+)
+
+const (
+	authTokenActionCreate = "create"
+	authTokenActionList   = "list"
+	authTokenActionRevoke = "revoke"
+)
+
+type authTokenCreateSettings struct {
+	Name        string   `glazed:"name"`
+	Scopes      []string `glazed:"scope"`
+	WorkspaceID string   `glazed:"workspace-id"`
+	CertSubject string   `glazed:"cert-subject"`
+}
+
+type authTokenIDArg struct {
+	TokenID string `glazed:"token-id"`
+}
+
+type authTokenCommand struct {
+	*cmds.CommandDescription
+	action string
+}
+
+var _ cmds.WriterCommand = &authTokenCommand{}
+
+// RunIntoWriter manages tokens directly in the --db store rather than through
+// the daemon, so the first token can be issued before any exists.
+func (c *authTokenCommand) RunIntoWriter(ctx context.Context, vals *values.Values, w io.Writer) error {
+	store, err := vmstore.NewVMStore(dbPath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	auth := vmcontrol.NewAuthService(store, store)
+
+	switch c.action {
+	case authTokenActionCreate:
+		settings := &authTokenCreateSettings{}
+		if err := decodeDefault(vals, settings); err != nil {
+			return err
+		}
+		created, err := auth.Create(ctx, vmcontrol.CreateTokenInput{
+			Name:        settings.Name,
+			Scopes:      settings.Scopes,
+			WorkspaceID: settings.WorkspaceID,
+			CertSubject: settings.CertSubject,
+		})
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(w, "Created token: %s (%s)\n", created.Token.ID, created.Token.Name)
+		_, _ = fmt.Fprintf(w, "Scopes: %s\n", strings.Join(created.Token.Scopes, ", "))
+		if created.Token.WorkspaceID != "" {
+			_, _ = fmt.Fprintf(w, "Workspace: %s\n", created.Token.WorkspaceID)
+		}
+		if created.Token.CertSubject != "" {
+			_, _ = fmt.Fprintf(w, "Certificate subject: %s\n", created.Token.CertSubject)
+			_, _ = fmt.Fprintln(w, "Clients authenticate with a certificate carrying this subject; the token has no secret.")
+			return nil
+		}
+		_, _ = fmt.Fprintln(w)
+		_, _ = fmt.Fprintf(w, "%s\n\n", created.Secret)
+		_, _ = fmt.Fprintln(w, "Store this token now; it cannot be shown again.")
+		return nil
+	case authTokenActionList:
+		tokens, err := auth.List(ctx)
+		if err != nil {
+			return err
+		}
+		if len(tokens) == 0 {
+			_, _ = fmt.Fprintln(w, "No API tokens.")
+			return nil
+		}
+		_, _ = fmt.Fprintf(w, "API tokens (%d):\n\n", len(tokens))
+		for _, token := range tokens {
+			status := "active"
+			if token.RevokedAt != nil {
+				status = "revoked " + token.RevokedAt.Format("2006-01-02 15:04:05")
+			}
+			lastUsed := "never"
+			if token.LastUsedAt != nil {
+				lastUsed = token.LastUsedAt.Format("2006-01-02 15:04:05")
+			}
+			_, _ = fmt.Fprintf(w, "  %s - %s (%s)\n", token.ID, token.Name, status)
+			_, _ = fmt.Fprintf(w, "    Scopes: %s\n", strings.Join(token.Scopes, ", "))
+			if token.WorkspaceID != "" {
+				_, _ = fmt.Fprintf(w, "    Workspace: %s\n", token.WorkspaceID)
+			}
+			if token.CertSubject != "" {
+				_, _ = fmt.Fprintf(w, "    Certificate subject: %s\n", token.CertSubject)
+			}
+			_, _ = fmt.Fprintf(w, "    Created: %s\n", token.CreatedAt.Format("2006-01-02 15:04:05"))
+			_, _ = fmt.Fprintf(w, "    Last used: %s\n\n", lastUsed)
+		}
+		return nil
+	case authTokenActionRevoke:
+		settings := &authTokenIDArg{}
+		if err := decodeDefault(vals, settings); err != nil {
+			return err
+		}
+		token, err := auth.Revoke(ctx, settings.TokenID)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(w, "Revoked token: %s (%s)\n", token.ID, token.Name)
+		return nil
+	default:
+		return fmt.Errorf("unknown auth token action: %s", c.action)
+	}
+}
+
+func newAuthCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Manage daemon API authentication",
+	}
+
+	tokenCmd := &cobra.Command{
+		Use:   "token",
+		Short: "Manage API tokens",
+		Long:  "Create, list and revoke the bearer tokens accepted by a daemon started with --require-auth. Tokens are stored hashed in the --db database.",
+	}
+	tokenCmd.AddCommand(
+		newAuthTokenCreateCommand(),
+		newAuthTokenListCommand(),
+		newAuthTokenRevokeCommand(),
+	)
+	cmd.AddCommand(tokenCmd)
+
+	return cmd
+}
+
+func newAuthTokenCreateCommand() *cobra.Command {
+	return buildCobraCommand(&authTokenCommand{
+		CommandDescription: commandDescription(
+			"create",
+			"Create an API token",
+			fmt.Sprintf("Create an API token and print its secret once. Scopes are %s; every scope allows reads, and a token without --scope is read-only. --workspace-id confines the token to one workspace. --cert-subject binds the token to a client certificate subject instead of issuing a secret, for daemons that verify client certificates.", strings.Join(vmmodels.TokenScopes(), ", ")),
+			[]*fields.Definition{
+				fields.New("name", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Token name (required)")),
+				fields.New("scope", fields.TypeStringList, fields.WithHelp("Scopes to grant (default read)")),
+				fields.New("workspace-id", fields.TypeString, fields.WithHelp("Workspace to confine the token to (default unconfined)")),
+				fields.New("cert-subject", fields.TypeString, fields.WithHelp("Client certificate subject the token authenticates, e.g. \"CN=build-host,O=ci\"; a bare name means CN=name")),
+			},
+			nil,
+			false,
+		),
+		action: authTokenActionCreate,
+	})
+}
+
+func newAuthTokenListCommand() *cobra.Command {
+	return buildCobraCommand(&authTokenCommand{
+		CommandDescription: commandDescription(
+			"list",
+			"List API tokens",
+			"List API tokens, including revoked ones. Secrets are never shown.",
+			nil,
+			nil,
+			false,
+		),
+		action: authTokenActionList,
+	})
+}
+
+func newAuthTokenRevokeCommand() *cobra.Command {
+	return buildCobraCommand(&authTokenCommand{
+		CommandDescription: commandDescription(
+			"revoke",
+			"Revoke an API token",
+			"Revoke an API token. Requests carrying it are rejected from then on.",
+			nil,
+			[]*fields.Definition{fields.New("token-id", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Token ID"))},
+			false,
+		),
+		action: authTokenActionRevoke,
+	})
+}
@@ -46,6 +46,7 @@ const (
 	execActionList    = "list"
 	execActionGet     = "get"
 	execActionEvents  = "events"
+	execActionCancel  = "cancel"
 )
 
 type execCommand struct {
@@ -56,7 +57,10 @@ type execCommand struct {
 var _ cmds.WriterCommand = &execCommand{}
 
 func (c *execCommand) RunIntoWriter(_ context.Context, vals *values.Values, w io.Writer) error {
-	client := vmclient.New(serverURL, nil)
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
 
 	switch c.action {
 	case execActionRepl:
@@ -221,6 +225,20 @@ func (c *execCommand) RunIntoWriter(_ context.Context, vals *values.Values, w io
 			_, _ = fmt.Fprintf(w, "%-5d %-20s %-15s %s\n", event.Seq, event.Ts.Format("15:04:05"), event.Type, payloadStr)
 		}
 		return nil
+	case execActionCancel:
+		settings := &execGetSettings{}
+		if err := decodeDefault(vals, settings); err != nil {
+			return err
+		}
+
+		execution, err := client.CancelExecution(context.Background(), settings.ExecutionID)
+		if err != nil {
+			return err
+		}
+
+		_, _ = fmt.Fprintf(w, "Cancelled execution: %s\n", execution.ID)
+		_, _ = fmt.Fprintf(w, "Status: %s\n", execution.Status)
+		return nil
 	default:
 		return fmt.Errorf("unknown exec action: %s", c.action)
 	}
@@ -239,6 +257,7 @@ func newExecCommand() *cobra.Command {
 		newExecListCommand(),
 		newExecGetCommand(),
 		newExecEventsCommand(),
+		newExecCancelCommand(),
 	)
 
 	return cmd
@@ -342,3 +361,21 @@ func newExecEventsCommand() *cobra.Command {
 
 	return buildCobraCommand(command)
 }
+
+func newExecCancelCommand() *cobra.Command {
+	command := &execCommand{
+		CommandDescription: commandDescription(
+			"cancel",
+			"Cancel a queued execution",
+			"Cancel an execution that is waiting in its session queue. Running executions cannot be cancelled.",
+			nil,
+			[]*fields.Definition{
+				fields.New("execution-id", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Execution ID")),
+			},
+			false,
+		),
+		action: execActionCancel,
+	}
+
+	return buildCobraCommand(command)
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"path/filepath"
+	"os"
+	"strings"
 
 	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
 	"github.com/go-go-golems/glazed/pkg/cmds/values"
 	"github.com/go-go-golems/vm-system/pkg/libloader"
-	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+	"github.com/go-go-golems/vm-system/pkg/vmclient"
 	"github.com/spf13/cobra"
 )
 
@@ -19,8 +21,24 @@ const (
 	libsActionDownload  = "download"
 	libsActionList      = "list"
 	libsActionCacheInfo = "cache-info"
+	libsActionAdd       = "add"
+	libsActionRemove    = "remove"
 )
 
+type libsAddSettings struct {
+	Name        string   `glazed:"name"`
+	Version     string   `glazed:"version"`
+	Global      string   `glazed:"global"`
+	Description string   `glazed:"description"`
+	File        string   `glazed:"file"`
+	Entry       string   `glazed:"entry"`
+	Depends     []string `glazed:"depends"`
+}
+
+type libsRefArg struct {
+	Ref string `glazed:"library-ref"`
+}
+
 type libsCommand struct {
 	*cmds.CommandDescription
 	action string
@@ -28,10 +46,10 @@ type libsCommand struct {
 
 var _ cmds.WriterCommand = &libsCommand{}
 
-func (c *libsCommand) RunIntoWriter(_ context.Context, _ *values.Values, w io.Writer) error {
+func (c *libsCommand) RunIntoWriter(_ context.Context, vals *values.Values, w io.Writer) error {
 	switch c.action {
 	case libsActionDownload:
-		cacheDir := filepath.Join(".", ".vm-cache", "libraries")
+		cacheDir := libloader.CacheDir(resolvedDataDir())
 
 		cache, err := libloader.NewLibraryCache(cacheDir)
 		if err != nil {
@@ -50,18 +68,85 @@ func (c *libsCommand) RunIntoWriter(_ context.Context, _ *values.Values, w io.Wr
 		}
 		return nil
 	case libsActionList:
-		libraries := vmmodels.BuiltinLibraries()
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
+		libraries, err := client.ListLibraries(context.Background())
+		if err != nil {
+			return err
+		}
 
 		_, _ = fmt.Fprintf(w, "Available libraries (%d):\n\n", len(libraries))
 		for _, lib := range libraries {
-			_, _ = fmt.Fprintf(w, "  %s - %s v%s\n", lib.ID, lib.Name, lib.Version)
-			_, _ = fmt.Fprintf(w, "    %s\n", lib.Description)
+			kind := lib.Type
+			if lib.Builtin {
+				kind = "builtin"
+			}
+			_, _ = fmt.Fprintf(w, "  %s - %s v%s (%s)\n", lib.Ref, lib.Name, lib.Version, kind)
+			if lib.Description != "" {
+				_, _ = fmt.Fprintf(w, "    %s\n", lib.Description)
+			}
 			_, _ = fmt.Fprintf(w, "    Source: %s\n", lib.Source)
-			_, _ = fmt.Fprintf(w, "    Global: %s\n\n", lib.Config["global"])
+			if len(lib.Dependencies) > 0 {
+				_, _ = fmt.Fprintf(w, "    Depends: %s\n", strings.Join(lib.Dependencies, ", "))
+			}
+			_, _ = fmt.Fprintf(w, "    Global: %s\n\n", lib.Global())
+		}
+		return nil
+	case libsActionAdd:
+		settings := &libsAddSettings{}
+		if err := decodeDefault(vals, settings); err != nil {
+			return err
+		}
+
+		request := vmclient.RegisterLibraryRequest{
+			Name:         settings.Name,
+			Version:      settings.Version,
+			Global:       settings.Global,
+			Description:  settings.Description,
+			Entry:        settings.Entry,
+			Dependencies: settings.Depends,
+		}
+		if settings.File != "" {
+			data, err := os.ReadFile(settings.File)
+			if err != nil {
+				return err
+			}
+			if strings.HasSuffix(settings.File, ".tgz") || strings.HasSuffix(settings.File, ".tar.gz") {
+				request.Tarball = data
+			} else {
+				request.Content = string(data)
+			}
+		}
+
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
+		library, err := client.RegisterLibrary(context.Background(), request)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(w, "Registered library: %s (global %s)\n", library.Ref, library.Global())
+		return nil
+	case libsActionRemove:
+		settings := &libsRefArg{}
+		if err := decodeDefault(vals, settings); err != nil {
+			return err
+		}
+
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
+		if err := client.RemoveLibrary(context.Background(), settings.Ref); err != nil {
+			return err
 		}
+		_, _ = fmt.Fprintf(w, "Removed library: %s\n", settings.Ref)
 		return nil
 	case libsActionCacheInfo:
-		cacheDir := filepath.Join(".", ".vm-cache", "libraries")
+		cacheDir := libloader.CacheDir(resolvedDataDir())
 
 		cache, err := libloader.NewLibraryCache(cacheDir)
 		if err != nil {
@@ -105,6 +190,8 @@ func newLibsCommand() *cobra.Command {
 	cmd.AddCommand(
 		newLibsDownloadCommand(),
 		newLibsListCommand(),
+		newLibsAddCommand(),
+		newLibsRemoveCommand(),
 		newLibsCacheInfoCommand(),
 	)
 
@@ -116,7 +203,7 @@ func newLibsDownloadCommand() *cobra.Command {
 		CommandDescription: commandDescription(
 			"download",
 			"Download all builtin libraries",
-			"Download all built-in libraries into the local .vm-cache/libraries directory.",
+			"Download all built-in libraries into the library cache under the data directory (--data-dir, default .vm-cache next to --db).",
 			nil,
 			nil,
 			false,
@@ -131,7 +218,7 @@ func newLibsListCommand() *cobra.Command {
 		CommandDescription: commandDescription(
 			"list",
 			"List available libraries",
-			"List available built-in JavaScript libraries.",
+			"List the daemon's library catalog: built-in libraries followed by registered ones.",
 			nil,
 			nil,
 			false,
@@ -141,6 +228,44 @@ func newLibsListCommand() *cobra.Command {
 	return buildCobraCommand(command)
 }
 
+func newLibsAddCommand() *cobra.Command {
+	command := &libsCommand{
+		CommandDescription: commandDescription(
+			"add",
+			"Register a library",
+			"Register a JavaScript library with the daemon. --file uploads a local .js file or an offline npm .tgz tarball. Name, version and description default to the tarball's package.json.",
+			[]*fields.Definition{
+				fields.New("name", fields.TypeString, fields.WithHelp("Library name")),
+				fields.New("version", fields.TypeString, fields.WithHelp("Library version")),
+				fields.New("global", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Global name the library defines (required)")),
+				fields.New("description", fields.TypeString, fields.WithHelp("Library description")),
+				fields.New("file", fields.TypeString, fields.WithHelp("Local .js or .tgz file to upload")),
+				fields.New("entry", fields.TypeString, fields.WithHelp("Tarball file to load instead of the package.json entry")),
+				fields.New("depends", fields.TypeStringList, fields.WithHelp("Libraries (name@version) to load before this one")),
+			},
+			nil,
+			false,
+		),
+		action: libsActionAdd,
+	}
+	return buildCobraCommand(command)
+}
+
+func newLibsRemoveCommand() *cobra.Command {
+	command := &libsCommand{
+		CommandDescription: commandDescription(
+			"remove",
+			"Remove a registered library",
+			"Remove a registered library and its cached code. Libraries still used by a template cannot be removed.",
+			nil,
+			[]*fields.Definition{fields.New("library-ref", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Library ref (<name>-<version>)"))},
+			false,
+		),
+		action: libsActionRemove,
+	}
+	return buildCobraCommand(command)
+}
+
 func newLibsCacheInfoCommand() *cobra.Command {
 	command := &libsCommand{
 		CommandDescription: commandDescription(
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmcp"
+)
+
+type mcpCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.BareCommand = &mcpCommand{}
+
+func (c *mcpCommand) Run(_ context.Context, _ *values.Values) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	return vmmcp.NewServer(client).Run(ctx, &mcp.StdioTransport{})
+}
+
+func newMCPCommand() *cobra.Command {
+	return buildCobraCommand(&mcpCommand{
+		CommandDescription: commandDescription(
+			"mcp",
+			"Serve vm-system tools to agents over MCP",
+			"Speak the Model Context Protocol on stdin/stdout, exposing tools to list and create templates, create sessions, evaluate code, run files and read execution history against the daemon at --server-url, or in process against --db.",
+			nil,
+			nil,
+			false,
+		),
+	})
+}
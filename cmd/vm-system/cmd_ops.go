@@ -4,22 +4,24 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 
-	"github.com/go-go-golems/vm-system/pkg/vmclient"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
 )
 
 func newOpsCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "ops",
 		Short: "Operational daemon commands",
-		Long:  "Read daemon health and runtime summary endpoints.",
+		Long:  "Read daemon health, runtime summary and audit trail endpoints.",
 	}
 
 	cmd.AddCommand(
 		newOpsHealthCommand(),
 		newOpsRuntimeSummaryCommand(),
+		newOpsAuditCommand(),
 	)
 
 	return cmd
@@ -30,7 +32,10 @@ func newOpsHealthCommand() *cobra.Command {
 		Use:   "health",
 		Short: "Get daemon health",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client := vmclient.New(serverURL, nil)
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
 			status, err := client.Health(context.Background())
 			if err != nil {
 				return err
@@ -51,7 +56,10 @@ func newOpsRuntimeSummaryCommand() *cobra.Command {
 		Use:   "runtime-summary",
 		Short: "Get daemon runtime summary",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client := vmclient.New(serverURL, nil)
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
 			summary, err := client.RuntimeSummary(context.Background())
 			if err != nil {
 				return err
@@ -66,3 +74,73 @@ func newOpsRuntimeSummaryCommand() *cobra.Command {
 		},
 	}
 }
+
+func newOpsAuditCommand() *cobra.Command {
+	var (
+		filter vmmodels.AuditFilter
+		since  string
+	)
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "List audit events",
+		Long:  "List the daemon's audit trail of template, library, workspace, session and execution changes, newest first.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if since != "" {
+				parsed, err := parseSince(since, time.Now())
+				if err != nil {
+					return err
+				}
+				filter.Since = parsed
+			}
+
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
+			events, err := client.ListAuditEvents(context.Background(), filter)
+			if err != nil {
+				return err
+			}
+			if len(events) == 0 {
+				fmt.Println("No audit events.")
+				return nil
+			}
+			for _, event := range events {
+				resource := event.ResourceType
+				if event.ResourceID != "" {
+					resource += " " + event.ResourceID
+				}
+				outcome := event.Outcome
+				if event.ErrorCode != "" {
+					outcome += " " + event.ErrorCode
+				}
+				fmt.Printf("%s  %-24s %-28s %s (%s)\n", event.CreatedAt.Format("2006-01-02 15:04:05"), event.Actor, event.Action, resource, outcome)
+				if len(event.Details) > 0 {
+					fmt.Printf("    %s\n", event.Details)
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&filter.Action, "action", "", "Only events with this action (e.g. template.module.add)")
+	cmd.Flags().StringVar(&filter.Actor, "actor", "", "Only events by this actor (e.g. token:ci or anonymous)")
+	cmd.Flags().StringVar(&filter.ResourceType, "resource-type", "", "Only events on this resource type (template, library, workspace, session, execution)")
+	cmd.Flags().StringVar(&filter.ResourceID, "resource-id", "", "Only events on this resource")
+	cmd.Flags().StringVar(&filter.WorkspaceID, "workspace-id", "", "Only events in this workspace")
+	cmd.Flags().StringVar(&filter.Outcome, "outcome", "", "Only success or failure events")
+	cmd.Flags().StringVar(&since, "since", "", "Only events after this RFC 3339 time or this long ago (e.g. 24h)")
+	cmd.Flags().IntVar(&filter.Limit, "limit", 100, "Maximum number of events")
+	return cmd
+}
+
+// parseSince accepts an RFC 3339 timestamp or a duration before now.
+func parseSince(raw string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(raw); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("--since must be an RFC 3339 time or a duration, got %q", raw)
+	}
+	return t, nil
+}
@@ -10,12 +10,16 @@ func TestRootCommandRegistersExpectedTopLevelCommands(t *testing.T) {
 	root := newRootCommand(help.NewHelpSystem())
 
 	expected := map[string]bool{
-		"serve":    true,
-		"template": true,
-		"session":  true,
-		"exec":     true,
-		"ops":      true,
-		"libs":     true,
+		"serve":     true,
+		"template":  true,
+		"session":   true,
+		"exec":      true,
+		"ops":       true,
+		"libs":      true,
+		"auth":      true,
+		"workspace": true,
+		"mcp":       true,
+		"run":       true,
 	}
 
 	seen := map[string]bool{}
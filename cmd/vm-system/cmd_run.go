@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/spf13/cobra"
+
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmexec"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+	"github.com/go-go-golems/vm-system/pkg/vmrun"
+	"github.com/go-go-golems/vm-system/pkg/vmstore"
+)
+
+type runSettings struct {
+	Template string `glazed:"template"`
+	Worktree string `glazed:"worktree"`
+	Timeout  string `glazed:"timeout"`
+	ArgsJSON string `glazed:"args"`
+	EnvJSON  string `glazed:"env"`
+	Report   string `glazed:"report"`
+	Path     string `glazed:"path"`
+}
+
+type runCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.WriterCommand = &runCommand{}
+
+func (c *runCommand) RunIntoWriter(_ context.Context, vals *values.Values, w io.Writer) error {
+	settings := &runSettings{}
+	if err := decodeDefault(vals, settings); err != nil {
+		return err
+	}
+
+	spec, err := resolveRunTemplate(settings.Template)
+	if err != nil {
+		return err
+	}
+	timeout, err := time.ParseDuration(settings.Timeout)
+	if err != nil {
+		return fmt.Errorf("invalid --timeout: %w", err)
+	}
+	var argsMap map[string]interface{}
+	var envMap map[string]interface{}
+	if settings.ArgsJSON != "" {
+		if err := json.Unmarshal([]byte(settings.ArgsJSON), &argsMap); err != nil {
+			return fmt.Errorf("invalid args JSON: %w", err)
+		}
+	}
+	if settings.EnvJSON != "" {
+		if err := json.Unmarshal([]byte(settings.EnvJSON), &envMap); err != nil {
+			return fmt.Errorf("invalid env JSON: %w", err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	report, err := vmrun.Run(ctx, vmrun.Options{
+		Template: spec,
+		Worktree: settings.Worktree,
+		Path:     settings.Path,
+		Args:     argsMap,
+		Env:      envMap,
+		Timeout:  timeout,
+		DataDir:  resolvedDataDir(),
+		Observer: vmexec.Observer{Event: func(event *vmmodels.ExecutionEvent) {
+			printRunEvent(w, os.Stderr, event)
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	if settings.Report != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(settings.Report, append(data, '\n'), 0o644); err != nil {
+			return fmt.Errorf("write report: %w", err)
+		}
+	}
+	if report.Failed() {
+		return fmt.Errorf("run %s: %s: %s", settings.Path, report.Outcome, report.Error)
+	}
+	return nil
+}
+
+// printRunEvent writes an execution event the way a script's own output
+// would appear: console output on stdout (warnings and errors on stderr),
+// uncaught exceptions on stderr.
+func printRunEvent(stdout, stderr io.Writer, event *vmmodels.ExecutionEvent) {
+	switch vmmodels.EventType(event.Type) {
+	case vmmodels.EventConsole:
+		payload := vmmodels.ConsolePayload{}
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return
+		}
+		out := stdout
+		if payload.Level == "warn" || payload.Level == "error" {
+			out = stderr
+		}
+		_, _ = fmt.Fprintln(out, payload.Text)
+	case vmmodels.EventStdout, vmmodels.EventStderr:
+		payload := struct {
+			Text string `json:"text"`
+		}{}
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return
+		}
+		out := stdout
+		if event.Type == string(vmmodels.EventStderr) {
+			out = stderr
+		}
+		_, _ = fmt.Fprint(out, payload.Text)
+	case vmmodels.EventException:
+		payload := vmmodels.ExceptionPayload{}
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return
+		}
+		text := payload.Stack
+		if text == "" {
+			text = payload.Message
+		}
+		_, _ = fmt.Fprintf(stderr, "Uncaught %s\n", strings.TrimRight(text, "\n"))
+	}
+}
+
+// resolveRunTemplate reads the template spec file at ref. Any other ref names
+// a template: with --db it is exported from that database, otherwise a
+// template with default settings is created under that name.
+func resolveRunTemplate(ref string) (*vmmodels.TemplateSpec, error) {
+	if ref == "-" || isSpecFile(ref) {
+		return readTemplateSpec(ref)
+	}
+	if !inProcess {
+		return &vmmodels.TemplateSpec{Name: ref}, nil
+	}
+
+	store, err := vmstore.NewVMStore(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("open store: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+	templates := vmcontrol.NewTemplateService(store)
+	ctx := context.Background()
+	all, err := templates.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, template := range all {
+		if template.Name == ref {
+			return templates.Export(ctx, template.ID)
+		}
+	}
+	return nil, fmt.Errorf("template %q not found in %s", ref, dbPath)
+}
+
+// isSpecFile reports whether ref is an existing file or has a spec file
+// extension, so a mistyped path is reported instead of taken for a name.
+func isSpecFile(ref string) bool {
+	for _, ext := range []string{".yaml", ".yml", ".json"} {
+		if strings.HasSuffix(ref, ext) {
+			return true
+		}
+	}
+	info, err := os.Stat(ref)
+	return err == nil && !info.IsDir()
+}
+
+func newRunCommand() *cobra.Command {
+	return buildCobraCommand(&runCommand{
+		CommandDescription: commandDescription(
+			"run",
+			"Run a file in a throwaway session, without a daemon",
+			"Build a session from --template on --worktree in an in-memory store in this process, run the file, and stream its console output to stdout and stderr. Exits non-zero when the file throws, times out or breaks a template limit. --template is a YAML or JSON template spec file, or a template name: looked up in --db when given, otherwise created with default settings.",
+			[]*fields.Definition{
+				fields.New("template", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Template spec file, or template name")),
+				fields.New("worktree", fields.TypeString, fields.WithDefault("."), fields.WithHelp("Worktree directory the session runs in")),
+				fields.New("timeout", fields.TypeString, fields.WithDefault("0"), fields.WithHelp("Interrupt the file after this long (0 uses the template's wall_ms limit)")),
+				fields.New("args", fields.TypeString, fields.WithDefault("{}"), fields.WithHelp("Arguments as JSON")),
+				fields.New("env", fields.TypeString, fields.WithDefault("{}"), fields.WithHelp("Environment as JSON")),
+				fields.New("report", fields.TypeString, fields.WithDefault(""), fields.WithHelp("Write a JSON report of the execution and its events to this file")),
+			},
+			[]*fields.Definition{
+				fields.New("path", fields.TypeString, fields.WithRequired(true), fields.WithHelp("File to run, relative to --worktree")),
+			},
+			false,
+		),
+	})
+}
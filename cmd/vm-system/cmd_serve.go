@@ -2,9 +2,13 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/go-go-golems/glazed/pkg/cmds"
 	"github.com/go-go-golems/glazed/pkg/cmds/fields"
@@ -14,11 +18,29 @@ import (
 
 	"github.com/go-go-golems/vm-system/internal/web"
 	"github.com/go-go-golems/vm-system/pkg/vmdaemon"
+	vmgrpc "github.com/go-go-golems/vm-system/pkg/vmtransport/grpc"
 	vmhttp "github.com/go-go-golems/vm-system/pkg/vmtransport/http"
 )
 
 type serveSettings struct {
-	ListenAddr string `glazed:"listen"`
+	ListenAddr   string `glazed:"listen"`
+	SocketMode   string `glazed:"socket-mode"`
+	SocketOwner  string `glazed:"socket-owner"`
+	QueueDepth   int    `glazed:"queue-depth"`
+	QueueMaxWait string `glazed:"queue-max-wait"`
+
+	TLSCert     string `glazed:"tls-cert"`
+	TLSKey      string `glazed:"tls-key"`
+	TLSClientCA string `glazed:"tls-client-ca"`
+
+	MaxSessions             int `glazed:"max-sessions"`
+	MaxSessionsPerTemplate  int `glazed:"max-sessions-per-template"`
+	MaxSessionsPerWorkspace int `glazed:"max-sessions-per-workspace"`
+	MaxConcurrentExecutions int `glazed:"max-concurrent-executions"`
+
+	RequireAuth bool `glazed:"require-auth"`
+	GRPC        bool `glazed:"grpc"`
+	Ephemeral   bool `glazed:"ephemeral"`
 }
 
 type serveCommand struct {
@@ -33,8 +55,34 @@ func (c *serveCommand) Run(_ context.Context, vals *values.Values) error {
 		return err
 	}
 
+	if settings.Ephemeral && settings.RequireAuth {
+		// Tokens are issued into --db, which an ephemeral daemon never reads.
+		return fmt.Errorf("--require-auth cannot be combined with --ephemeral: API tokens live in --db")
+	}
+
 	cfg := vmdaemon.DefaultConfig(dbPath)
+	cfg.DataDir = resolvedDataDir()
+	cfg.Ephemeral = settings.Ephemeral
 	cfg.ListenAddr = settings.ListenAddr
+	socketMode, err := strconv.ParseUint(settings.SocketMode, 8, 32)
+	if err != nil || socketMode > 0o777 {
+		return fmt.Errorf("invalid --socket-mode %q: expected octal permissions such as 0600", settings.SocketMode)
+	}
+	cfg.SocketMode = os.FileMode(socketMode)
+	cfg.SocketOwner = settings.SocketOwner
+	cfg.TLSCertFile = settings.TLSCert
+	cfg.TLSKeyFile = settings.TLSKey
+	cfg.TLSClientCAFile = settings.TLSClientCA
+	cfg.QueueDepth = settings.QueueDepth
+	queueMaxWait, err := time.ParseDuration(settings.QueueMaxWait)
+	if err != nil {
+		return fmt.Errorf("invalid --queue-max-wait: %w", err)
+	}
+	cfg.QueueMaxWait = queueMaxWait
+	cfg.MaxSessions = settings.MaxSessions
+	cfg.MaxSessionsPerTemplate = settings.MaxSessionsPerTemplate
+	cfg.MaxSessionsPerWorkspace = settings.MaxSessionsPerWorkspace
+	cfg.MaxConcurrentExecutions = settings.MaxConcurrentExecutions
 
 	app, err := vmdaemon.New(cfg, nil)
 	if err != nil {
@@ -42,7 +90,18 @@ func (c *serveCommand) Run(_ context.Context, vals *values.Values) error {
 	}
 	defer app.Close()
 
-	apiHandler := vmhttp.NewHandler(app.Core())
+	var (
+		handlerOpts []vmhttp.HandlerOption
+		grpcOpts    []vmgrpc.ServerOption
+	)
+	if settings.RequireAuth {
+		handlerOpts = append(handlerOpts, vmhttp.RequireAuth())
+		grpcOpts = append(grpcOpts, vmgrpc.RequireAuth())
+	}
+	var apiHandler http.Handler = vmhttp.NewHandler(app.Core(), handlerOpts...)
+	if settings.GRPC {
+		apiHandler = vmgrpc.Handler(vmgrpc.NewServer(app.Core(), grpcOpts...), apiHandler)
+	}
 	publicFS, fsErr := web.PublicFS()
 	if fsErr != nil {
 		app.SetHandler(apiHandler)
@@ -59,6 +118,12 @@ func (c *serveCommand) Run(_ context.Context, vals *values.Values) error {
 	log.Info().
 		Str("component", "daemon").
 		Str("listen_addr", cfg.ListenAddr).
+		Str("data_dir", cfg.DataDir).
+		Bool("ephemeral", cfg.Ephemeral).
+		Bool("tls", cfg.TLSCertFile != "").
+		Bool("client_certs", cfg.TLSClientCAFile != "").
+		Bool("require_auth", settings.RequireAuth).
+		Bool("grpc", settings.GRPC).
 		Msg("vm-system daemon listening")
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
@@ -74,7 +139,21 @@ func newServeCommand() *cobra.Command {
 			"Run the vm-system daemon host",
 			"Start a long-lived daemon process that hosts runtime sessions and serves API requests.",
 			[]*fields.Definition{
-				fields.New("listen", fields.TypeString, fields.WithDefault("127.0.0.1:3210"), fields.WithHelp("HTTP listen address")),
+				fields.New("listen", fields.TypeString, fields.WithDefault("127.0.0.1:3210"), fields.WithHelp("HTTP listen address: host:port, or unix:///path/to/sock for a Unix domain socket")),
+				fields.New("socket-mode", fields.TypeString, fields.WithDefault("0600"), fields.WithHelp("Permissions of a unix:// listen socket (octal)")),
+				fields.New("socket-owner", fields.TypeString, fields.WithHelp("Owner of a unix:// listen socket as user[:group] (default the daemon user)")),
+				fields.New("tls-cert", fields.TypeString, fields.WithHelp("PEM server certificate; with --tls-key, serve HTTPS")),
+				fields.New("tls-key", fields.TypeString, fields.WithHelp("PEM private key for --tls-cert")),
+				fields.New("tls-client-ca", fields.TypeString, fields.WithHelp("PEM CA bundle; clients must present a certificate signed by one of its CAs")),
+				fields.New("queue-depth", fields.TypeInteger, fields.WithDefault(16), fields.WithHelp("Executions that may wait per session before SESSION_BUSY (0 disables queueing)")),
+				fields.New("queue-max-wait", fields.TypeString, fields.WithDefault("10s"), fields.WithHelp("Longest time an execution waits in a session queue (above 0 and under the 30s write timeout)")),
+				fields.New("max-sessions", fields.TypeInteger, fields.WithDefault(0), fields.WithHelp("Live sessions allowed across the daemon (0 is unlimited)")),
+				fields.New("max-sessions-per-template", fields.TypeInteger, fields.WithDefault(0), fields.WithHelp("Live sessions allowed per template (0 is unlimited)")),
+				fields.New("max-sessions-per-workspace", fields.TypeInteger, fields.WithDefault(0), fields.WithHelp("Live sessions allowed per workspace ID (0 is unlimited)")),
+				fields.New("max-concurrent-executions", fields.TypeInteger, fields.WithDefault(0), fields.WithHelp("Executions running at once across the daemon; others wait, scheduled fairly across workspaces (0 is unlimited)")),
+				fields.New("require-auth", fields.TypeBool, fields.WithDefault(false), fields.WithHelp("Require a bearer API token, or a client certificate bound to one, on every API request except health (see 'vm-system auth token create')")),
+				fields.New("grpc", fields.TypeBool, fields.WithDefault(true), fields.WithHelp("Also serve the gRPC API on the listen address (HTTP/2 requests with content-type application/grpc)")),
+				fields.New("ephemeral", fields.TypeBool, fields.WithDefault(false), fields.WithHelp("Keep templates, sessions and executions in memory instead of --db; everything is lost when the daemon exits")),
 			},
 			nil,
 			false,
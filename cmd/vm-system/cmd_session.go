@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"github.com/go-go-golems/glazed/pkg/cmds/fields"
 	"github.com/go-go-golems/glazed/pkg/cmds/values"
 	"github.com/go-go-golems/vm-system/pkg/vmclient"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
 	"github.com/spf13/cobra"
 )
 
@@ -21,7 +23,8 @@ type sessionCreateSettings struct {
 }
 
 type sessionListSettings struct {
-	Status string `glazed:"status"`
+	Status      string `glazed:"status"`
+	WorkspaceID string `glazed:"workspace-id"`
 }
 
 type sessionIDArg struct {
@@ -43,7 +46,10 @@ type sessionCommand struct {
 var _ cmds.WriterCommand = &sessionCommand{}
 
 func (c *sessionCommand) RunIntoWriter(_ context.Context, vals *values.Values, w io.Writer) error {
-	client := vmclient.New(serverURL, nil)
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
 
 	switch c.action {
 	case sessionActionCreate:
@@ -65,6 +71,7 @@ func (c *sessionCommand) RunIntoWriter(_ context.Context, vals *values.Values, w
 		_, _ = fmt.Fprintf(w, "Created session: %s\n", session.ID)
 		_, _ = fmt.Fprintf(w, "Status: %s\n", session.Status)
 		_, _ = fmt.Fprintf(w, "Template ID: %s\n", session.VMID)
+		_, _ = fmt.Fprintf(w, "Template Revision: %d\n", session.TemplateRevision)
 		_, _ = fmt.Fprintf(w, "Workspace ID: %s\n", session.WorkspaceID)
 		_, _ = fmt.Fprintf(w, "Base Commit: %s\n", session.BaseCommitOID)
 		_, _ = fmt.Fprintf(w, "Worktree Path: %s\n", session.WorktreePath)
@@ -75,7 +82,7 @@ func (c *sessionCommand) RunIntoWriter(_ context.Context, vals *values.Values, w
 			return err
 		}
 
-		sessions, err := client.ListSessions(context.Background(), settings.Status)
+		sessions, err := client.ListWorkspaceSessions(context.Background(), settings.WorkspaceID, settings.Status)
 		if err != nil {
 			return err
 		}
@@ -85,10 +92,10 @@ func (c *sessionCommand) RunIntoWriter(_ context.Context, vals *values.Values, w
 			return nil
 		}
 
-		_, _ = fmt.Fprintf(w, "%-36s %-36s %-10s %-20s\n", "Session ID", "Template ID", "Status", "Created")
-		_, _ = fmt.Fprintln(w, "------------------------------------------------------------------------------------------------------")
+		_, _ = fmt.Fprintf(w, "%-36s %-36s %-20s %-10s %-20s\n", "Session ID", "Template ID", "Workspace ID", "Status", "Created")
+		_, _ = fmt.Fprintln(w, "---------------------------------------------------------------------------------------------------------------------------")
 		for _, session := range sessions {
-			_, _ = fmt.Fprintf(w, "%-36s %-36s %-10s %-20s\n", session.ID, session.VMID, session.Status, session.CreatedAt.Format(time.RFC3339))
+			_, _ = fmt.Fprintf(w, "%-36s %-36s %-20s %-10s %-20s\n", session.ID, session.VMID, session.WorkspaceID, session.Status, session.CreatedAt.Format(time.RFC3339))
 		}
 		return nil
 	case sessionActionGet:
@@ -104,17 +111,28 @@ func (c *sessionCommand) RunIntoWriter(_ context.Context, vals *values.Values, w
 
 		_, _ = fmt.Fprintf(w, "Session ID: %s\n", session.ID)
 		_, _ = fmt.Fprintf(w, "Template ID: %s\n", session.VMID)
+		_, _ = fmt.Fprintf(w, "Template Revision: %d\n", session.TemplateRevision)
 		_, _ = fmt.Fprintf(w, "Workspace ID: %s\n", session.WorkspaceID)
 		_, _ = fmt.Fprintf(w, "Base Commit: %s\n", session.BaseCommitOID)
 		_, _ = fmt.Fprintf(w, "Worktree Path: %s\n", session.WorktreePath)
 		_, _ = fmt.Fprintf(w, "Status: %s\n", session.Status)
 		_, _ = fmt.Fprintf(w, "Created: %s\n", session.CreatedAt.Format(time.RFC3339))
+		_, _ = fmt.Fprintf(w, "Last Activity: %s\n", session.LastActivityAt.Format(time.RFC3339))
+		if session.ExpiresAt != nil && session.ExpiresInMs != nil {
+			_, _ = fmt.Fprintf(w, "Expires: %s (in %s)\n", session.ExpiresAt.Format(time.RFC3339), time.Duration(*session.ExpiresInMs)*time.Millisecond)
+		}
 		if session.ClosedAt != nil {
 			_, _ = fmt.Fprintf(w, "Closed: %s\n", session.ClosedAt.Format(time.RFC3339))
 		}
 		if session.LastError != "" {
 			_, _ = fmt.Fprintf(w, "Last Error: %s\n", session.LastError)
 		}
+		var meta vmmodels.SessionRuntimeMeta
+		if len(session.RuntimeMeta) > 0 && json.Unmarshal(session.RuntimeMeta, &meta) == nil {
+			for _, lib := range meta.Libraries {
+				_, _ = fmt.Fprintf(w, "Library: %s sha256:%s\n", lib.Ref, lib.SHA256)
+			}
+		}
 		return nil
 	case sessionActionClose:
 		args := &sessionIDArg{}
@@ -158,7 +176,7 @@ func newSessionCreateCommand() *cobra.Command {
 			"Create a new VM runtime session from a template.",
 			[]*fields.Definition{
 				fields.New("template-id", fields.TypeString, fields.WithHelp("Template ID (required)"), fields.WithRequired(true)),
-				fields.New("workspace-id", fields.TypeString, fields.WithHelp("Workspace ID (required)"), fields.WithRequired(true)),
+				fields.New("workspace-id", fields.TypeString, fields.WithHelp("Workspace ID (required unless --token is bound to a workspace)")),
 				fields.New("base-commit", fields.TypeString, fields.WithHelp("Base commit OID (required)"), fields.WithRequired(true)),
 				fields.New("worktree-path", fields.TypeString, fields.WithHelp("Worktree path (required)"), fields.WithRequired(true)),
 			},
@@ -176,9 +194,10 @@ func newSessionListCommand() *cobra.Command {
 		CommandDescription: commandDescription(
 			"list",
 			"List VM sessions",
-			"List VM sessions and optionally filter by status.",
+			"List VM sessions and optionally filter by status and workspace. Workspace-bound tokens only see their workspace's sessions.",
 			[]*fields.Definition{
-				fields.New("status", fields.TypeString, fields.WithDefault(""), fields.WithHelp("Filter by status (starting, ready, crashed, closed)")),
+				fields.New("status", fields.TypeString, fields.WithDefault(""), fields.WithHelp("Filter by status (starting, ready, crashed, closed, expired)")),
+				fields.New("workspace-id", fields.TypeString, fields.WithDefault(""), fields.WithHelp("Filter by workspace ID")),
 			},
 			nil,
 			false,
@@ -3,8 +3,9 @@ package main
 import "github.com/spf13/cobra"
 
 type templateCreateSettings struct {
-	Name   string `glazed:"name"`
-	Engine string `glazed:"engine"`
+	Name        string `glazed:"name"`
+	Engine      string `glazed:"engine"`
+	WorkspaceID string `glazed:"workspace-id"`
 }
 
 type templateIDArg struct {
@@ -16,6 +17,27 @@ type templateNameFlag struct {
 	Name       string `glazed:"name"`
 }
 
+type templateUpdateSettings struct {
+	TemplateID string `glazed:"template-id"`
+	Name       string `glazed:"name"`
+	Active     string `glazed:"active"`
+}
+
+type templateCapabilityIDArgs struct {
+	TemplateID   string `glazed:"template-id"`
+	CapabilityID string `glazed:"capability-id"`
+}
+
+type templateStartupFileIDArgs struct {
+	TemplateID    string `glazed:"template-id"`
+	StartupFileID string `glazed:"startup-file-id"`
+}
+
+type templateReorderStartupSettings struct {
+	TemplateID     string   `glazed:"template-id"`
+	StartupFileIDs []string `glazed:"startup-file-ids"`
+}
+
 type templateAddCapabilitySettings struct {
 	TemplateID string `glazed:"template-id"`
 	Kind       string `glazed:"kind"`
@@ -27,10 +49,18 @@ type templateAddCapabilitySettings struct {
 type templateAddStartupSettings struct {
 	TemplateID string `glazed:"template-id"`
 	Path       string `glazed:"path"`
+	SourceFile string `glazed:"source-file"`
 	Mode       string `glazed:"mode"`
 	OrderIndex int    `glazed:"order"`
 }
 
+type templateUpdateStartupSettings struct {
+	TemplateID    string `glazed:"template-id"`
+	StartupFileID string `glazed:"startup-file-id"`
+	SourceFile    string `glazed:"source-file"`
+	OrderIndex    int    `glazed:"order"`
+}
+
 func newTemplateCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "template",
@@ -43,6 +73,11 @@ func newTemplateCommand() *cobra.Command {
 		newTemplateListCommand(),
 		newTemplateGetCommand(),
 		newTemplateDeleteCommand(),
+		newTemplateUpdateCommand(),
+		newTemplateCloneCommand(),
+		newTemplateExportCommand(),
+		newTemplateApplyCommand(),
+		newTemplateRevisionsCommand(),
 		newTemplateAddModuleCommand(),
 		newTemplateRemoveModuleCommand(),
 		newTemplateListModulesCommand(),
@@ -53,8 +88,12 @@ func newTemplateCommand() *cobra.Command {
 		newTemplateListAvailableLibrariesCommand(),
 		newTemplateAddCapabilityCommand(),
 		newTemplateListCapabilitiesCommand(),
+		newTemplateRemoveCapabilityCommand(),
 		newTemplateAddStartupFileCommand(),
 		newTemplateListStartupFilesCommand(),
+		newTemplateUpdateStartupFileCommand(),
+		newTemplateRemoveStartupFileCommand(),
+		newTemplateReorderStartupFilesCommand(),
 	)
 
 	return cmd
@@ -5,13 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strconv"
 	"time"
 
 	"github.com/go-go-golems/glazed/pkg/cmds"
 	"github.com/go-go-golems/glazed/pkg/cmds/fields"
 	"github.com/go-go-golems/glazed/pkg/cmds/values"
 	"github.com/go-go-golems/vm-system/pkg/vmclient"
-	"github.com/go-go-golems/vm-system/pkg/vmmodels"
 	"github.com/spf13/cobra"
 )
 
@@ -20,10 +20,13 @@ const (
 	templateCoreActionList                   = "list"
 	templateCoreActionGet                    = "get"
 	templateCoreActionDelete                 = "delete"
+	templateCoreActionUpdate                 = "update"
+	templateCoreActionClone                  = "clone"
 	templateCoreActionListAvailableModules   = "list-available-modules"
 	templateCoreActionListAvailableLibraries = "list-available-libraries"
 	templateCoreActionAddCapability          = "add-capability"
 	templateCoreActionListCapabilities       = "list-capabilities"
+	templateCoreActionRemoveCapability       = "remove-capability"
 )
 
 type templateCoreCommand struct {
@@ -34,7 +37,10 @@ type templateCoreCommand struct {
 var _ cmds.WriterCommand = &templateCoreCommand{}
 
 func (c *templateCoreCommand) RunIntoWriter(_ context.Context, vals *values.Values, w io.Writer) error {
-	client := vmclient.New(serverURL, nil)
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
 
 	switch c.action {
 	case templateCoreActionCreate:
@@ -44,8 +50,9 @@ func (c *templateCoreCommand) RunIntoWriter(_ context.Context, vals *values.Valu
 		}
 
 		template, err := client.CreateTemplate(context.Background(), vmclient.CreateTemplateRequest{
-			Name:   settings.Name,
-			Engine: settings.Engine,
+			Name:        settings.Name,
+			Engine:      settings.Engine,
+			WorkspaceID: settings.WorkspaceID,
 		})
 		if err != nil {
 			return err
@@ -89,6 +96,10 @@ func (c *templateCoreCommand) RunIntoWriter(_ context.Context, vals *values.Valu
 		_, _ = fmt.Fprintf(w, "Template: %s\n", template.Name)
 		_, _ = fmt.Fprintf(w, "ID: %s\n", template.ID)
 		_, _ = fmt.Fprintf(w, "Engine: %s\n", template.Engine)
+		if template.WorkspaceID != "" {
+			_, _ = fmt.Fprintf(w, "Workspace ID: %s\n", template.WorkspaceID)
+		}
+		_, _ = fmt.Fprintf(w, "Revision: %d\n", template.Revision)
 		_, _ = fmt.Fprintf(w, "Active: %v\n", template.IsActive)
 		_, _ = fmt.Fprintf(w, "Created: %s\n", template.CreatedAt.Format(time.RFC3339))
 		_, _ = fmt.Fprintf(w, "Updated: %s\n", template.UpdatedAt.Format(time.RFC3339))
@@ -144,8 +155,52 @@ func (c *templateCoreCommand) RunIntoWriter(_ context.Context, vals *values.Valu
 
 		_, _ = fmt.Fprintf(w, "Deleted template: %s\n", settings.TemplateID)
 		return nil
+	case templateCoreActionUpdate:
+		settings := &templateUpdateSettings{}
+		if err := decodeDefault(vals, settings); err != nil {
+			return err
+		}
+
+		request := vmclient.UpdateTemplateRequest{}
+		if settings.Name != "" {
+			request.Name = &settings.Name
+		}
+		if settings.Active != "" {
+			active, err := strconv.ParseBool(settings.Active)
+			if err != nil {
+				return fmt.Errorf("invalid --active value %q: expected true or false", settings.Active)
+			}
+			request.IsActive = &active
+		}
+		if request.Name == nil && request.IsActive == nil {
+			return fmt.Errorf("nothing to update: pass --name and/or --active")
+		}
+
+		template, err := client.UpdateTemplate(context.Background(), settings.TemplateID, request)
+		if err != nil {
+			return err
+		}
+
+		_, _ = fmt.Fprintf(w, "Updated template: %s (ID: %s, active: %v)\n", template.Name, template.ID, template.IsActive)
+		return nil
+	case templateCoreActionClone:
+		settings := &templateNameFlag{}
+		if err := decodeDefault(vals, settings); err != nil {
+			return err
+		}
+
+		template, err := client.CloneTemplate(context.Background(), settings.TemplateID, vmclient.CloneTemplateRequest{Name: settings.Name})
+		if err != nil {
+			return err
+		}
+
+		_, _ = fmt.Fprintf(w, "Cloned template %s into: %s (ID: %s)\n", settings.TemplateID, template.Name, template.ID)
+		return nil
 	case templateCoreActionListAvailableModules:
-		modules := vmmodels.BuiltinModules()
+		modules, err := client.ListAvailableModules(context.Background())
+		if err != nil {
+			return err
+		}
 		data, err := json.MarshalIndent(modules, "", "  ")
 		if err != nil {
 			return err
@@ -153,7 +208,10 @@ func (c *templateCoreCommand) RunIntoWriter(_ context.Context, vals *values.Valu
 		_, _ = fmt.Fprintln(w, string(data))
 		return nil
 	case templateCoreActionListAvailableLibraries:
-		libraries := vmmodels.BuiltinLibraries()
+		libraries, err := client.ListLibraries(context.Background())
+		if err != nil {
+			return err
+		}
 		data, err := json.MarshalIndent(libraries, "", "  ")
 		if err != nil {
 			return err
@@ -199,8 +257,8 @@ func (c *templateCoreCommand) RunIntoWriter(_ context.Context, vals *values.Valu
 			return nil
 		}
 
-		_, _ = fmt.Fprintf(w, "%-10s %-20s %-10s %-30s\n", "Kind", "Name", "Enabled", "Config")
-		_, _ = fmt.Fprintln(w, "--------------------------------------------------------------------------------")
+		_, _ = fmt.Fprintf(w, "%-36s %-10s %-20s %-10s %-30s\n", "ID", "Kind", "Name", "Enabled", "Config")
+		_, _ = fmt.Fprintln(w, "---------------------------------------------------------------------------------------------------------------------")
 		for _, capability := range capabilities {
 			enabledText := "no"
 			if capability.Enabled {
@@ -210,8 +268,20 @@ func (c *templateCoreCommand) RunIntoWriter(_ context.Context, vals *values.Valu
 			if len(configText) > 30 {
 				configText = configText[:27] + "..."
 			}
-			_, _ = fmt.Fprintf(w, "%-10s %-20s %-10s %-30s\n", capability.Kind, capability.Name, enabledText, configText)
+			_, _ = fmt.Fprintf(w, "%-36s %-10s %-20s %-10s %-30s\n", capability.ID, capability.Kind, capability.Name, enabledText, configText)
+		}
+		return nil
+	case templateCoreActionRemoveCapability:
+		settings := &templateCapabilityIDArgs{}
+		if err := decodeDefault(vals, settings); err != nil {
+			return err
 		}
+
+		if err := client.DeleteTemplateCapability(context.Background(), settings.TemplateID, settings.CapabilityID); err != nil {
+			return err
+		}
+
+		_, _ = fmt.Fprintf(w, "Removed capability %s from template %s\n", settings.CapabilityID, settings.TemplateID)
 		return nil
 	default:
 		return fmt.Errorf("unknown template core action: %s", c.action)
@@ -227,6 +297,7 @@ func newTemplateCreateCommand() *cobra.Command {
 			[]*fields.Definition{
 				fields.New("name", fields.TypeString, fields.WithHelp("Template name (required)"), fields.WithRequired(true)),
 				fields.New("engine", fields.TypeString, fields.WithDefault("goja"), fields.WithHelp("Engine type (goja, quickjs, node, custom)")),
+				fields.New("workspace-id", fields.TypeString, fields.WithHelp("Owning workspace (default: shared, or the token's workspace)")),
 			},
 			nil,
 			false,
@@ -290,7 +361,7 @@ func newTemplateListAvailableModulesCommand() *cobra.Command {
 		CommandDescription: commandDescription(
 			"list-available-modules",
 			"List configurable native module catalog",
-			"List configurable native module catalog (JavaScript built-ins are always available and not template-configurable).",
+			"List the daemon's configurable module catalog, including host modules registered by embedding programs (JavaScript built-ins are always available and not template-configurable).",
 			nil,
 			nil,
 			false,
@@ -304,8 +375,8 @@ func newTemplateListAvailableLibrariesCommand() *cobra.Command {
 	command := &templateCoreCommand{
 		CommandDescription: commandDescription(
 			"list-available-libraries",
-			"List built-in library catalog",
-			"List built-in library catalog.",
+			"List the library catalog",
+			"List built-in and registered libraries that templates can use.",
 			nil,
 			nil,
 			false,
@@ -349,3 +420,60 @@ func newTemplateListCapabilitiesCommand() *cobra.Command {
 	}
 	return buildCobraCommand(command)
 }
+
+func newTemplateUpdateCommand() *cobra.Command {
+	command := &templateCoreCommand{
+		CommandDescription: commandDescription(
+			"update",
+			"Rename or (de)activate a template",
+			"Rename a template and/or toggle whether it is active. Inactive templates keep their existing sessions but reject new ones.",
+			[]*fields.Definition{
+				fields.New("name", fields.TypeString, fields.WithDefault(""), fields.WithHelp("New template name")),
+				fields.New("active", fields.TypeString, fields.WithDefault(""), fields.WithHelp("Set active state (true, false)")),
+			},
+			[]*fields.Definition{
+				fields.New("template-id", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Template ID")),
+			},
+			false,
+		),
+		action: templateCoreActionUpdate,
+	}
+	return buildCobraCommand(command)
+}
+
+func newTemplateCloneCommand() *cobra.Command {
+	command := &templateCoreCommand{
+		CommandDescription: commandDescription(
+			"clone",
+			"Clone a template",
+			"Copy a template's settings, modules, libraries, capabilities, and startup files into a new template. The name defaults to <name>-copy.",
+			[]*fields.Definition{
+				fields.New("name", fields.TypeString, fields.WithDefault(""), fields.WithHelp("Name for the new template")),
+			},
+			[]*fields.Definition{
+				fields.New("template-id", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Template ID")),
+			},
+			false,
+		),
+		action: templateCoreActionClone,
+	}
+	return buildCobraCommand(command)
+}
+
+func newTemplateRemoveCapabilityCommand() *cobra.Command {
+	command := &templateCoreCommand{
+		CommandDescription: commandDescription(
+			"remove-capability",
+			"Remove a capability from a template",
+			"Remove a capability from a template by capability ID (see list-capabilities).",
+			nil,
+			[]*fields.Definition{
+				fields.New("template-id", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Template ID")),
+				fields.New("capability-id", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Capability ID")),
+			},
+			false,
+		),
+		action: templateCoreActionRemoveCapability,
+	}
+	return buildCobraCommand(command)
+}
@@ -26,7 +26,10 @@ type templateLibrariesCommand struct {
 var _ cmds.WriterCommand = &templateLibrariesCommand{}
 
 func (c *templateLibrariesCommand) RunIntoWriter(_ context.Context, vals *values.Values, w io.Writer) error {
-	client := vmclient.New(serverURL, nil)
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
 
 	switch c.action {
 	case templateLibrariesActionAdd:
@@ -83,7 +86,7 @@ func newTemplateAddLibraryCommand() *cobra.Command {
 			"Add a library to a template",
 			"Add a library to a template.",
 			[]*fields.Definition{
-				fields.New("name", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Library name (required)")),
+				fields.New("name", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Library reference: name@version, or a name with a single version (required)")),
 			},
 			[]*fields.Definition{fields.New("template-id", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Template ID"))},
 			false,
@@ -100,7 +103,7 @@ func newTemplateRemoveLibraryCommand() *cobra.Command {
 			"Remove a library from a template",
 			"Remove a library from a template.",
 			[]*fields.Definition{
-				fields.New("name", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Library name (required)")),
+				fields.New("name", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Library reference: name@version, or a name with a single version (required)")),
 			},
 			[]*fields.Definition{fields.New("template-id", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Template ID"))},
 			false,
@@ -26,7 +26,10 @@ type templateModulesCommand struct {
 var _ cmds.WriterCommand = &templateModulesCommand{}
 
 func (c *templateModulesCommand) RunIntoWriter(_ context.Context, vals *values.Values, w io.Writer) error {
-	client := vmclient.New(serverURL, nil)
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
 
 	switch c.action {
 	case templateModulesActionAdd:
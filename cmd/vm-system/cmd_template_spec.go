@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/vm-system/pkg/vmclient"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	templateSpecActionExport    = "export"
+	templateSpecActionApply     = "apply"
+	templateSpecActionRevisions = "revisions"
+)
+
+type templateExportSettings struct {
+	TemplateID string `glazed:"template-id"`
+	Format     string `glazed:"format"`
+}
+
+type templateApplySettings struct {
+	File   string `glazed:"file"`
+	DryRun bool   `glazed:"dry-run"`
+}
+
+type templateRevisionsSettings struct {
+	TemplateID string `glazed:"template-id"`
+	Revision   int    `glazed:"revision"`
+}
+
+type templateSpecCommand struct {
+	*cmds.CommandDescription
+	action string
+}
+
+var _ cmds.WriterCommand = &templateSpecCommand{}
+
+func (c *templateSpecCommand) RunIntoWriter(_ context.Context, vals *values.Values, w io.Writer) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	switch c.action {
+	case templateSpecActionExport:
+		settings := &templateExportSettings{}
+		if err := decodeDefault(vals, settings); err != nil {
+			return err
+		}
+
+		spec, err := client.ExportTemplate(context.Background(), settings.TemplateID)
+		if err != nil {
+			return err
+		}
+
+		var data []byte
+		switch strings.ToLower(settings.Format) {
+		case "json":
+			data, err = json.MarshalIndent(spec, "", "  ")
+			data = append(data, '\n')
+		case "yaml", "":
+			var buf bytes.Buffer
+			encoder := yaml.NewEncoder(&buf)
+			encoder.SetIndent(2)
+			err = encoder.Encode(spec)
+			data = buf.Bytes()
+		default:
+			return fmt.Errorf("unsupported format %q (expected yaml or json)", settings.Format)
+		}
+		if err != nil {
+			return err
+		}
+		_, _ = w.Write(data)
+		return nil
+	case templateSpecActionApply:
+		settings := &templateApplySettings{}
+		if err := decodeDefault(vals, settings); err != nil {
+			return err
+		}
+
+		spec, err := readTemplateSpec(settings.File)
+		if err != nil {
+			return err
+		}
+
+		result, err := client.ApplyTemplate(context.Background(), spec, settings.DryRun)
+		if err != nil {
+			return err
+		}
+
+		verb := "Applied"
+		if result.DryRun {
+			verb = "Would apply"
+		}
+		if result.Template != nil {
+			_, _ = fmt.Fprintf(w, "%s template: %s (ID: %s)\n", verb, spec.Name, result.Template.ID)
+		} else {
+			_, _ = fmt.Fprintf(w, "%s template: %s\n", verb, spec.Name)
+		}
+		if len(result.Changes) == 0 {
+			_, _ = fmt.Fprintln(w, "No changes")
+			return nil
+		}
+		for _, change := range result.Changes {
+			_, _ = fmt.Fprintf(w, "  %s %s %s\n", templateChangeMarker(change.Action), change.Resource, change.Name)
+		}
+		return nil
+	case templateSpecActionRevisions:
+		settings := &templateRevisionsSettings{}
+		if err := decodeDefault(vals, settings); err != nil {
+			return err
+		}
+
+		var revisions []vmclient.TemplateRevisionResponse
+		if settings.Revision > 0 {
+			revision, err := client.GetTemplateRevision(context.Background(), settings.TemplateID, settings.Revision)
+			if err != nil {
+				return err
+			}
+			revisions = append(revisions, *revision)
+		} else {
+			var err error
+			revisions, err = client.ListTemplateRevisions(context.Background(), settings.TemplateID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(revisions) == 0 {
+			_, _ = fmt.Fprintln(w, "No revisions found")
+			return nil
+		}
+		for _, revision := range revisions {
+			_, _ = fmt.Fprintf(w, "Revision %d (%s)\n", revision.Revision, revision.CreatedAt.Format(time.RFC3339))
+			for _, change := range revision.Changes {
+				_, _ = fmt.Fprintf(w, "  %s %s %s\n", templateChangeMarker(change.Action), change.Resource, change.Name)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown template spec action: %s", c.action)
+	}
+}
+
+// readTemplateSpec reads a YAML or JSON template spec from path, or from stdin
+// when path is "-".
+func readTemplateSpec(path string) (*vmmodels.TemplateSpec, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read template spec: %w", err)
+	}
+
+	// JSON is valid YAML, so one decoder handles both formats.
+	spec := &vmmodels.TemplateSpec{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(spec); err != nil {
+		return nil, fmt.Errorf("parse template spec %s: %w", path, err)
+	}
+	return spec, nil
+}
+
+func templateChangeMarker(action string) string {
+	switch action {
+	case "add", "create":
+		return "+"
+	case "remove":
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+func newTemplateExportCommand() *cobra.Command {
+	command := &templateSpecCommand{
+		CommandDescription: commandDescription(
+			"export",
+			"Export a template as a declarative spec",
+			"Export a template (engine, settings, modules, libraries, capabilities, startup files) as a YAML or JSON spec suitable for version control and 'template apply'.",
+			[]*fields.Definition{
+				fields.New("format", fields.TypeString, fields.WithDefault("yaml"), fields.WithHelp("Output format (yaml, json)")),
+			},
+			[]*fields.Definition{
+				fields.New("template-id", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Template ID")),
+			},
+			false,
+		),
+		action: templateSpecActionExport,
+	}
+	return buildCobraCommand(command)
+}
+
+func newTemplateApplyCommand() *cobra.Command {
+	command := &templateSpecCommand{
+		CommandDescription: commandDescription(
+			"apply",
+			"Create or update a template from a spec",
+			"Apply a YAML or JSON template spec. The template is matched by name: it is created if missing, otherwise updated so it matches the spec. Use --dry-run to preview the changes.",
+			[]*fields.Definition{
+				fields.New("file", fields.TypeString, fields.WithShortFlag("f"), fields.WithRequired(true), fields.WithHelp("Spec file path, or - for stdin (required)")),
+				fields.New("dry-run", fields.TypeBool, fields.WithDefault(false), fields.WithHelp("Show changes without applying them")),
+			},
+			nil,
+			false,
+		),
+		action: templateSpecActionApply,
+	}
+	return buildCobraCommand(command)
+}
+
+func newTemplateRevisionsCommand() *cobra.Command {
+	command := &templateSpecCommand{
+		CommandDescription: commandDescription(
+			"revisions",
+			"List template revisions",
+			"List the immutable revisions recorded for a template, with the changes each revision made. Sessions record the revision they were created from.",
+			[]*fields.Definition{
+				fields.New("revision", fields.TypeInteger, fields.WithDefault(0), fields.WithHelp("Show only this revision")),
+			},
+			[]*fields.Definition{
+				fields.New("template-id", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Template ID")),
+			},
+			false,
+		),
+		action: templateSpecActionRevisions,
+	}
+	return buildCobraCommand(command)
+}
@@ -4,18 +4,23 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
 	"strings"
 
 	"github.com/go-go-golems/glazed/pkg/cmds"
 	"github.com/go-go-golems/glazed/pkg/cmds/fields"
 	"github.com/go-go-golems/glazed/pkg/cmds/values"
 	"github.com/go-go-golems/vm-system/pkg/vmclient"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
 	"github.com/spf13/cobra"
 )
 
 const (
-	templateStartupActionAdd  = "add-startup"
-	templateStartupActionList = "list-startup"
+	templateStartupActionAdd     = "add-startup"
+	templateStartupActionList    = "list-startup"
+	templateStartupActionUpdate  = "update-startup"
+	templateStartupActionRemove  = "remove-startup"
+	templateStartupActionReorder = "reorder-startup"
 )
 
 type templateStartupCommand struct {
@@ -26,7 +31,10 @@ type templateStartupCommand struct {
 var _ cmds.WriterCommand = &templateStartupCommand{}
 
 func (c *templateStartupCommand) RunIntoWriter(_ context.Context, vals *values.Values, w io.Writer) error {
-	client := vmclient.New(serverURL, nil)
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
 
 	switch c.action {
 	case templateStartupActionAdd:
@@ -43,16 +51,55 @@ func (c *templateStartupCommand) RunIntoWriter(_ context.Context, vals *values.V
 		}
 		settings.Mode = mode
 
-		startup, err := client.AddTemplateStartupFile(context.Background(), settings.TemplateID, vmclient.AddTemplateStartupFileRequest{
+		request := vmclient.AddTemplateStartupFileRequest{
 			Path:       settings.Path,
 			OrderIndex: settings.OrderIndex,
 			Mode:       settings.Mode,
-		})
+		}
+		if settings.SourceFile != "" {
+			source, err := os.ReadFile(settings.SourceFile)
+			if err != nil {
+				return fmt.Errorf("read source file: %w", err)
+			}
+			request.Kind = vmmodels.StartupKindInline
+			request.Source = string(source)
+		}
+
+		startup, err := client.AddTemplateStartupFile(context.Background(), settings.TemplateID, request)
+		if err != nil {
+			return err
+		}
+
+		_, _ = fmt.Fprintf(w, "Added %s startup file: %s (order: %d) to template %s\n", startup.Kind, startup.Path, startup.OrderIndex, settings.TemplateID)
+		return nil
+	case templateStartupActionUpdate:
+		settings := &templateUpdateStartupSettings{}
+		if err := decodeDefault(vals, settings); err != nil {
+			return err
+		}
+
+		request := vmclient.UpdateTemplateStartupFileRequest{}
+		if settings.SourceFile != "" {
+			data, err := os.ReadFile(settings.SourceFile)
+			if err != nil {
+				return fmt.Errorf("read source file: %w", err)
+			}
+			source := string(data)
+			request.Source = &source
+		}
+		if settings.OrderIndex >= 0 {
+			request.OrderIndex = &settings.OrderIndex
+		}
+		if request.Source == nil && request.OrderIndex == nil {
+			return fmt.Errorf("nothing to update: pass --source-file and/or --order")
+		}
+
+		startup, err := client.UpdateTemplateStartupFile(context.Background(), settings.TemplateID, settings.StartupFileID, request)
 		if err != nil {
 			return err
 		}
 
-		_, _ = fmt.Fprintf(w, "Added startup file: %s (order: %d) to template %s\n", startup.Path, startup.OrderIndex, settings.TemplateID)
+		_, _ = fmt.Fprintf(w, "Updated startup file: %s (order: %d) in template %s\n", startup.Path, startup.OrderIndex, settings.TemplateID)
 		return nil
 	case templateStartupActionList:
 		settings := &templateIDArg{}
@@ -70,25 +117,57 @@ func (c *templateStartupCommand) RunIntoWriter(_ context.Context, vals *values.V
 			return nil
 		}
 
-		_, _ = fmt.Fprintf(w, "%-5s %-10s %-50s\n", "Order", "Mode", "Path")
-		_, _ = fmt.Fprintln(w, "----------------------------------------------------------------------")
-		for _, file := range files {
-			_, _ = fmt.Fprintf(w, "%-5d %-10s %-50s\n", file.OrderIndex, file.Mode, file.Path)
+		writeStartupFileTable(w, files)
+		return nil
+	case templateStartupActionRemove:
+		settings := &templateStartupFileIDArgs{}
+		if err := decodeDefault(vals, settings); err != nil {
+			return err
+		}
+
+		if err := client.DeleteTemplateStartupFile(context.Background(), settings.TemplateID, settings.StartupFileID); err != nil {
+			return err
+		}
+
+		_, _ = fmt.Fprintf(w, "Removed startup file %s from template %s\n", settings.StartupFileID, settings.TemplateID)
+		return nil
+	case templateStartupActionReorder:
+		settings := &templateReorderStartupSettings{}
+		if err := decodeDefault(vals, settings); err != nil {
+			return err
 		}
+
+		files, err := client.ReorderTemplateStartupFiles(context.Background(), settings.TemplateID, vmclient.ReorderTemplateStartupFilesRequest{
+			StartupFileIDs: settings.StartupFileIDs,
+		})
+		if err != nil {
+			return err
+		}
+
+		writeStartupFileTable(w, files)
 		return nil
 	default:
 		return fmt.Errorf("unknown template startup action: %s", c.action)
 	}
 }
 
+func writeStartupFileTable(w io.Writer, files []*vmmodels.VMStartupFile) {
+	_, _ = fmt.Fprintf(w, "%-36s %-5s %-7s %-10s %-50s\n", "ID", "Order", "Kind", "Mode", "Path")
+	_, _ = fmt.Fprintln(w, "-------------------------------------------------------------------------------------------------------------------")
+	for _, file := range files {
+		_, _ = fmt.Fprintf(w, "%-36s %-5d %-7s %-10s %-50s\n", file.ID, file.OrderIndex, file.Kind, file.Mode, file.Path)
+	}
+}
+
 func newTemplateAddStartupFileCommand() *cobra.Command {
 	command := &templateStartupCommand{
 		CommandDescription: commandDescription(
 			"add-startup",
 			"Add a startup file to a template",
-			"Add a startup file to a template.",
+			"Add a startup file to a template. By default the entry references a file in each session's worktree; with --source-file the local file's contents are stored in the template as an inline script that needs no worktree file.",
 			[]*fields.Definition{
-				fields.New("path", fields.TypeString, fields.WithRequired(true), fields.WithHelp("File path (required)")),
+				fields.New("path", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Worktree file path, or the entry name for inline scripts (required)")),
+				fields.New("source-file", fields.TypeString, fields.WithDefault(""), fields.WithHelp("Local file whose contents become an inline startup script")),
 				fields.New("mode", fields.TypeString, fields.WithDefault("eval"), fields.WithHelp("Startup mode (only eval is currently supported)")),
 				fields.New("order", fields.TypeInteger, fields.WithDefault(10), fields.WithHelp("Order index")),
 			},
@@ -114,3 +193,60 @@ func newTemplateListStartupFilesCommand() *cobra.Command {
 	}
 	return buildCobraCommand(command)
 }
+
+func newTemplateUpdateStartupFileCommand() *cobra.Command {
+	command := &templateStartupCommand{
+		CommandDescription: commandDescription(
+			"update-startup",
+			"Edit a template's startup file",
+			"Change a startup file's order, or replace the source of an inline startup script with the contents of a local file.",
+			[]*fields.Definition{
+				fields.New("source-file", fields.TypeString, fields.WithDefault(""), fields.WithHelp("Local file with the new inline script source")),
+				fields.New("order", fields.TypeInteger, fields.WithDefault(-1), fields.WithHelp("New order index (unchanged if negative)")),
+			},
+			[]*fields.Definition{
+				fields.New("template-id", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Template ID")),
+				fields.New("startup-file-id", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Startup file ID")),
+			},
+			false,
+		),
+		action: templateStartupActionUpdate,
+	}
+	return buildCobraCommand(command)
+}
+
+func newTemplateRemoveStartupFileCommand() *cobra.Command {
+	command := &templateStartupCommand{
+		CommandDescription: commandDescription(
+			"remove-startup",
+			"Remove a startup file from a template",
+			"Remove a startup file from a template by startup file ID (see list-startup).",
+			nil,
+			[]*fields.Definition{
+				fields.New("template-id", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Template ID")),
+				fields.New("startup-file-id", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Startup file ID")),
+			},
+			false,
+		),
+		action: templateStartupActionRemove,
+	}
+	return buildCobraCommand(command)
+}
+
+func newTemplateReorderStartupFilesCommand() *cobra.Command {
+	command := &templateStartupCommand{
+		CommandDescription: commandDescription(
+			"reorder-startup",
+			"Reorder a template's startup files",
+			"Set the startup order by listing every startup file ID of the template in the desired order. Order indexes are reassigned as 10, 20, 30, ...",
+			nil,
+			[]*fields.Definition{
+				fields.New("template-id", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Template ID")),
+				fields.New("startup-file-ids", fields.TypeStringList, fields.WithRequired(true), fields.WithHelp("Startup file IDs in execution order")),
+			},
+			false,
+		),
+		action: templateStartupActionReorder,
+	}
+	return buildCobraCommand(command)
+}
@@ -14,6 +14,15 @@ func TestTemplateCommandIncludesModuleAndLibrarySubcommands(t *testing.T) {
 		"list-libraries":           true,
 		"list-available-modules":   true,
 		"list-available-libraries": true,
+		"export":                   true,
+		"apply":                    true,
+		"revisions":                true,
+		"update":                   true,
+		"clone":                    true,
+		"remove-capability":        true,
+		"update-startup":           true,
+		"remove-startup":           true,
+		"reorder-startup":          true,
 	}
 
 	seen := map[string]bool{}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/vm-system/pkg/vmclient"
+	"github.com/spf13/cobra"
+)
+
+const (
+	workspaceActionCreate = "create"
+	workspaceActionList   = "list"
+	workspaceActionGet    = "get"
+)
+
+type workspaceCreateSettings struct {
+	ID   string `glazed:"id"`
+	Name string `glazed:"name"`
+}
+
+type workspaceIDArg struct {
+	WorkspaceID string `glazed:"workspace-id"`
+}
+
+type workspaceCommand struct {
+	*cmds.CommandDescription
+	action string
+}
+
+var _ cmds.WriterCommand = &workspaceCommand{}
+
+func (c *workspaceCommand) RunIntoWriter(ctx context.Context, vals *values.Values, w io.Writer) error {
+	switch c.action {
+	case workspaceActionCreate:
+		settings := &workspaceCreateSettings{}
+		if err := decodeDefault(vals, settings); err != nil {
+			return err
+		}
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
+		workspace, err := client.CreateWorkspace(ctx, vmclient.CreateWorkspaceRequest{
+			ID:   settings.ID,
+			Name: settings.Name,
+		})
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(w, "Created workspace: %s (%s)\n", workspace.ID, workspace.Name)
+		return nil
+	case workspaceActionList:
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
+		workspaces, err := client.ListWorkspaces(ctx)
+		if err != nil {
+			return err
+		}
+		if len(workspaces) == 0 {
+			_, _ = fmt.Fprintln(w, "No workspaces.")
+			return nil
+		}
+		_, _ = fmt.Fprintf(w, "Workspaces (%d):\n\n", len(workspaces))
+		for _, workspace := range workspaces {
+			_, _ = fmt.Fprintf(w, "  %s - %s (created %s)\n", workspace.ID, workspace.Name, workspace.CreatedAt.Format("2006-01-02 15:04:05"))
+		}
+		return nil
+	case workspaceActionGet:
+		settings := &workspaceIDArg{}
+		if err := decodeDefault(vals, settings); err != nil {
+			return err
+		}
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
+		workspace, err := client.GetWorkspace(ctx, settings.WorkspaceID)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(w, "Workspace: %s\n", workspace.ID)
+		_, _ = fmt.Fprintf(w, "Name: %s\n", workspace.Name)
+		_, _ = fmt.Fprintf(w, "Created: %s\n", workspace.CreatedAt.Format("2006-01-02 15:04:05"))
+		return nil
+	default:
+		return fmt.Errorf("unknown workspace action: %s", c.action)
+	}
+}
+
+func newWorkspaceCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "workspace",
+		Short: "Manage workspaces",
+		Long:  "Workspaces group sessions, executions and templates. Tokens bound to a workspace only see and change that workspace's resources.",
+	}
+
+	cmd.AddCommand(
+		newWorkspaceCreateCommand(),
+		newWorkspaceListCommand(),
+		newWorkspaceGetCommand(),
+	)
+
+	return cmd
+}
+
+func newWorkspaceCreateCommand() *cobra.Command {
+	return buildCobraCommand(&workspaceCommand{
+		CommandDescription: commandDescription(
+			"create",
+			"Create a workspace",
+			"Register a workspace. Workspaces are also registered the first time a session, template or token names them.",
+			[]*fields.Definition{
+				fields.New("id", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Workspace ID (required)")),
+				fields.New("name", fields.TypeString, fields.WithHelp("Display name (default the ID)")),
+			},
+			nil,
+			false,
+		),
+		action: workspaceActionCreate,
+	})
+}
+
+func newWorkspaceListCommand() *cobra.Command {
+	return buildCobraCommand(&workspaceCommand{
+		CommandDescription: commandDescription(
+			"list",
+			"List workspaces",
+			"List the workspaces visible to the caller.",
+			nil,
+			nil,
+			false,
+		),
+		action: workspaceActionList,
+	})
+}
+
+func newWorkspaceGetCommand() *cobra.Command {
+	return buildCobraCommand(&workspaceCommand{
+		CommandDescription: commandDescription(
+			"get",
+			"Get workspace details",
+			"Show a workspace.",
+			nil,
+			[]*fields.Definition{fields.New("workspace-id", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Workspace ID"))},
+			false,
+		),
+		action: workspaceActionGet,
+	})
+}
@@ -8,12 +8,25 @@ import (
 	"github.com/go-go-golems/glazed/pkg/help"
 	help_cmd "github.com/go-go-golems/glazed/pkg/help/cmd"
 	"github.com/go-go-golems/vm-system/pkg/doc"
+	"github.com/go-go-golems/vm-system/pkg/vmclient"
+	"github.com/go-go-golems/vm-system/pkg/vmdaemon"
 	"github.com/spf13/cobra"
 )
 
 var (
-	dbPath    string
-	serverURL string
+	dbPath     string
+	dataDir    string
+	serverURL  string
+	apiToken   string
+	caCert     string
+	clientCert string
+	clientKey  string
+
+	// inProcess is set when --db is given without --server-url: client
+	// commands then run against a core in this process instead of a daemon.
+	inProcess bool
+	// localApp hosts that core once a command asks for a client.
+	localApp *vmdaemon.App
 )
 
 func newRootCommand(helpSystem *help.HelpSystem) *cobra.Command {
@@ -22,6 +35,7 @@ func newRootCommand(helpSystem *help.HelpSystem) *cobra.Command {
 		Short: "JavaScript VM system with goja",
 		Long:  `A VM subsystem that manages JavaScript execution with goja, integrating with dual-storage workspaces.`,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			inProcess = cmd.Flags().Changed("db") && !cmd.Flags().Changed("server-url")
 			return logging.InitLoggerFromCobra(cmd)
 		},
 	}
@@ -29,7 +43,12 @@ func newRootCommand(helpSystem *help.HelpSystem) *cobra.Command {
 	_ = logging.AddLoggingSectionToRootCommand(rootCmd, "vm-system")
 
 	rootCmd.PersistentFlags().StringVar(&dbPath, "db", "vm-system.db", "Path to SQLite database")
-	rootCmd.PersistentFlags().StringVar(&serverURL, "server-url", "http://127.0.0.1:3210", "Daemon base URL for client mode commands")
+	rootCmd.PersistentFlags().StringVar(&dataDir, "data-dir", "", "Daemon data directory holding the library cache (default: .vm-cache next to --db)")
+	rootCmd.PersistentFlags().StringVar(&serverURL, "server-url", "http://127.0.0.1:3210", "Daemon base URL for client mode commands, or unix:///path/to/sock")
+	rootCmd.PersistentFlags().StringVar(&apiToken, "token", os.Getenv("VM_SYSTEM_TOKEN"), "API token for daemons started with --require-auth (default $VM_SYSTEM_TOKEN)")
+	rootCmd.PersistentFlags().StringVar(&caCert, "ca-cert", "", "PEM CA bundle to trust for an https --server-url, in addition to the system roots")
+	rootCmd.PersistentFlags().StringVar(&clientCert, "client-cert", "", "PEM client certificate to present to daemons that verify client certificates")
+	rootCmd.PersistentFlags().StringVar(&clientKey, "client-key", "", "PEM private key for --client-cert")
 
 	rootCmd.AddCommand(
 		newServeCommand(),
@@ -37,18 +56,59 @@ func newRootCommand(helpSystem *help.HelpSystem) *cobra.Command {
 		newSessionCommand(),
 		newExecCommand(),
 		newOpsCommand(),
+		newAuthCommand(),
+		newWorkspaceCommand(),
+		newMCPCommand(),
+		newRunCommand(),
 		libsCmd,
 	)
 
 	return rootCmd
 }
 
+// resolvedDataDir returns --data-dir, or the daemon default derived from --db.
+func resolvedDataDir() string {
+	if dataDir != "" {
+		return dataDir
+	}
+	return vmdaemon.DefaultDataDir(dbPath)
+}
+
+// newClient returns a daemon client for --server-url sending --token and
+// using --ca-cert, --client-cert and --client-key for TLS. With --db and no
+// --server-url it instead opens the database and serves the client's calls
+// in process.
+func newClient() (vmclient.API, error) {
+	if !inProcess {
+		return vmclient.New(serverURL, nil,
+			vmclient.WithToken(apiToken),
+			vmclient.WithTLSFiles(caCert, clientCert, clientKey),
+		), nil
+	}
+	if localApp == nil {
+		cfg := vmdaemon.DefaultConfig(dbPath)
+		cfg.DataDir = resolvedDataDir()
+		// A daemon may be serving the same database; its sessions are live.
+		cfg.KeepSessions = true
+		app, err := vmdaemon.New(cfg, nil)
+		if err != nil {
+			return nil, err
+		}
+		localApp = app
+	}
+	return vmclient.NewLocal(localApp.Core()), nil
+}
+
 func main() {
 	helpSystem := help.NewHelpSystem()
 	_ = doc.AddDocToHelpSystem(helpSystem)
 	rootCmd := newRootCommand(helpSystem)
 
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	if localApp != nil {
+		_ = localApp.Close()
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-go-golems/glazed/pkg/help"
+	"github.com/go-go-golems/vm-system/pkg/vmclient"
+	"github.com/go-go-golems/vm-system/pkg/vmdaemon"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+func TestDBClientLeavesDaemonSessionsOpen(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "vm-system.db")
+	daemon, err := vmdaemon.New(vmdaemon.DefaultConfig(dbPath), nil)
+	if err != nil {
+		t.Fatalf("new daemon app: %v", err)
+	}
+	defer daemon.Close()
+
+	ctx := context.Background()
+	client := vmclient.NewLocal(daemon.Core())
+	template, err := client.CreateTemplate(ctx, vmclient.CreateTemplateRequest{Name: "shared-db"})
+	if err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	session, err := client.CreateSession(ctx, vmclient.CreateSessionRequest{
+		TemplateID:    template.ID,
+		WorkspaceID:   "ws-shared",
+		BaseCommitOID: "deadbeef",
+		WorktreePath:  t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+
+	root := newRootCommand(help.NewHelpSystem())
+	root.SetArgs([]string{"--db", dbPath, "session", "list"})
+	err = root.Execute()
+	if localApp != nil {
+		_ = localApp.Close()
+		localApp = nil
+	}
+	if err != nil {
+		t.Fatalf("run --db client: %v", err)
+	}
+
+	got, err := client.GetSession(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("get session: %v", err)
+	}
+	if got.Status != string(vmmodels.SessionReady) {
+		t.Fatalf("expected session to stay ready, got %q (%s)", got.Status, got.LastError)
+	}
+	exec, err := client.ExecuteREPL(ctx, vmclient.ExecuteREPLRequest{SessionID: session.ID, Input: "1 + 1"})
+	if err != nil || exec.Status != "ok" {
+		t.Fatalf("expected the daemon session to keep executing, got %+v (%v)", exec, err)
+	}
+}
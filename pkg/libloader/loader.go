@@ -2,11 +2,13 @@ package libloader
 
 import (
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,7 +17,27 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-// LibraryCache manages downloaded library files
+// DefaultDataDir is the data directory used when none is configured,
+// relative to the working directory.
+const DefaultDataDir = ".vm-cache"
+
+// DefaultCacheDir is the library cache under DefaultDataDir.
+var DefaultCacheDir = CacheDir(DefaultDataDir)
+
+// ErrChecksumMismatch reports library code whose SHA-256 differs from the
+// expected or pinned one.
+var ErrChecksumMismatch = errors.New("library checksum mismatch")
+
+// CacheDir returns the library cache directory under a data directory.
+func CacheDir(dataDir string) string {
+	return filepath.Join(dataDir, "libraries")
+}
+
+// LibraryCache manages downloaded library files. Code is content-addressed:
+// it is stored as sha256/<hex>.js, and refs/<ref> pins each library ref to
+// the SHA-256 it must have. A library's expected SHA-256, when set, is checked
+// on download; otherwise the first cached copy sets the pin. Every load
+// verifies the code against the pin.
 type LibraryCache struct {
 	cacheDir string
 	mu       sync.RWMutex
@@ -25,8 +47,10 @@ type LibraryCache struct {
 
 // NewLibraryCache creates a new library cache
 func NewLibraryCache(cacheDir string) (*LibraryCache, error) {
-	if err := os.MkdirAll(cacheDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	for _, dir := range []string{cacheDir, filepath.Join(cacheDir, "sha256"), filepath.Join(cacheDir, "refs")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create cache directory: %w", err)
+		}
 	}
 
 	return &LibraryCache{
@@ -86,14 +110,16 @@ func (lc *LibraryCache) Download(lib vmmodels.Library) error {
 	lc.mu.Lock()
 	defer lc.mu.Unlock()
 
-	// Generate cache filename based on library ID and version
-	filename := fmt.Sprintf("%s-%s.js", lib.ID, lib.Version)
-	cachePath := filepath.Join(lc.cacheDir, filename)
+	if err := lc.adoptLegacy(lib); err != nil {
+		return err
+	}
 
 	// Check if already cached
-	if _, err := os.Stat(cachePath); err == nil {
+	if _, cachePath, err := lc.verify(lib); err == nil {
 		lc.cached[lib.ID] = cachePath
 		return nil
+	} else if errors.Is(err, ErrChecksumMismatch) {
+		lc.logger.Warn().Err(err).Str("library", lib.ID).Msg("cached library is corrupt; downloading again")
 	}
 
 	// Download from source
@@ -111,28 +137,172 @@ func (lc *LibraryCache) Download(lib vmmodels.Library) error {
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
-	// Create temporary file
-	tmpPath := cachePath + ".tmp"
-	tmpFile, err := os.Create(tmpPath)
+	code, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to create temp file: %w", err)
+		return fmt.Errorf("failed to read library: %w", err)
 	}
-	defer tmpFile.Close()
 
-	// Copy content
-	_, err = io.Copy(tmpFile, resp.Body)
+	_, err = lc.put(lib, code)
+	return err
+}
+
+// Store writes code for a registered library into the cache and pins it,
+// returning its SHA-256.
+func (lc *LibraryCache) Store(lib vmmodels.Library, code []byte) (string, error) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	return lc.put(lib, code)
+}
+
+// Load returns the verified code of a library and its SHA-256. A flat
+// <ref>.js file left by older versions is adopted into the cache on first load.
+func (lc *LibraryCache) Load(lib vmmodels.Library) ([]byte, string, error) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	if err := lc.adoptLegacy(lib); err != nil {
+		return nil, "", err
+	}
+	code, cachePath, err := lc.verify(lib)
 	if err != nil {
-		os.Remove(tmpPath)
-		return fmt.Errorf("failed to write library: %w", err)
+		return nil, "", err
 	}
+	lc.cached[lib.ID] = cachePath
+	return code, lc.pin(libraryRef(lib)), nil
+}
 
-	// Rename to final location
-	if err := os.Rename(tmpPath, cachePath); err != nil {
-		os.Remove(tmpPath)
-		return fmt.Errorf("failed to move library: %w", err)
+// Remove unpins a library and deletes its code unless another ref pins the
+// same content.
+func (lc *LibraryCache) Remove(lib vmmodels.Library) error {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	ref := libraryRef(lib)
+	sum := lc.pin(ref)
+	if err := os.Remove(lc.refPath(ref)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove library: %w", err)
+	}
+	delete(lc.cached, lib.ID)
+	if sum == "" {
+		return nil
+	}
+
+	entries, err := os.ReadDir(filepath.Join(lc.cacheDir, "refs"))
+	if err != nil {
+		return fmt.Errorf("failed to read cache directory: %w", err)
+	}
+	for _, entry := range entries {
+		if lc.pin(entry.Name()) == sum {
+			return nil
+		}
+	}
+	if err := os.Remove(lc.blobPath(sum)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove library: %w", err)
+	}
+	return nil
+}
+
+// put verifies code against the expected and pinned SHA-256, then writes the
+// content file and the pin. Callers hold lc.mu.
+func (lc *LibraryCache) put(lib vmmodels.Library, code []byte) (string, error) {
+	ref := libraryRef(lib)
+	sum := fmt.Sprintf("%x", sha256.Sum256(code))
+	if lib.SHA256 != "" && lib.SHA256 != sum {
+		return "", fmt.Errorf("%w: %s: expected %s, got %s", ErrChecksumMismatch, ref, lib.SHA256, sum)
+	}
+	if pinned := lc.pin(ref); pinned != "" && pinned != sum {
+		return "", fmt.Errorf("%w: %s: pinned %s, got %s", ErrChecksumMismatch, ref, pinned, sum)
+	}
+
+	cachePath := lc.blobPath(sum)
+	if err := writeFileAtomic(cachePath, code); err != nil {
+		return "", err
+	}
+	if err := writeFileAtomic(lc.refPath(ref), []byte(sum+"\n")); err != nil {
+		return "", err
 	}
 
 	lc.cached[lib.ID] = cachePath
+	return sum, nil
+}
+
+// verify reads the cached code of lib and checks it against the pin and the
+// expected SHA-256. Callers hold lc.mu.
+func (lc *LibraryCache) verify(lib vmmodels.Library) ([]byte, string, error) {
+	ref := libraryRef(lib)
+	pinned := lc.pin(ref)
+	if pinned == "" {
+		return nil, "", fmt.Errorf("library %s not cached", ref)
+	}
+	if lib.SHA256 != "" && lib.SHA256 != pinned {
+		return nil, "", fmt.Errorf("%w: %s: expected %s, pinned %s", ErrChecksumMismatch, ref, lib.SHA256, pinned)
+	}
+
+	cachePath := lc.blobPath(pinned)
+	code, err := os.ReadFile(cachePath)
+	if err != nil {
+		return nil, "", fmt.Errorf("library %s not cached: %w", ref, err)
+	}
+	if sum := fmt.Sprintf("%x", sha256.Sum256(code)); sum != pinned {
+		return nil, "", fmt.Errorf("%w: %s: pinned %s, got %s", ErrChecksumMismatch, ref, pinned, sum)
+	}
+	return code, cachePath, nil
+}
+
+// adoptLegacy moves a flat <ref>.js file into the content-addressed layout.
+// Callers hold lc.mu.
+func (lc *LibraryCache) adoptLegacy(lib vmmodels.Library) error {
+	ref := libraryRef(lib)
+	if lc.pin(ref) != "" {
+		return nil
+	}
+	legacyPath := filepath.Join(lc.cacheDir, ref+".js")
+	code, err := os.ReadFile(legacyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read library: %w", err)
+	}
+	if _, err := lc.put(lib, code); err != nil {
+		return err
+	}
+	return os.Remove(legacyPath)
+}
+
+func (lc *LibraryCache) pin(ref string) string {
+	data, err := os.ReadFile(lc.refPath(ref))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func (lc *LibraryCache) blobPath(sum string) string {
+	return filepath.Join(lc.cacheDir, "sha256", sum+".js")
+}
+
+func (lc *LibraryCache) refPath(ref string) string {
+	return filepath.Join(lc.cacheDir, "refs", ref)
+}
+
+func libraryRef(lib vmmodels.Library) string {
+	if lib.Ref != "" {
+		return lib.Ref
+	}
+	return vmmodels.LibraryRef(lib.ID, lib.Version)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write library: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to move library: %w", err)
+	}
 	return nil
 }
 
@@ -164,34 +334,18 @@ func (lc *LibraryCache) LoadLibraryCode(libraryID string) (string, error) {
 	return string(data), nil
 }
 
-// LoadExistingCache scans the cache directory and loads existing libraries
+// LoadExistingCache registers the builtin libraries already in the cache,
+// adopting flat files left by older versions.
 func (lc *LibraryCache) LoadExistingCache() error {
 	lc.mu.Lock()
 	defer lc.mu.Unlock()
 
-	entries, err := os.ReadDir(lc.cacheDir)
-	if err != nil {
-		return fmt.Errorf("failed to read cache directory: %w", err)
-	}
-
-	libraries := vmmodels.BuiltinLibraries()
-	libraryMap := make(map[string]vmmodels.Library)
-	for _, lib := range libraries {
-		libraryMap[lib.ID] = lib
-	}
-
-	for _, entry := range entries {
-		if entry.IsDir() || filepath.Ext(entry.Name()) != ".js" {
-			continue
+	for _, lib := range vmmodels.BuiltinLibraries() {
+		if err := lc.adoptLegacy(lib); err != nil {
+			return err
 		}
-
-		// Try to match filename to library
-		for id, lib := range libraryMap {
-			expectedName := fmt.Sprintf("%s-%s.js", lib.ID, lib.Version)
-			if entry.Name() == expectedName {
-				lc.cached[id] = filepath.Join(lc.cacheDir, entry.Name())
-				break
-			}
+		if pinned := lc.pin(lib.Ref); pinned != "" {
+			lc.cached[lib.ID] = lc.blobPath(pinned)
 		}
 	}
 
@@ -2,6 +2,7 @@ package libloader
 
 import (
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -37,8 +38,12 @@ func TestLoadExistingCacheDiscoversBuiltinLibrary(t *testing.T) {
 	if err != nil {
 		t.Fatalf("expected lodash to be discovered, got %v", err)
 	}
-	if filepath.Base(gotPath) != "lodash-4.17.21.js" {
-		t.Fatalf("expected discovered filename lodash-4.17.21.js, got %q", filepath.Base(gotPath))
+	expectedName := fmt.Sprintf("%x.js", sha256.Sum256([]byte("var _ = {};")))
+	if filepath.Base(gotPath) != expectedName {
+		t.Fatalf("expected legacy file adopted as %s, got %q", expectedName, filepath.Base(gotPath))
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, "lodash-4.17.21.js")); !os.IsNotExist(err) {
+		t.Fatalf("expected legacy file to be moved, stat err=%v", err)
 	}
 
 	if _, err := lc.GetLibraryPath("custom"); err == nil {
@@ -89,8 +94,9 @@ func TestDownloadCacheHitLoadCodeAndChecksum(t *testing.T) {
 	if err != nil {
 		t.Fatalf("get library path: %v", err)
 	}
-	if filepath.Base(gotPath) != "testlib-1.0.0.js" {
-		t.Fatalf("expected deterministic filename testlib-1.0.0.js, got %q", filepath.Base(gotPath))
+	expectedName := fmt.Sprintf("%x.js", sha256.Sum256([]byte("console.log('hello-lib');")))
+	if filepath.Base(gotPath) != expectedName {
+		t.Fatalf("expected content-addressed filename %s, got %q", expectedName, filepath.Base(gotPath))
 	}
 
 	code, err := lc.LoadLibraryCode("testlib")
@@ -138,3 +144,75 @@ func TestDownloadReturnsErrorOnUnexpectedHTTPStatus(t *testing.T) {
 		t.Fatalf("expected status-code error, got %v", err)
 	}
 }
+
+func TestDownloadRejectsUnexpectedChecksum(t *testing.T) {
+	t.Parallel()
+
+	cacheDir := t.TempDir()
+	lc, err := NewLibraryCache(cacheDir)
+	if err != nil {
+		t.Fatalf("new library cache: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("var tampered = true;"))
+	}))
+	defer server.Close()
+
+	err = lc.Download(vmmodels.Library{
+		ID:      "pinned",
+		Version: "1.0.0",
+		Source:  server.URL,
+		SHA256:  fmt.Sprintf("%x", sha256.Sum256([]byte("var original = true;"))),
+	})
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	if _, err := lc.GetLibraryPath("pinned"); err == nil {
+		t.Fatalf("expected rejected library not to be cached")
+	}
+}
+
+func TestLoadVerifiesPinnedChecksum(t *testing.T) {
+	t.Parallel()
+
+	cacheDir := t.TempDir()
+	lc, err := NewLibraryCache(cacheDir)
+	if err != nil {
+		t.Fatalf("new library cache: %v", err)
+	}
+
+	lib := vmmodels.Library{ID: "local", Version: "1.0.0"}
+	sum, err := lc.Store(lib, []byte("var local = 1;"))
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+
+	code, loadedSum, err := lc.Load(lib)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if string(code) != "var local = 1;" || loadedSum != sum {
+		t.Fatalf("unexpected load result %q %s", code, loadedSum)
+	}
+
+	// A different version of the code can't replace the pinned one.
+	if _, err := lc.Store(lib, []byte("var local = 2;")); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected re-store with different code to fail, got %v", err)
+	}
+
+	// Corrupting the cached file is detected on load.
+	if err := os.WriteFile(filepath.Join(cacheDir, "sha256", sum+".js"), []byte("var local = 3;"), 0o644); err != nil {
+		t.Fatalf("corrupt cache: %v", err)
+	}
+	if _, _, err := lc.Load(lib); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected corrupted library load to fail, got %v", err)
+	}
+
+	if err := lc.Remove(lib); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if _, _, err := lc.Load(lib); err == nil || errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected removed library to be missing, got %v", err)
+	}
+}
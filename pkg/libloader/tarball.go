@@ -0,0 +1,120 @@
+package libloader
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// maxTarballEntrySize bounds the size of a single file read from a tarball.
+const maxTarballEntrySize = 32 << 20
+
+// TarballPackage is the library code and metadata extracted from an npm
+// package tarball.
+type TarballPackage struct {
+	Name        string
+	Version     string
+	Description string
+	Entry       string
+	Code        []byte
+}
+
+type packageJSON struct {
+	Name        string          `json:"name"`
+	Version     string          `json:"version"`
+	Description string          `json:"description"`
+	Main        string          `json:"main"`
+	Browser     json.RawMessage `json:"browser"`
+	Unpkg       string          `json:"unpkg"`
+	JSDelivr    string          `json:"jsdelivr"`
+}
+
+// ExtractTarball reads an npm package tarball (as produced by `npm pack`) and
+// returns the code of its browser bundle. entry selects the file explicitly;
+// otherwise the unpkg, jsdelivr, browser and main fields of package.json are
+// tried in that order, then index.js.
+func ExtractTarball(data []byte, entry string) (*TarballPackage, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("read tarball: %w", err)
+	}
+	defer gz.Close()
+
+	files := map[string][]byte{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read tarball: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if header.Size > maxTarballEntrySize {
+			return nil, fmt.Errorf("tarball entry %s exceeds %d bytes", header.Name, maxTarballEntrySize)
+		}
+		content, err := io.ReadAll(io.LimitReader(tr, maxTarballEntrySize))
+		if err != nil {
+			return nil, fmt.Errorf("read tarball entry %s: %w", header.Name, err)
+		}
+		files[tarballRelPath(header.Name)] = content
+	}
+
+	pkg := &TarballPackage{}
+	var meta packageJSON
+	if raw, ok := files["package.json"]; ok {
+		if err := json.Unmarshal(raw, &meta); err != nil {
+			return nil, fmt.Errorf("parse package.json: %w", err)
+		}
+		pkg.Name = meta.Name
+		pkg.Version = meta.Version
+		pkg.Description = meta.Description
+	}
+
+	candidates := []string{entry}
+	if entry == "" {
+		var browser string
+		_ = json.Unmarshal(meta.Browser, &browser)
+		candidates = []string{meta.Unpkg, meta.JSDelivr, browser, meta.Main, "index.js"}
+	}
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		name := path.Clean(strings.TrimPrefix(candidate, "./"))
+		if code, ok := files[name]; ok {
+			pkg.Entry = name
+			pkg.Code = code
+			return pkg, nil
+		}
+		if code, ok := files[name+".js"]; ok {
+			pkg.Entry = name + ".js"
+			pkg.Code = code
+			return pkg, nil
+		}
+	}
+
+	if entry != "" {
+		return nil, fmt.Errorf("entry %s not found in tarball", entry)
+	}
+	return nil, fmt.Errorf("no entry file found in tarball")
+}
+
+// tarballRelPath strips the top-level directory npm puts every file under
+// (usually "package/").
+func tarballRelPath(name string) string {
+	name = path.Clean(strings.TrimPrefix(name, "./"))
+	if i := strings.Index(name, "/"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
@@ -0,0 +1,78 @@
+package libloader
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"testing"
+)
+
+func buildTarball(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("write header: %v", err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("write content: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("close tar: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("close gzip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractTarballPrefersBrowserBundle(t *testing.T) {
+	t.Parallel()
+
+	data := buildTarball(t, map[string]string{
+		"package/package.json":     `{"name":"tiny","version":"1.2.3","description":"tiny lib","main":"lib/index.js","unpkg":"dist/tiny.min.js"}`,
+		"package/lib/index.js":     "module.exports = {};",
+		"package/dist/tiny.min.js": "var tiny = {};",
+		"package/README.md":        "# tiny",
+	})
+
+	pkg, err := ExtractTarball(data, "")
+	if err != nil {
+		t.Fatalf("extract tarball: %v", err)
+	}
+	if pkg.Name != "tiny" || pkg.Version != "1.2.3" || pkg.Description != "tiny lib" {
+		t.Fatalf("unexpected package metadata: %+v", pkg)
+	}
+	if pkg.Entry != "dist/tiny.min.js" || string(pkg.Code) != "var tiny = {};" {
+		t.Fatalf("expected unpkg bundle, got %s: %q", pkg.Entry, pkg.Code)
+	}
+}
+
+func TestExtractTarballExplicitEntry(t *testing.T) {
+	t.Parallel()
+
+	data := buildTarball(t, map[string]string{
+		"package/package.json": `{"name":"tiny","version":"1.0.0","main":"index.js"}`,
+		"package/index.js":     "module.exports = {};",
+		"package/umd/tiny.js":  "var tiny = {};",
+	})
+
+	pkg, err := ExtractTarball(data, "./umd/tiny")
+	if err != nil {
+		t.Fatalf("extract tarball: %v", err)
+	}
+	if pkg.Entry != "umd/tiny.js" {
+		t.Fatalf("expected umd/tiny.js entry, got %s", pkg.Entry)
+	}
+
+	if _, err := ExtractTarball(data, "missing.js"); err == nil {
+		t.Fatalf("expected missing entry to fail")
+	}
+	if _, err := ExtractTarball([]byte("not a tarball"), ""); err == nil {
+		t.Fatalf("expected invalid tarball to fail")
+	}
+}
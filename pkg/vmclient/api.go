@@ -0,0 +1,73 @@
+package vmclient
+
+import (
+	"context"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+// API is the template, library, workspace, session, execution and operations
+// surface of the vm-system daemon. A Client from New talks to a daemon over
+// HTTP; a Local from NewLocal calls a core in process with the same error
+// semantics.
+type API interface {
+	// Templates
+	CreateTemplate(ctx context.Context, request CreateTemplateRequest) (*vmmodels.VM, error)
+	ListTemplates(ctx context.Context) ([]*vmmodels.VM, error)
+	GetTemplate(ctx context.Context, templateID string) (*TemplateDetailResponse, error)
+	DeleteTemplate(ctx context.Context, templateID string) error
+	UpdateTemplate(ctx context.Context, templateID string, request UpdateTemplateRequest) (*vmmodels.VM, error)
+	CloneTemplate(ctx context.Context, templateID string, request CloneTemplateRequest) (*vmmodels.VM, error)
+	AddTemplateCapability(ctx context.Context, templateID string, request AddTemplateCapabilityRequest) (*vmmodels.VMCapability, error)
+	ListTemplateCapabilities(ctx context.Context, templateID string) ([]*vmmodels.VMCapability, error)
+	DeleteTemplateCapability(ctx context.Context, templateID, capabilityID string) error
+	AddTemplateStartupFile(ctx context.Context, templateID string, request AddTemplateStartupFileRequest) (*vmmodels.VMStartupFile, error)
+	ListTemplateStartupFiles(ctx context.Context, templateID string) ([]*vmmodels.VMStartupFile, error)
+	UpdateTemplateStartupFile(ctx context.Context, templateID, startupFileID string, request UpdateTemplateStartupFileRequest) (*vmmodels.VMStartupFile, error)
+	DeleteTemplateStartupFile(ctx context.Context, templateID, startupFileID string) error
+	ReorderTemplateStartupFiles(ctx context.Context, templateID string, request ReorderTemplateStartupFilesRequest) ([]*vmmodels.VMStartupFile, error)
+	ListAvailableModules(ctx context.Context) ([]vmmodels.ExposedModule, error)
+	ListTemplateModules(ctx context.Context, templateID string) ([]string, error)
+	AddTemplateModule(ctx context.Context, templateID string, request AddTemplateModuleRequest) (*TemplateNamedResourceResponse, error)
+	RemoveTemplateModule(ctx context.Context, templateID, moduleName string) (*TemplateNamedResourceResponse, error)
+	ListTemplateLibraries(ctx context.Context, templateID string) ([]string, error)
+	AddTemplateLibrary(ctx context.Context, templateID string, request AddTemplateLibraryRequest) (*TemplateNamedResourceResponse, error)
+	RemoveTemplateLibrary(ctx context.Context, templateID, libraryName string) (*TemplateNamedResourceResponse, error)
+	ExportTemplate(ctx context.Context, templateID string) (*vmmodels.TemplateSpec, error)
+	ApplyTemplate(ctx context.Context, spec *vmmodels.TemplateSpec, dryRun bool) (*ApplyTemplateResponse, error)
+	ListTemplateRevisions(ctx context.Context, templateID string) ([]TemplateRevisionResponse, error)
+	GetTemplateRevision(ctx context.Context, templateID string, revision int) (*TemplateRevisionResponse, error)
+
+	// Libraries
+	ListLibraries(ctx context.Context) ([]vmmodels.Library, error)
+	GetLibrary(ctx context.Context, ref string) (*vmmodels.Library, error)
+	RegisterLibrary(ctx context.Context, request RegisterLibraryRequest) (*vmmodels.Library, error)
+	RemoveLibrary(ctx context.Context, ref string) error
+
+	// Workspaces
+	CreateWorkspace(ctx context.Context, request CreateWorkspaceRequest) (*vmmodels.Workspace, error)
+	ListWorkspaces(ctx context.Context) ([]vmmodels.Workspace, error)
+	GetWorkspace(ctx context.Context, workspaceID string) (*vmmodels.Workspace, error)
+
+	// Sessions
+	CreateSession(ctx context.Context, request CreateSessionRequest) (*vmmodels.VMSession, error)
+	ListSessions(ctx context.Context, status string) ([]*vmmodels.VMSession, error)
+	ListWorkspaceSessions(ctx context.Context, workspaceID, status string) ([]*vmmodels.VMSession, error)
+	GetSession(ctx context.Context, sessionID string) (*vmmodels.VMSession, error)
+	CloseSession(ctx context.Context, sessionID string) (*vmmodels.VMSession, error)
+
+	// Executions
+	ExecuteREPL(ctx context.Context, request ExecuteREPLRequest) (*vmmodels.Execution, error)
+	ExecuteRunFile(ctx context.Context, request ExecuteRunFileRequest) (*vmmodels.Execution, error)
+	ListExecutions(ctx context.Context, sessionID string, limit int) ([]*vmmodels.Execution, error)
+	GetExecution(ctx context.Context, executionID string) (*vmmodels.Execution, error)
+	GetExecutionEvents(ctx context.Context, executionID string, afterSeq int) ([]*vmmodels.ExecutionEvent, error)
+	CancelExecution(ctx context.Context, executionID string) (*vmmodels.Execution, error)
+
+	// Operations
+	Health(ctx context.Context) (map[string]interface{}, error)
+	RuntimeSummary(ctx context.Context) (map[string]interface{}, error)
+	ListAuditEvents(ctx context.Context, filter vmmodels.AuditFilter) ([]*vmmodels.AuditEvent, error)
+}
+
+var _ API = (*Client)(nil)
@@ -8,15 +8,15 @@ import (
 )
 
 type ExecuteREPLRequest struct {
-	SessionID string `json:"session_id"`
-	Input     string `json:"input"`
+	SessionID string `json:"session_id" jsonschema:"ID of a ready session"`
+	Input     string `json:"input" jsonschema:"JavaScript to evaluate; the value of the last expression is returned"`
 }
 
 type ExecuteRunFileRequest struct {
-	SessionID string                 `json:"session_id"`
-	Path      string                 `json:"path"`
-	Args      map[string]interface{} `json:"args"`
-	Env       map[string]interface{} `json:"env"`
+	SessionID string                 `json:"session_id" jsonschema:"ID of a ready session"`
+	Path      string                 `json:"path" jsonschema:"path of a JavaScript file relative to the session worktree"`
+	Args      map[string]interface{} `json:"args,omitempty" jsonschema:"arguments exposed to the file"`
+	Env       map[string]interface{} `json:"env,omitempty" jsonschema:"environment exposed to the file"`
 }
 
 func (c *Client) ExecuteREPL(ctx context.Context, request ExecuteREPLRequest) (*vmmodels.Execution, error) {
@@ -66,3 +66,13 @@ func (c *Client) GetExecutionEvents(ctx context.Context, executionID string, aft
 	}
 	return events, nil
 }
+
+// CancelExecution cancels an execution that is still queued behind another
+// execution of its session.
+func (c *Client) CancelExecution(ctx context.Context, executionID string) (*vmmodels.Execution, error) {
+	var execution vmmodels.Execution
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/executions/%s/cancel", executionID), map[string]interface{}{}, &execution); err != nil {
+		return nil, err
+	}
+	return &execution, nil
+}
@@ -0,0 +1,50 @@
+package vmclient
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+// RegisterLibraryRequest registers a library from exactly one of Content
+// (uploaded source) or Tarball (an npm .tgz).
+type RegisterLibraryRequest struct {
+	Name         string   `json:"name,omitempty"`
+	Version      string   `json:"version,omitempty"`
+	Global       string   `json:"global,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	Content      string   `json:"content,omitempty"`
+	Tarball      []byte   `json:"tarball,omitempty"`
+	Entry        string   `json:"entry,omitempty"`
+	Dependencies []string `json:"dependencies,omitempty"`
+}
+
+func (c *Client) ListLibraries(ctx context.Context) ([]vmmodels.Library, error) {
+	var libraries []vmmodels.Library
+	if err := c.do(ctx, "GET", "/api/v1/libraries", nil, &libraries); err != nil {
+		return nil, err
+	}
+	return libraries, nil
+}
+
+func (c *Client) GetLibrary(ctx context.Context, ref string) (*vmmodels.Library, error) {
+	var library vmmodels.Library
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/libraries/%s", url.PathEscape(ref)), nil, &library); err != nil {
+		return nil, err
+	}
+	return &library, nil
+}
+
+func (c *Client) RegisterLibrary(ctx context.Context, request RegisterLibraryRequest) (*vmmodels.Library, error) {
+	var library vmmodels.Library
+	if err := c.do(ctx, "POST", "/api/v1/libraries", request, &library); err != nil {
+		return nil, err
+	}
+	return &library, nil
+}
+
+func (c *Client) RemoveLibrary(ctx context.Context, ref string) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/libraries/%s", url.PathEscape(ref)), nil, nil)
+}
@@ -0,0 +1,259 @@
+package vmclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	stdhttp "net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+	vmhttp "github.com/go-go-golems/vm-system/pkg/vmtransport/http"
+)
+
+// Local calls a core in process, without a daemon. It validates requests as
+// the REST handlers do, reports core errors with the APIError a daemon would
+// return and records mutating calls in the audit trail, so callers cannot
+// tell it from a Client.
+type Local struct {
+	core *vmcontrol.Core
+}
+
+var _ API = (*Local)(nil)
+
+// NewLocal returns a client that calls core in process.
+func NewLocal(core *vmcontrol.Core) *Local {
+	return &Local{core: core}
+}
+
+func validationError(message string, details interface{}) error {
+	return &APIError{StatusCode: stdhttp.StatusBadRequest, Code: "VALIDATION_ERROR", Message: message, Details: details}
+}
+
+// coreError converts an error returned by vmcontrol to the APIError the REST
+// API reports for it.
+func coreError(err error, details interface{}) error {
+	status, code, message := vmhttp.ErrorStatus(err)
+	return &APIError{StatusCode: status, Code: code, Message: message, Details: details}
+}
+
+// localAudit collects what a mutating call knows about the resource it acted
+// on until done records it.
+type localAudit struct {
+	core        *vmcontrol.Core
+	ctx         context.Context
+	action      string
+	status      int
+	resourceID  string
+	workspaceID string
+	details     map[string]interface{}
+}
+
+// audit starts the audit event of action, which reports status when the
+// call succeeds.
+func (l *Local) audit(ctx context.Context, action string, status int, resourceID string) *localAudit {
+	return &localAudit{
+		core:       l.core,
+		ctx:        ctx,
+		action:     action,
+		status:     status,
+		resourceID: resourceID,
+		details:    map[string]interface{}{},
+	}
+}
+
+func (a *localAudit) resource(resourceID, workspaceID string) {
+	a.resourceID = resourceID
+	a.workspaceID = workspaceID
+}
+
+func (a *localAudit) detail(key string, value interface{}) {
+	a.details[key] = value
+}
+
+// done appends the audit event, a failure when err is set.
+func (a *localAudit) done(err error) {
+	resourceType, _, _ := strings.Cut(a.action, ".")
+	event := &vmmodels.AuditEvent{
+		RequestID:    uuid.NewString(),
+		Actor:        "anonymous",
+		RemoteAddr:   "local",
+		Action:       a.action,
+		ResourceType: resourceType,
+		ResourceID:   a.resourceID,
+		WorkspaceID:  a.workspaceID,
+		Outcome:      vmmodels.AuditOutcomeSuccess,
+		StatusCode:   a.status,
+	}
+	if token, ok := vmcontrol.TokenFromContext(a.ctx); ok {
+		event.Actor = "token:" + token.Name
+		event.TokenID = token.ID
+		if token.WorkspaceID != "" {
+			event.WorkspaceID = token.WorkspaceID
+		}
+	}
+	if err != nil {
+		event.Outcome = vmmodels.AuditOutcomeFailure
+		event.StatusCode = stdhttp.StatusInternalServerError
+		event.ErrorCode = "INTERNAL"
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			event.StatusCode = apiErr.StatusCode
+			event.ErrorCode = apiErr.Code
+		}
+	}
+	if len(a.details) > 0 {
+		if data, err := json.Marshal(a.details); err == nil {
+			event.Details = data
+		}
+	}
+
+	if err := a.core.Audit.Record(a.ctx, event); err != nil {
+		log.Warn().Err(err).
+			Str("request_id", event.RequestID).
+			Str("action", a.action).
+			Msg("failed to record audit event")
+	}
+}
+
+// auditHash returns the hex SHA-256 of a string's bytes, or of the JSON
+// encoding of any other value, as the REST API records execution input.
+func auditHash(v interface{}) string {
+	data, ok := v.(string)
+	if !ok {
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		data = string(encoded)
+	}
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+func (l *Local) Health(_ context.Context) (map[string]interface{}, error) {
+	return map[string]interface{}{"status": "ok"}, nil
+}
+
+// RuntimeSummary returns the summary in the JSON shape the REST API serves.
+func (l *Local) RuntimeSummary(ctx context.Context) (map[string]interface{}, error) {
+	data, err := json.Marshal(l.core.Registry.Summary(ctx))
+	if err != nil {
+		return nil, err
+	}
+	var summary map[string]interface{}
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+func (l *Local) ListAuditEvents(ctx context.Context, filter vmmodels.AuditFilter) ([]*vmmodels.AuditEvent, error) {
+	if filter.Outcome != "" && filter.Outcome != vmmodels.AuditOutcomeSuccess && filter.Outcome != vmmodels.AuditOutcomeFailure {
+		return nil, validationError("outcome must be success or failure", nil)
+	}
+	// A Client sends since with second precision and leaves out a limit
+	// below 1.
+	filter.Since = filter.Since.Truncate(time.Second)
+	filter.Limit = max(filter.Limit, 0)
+	events, err := l.core.Audit.List(ctx, filter)
+	if err != nil {
+		return nil, coreError(err, nil)
+	}
+	return events, nil
+}
+
+func (l *Local) ListLibraries(ctx context.Context) ([]vmmodels.Library, error) {
+	libraries, err := l.core.Libraries.List(ctx)
+	if err != nil {
+		return nil, coreError(err, nil)
+	}
+	return libraries, nil
+}
+
+func (l *Local) GetLibrary(ctx context.Context, ref string) (*vmmodels.Library, error) {
+	library, err := l.core.Libraries.Get(ctx, ref)
+	if err != nil {
+		return nil, coreError(err, map[string]interface{}{"library_ref": ref})
+	}
+	return library, nil
+}
+
+func (l *Local) RegisterLibrary(ctx context.Context, request RegisterLibraryRequest) (library *vmmodels.Library, err error) {
+	audit := l.audit(ctx, "library.register", stdhttp.StatusCreated, "")
+	defer func() { audit.done(err) }()
+	audit.detail("name", request.Name)
+	audit.detail("version", request.Version)
+
+	library, err = l.core.Libraries.Register(ctx, vmcontrol.RegisterLibraryInput{
+		Name:         request.Name,
+		Version:      request.Version,
+		Global:       request.Global,
+		Description:  request.Description,
+		Content:      request.Content,
+		Tarball:      request.Tarball,
+		Entry:        request.Entry,
+		Dependencies: request.Dependencies,
+	})
+	if err != nil {
+		return nil, coreError(err, map[string]interface{}{"name": request.Name, "version": request.Version})
+	}
+	audit.resource(library.Ref, "")
+	return library, nil
+}
+
+func (l *Local) RemoveLibrary(ctx context.Context, ref string) (err error) {
+	audit := l.audit(ctx, "library.remove", stdhttp.StatusOK, ref)
+	defer func() { audit.done(err) }()
+
+	if err := l.core.Libraries.Remove(ctx, ref); err != nil {
+		return coreError(err, map[string]interface{}{"library_ref": ref})
+	}
+	return nil
+}
+
+func (l *Local) CreateWorkspace(ctx context.Context, request CreateWorkspaceRequest) (workspace *vmmodels.Workspace, err error) {
+	audit := l.audit(ctx, "workspace.create", stdhttp.StatusCreated, "")
+	defer func() { audit.done(err) }()
+
+	if request.ID == "" {
+		return nil, validationError("id is required", nil)
+	}
+	audit.resource(request.ID, request.ID)
+
+	workspace, err = l.core.Workspaces.Create(ctx, vmcontrol.CreateWorkspaceInput{
+		ID:   request.ID,
+		Name: request.Name,
+	})
+	if err != nil {
+		return nil, coreError(err, map[string]interface{}{"workspace_id": request.ID})
+	}
+	return workspace, nil
+}
+
+func (l *Local) ListWorkspaces(ctx context.Context) ([]vmmodels.Workspace, error) {
+	listed, err := l.core.Workspaces.List(ctx)
+	if err != nil {
+		return nil, coreError(err, nil)
+	}
+	workspaces := make([]vmmodels.Workspace, 0, len(listed))
+	for _, workspace := range listed {
+		workspaces = append(workspaces, *workspace)
+	}
+	return workspaces, nil
+}
+
+func (l *Local) GetWorkspace(ctx context.Context, workspaceID string) (*vmmodels.Workspace, error) {
+	workspace, err := l.core.Workspaces.Get(ctx, workspaceID)
+	if err != nil {
+		return nil, coreError(err, map[string]interface{}{"workspace_id": workspaceID})
+	}
+	return workspace, nil
+}
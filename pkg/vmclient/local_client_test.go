@@ -0,0 +1,125 @@
+package vmclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-go-golems/vm-system/pkg/vmclient"
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+	"github.com/go-go-golems/vm-system/pkg/vmstore"
+	vmhttp "github.com/go-go-golems/vm-system/pkg/vmtransport/http"
+)
+
+func TestLocalClientMatchesDaemonErrors(t *testing.T) {
+	store, err := vmstore.NewVMStore(filepath.Join(t.TempDir(), "vm-system.db"))
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	core := vmcontrol.NewCore(store)
+	daemon := httptest.NewServer(vmhttp.NewHandler(core))
+	defer daemon.Close()
+
+	ctx := context.Background()
+	local := vmclient.NewLocal(core)
+	remote := vmclient.New(daemon.URL, daemon.Client())
+
+	template, err := local.CreateTemplate(ctx, vmclient.CreateTemplateRequest{Name: "local-template"})
+	if err != nil {
+		t.Fatalf("create template in process: %v", err)
+	}
+	session, err := local.CreateSession(ctx, vmclient.CreateSessionRequest{
+		TemplateID:    template.ID,
+		WorkspaceID:   "ws-local",
+		BaseCommitOID: "deadbeef",
+		WorktreePath:  t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("create session in process: %v", err)
+	}
+	exec, err := local.ExecuteREPL(ctx, vmclient.ExecuteREPLRequest{SessionID: session.ID, Input: "40 + 2"})
+	if err != nil {
+		t.Fatalf("execute in process: %v", err)
+	}
+	if exec.Status != "ok" || !strings.Contains(string(exec.Result), `"preview":"42"`) {
+		t.Fatalf("unexpected execution %+v", exec)
+	}
+	// Both clients see the same core, and in-process calls are audited.
+	if _, err := remote.GetExecution(ctx, exec.ID); err != nil {
+		t.Fatalf("get execution over HTTP: %v", err)
+	}
+	events, err := remote.ListAuditEvents(ctx, vmmodels.AuditFilter{Action: "execution.repl"})
+	if err != nil || len(events) != 1 || events[0].ResourceID != exec.ID || events[0].Outcome != vmmodels.AuditOutcomeSuccess {
+		t.Fatalf("expected the in-process execution audited, got %+v (%v)", events, err)
+	}
+
+	const unknownID = "00000000-0000-0000-0000-000000000000"
+	cases := []struct {
+		name string
+		call func(vmclient.API) error
+		code string
+	}{
+		{"unknown template", func(c vmclient.API) error {
+			_, err := c.GetTemplate(ctx, unknownID)
+			return err
+		}, "TEMPLATE_NOT_FOUND"},
+		{"malformed session id", func(c vmclient.API) error {
+			_, err := c.GetSession(ctx, "not-a-uuid")
+			return err
+		}, "VALIDATION_ERROR"},
+		{"unknown session", func(c vmclient.API) error {
+			_, err := c.ExecuteREPL(ctx, vmclient.ExecuteREPLRequest{SessionID: unknownID, Input: "1"})
+			return err
+		}, "SESSION_NOT_FOUND"},
+		{"finished execution", func(c vmclient.API) error {
+			_, err := c.CancelExecution(ctx, exec.ID)
+			return err
+		}, "EXECUTION_NOT_CANCELLABLE"},
+		{"missing template name", func(c vmclient.API) error {
+			_, err := c.CreateTemplate(ctx, vmclient.CreateTemplateRequest{})
+			return err
+		}, "VALIDATION_ERROR"},
+		{"startup file outside the worktree", func(c vmclient.API) error {
+			_, err := c.AddTemplateStartupFile(ctx, template.ID, vmclient.AddTemplateStartupFileRequest{Path: "../init.js"})
+			return err
+		}, "INVALID_PATH"},
+		{"unsupported startup mode", func(c vmclient.API) error {
+			_, err := c.AddTemplateStartupFile(ctx, template.ID, vmclient.AddTemplateStartupFileRequest{Path: "init.js", Mode: "import"})
+			return err
+		}, "STARTUP_MODE_UNSUPPORTED"},
+		{"non-positive limit", func(c vmclient.API) error {
+			_, err := c.ListExecutions(ctx, session.ID, 0)
+			return err
+		}, "VALIDATION_ERROR"},
+		{"unknown library", func(c vmclient.API) error {
+			_, err := c.GetLibrary(ctx, "missing-1.0.0")
+			return err
+		}, "LIBRARY_NOT_FOUND"},
+	}
+	for _, tc := range cases {
+		localErr, remoteErr := apiError(t, tc.call(local)), apiError(t, tc.call(remote))
+		if localErr.Code != tc.code {
+			t.Fatalf("%s: expected %s in process, got %+v", tc.name, tc.code, localErr)
+		}
+		if *localErr != *remoteErr {
+			t.Fatalf("%s: in-process error %+v differs from daemon error %+v", tc.name, localErr, remoteErr)
+		}
+	}
+}
+
+func apiError(t *testing.T, err error) *vmclient.APIError {
+	t.Helper()
+	var apiErr *vmclient.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %T (%v)", err, err)
+	}
+	// Details are maps, which are not comparable; the code, status and
+	// message carry the semantics.
+	apiErr.Details = nil
+	return apiErr
+}
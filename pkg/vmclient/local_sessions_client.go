@@ -0,0 +1,212 @@
+package vmclient
+
+import (
+	"context"
+	stdhttp "net/http"
+
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+func parseSessionID(raw string) (string, error) {
+	id, err := vmmodels.ParseSessionID(raw)
+	if err != nil {
+		return "", validationError("session_id must be a valid UUID", nil)
+	}
+	return id.String(), nil
+}
+
+func parseExecutionID(raw string) (string, error) {
+	id, err := vmmodels.ParseExecutionID(raw)
+	if err != nil {
+		return "", validationError("execution_id must be a valid UUID", nil)
+	}
+	return id.String(), nil
+}
+
+func (l *Local) CreateSession(ctx context.Context, request CreateSessionRequest) (session *vmmodels.VMSession, err error) {
+	audit := l.audit(ctx, "session.create", stdhttp.StatusCreated, "")
+	defer func() { audit.done(err) }()
+
+	// A workspace-bound token implies its workspace.
+	_, confined := vmcontrol.WorkspaceFromContext(ctx)
+	if request.TemplateID == "" || (request.WorkspaceID == "" && !confined) || request.BaseCommitOID == "" || request.WorktreePath == "" {
+		return nil, validationError("template_id, workspace_id, base_commit_oid, and worktree_path are required", nil)
+	}
+	templateID, err := parseTemplateID(request.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	audit.detail("template_id", templateID)
+
+	session, err = l.core.Sessions.Create(ctx, vmcontrol.CreateSessionInput{
+		TemplateID:    templateID,
+		WorkspaceID:   request.WorkspaceID,
+		BaseCommitOID: request.BaseCommitOID,
+		WorktreePath:  request.WorktreePath,
+	})
+	if err != nil {
+		return nil, coreError(err, templateDetails(templateID))
+	}
+	audit.resource(session.ID, session.WorkspaceID)
+	return session, nil
+}
+
+func (l *Local) ListSessions(ctx context.Context, status string) ([]*vmmodels.VMSession, error) {
+	return l.ListWorkspaceSessions(ctx, "", status)
+}
+
+func (l *Local) ListWorkspaceSessions(ctx context.Context, workspaceID, status string) ([]*vmmodels.VMSession, error) {
+	sessions, err := l.core.Sessions.List(ctx, status, workspaceID)
+	if err != nil {
+		return nil, coreError(err, nil)
+	}
+	return sessions, nil
+}
+
+func (l *Local) GetSession(ctx context.Context, rawSessionID string) (*vmmodels.VMSession, error) {
+	sessionID, err := parseSessionID(rawSessionID)
+	if err != nil {
+		return nil, err
+	}
+	session, err := l.core.Sessions.Get(ctx, sessionID)
+	if err != nil {
+		return nil, coreError(err, map[string]interface{}{"session_id": sessionID})
+	}
+	return session, nil
+}
+
+func (l *Local) CloseSession(ctx context.Context, rawSessionID string) (session *vmmodels.VMSession, err error) {
+	audit := l.audit(ctx, "session.close", stdhttp.StatusOK, rawSessionID)
+	defer func() { audit.done(err) }()
+
+	sessionID, err := parseSessionID(rawSessionID)
+	if err != nil {
+		return nil, err
+	}
+	session, err = l.core.Sessions.Close(ctx, sessionID)
+	if err != nil {
+		return nil, coreError(err, map[string]interface{}{"session_id": sessionID})
+	}
+	return session, nil
+}
+
+func (l *Local) ExecuteREPL(ctx context.Context, request ExecuteREPLRequest) (exec *vmmodels.Execution, err error) {
+	audit := l.audit(ctx, "execution.repl", stdhttp.StatusCreated, "")
+	defer func() { audit.done(err) }()
+
+	if request.SessionID == "" || request.Input == "" {
+		return nil, validationError("session_id and input are required", nil)
+	}
+	sessionID, err := parseSessionID(request.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	audit.detail("session_id", sessionID)
+	audit.detail("input_sha256", auditHash(request.Input))
+
+	exec, err = l.core.Executions.ExecuteREPL(ctx, vmcontrol.ExecuteREPLInput{
+		SessionID: sessionID,
+		Input:     request.Input,
+	})
+	if err != nil {
+		return nil, coreError(err, map[string]interface{}{"session_id": sessionID})
+	}
+	audit.resource(exec.ID, exec.WorkspaceID)
+	return exec, nil
+}
+
+func (l *Local) ExecuteRunFile(ctx context.Context, request ExecuteRunFileRequest) (exec *vmmodels.Execution, err error) {
+	audit := l.audit(ctx, "execution.run_file", stdhttp.StatusCreated, "")
+	defer func() { audit.done(err) }()
+
+	if request.SessionID == "" || request.Path == "" {
+		return nil, validationError("session_id and path are required", nil)
+	}
+	sessionID, err := parseSessionID(request.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	audit.detail("session_id", sessionID)
+	audit.detail("path", request.Path)
+	// Hash the request as the REST handler decodes it, so both record the
+	// same input hash.
+	audit.detail("input_sha256", auditHash(struct {
+		SessionID string                 `json:"session_id"`
+		Path      string                 `json:"path"`
+		Args      map[string]interface{} `json:"args"`
+		Env       map[string]interface{} `json:"env"`
+	}{request.SessionID, request.Path, request.Args, request.Env}))
+
+	exec, err = l.core.Executions.ExecuteRunFile(ctx, vmcontrol.ExecuteRunFileInput{
+		SessionID: sessionID,
+		Path:      request.Path,
+		Args:      request.Args,
+		Env:       request.Env,
+	})
+	if err != nil {
+		return nil, coreError(err, map[string]interface{}{"session_id": sessionID})
+	}
+	audit.resource(exec.ID, exec.WorkspaceID)
+	return exec, nil
+}
+
+func (l *Local) ListExecutions(ctx context.Context, rawSessionID string, limit int) ([]*vmmodels.Execution, error) {
+	if rawSessionID == "" {
+		return nil, validationError("session_id query param is required", nil)
+	}
+	sessionID, err := parseSessionID(rawSessionID)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		return nil, validationError("limit must be a positive integer", nil)
+	}
+	execs, err := l.core.Executions.List(ctx, sessionID, limit)
+	if err != nil {
+		return nil, coreError(err, map[string]interface{}{"session_id": sessionID})
+	}
+	return execs, nil
+}
+
+func (l *Local) GetExecution(ctx context.Context, rawExecutionID string) (*vmmodels.Execution, error) {
+	executionID, err := parseExecutionID(rawExecutionID)
+	if err != nil {
+		return nil, err
+	}
+	exec, err := l.core.Executions.Get(ctx, executionID)
+	if err != nil {
+		return nil, coreError(err, map[string]interface{}{"execution_id": executionID})
+	}
+	return exec, nil
+}
+
+func (l *Local) GetExecutionEvents(ctx context.Context, rawExecutionID string, afterSeq int) ([]*vmmodels.ExecutionEvent, error) {
+	executionID, err := parseExecutionID(rawExecutionID)
+	if err != nil {
+		return nil, err
+	}
+	if afterSeq < 0 {
+		return nil, validationError("after_seq must be a non-negative integer", nil)
+	}
+	events, err := l.core.Executions.Events(ctx, executionID, afterSeq)
+	if err != nil {
+		return nil, coreError(err, map[string]interface{}{"execution_id": executionID})
+	}
+	return events, nil
+}
+
+func (l *Local) CancelExecution(ctx context.Context, rawExecutionID string) (exec *vmmodels.Execution, err error) {
+	audit := l.audit(ctx, "execution.cancel", stdhttp.StatusOK, rawExecutionID)
+	defer func() { audit.done(err) }()
+
+	executionID, err := parseExecutionID(rawExecutionID)
+	if err != nil {
+		return nil, err
+	}
+	exec, err = l.core.Executions.Cancel(ctx, executionID)
+	if err != nil {
+		return nil, coreError(err, map[string]interface{}{"execution_id": executionID})
+	}
+	return exec, nil
+}
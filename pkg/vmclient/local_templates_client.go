@@ -0,0 +1,530 @@
+package vmclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	stdhttp "net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+	"github.com/go-go-golems/vm-system/pkg/vmpath"
+)
+
+func parseTemplateID(raw string) (string, error) {
+	id, err := vmmodels.ParseTemplateID(raw)
+	if err != nil {
+		return "", validationError("template_id must be a valid UUID", nil)
+	}
+	return id.String(), nil
+}
+
+func templateDetails(templateID string) map[string]interface{} {
+	return map[string]interface{}{"template_id": templateID}
+}
+
+func (l *Local) CreateTemplate(ctx context.Context, request CreateTemplateRequest) (template *vmmodels.VM, err error) {
+	audit := l.audit(ctx, "template.create", stdhttp.StatusCreated, "")
+	defer func() { audit.done(err) }()
+
+	if request.Name == "" {
+		return nil, validationError("name is required", nil)
+	}
+	audit.detail("name", request.Name)
+
+	template, err = l.core.Templates.Create(ctx, vmcontrol.CreateTemplateInput{
+		Name:        request.Name,
+		Engine:      request.Engine,
+		WorkspaceID: request.WorkspaceID,
+	})
+	if err != nil {
+		return nil, coreError(err, nil)
+	}
+	audit.resource(template.ID, template.WorkspaceID)
+	return template, nil
+}
+
+func (l *Local) ListTemplates(ctx context.Context) ([]*vmmodels.VM, error) {
+	templates, err := l.core.Templates.List(ctx)
+	if err != nil {
+		return nil, coreError(err, nil)
+	}
+	return templates, nil
+}
+
+func (l *Local) GetTemplate(ctx context.Context, rawTemplateID string) (*TemplateDetailResponse, error) {
+	templateID, err := parseTemplateID(rawTemplateID)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := l.core.Templates.Get(ctx, templateID)
+	if err != nil {
+		return nil, coreError(err, templateDetails(templateID))
+	}
+	settings, err := l.core.Templates.GetSettings(ctx, templateID)
+	if err != nil && !errors.Is(err, vmmodels.ErrVMNotFound) {
+		return nil, coreError(err, templateDetails(templateID))
+	}
+	caps, err := l.core.Templates.ListCapabilities(ctx, templateID)
+	if err != nil {
+		return nil, coreError(err, templateDetails(templateID))
+	}
+	startup, err := l.core.Templates.ListStartupFiles(ctx, templateID)
+	if err != nil {
+		return nil, coreError(err, templateDetails(templateID))
+	}
+
+	return &TemplateDetailResponse{
+		Template:     template,
+		Settings:     settings,
+		Capabilities: caps,
+		StartupFiles: startup,
+	}, nil
+}
+
+func (l *Local) DeleteTemplate(ctx context.Context, rawTemplateID string) (err error) {
+	audit := l.audit(ctx, "template.delete", stdhttp.StatusOK, rawTemplateID)
+	defer func() { audit.done(err) }()
+
+	templateID, err := parseTemplateID(rawTemplateID)
+	if err != nil {
+		return err
+	}
+	if err := l.core.Templates.Delete(ctx, templateID); err != nil {
+		return coreError(err, templateDetails(templateID))
+	}
+	return nil
+}
+
+func (l *Local) UpdateTemplate(ctx context.Context, rawTemplateID string, request UpdateTemplateRequest) (template *vmmodels.VM, err error) {
+	audit := l.audit(ctx, "template.update", stdhttp.StatusOK, rawTemplateID)
+	defer func() { audit.done(err) }()
+
+	templateID, err := parseTemplateID(rawTemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Name == nil && request.IsActive == nil {
+		return nil, validationError("name or is_active is required", nil)
+	}
+	if request.Name != nil && strings.TrimSpace(*request.Name) == "" {
+		return nil, validationError("name must not be empty", nil)
+	}
+	if request.Name != nil {
+		audit.detail("name", *request.Name)
+	}
+	if request.IsActive != nil {
+		audit.detail("is_active", *request.IsActive)
+	}
+
+	template, err = l.core.Templates.Update(ctx, templateID, vmcontrol.UpdateTemplateInput{
+		Name:     request.Name,
+		IsActive: request.IsActive,
+	})
+	if err != nil {
+		return nil, coreError(err, templateDetails(templateID))
+	}
+	return template, nil
+}
+
+func (l *Local) CloneTemplate(ctx context.Context, rawTemplateID string, request CloneTemplateRequest) (template *vmmodels.VM, err error) {
+	audit := l.audit(ctx, "template.clone", stdhttp.StatusCreated, rawTemplateID)
+	defer func() { audit.done(err) }()
+
+	templateID, err := parseTemplateID(rawTemplateID)
+	if err != nil {
+		return nil, err
+	}
+	template, err = l.core.Templates.Clone(ctx, templateID, vmcontrol.CloneTemplateInput{Name: request.Name})
+	if err != nil {
+		return nil, coreError(err, templateDetails(templateID))
+	}
+	audit.detail("clone_id", template.ID)
+	return template, nil
+}
+
+func (l *Local) AddTemplateCapability(ctx context.Context, rawTemplateID string, request AddTemplateCapabilityRequest) (capability *vmmodels.VMCapability, err error) {
+	audit := l.audit(ctx, "template.capability.add", stdhttp.StatusCreated, rawTemplateID)
+	defer func() { audit.done(err) }()
+
+	templateID, err := parseTemplateID(rawTemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Kind == "" || request.Name == "" {
+		return nil, validationError("kind and name are required", nil)
+	}
+	config, err := json.Marshal(request.Config)
+	if err != nil {
+		return nil, fmt.Errorf("marshal capability config: %w", err)
+	}
+	audit.detail("kind", request.Kind)
+	audit.detail("name", request.Name)
+	audit.detail("enabled", request.Enabled)
+
+	capability = &vmmodels.VMCapability{
+		ID:      uuid.NewString(),
+		VMID:    templateID,
+		Kind:    request.Kind,
+		Name:    request.Name,
+		Enabled: request.Enabled,
+		Config:  config,
+	}
+	if err := l.core.Templates.AddCapability(ctx, capability); err != nil {
+		return nil, coreError(err, templateDetails(templateID))
+	}
+	audit.detail("capability_id", capability.ID)
+	return capability, nil
+}
+
+func (l *Local) ListTemplateCapabilities(ctx context.Context, rawTemplateID string) ([]*vmmodels.VMCapability, error) {
+	templateID, err := parseTemplateID(rawTemplateID)
+	if err != nil {
+		return nil, err
+	}
+	caps, err := l.core.Templates.ListCapabilities(ctx, templateID)
+	if err != nil {
+		return nil, coreError(err, templateDetails(templateID))
+	}
+	return caps, nil
+}
+
+func (l *Local) DeleteTemplateCapability(ctx context.Context, rawTemplateID, capabilityID string) (err error) {
+	audit := l.audit(ctx, "template.capability.remove", stdhttp.StatusOK, rawTemplateID)
+	defer func() { audit.done(err) }()
+	audit.detail("capability_id", capabilityID)
+
+	templateID, err := parseTemplateID(rawTemplateID)
+	if err != nil {
+		return err
+	}
+	if capabilityID == "" {
+		return validationError("capability_id is required", nil)
+	}
+	if err := l.core.Templates.DeleteCapability(ctx, templateID, capabilityID); err != nil {
+		return coreError(err, map[string]interface{}{"template_id": templateID, "capability_id": capabilityID})
+	}
+	return nil
+}
+
+func (l *Local) AddTemplateStartupFile(ctx context.Context, rawTemplateID string, request AddTemplateStartupFileRequest) (startup *vmmodels.VMStartupFile, err error) {
+	audit := l.audit(ctx, "template.startup_file.add", stdhttp.StatusCreated, rawTemplateID)
+	defer func() { audit.done(err) }()
+
+	templateID, err := parseTemplateID(rawTemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Path == "" {
+		return nil, validationError("path is required", nil)
+	}
+	parsedPath, err := vmpath.ParseRelWorktreePath(request.Path)
+	if err != nil {
+		switch {
+		case errors.Is(err, vmpath.ErrAbsoluteRelativePath), errors.Is(err, vmpath.ErrTraversalRelativePath), errors.Is(err, vmpath.ErrEmptyRelativePath):
+			return nil, coreError(vmmodels.ErrPathTraversal, templateDetails(templateID))
+		default:
+			return nil, &APIError{StatusCode: stdhttp.StatusBadRequest, Code: "INVALID_REQUEST", Message: err.Error()}
+		}
+	}
+	mode := strings.ToLower(strings.TrimSpace(request.Mode))
+	if request.Mode == "" {
+		mode = "eval"
+	}
+	if mode != "eval" {
+		return nil, coreError(vmmodels.ErrStartupModeUnsupported, map[string]interface{}{
+			"template_id":      templateID,
+			"requested_mode":   mode,
+			"supported_modes":  []interface{}{"eval"},
+			"migration_option": "Use mode=eval until import support is implemented",
+		})
+	}
+
+	startup = &vmmodels.VMStartupFile{
+		ID:         uuid.NewString(),
+		VMID:       templateID,
+		Kind:       request.Kind,
+		Path:       parsedPath.String(),
+		Source:     request.Source,
+		OrderIndex: request.OrderIndex,
+		Mode:       mode,
+	}
+	audit.detail("path", startup.Path)
+	if err := l.core.Templates.AddStartupFile(ctx, startup); err != nil {
+		return nil, coreError(err, templateDetails(templateID))
+	}
+	audit.detail("startup_file_id", startup.ID)
+	return startup, nil
+}
+
+func (l *Local) ListTemplateStartupFiles(ctx context.Context, rawTemplateID string) ([]*vmmodels.VMStartupFile, error) {
+	templateID, err := parseTemplateID(rawTemplateID)
+	if err != nil {
+		return nil, err
+	}
+	files, err := l.core.Templates.ListStartupFiles(ctx, templateID)
+	if err != nil {
+		return nil, coreError(err, templateDetails(templateID))
+	}
+	return files, nil
+}
+
+func (l *Local) UpdateTemplateStartupFile(ctx context.Context, rawTemplateID, startupFileID string, request UpdateTemplateStartupFileRequest) (file *vmmodels.VMStartupFile, err error) {
+	audit := l.audit(ctx, "template.startup_file.update", stdhttp.StatusOK, rawTemplateID)
+	defer func() { audit.done(err) }()
+	audit.detail("startup_file_id", startupFileID)
+
+	templateID, err := parseTemplateID(rawTemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Source == nil && request.OrderIndex == nil && request.Mode == nil {
+		return nil, validationError("source, order_index, or mode is required", nil)
+	}
+
+	file, err = l.core.Templates.UpdateStartupFile(ctx, templateID, startupFileID, vmcontrol.UpdateStartupFileInput{
+		Source:     request.Source,
+		OrderIndex: request.OrderIndex,
+		Mode:       request.Mode,
+	})
+	if err != nil {
+		return nil, coreError(err, map[string]interface{}{"template_id": templateID, "startup_file_id": startupFileID})
+	}
+	return file, nil
+}
+
+func (l *Local) DeleteTemplateStartupFile(ctx context.Context, rawTemplateID, startupFileID string) (err error) {
+	audit := l.audit(ctx, "template.startup_file.remove", stdhttp.StatusOK, rawTemplateID)
+	defer func() { audit.done(err) }()
+	audit.detail("startup_file_id", startupFileID)
+
+	templateID, err := parseTemplateID(rawTemplateID)
+	if err != nil {
+		return err
+	}
+	if startupFileID == "" {
+		return validationError("startup_file_id is required", nil)
+	}
+	if err := l.core.Templates.DeleteStartupFile(ctx, templateID, startupFileID); err != nil {
+		return coreError(err, map[string]interface{}{"template_id": templateID, "startup_file_id": startupFileID})
+	}
+	return nil
+}
+
+func (l *Local) ReorderTemplateStartupFiles(ctx context.Context, rawTemplateID string, request ReorderTemplateStartupFilesRequest) (files []*vmmodels.VMStartupFile, err error) {
+	audit := l.audit(ctx, "template.startup_file.reorder", stdhttp.StatusOK, rawTemplateID)
+	defer func() { audit.done(err) }()
+
+	templateID, err := parseTemplateID(rawTemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if request.StartupFileIDs == nil {
+		return nil, validationError("startup_file_ids is required", nil)
+	}
+	files, err = l.core.Templates.ReorderStartupFiles(ctx, templateID, request.StartupFileIDs)
+	if err != nil {
+		return nil, coreError(err, templateDetails(templateID))
+	}
+	return files, nil
+}
+
+func (l *Local) ListAvailableModules(ctx context.Context) ([]vmmodels.ExposedModule, error) {
+	return l.core.Templates.ListAvailableModules(ctx), nil
+}
+
+func (l *Local) ListTemplateModules(ctx context.Context, rawTemplateID string) ([]string, error) {
+	templateID, err := parseTemplateID(rawTemplateID)
+	if err != nil {
+		return nil, err
+	}
+	modules, err := l.core.Templates.ListModules(ctx, templateID)
+	if err != nil {
+		return nil, coreError(err, templateDetails(templateID))
+	}
+	return modules, nil
+}
+
+func (l *Local) AddTemplateModule(ctx context.Context, rawTemplateID string, request AddTemplateModuleRequest) (response *TemplateNamedResourceResponse, err error) {
+	audit := l.audit(ctx, "template.module.add", stdhttp.StatusCreated, rawTemplateID)
+	defer func() { audit.done(err) }()
+
+	templateID, err := parseTemplateID(rawTemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Name == "" {
+		return nil, validationError("name is required", nil)
+	}
+	audit.detail("module_name", request.Name)
+
+	if err := l.core.Templates.AddModule(ctx, templateID, request.Name); err != nil {
+		return nil, coreError(err, templateDetails(templateID))
+	}
+	return &TemplateNamedResourceResponse{TemplateID: templateID, Name: request.Name}, nil
+}
+
+func (l *Local) RemoveTemplateModule(ctx context.Context, rawTemplateID, moduleName string) (response *TemplateNamedResourceResponse, err error) {
+	audit := l.audit(ctx, "template.module.remove", stdhttp.StatusOK, rawTemplateID)
+	defer func() { audit.done(err) }()
+	audit.detail("module_name", moduleName)
+
+	templateID, err := parseTemplateID(rawTemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if moduleName == "" {
+		return nil, validationError("module_name is required", nil)
+	}
+	if err := l.core.Templates.RemoveModule(ctx, templateID, moduleName); err != nil {
+		return nil, coreError(err, templateDetails(templateID))
+	}
+	return &TemplateNamedResourceResponse{TemplateID: templateID, Name: moduleName, Status: "ok"}, nil
+}
+
+func (l *Local) ListTemplateLibraries(ctx context.Context, rawTemplateID string) ([]string, error) {
+	templateID, err := parseTemplateID(rawTemplateID)
+	if err != nil {
+		return nil, err
+	}
+	libraries, err := l.core.Templates.ListLibraries(ctx, templateID)
+	if err != nil {
+		return nil, coreError(err, templateDetails(templateID))
+	}
+	return libraries, nil
+}
+
+func (l *Local) AddTemplateLibrary(ctx context.Context, rawTemplateID string, request AddTemplateLibraryRequest) (response *TemplateNamedResourceResponse, err error) {
+	audit := l.audit(ctx, "template.library.add", stdhttp.StatusCreated, rawTemplateID)
+	defer func() { audit.done(err) }()
+
+	templateID, err := parseTemplateID(rawTemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Name == "" {
+		return nil, validationError("name is required", nil)
+	}
+	audit.detail("library_name", request.Name)
+
+	if err := l.core.Templates.AddLibrary(ctx, templateID, request.Name); err != nil {
+		return nil, coreError(err, templateDetails(templateID))
+	}
+	return &TemplateNamedResourceResponse{TemplateID: templateID, Name: request.Name}, nil
+}
+
+func (l *Local) RemoveTemplateLibrary(ctx context.Context, rawTemplateID, libraryName string) (response *TemplateNamedResourceResponse, err error) {
+	audit := l.audit(ctx, "template.library.remove", stdhttp.StatusOK, rawTemplateID)
+	defer func() { audit.done(err) }()
+	audit.detail("library_name", libraryName)
+
+	templateID, err := parseTemplateID(rawTemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if libraryName == "" {
+		return nil, validationError("library_name is required", nil)
+	}
+	if err := l.core.Templates.RemoveLibrary(ctx, templateID, libraryName); err != nil {
+		return nil, coreError(err, templateDetails(templateID))
+	}
+	return &TemplateNamedResourceResponse{TemplateID: templateID, Name: libraryName, Status: "ok"}, nil
+}
+
+func (l *Local) ExportTemplate(ctx context.Context, rawTemplateID string) (*vmmodels.TemplateSpec, error) {
+	templateID, err := parseTemplateID(rawTemplateID)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := l.core.Templates.Export(ctx, templateID)
+	if err != nil {
+		return nil, coreError(err, templateDetails(templateID))
+	}
+	return spec, nil
+}
+
+func (l *Local) ApplyTemplate(ctx context.Context, spec *vmmodels.TemplateSpec, dryRun bool) (response *ApplyTemplateResponse, err error) {
+	audit := l.audit(ctx, "template.apply", stdhttp.StatusOK, "")
+	defer func() { audit.done(err) }()
+
+	if spec == nil || spec.Name == "" {
+		return nil, validationError("name is required", nil)
+	}
+	audit.detail("name", spec.Name)
+	audit.detail("dry_run", dryRun)
+
+	result, err := l.core.Templates.Apply(ctx, spec, dryRun)
+	if err != nil {
+		return nil, coreError(err, map[string]interface{}{"name": spec.Name})
+	}
+	if result.Template != nil {
+		audit.resource(result.Template.ID, result.Template.WorkspaceID)
+	}
+	audit.detail("changes", result.Changes)
+	if result.Created && !result.DryRun {
+		audit.status = stdhttp.StatusCreated
+	}
+	return &ApplyTemplateResponse{
+		Template: result.Template,
+		Created:  result.Created,
+		DryRun:   result.DryRun,
+		Changes:  templateChanges(result.Changes),
+	}, nil
+}
+
+func (l *Local) ListTemplateRevisions(ctx context.Context, rawTemplateID string) ([]TemplateRevisionResponse, error) {
+	templateID, err := parseTemplateID(rawTemplateID)
+	if err != nil {
+		return nil, err
+	}
+	diffs, err := l.core.Templates.ListRevisions(ctx, templateID)
+	if err != nil {
+		return nil, coreError(err, templateDetails(templateID))
+	}
+	revisions := make([]TemplateRevisionResponse, 0, len(diffs))
+	for _, diff := range diffs {
+		revisions = append(revisions, templateRevision(diff))
+	}
+	return revisions, nil
+}
+
+func (l *Local) GetTemplateRevision(ctx context.Context, rawTemplateID string, revision int) (*TemplateRevisionResponse, error) {
+	templateID, err := parseTemplateID(rawTemplateID)
+	if err != nil {
+		return nil, err
+	}
+	rawRevision := strconv.Itoa(revision)
+	if revision < 1 {
+		return nil, validationError("revision must be a positive integer", map[string]interface{}{"revision": rawRevision})
+	}
+	diff, err := l.core.Templates.GetRevision(ctx, templateID, revision)
+	if err != nil {
+		return nil, coreError(err, map[string]interface{}{"template_id": templateID, "revision": rawRevision})
+	}
+	response := templateRevision(diff)
+	return &response, nil
+}
+
+func templateRevision(diff *vmcontrol.TemplateRevisionDiff) TemplateRevisionResponse {
+	return TemplateRevisionResponse{
+		TemplateRevision: *diff.TemplateRevision,
+		Changes:          templateChanges(diff.Changes),
+	}
+}
+
+func templateChanges(changes []vmcontrol.TemplateChange) []TemplateChange {
+	if changes == nil {
+		return nil
+	}
+	converted := make([]TemplateChange, 0, len(changes))
+	for _, change := range changes {
+		converted = append(converted, TemplateChange(change))
+	}
+	return converted
+}
@@ -1,6 +1,12 @@
 package vmclient
 
-import "context"
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
 
 func (c *Client) Health(ctx context.Context) (map[string]interface{}, error) {
 	var response map[string]interface{}
@@ -17,3 +23,27 @@ func (c *Client) RuntimeSummary(ctx context.Context) (map[string]interface{}, er
 	}
 	return response, nil
 }
+
+// ListAuditEvents lists audit events matching filter, newest first.
+func (c *Client) ListAuditEvents(ctx context.Context, filter vmmodels.AuditFilter) ([]*vmmodels.AuditEvent, error) {
+	query := map[string]string{
+		"action":        filter.Action,
+		"actor":         filter.Actor,
+		"resource_type": filter.ResourceType,
+		"resource_id":   filter.ResourceID,
+		"workspace_id":  filter.WorkspaceID,
+		"outcome":       filter.Outcome,
+	}
+	if !filter.Since.IsZero() {
+		query["since"] = filter.Since.Format(time.RFC3339)
+	}
+	if filter.Limit > 0 {
+		query["limit"] = strconv.Itoa(filter.Limit)
+	}
+
+	var events []*vmmodels.AuditEvent
+	if err := c.do(ctx, "GET", withQuery("/api/v1/audit", query), nil, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -3,11 +3,15 @@ package vmclient
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	stdhttp "net/http"
 	"net/url"
+	"os"
 	"strings"
 	"time"
 )
@@ -16,16 +20,125 @@ import (
 type Client struct {
 	baseURL    string
 	httpClient *stdhttp.Client
+	token      string
+	tlsConfig  *tls.Config
+	err        error // configuration error returned by every call
 }
 
-func New(baseURL string, httpClient *stdhttp.Client) *Client {
+// Option configures a Client.
+type Option func(*Client)
+
+// WithToken sends token as a bearer token on every request.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = strings.TrimSpace(token)
+	}
+}
+
+// WithTLSConfig uses tlsConfig for https connections, e.g. to trust a
+// private CA or present a client certificate.
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(c *Client) {
+		c.tlsConfig = tlsConfig
+	}
+}
+
+// WithTLSFiles trusts the PEM CA bundle caFile in addition to the system
+// roots and presents the PEM client certificate certFile with its key
+// keyFile. Empty paths are skipped. A file that cannot be loaded fails every
+// call the client makes.
+func WithTLSFiles(caFile, certFile, keyFile string) Option {
+	return func(c *Client) {
+		if caFile == "" && certFile == "" && keyFile == "" {
+			return
+		}
+		tlsConfig, err := LoadTLSConfig(caFile, certFile, keyFile)
+		if err != nil {
+			c.err = err
+			return
+		}
+		c.tlsConfig = tlsConfig
+	}
+}
+
+// LoadTLSConfig builds a client TLS configuration from a PEM CA bundle and a
+// PEM client certificate and key. Empty paths are skipped.
+func LoadTLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		data, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read TLS CA: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("read TLS CA: %s holds no PEM certificates", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, fmt.Errorf("a TLS client certificate needs both a certificate and a key")
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load TLS client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// New returns a client for the daemon at baseURL: an http(s) URL, or
+// unix:///path/to/sock for a daemon listening on a Unix domain socket.
+func New(baseURL string, httpClient *stdhttp.Client, opts ...Option) *Client {
 	if httpClient == nil {
 		httpClient = &stdhttp.Client{Timeout: 30 * time.Second}
 	}
-	return &Client{
+	c := &Client{
 		baseURL:    strings.TrimRight(baseURL, "/"),
 		httpClient: httpClient,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if socketPath, ok := strings.CutPrefix(baseURL, "unix://"); ok {
+		c.httpClient = unixSocketClient(httpClient, socketPath)
+		c.baseURL = "http://unix"
+	} else if c.tlsConfig != nil {
+		c.httpClient = tlsClient(httpClient, c.tlsConfig)
+	}
+	return c
+}
+
+// tlsClient returns a copy of httpClient whose https connections use
+// tlsConfig.
+func tlsClient(httpClient *stdhttp.Client, tlsConfig *tls.Config) *stdhttp.Client {
+	transport, ok := httpClient.Transport.(*stdhttp.Transport)
+	if !ok || transport == nil {
+		transport = stdhttp.DefaultTransport.(*stdhttp.Transport)
+	}
+	transport = transport.Clone()
+	transport.TLSClientConfig = tlsConfig
+	tlsHTTPClient := *httpClient
+	tlsHTTPClient.Transport = transport
+	return &tlsHTTPClient
+}
+
+// unixSocketClient returns a copy of httpClient whose connections all go to
+// the Unix socket at socketPath.
+func unixSocketClient(httpClient *stdhttp.Client, socketPath string) *stdhttp.Client {
+	dialer := &net.Dialer{}
+	unixClient := *httpClient
+	unixClient.Transport = &stdhttp.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socketPath)
+		},
+	}
+	return &unixClient
 }
 
 type APIError struct {
@@ -51,6 +164,9 @@ type errorEnvelope struct {
 }
 
 func (c *Client) do(ctx context.Context, method, path string, in interface{}, out interface{}) error {
+	if c.err != nil {
+		return c.err
+	}
 	var body io.Reader
 	if in != nil {
 		data, err := json.Marshal(in)
@@ -68,6 +184,9 @@ func (c *Client) do(ctx context.Context, method, path string, in interface{}, ou
 	if in != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -124,3 +127,62 @@ func TestWithQuerySkipsEmptyValuesAndEncodesNonEmpty(t *testing.T) {
 		t.Fatalf("expected empty value to be omitted, got %q", got)
 	}
 }
+
+func TestClientWithTokenSendsBearerAuthorization(t *testing.T) {
+	t.Parallel()
+
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer server.Close()
+
+	if _, err := New(server.URL, server.Client(), WithToken("vms_secret")).Health(context.Background()); err != nil {
+		t.Fatalf("health: %v", err)
+	}
+	if got != "Bearer vms_secret" {
+		t.Fatalf("expected bearer authorization header, got %q", got)
+	}
+
+	if _, err := New(server.URL, server.Client()).Health(context.Background()); err != nil {
+		t.Fatalf("health: %v", err)
+	}
+	if got != "" {
+		t.Fatalf("expected no authorization header without a token, got %q", got)
+	}
+}
+
+func TestClientDialsUnixSocketURLs(t *testing.T) {
+	t.Parallel()
+
+	socketDir, err := os.MkdirTemp("", "vmc")
+	if err != nil {
+		t.Fatalf("mkdir temp: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(socketDir) })
+	socketPath := filepath.Join(socketDir, "vm.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	var gotPath string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	status, err := New("unix://"+socketPath, nil).Health(context.Background())
+	if err != nil {
+		t.Fatalf("health over unix socket: %v", err)
+	}
+	if status["status"] != "ok" || gotPath != "/api/v1/health" {
+		t.Fatalf("expected health response for /api/v1/health, got %v at %q", status, gotPath)
+	}
+}
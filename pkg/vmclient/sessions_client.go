@@ -8,10 +8,10 @@ import (
 )
 
 type CreateSessionRequest struct {
-	TemplateID    string `json:"template_id"`
-	WorkspaceID   string `json:"workspace_id"`
-	BaseCommitOID string `json:"base_commit_oid"`
-	WorktreePath  string `json:"worktree_path"`
+	TemplateID    string `json:"template_id" jsonschema:"ID of the template to build the session from"`
+	WorkspaceID   string `json:"workspace_id,omitempty" jsonschema:"workspace the session belongs to (defaults to the token's workspace)"`
+	BaseCommitOID string `json:"base_commit_oid" jsonschema:"commit the worktree is checked out at"`
+	WorktreePath  string `json:"worktree_path" jsonschema:"absolute path of an existing worktree directory"`
 }
 
 func (c *Client) CreateSession(ctx context.Context, request CreateSessionRequest) (*vmmodels.VMSession, error) {
@@ -23,7 +23,13 @@ func (c *Client) CreateSession(ctx context.Context, request CreateSessionRequest
 }
 
 func (c *Client) ListSessions(ctx context.Context, status string) ([]*vmmodels.VMSession, error) {
-	path := withQuery("/api/v1/sessions", map[string]string{"status": status})
+	return c.ListWorkspaceSessions(ctx, "", status)
+}
+
+// ListWorkspaceSessions lists the sessions of one workspace, or all visible
+// sessions when workspaceID is empty.
+func (c *Client) ListWorkspaceSessions(ctx context.Context, workspaceID, status string) ([]*vmmodels.VMSession, error) {
+	path := withQuery("/api/v1/sessions", map[string]string{"status": status, "workspace_id": workspaceID})
 	var sessions []*vmmodels.VMSession
 	if err := c.do(ctx, "GET", path, nil, &sessions); err != nil {
 		return nil, err
@@ -8,8 +8,9 @@ import (
 )
 
 type CreateTemplateRequest struct {
-	Name   string `json:"name"`
-	Engine string `json:"engine"`
+	Name        string `json:"name" jsonschema:"template name"`
+	Engine      string `json:"engine,omitempty" jsonschema:"runtime engine (default goja)"`
+	WorkspaceID string `json:"workspace_id,omitempty" jsonschema:"owning workspace; empty shares the template with every workspace"`
 }
 
 type TemplateDetailResponse struct {
@@ -47,6 +48,31 @@ func (c *Client) DeleteTemplate(ctx context.Context, templateID string) error {
 	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/templates/%s", templateID), nil, nil)
 }
 
+type UpdateTemplateRequest struct {
+	Name     *string `json:"name,omitempty"`
+	IsActive *bool   `json:"is_active,omitempty"`
+}
+
+func (c *Client) UpdateTemplate(ctx context.Context, templateID string, request UpdateTemplateRequest) (*vmmodels.VM, error) {
+	var template vmmodels.VM
+	if err := c.do(ctx, "PATCH", fmt.Sprintf("/api/v1/templates/%s", templateID), request, &template); err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+type CloneTemplateRequest struct {
+	Name string `json:"name,omitempty"`
+}
+
+func (c *Client) CloneTemplate(ctx context.Context, templateID string, request CloneTemplateRequest) (*vmmodels.VM, error) {
+	var template vmmodels.VM
+	if err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/templates/%s/clone", templateID), request, &template); err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
 type AddTemplateCapabilityRequest struct {
 	Kind    string      `json:"kind"`
 	Name    string      `json:"name"`
@@ -73,11 +99,19 @@ func (c *Client) ListTemplateCapabilities(ctx context.Context, templateID string
 }
 
 type AddTemplateStartupFileRequest struct {
+	Kind       string `json:"kind,omitempty"`
 	Path       string `json:"path"`
+	Source     string `json:"source,omitempty"`
 	OrderIndex int    `json:"order_index"`
 	Mode       string `json:"mode"`
 }
 
+type UpdateTemplateStartupFileRequest struct {
+	Source     *string `json:"source,omitempty"`
+	OrderIndex *int    `json:"order_index,omitempty"`
+	Mode       *string `json:"mode,omitempty"`
+}
+
 type AddTemplateModuleRequest struct {
 	Name string `json:"name"`
 }
@@ -110,6 +144,44 @@ func (c *Client) ListTemplateStartupFiles(ctx context.Context, templateID string
 	return startupFiles, nil
 }
 
+func (c *Client) DeleteTemplateCapability(ctx context.Context, templateID, capabilityID string) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/templates/%s/capabilities/%s", templateID, capabilityID), nil, nil)
+}
+
+func (c *Client) UpdateTemplateStartupFile(ctx context.Context, templateID, startupFileID string, request UpdateTemplateStartupFileRequest) (*vmmodels.VMStartupFile, error) {
+	var file vmmodels.VMStartupFile
+	path := fmt.Sprintf("/api/v1/templates/%s/startup-files/%s", templateID, startupFileID)
+	if err := c.do(ctx, "PATCH", path, request, &file); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+func (c *Client) DeleteTemplateStartupFile(ctx context.Context, templateID, startupFileID string) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/templates/%s/startup-files/%s", templateID, startupFileID), nil, nil)
+}
+
+type ReorderTemplateStartupFilesRequest struct {
+	StartupFileIDs []string `json:"startup_file_ids"`
+}
+
+func (c *Client) ReorderTemplateStartupFiles(ctx context.Context, templateID string, request ReorderTemplateStartupFilesRequest) ([]*vmmodels.VMStartupFile, error) {
+	var files []*vmmodels.VMStartupFile
+	path := fmt.Sprintf("/api/v1/templates/%s/startup-files:reorder", templateID)
+	if err := c.do(ctx, "POST", path, request, &files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func (c *Client) ListAvailableModules(ctx context.Context) ([]vmmodels.ExposedModule, error) {
+	var modules []vmmodels.ExposedModule
+	if err := c.do(ctx, "GET", "/api/v1/modules", nil, &modules); err != nil {
+		return nil, err
+	}
+	return modules, nil
+}
+
 func (c *Client) ListTemplateModules(ctx context.Context, templateID string) ([]string, error) {
 	var modules []string
 	path := fmt.Sprintf("/api/v1/templates/%s/modules", templateID)
@@ -163,3 +235,57 @@ func (c *Client) RemoveTemplateLibrary(ctx context.Context, templateID, libraryN
 	}
 	return &response, nil
 }
+
+type TemplateChange struct {
+	Action   string `json:"action"`
+	Resource string `json:"resource"`
+	Name     string `json:"name"`
+}
+
+type ApplyTemplateResponse struct {
+	Template *vmmodels.VM     `json:"template,omitempty"`
+	Created  bool             `json:"created"`
+	DryRun   bool             `json:"dry_run"`
+	Changes  []TemplateChange `json:"changes"`
+}
+
+func (c *Client) ExportTemplate(ctx context.Context, templateID string) (*vmmodels.TemplateSpec, error) {
+	var spec vmmodels.TemplateSpec
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/templates/%s/export", templateID), nil, &spec); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+func (c *Client) ApplyTemplate(ctx context.Context, spec *vmmodels.TemplateSpec, dryRun bool) (*ApplyTemplateResponse, error) {
+	var response ApplyTemplateResponse
+	query := map[string]string{}
+	if dryRun {
+		query["dry_run"] = "true"
+	}
+	if err := c.do(ctx, "POST", withQuery("/api/v1/templates:apply", query), spec, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+type TemplateRevisionResponse struct {
+	vmmodels.TemplateRevision
+	Changes []TemplateChange `json:"changes"`
+}
+
+func (c *Client) ListTemplateRevisions(ctx context.Context, templateID string) ([]TemplateRevisionResponse, error) {
+	var response []TemplateRevisionResponse
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/templates/%s/revisions", templateID), nil, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *Client) GetTemplateRevision(ctx context.Context, templateID string, revision int) (*TemplateRevisionResponse, error) {
+	var response TemplateRevisionResponse
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/templates/%s/revisions/%d", templateID, revision), nil, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
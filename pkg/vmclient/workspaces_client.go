@@ -0,0 +1,38 @@
+package vmclient
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+type CreateWorkspaceRequest struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+func (c *Client) CreateWorkspace(ctx context.Context, request CreateWorkspaceRequest) (*vmmodels.Workspace, error) {
+	var workspace vmmodels.Workspace
+	if err := c.do(ctx, "POST", "/api/v1/workspaces", request, &workspace); err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+func (c *Client) ListWorkspaces(ctx context.Context) ([]vmmodels.Workspace, error) {
+	var workspaces []vmmodels.Workspace
+	if err := c.do(ctx, "GET", "/api/v1/workspaces", nil, &workspaces); err != nil {
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+func (c *Client) GetWorkspace(ctx context.Context, workspaceID string) (*vmmodels.Workspace, error) {
+	var workspace vmmodels.Workspace
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/workspaces/%s", url.PathEscape(workspaceID)), nil, &workspace); err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
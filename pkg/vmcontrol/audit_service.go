@@ -0,0 +1,56 @@
+package vmcontrol
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// AuditService appends to and reads the audit trail. Transports record one
+// event per control-plane or execution request.
+type AuditService struct {
+	store AuditStorePort
+	now   func() time.Time
+}
+
+func NewAuditService(store AuditStorePort) *AuditService {
+	return &AuditService{store: store, now: time.Now}
+}
+
+// Record appends event, stamping its creation time.
+func (s *AuditService) Record(_ context.Context, event *vmmodels.AuditEvent) error {
+	event.CreatedAt = s.now().UTC()
+	return s.store.AppendAuditEvent(event)
+}
+
+// List returns the audit events matching filter, newest first. Callers
+// confined to a workspace only see events recorded in it. The limit defaults
+// to 100 and is capped at 1000.
+func (s *AuditService) List(ctx context.Context, filter vmmodels.AuditFilter) ([]*vmmodels.AuditEvent, error) {
+	if workspaceID, confined := WorkspaceFromContext(ctx); confined {
+		if filter.WorkspaceID != "" && filter.WorkspaceID != workspaceID {
+			return []*vmmodels.AuditEvent{}, nil
+		}
+		filter.WorkspaceID = workspaceID
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+	events, err := s.store.ListAuditEvents(filter)
+	if err != nil {
+		return nil, err
+	}
+	if events == nil {
+		events = []*vmmodels.AuditEvent{}
+	}
+	return events, nil
+}
@@ -0,0 +1,208 @@
+package vmcontrol
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+// tokenPrefix marks vm-system API token secrets so they are easy to spot in
+// config files and secret scanners.
+const tokenPrefix = "vms_"
+
+// tokenTouchInterval bounds how often a token's last_used_at is written, so
+// authenticated reads do not each cost a store write.
+const tokenTouchInterval = time.Minute
+
+// AuthService issues, lists, revokes and checks API tokens.
+type AuthService struct {
+	store      TokenStorePort
+	workspaces WorkspaceStorePort
+	now        func() time.Time
+}
+
+// NewAuthService builds an AuthService over store. workspaces registers the
+// workspaces tokens are bound to.
+func NewAuthService(store TokenStorePort, workspaces WorkspaceStorePort) *AuthService {
+	return &AuthService{store: store, workspaces: workspaces, now: time.Now}
+}
+
+// Create issues a new token. The returned secret is not stored and cannot be
+// recovered later. A token bound to a workspace confines its caller to that
+// workspace. A token bound to a client certificate subject gets no secret; it
+// authenticates callers presenting a verified certificate with that subject.
+func (s *AuthService) Create(_ context.Context, input CreateTokenInput) (*CreatedToken, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", vmmodels.ErrInvalidToken)
+	}
+	scopes := []string{}
+	seen := map[string]bool{}
+	for _, scope := range input.Scopes {
+		scope = strings.TrimSpace(scope)
+		if !vmmodels.IsTokenScope(scope) {
+			return nil, fmt.Errorf("%w: unknown scope %q (expected one of %s)", vmmodels.ErrInvalidToken, scope, strings.Join(vmmodels.TokenScopes(), ", "))
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		scopes = []string{vmmodels.ScopeRead}
+	}
+	workspaceID := ""
+	if strings.TrimSpace(input.WorkspaceID) != "" {
+		id, err := vmmodels.NormalizeWorkspaceID(input.WorkspaceID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.workspaces.EnsureWorkspace(id); err != nil {
+			return nil, err
+		}
+		workspaceID = id
+	}
+	certSubject := NormalizeCertSubject(input.CertSubject)
+	if certSubject != "" {
+		bound, err := s.store.GetAPITokenByCertSubject(certSubject)
+		if err == nil {
+			return nil, fmt.Errorf("%w: certificate subject %q is already bound to token %s", vmmodels.ErrInvalidToken, certSubject, bound.ID)
+		}
+		if !errors.Is(err, vmmodels.ErrTokenNotFound) {
+			return nil, err
+		}
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("generate token: %w", err)
+	}
+	secret := tokenPrefix + hex.EncodeToString(raw)
+
+	token := &vmmodels.APIToken{
+		ID:          uuid.NewString(),
+		Name:        name,
+		Scopes:      scopes,
+		WorkspaceID: workspaceID,
+		CertSubject: certSubject,
+		Hash:        hashToken(secret),
+		CreatedAt:   s.now().UTC(),
+	}
+	if err := s.store.CreateAPIToken(token); err != nil {
+		return nil, err
+	}
+	if certSubject != "" {
+		// The secret is never handed out, so the token cannot be used as a
+		// bearer token.
+		secret = ""
+	}
+	return &CreatedToken{Token: token, Secret: secret}, nil
+}
+
+// List returns every token, including revoked ones.
+func (s *AuthService) List(_ context.Context) ([]*vmmodels.APIToken, error) {
+	return s.store.ListAPITokens()
+}
+
+// Revoke marks a token revoked; later requests carrying it are rejected.
+// Revoking an already revoked token is a no-op.
+func (s *AuthService) Revoke(_ context.Context, id string) (*vmmodels.APIToken, error) {
+	token, err := s.store.GetAPIToken(strings.TrimSpace(id))
+	if err != nil {
+		return nil, err
+	}
+	if token.RevokedAt != nil {
+		return token, nil
+	}
+	now := s.now().UTC()
+	token.RevokedAt = &now
+	if err := s.store.UpdateAPIToken(token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// Authenticate returns the live token whose secret is secret, and
+// ErrUnauthenticated when there is none.
+func (s *AuthService) Authenticate(_ context.Context, secret string) (*vmmodels.APIToken, error) {
+	if !strings.HasPrefix(secret, tokenPrefix) {
+		return nil, vmmodels.ErrUnauthenticated
+	}
+	token, err := s.store.GetAPITokenByHash(hashToken(secret))
+	if err != nil {
+		if errors.Is(err, vmmodels.ErrTokenNotFound) {
+			return nil, vmmodels.ErrUnauthenticated
+		}
+		return nil, err
+	}
+	if token.RevokedAt != nil || token.CertSubject != "" {
+		return nil, vmmodels.ErrUnauthenticated
+	}
+	return s.touch(token)
+}
+
+// AuthenticateCertificate returns the live token bound to the subject of a
+// verified client certificate, and ErrUnauthenticated when there is none.
+func (s *AuthService) AuthenticateCertificate(_ context.Context, subject string) (*vmmodels.APIToken, error) {
+	subject = NormalizeCertSubject(subject)
+	if subject == "" {
+		return nil, vmmodels.ErrUnauthenticated
+	}
+	token, err := s.store.GetAPITokenByCertSubject(subject)
+	if err != nil {
+		if errors.Is(err, vmmodels.ErrTokenNotFound) {
+			return nil, vmmodels.ErrUnauthenticated
+		}
+		return nil, err
+	}
+	return s.touch(token)
+}
+
+// touch records that token was used, at most once per tokenTouchInterval.
+func (s *AuthService) touch(token *vmmodels.APIToken) (*vmmodels.APIToken, error) {
+	now := s.now().UTC()
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= tokenTouchInterval {
+		token.LastUsedAt = &now
+		if err := s.store.UpdateAPIToken(token); err != nil {
+			return nil, err
+		}
+	}
+	return token, nil
+}
+
+// NormalizeCertSubject trims a certificate subject and expands a bare common
+// name to "CN=name", the form x509 subjects take in RFC 2253 notation.
+func NormalizeCertSubject(subject string) string {
+	subject = strings.TrimSpace(subject)
+	if subject != "" && !strings.Contains(subject, "=") {
+		subject = "CN=" + subject
+	}
+	return subject
+}
+
+type tokenContextKey struct{}
+
+// ContextWithToken returns ctx carrying the authenticated token.
+func ContextWithToken(ctx context.Context, token *vmmodels.APIToken) context.Context {
+	return context.WithValue(ctx, tokenContextKey{}, token)
+}
+
+// TokenFromContext returns the token a request authenticated with, if any.
+func TokenFromContext(ctx context.Context) (*vmmodels.APIToken, bool) {
+	token, ok := ctx.Value(tokenContextKey{}).(*vmmodels.APIToken)
+	return token, ok && token != nil
+}
+
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
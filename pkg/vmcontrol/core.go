@@ -1,32 +1,58 @@
 package vmcontrol
 
 import (
+	"github.com/go-go-golems/vm-system/pkg/libloader"
 	"github.com/go-go-golems/vm-system/pkg/vmexec"
 	"github.com/go-go-golems/vm-system/pkg/vmsession"
-	"github.com/go-go-golems/vm-system/pkg/vmstore"
 )
 
 // Core is the transport-agnostic orchestration entrypoint used by daemon, CLI clients, and in-process consumers.
 type Core struct {
 	Templates  *TemplateService
+	Libraries  *LibraryService
 	Sessions   *SessionService
 	Executions *ExecutionService
+	Workspaces *WorkspaceService
 	Registry   *RuntimeRegistry
+	Auth       *AuthService
+	Audit      *AuditService
 }
 
-// NewCore builds the standard core wiring from concrete store + runtime implementations.
-func NewCore(store *vmstore.VMStore) *Core {
-	sessionRuntime := vmsession.NewSessionManager(store)
-	executionRuntime := vmexec.NewExecutor(store, sessionRuntime)
-	return NewCoreWithPorts(store, sessionRuntime, executionRuntime)
+// NewCore builds the standard core wiring from a store and the goja runtime implementations.
+func NewCore(store Store, opts ...Option) *Core {
+	cfg := newCoreConfig(opts)
+	sessionRuntime := vmsession.NewSessionManager(store,
+		vmsession.WithModuleRegistry(cfg.modules),
+		vmsession.WithLibraryDir(libloader.CacheDir(cfg.dataDir)),
+		vmsession.WithQuotas(cfg.quotas),
+	)
+	executionRuntime := vmexec.NewExecutor(store, sessionRuntime, vmexec.WithQueue(cfg.queue))
+	return NewCoreWithPorts(store, sessionRuntime, executionRuntime, opts...)
 }
 
 // NewCoreWithPorts allows tests and non-daemon embeddings to provide custom adapters.
-func NewCoreWithPorts(store StorePort, sessionRuntime SessionRuntimePort, executionRuntime ExecutionRuntimePort) *Core {
+func NewCoreWithPorts(store StorePort, sessionRuntime SessionRuntimePort, executionRuntime ExecutionRuntimePort, opts ...Option) *Core {
+	cfg := newCoreConfig(opts)
+	templates := NewTemplateService(store)
+	templates.modules = cfg.modules
+	libraries := NewLibraryService(store, store)
+	libraries.cacheDir = libloader.CacheDir(cfg.dataDir)
+	templates.libraries = libraries
+	workspaces := NewWorkspaceService(store)
+	templates.workspaces = workspaces
+	sessions := NewSessionService(store, sessionRuntime)
+	sessions.templates = store
+	sessions.workspaces = workspaces
+	registry := NewRuntimeRegistry(sessionRuntime, executionRuntime)
+	registry.templates = store
 	return &Core{
-		Templates:  NewTemplateService(store),
-		Sessions:   NewSessionService(store, sessionRuntime),
+		Templates:  templates,
+		Libraries:  libraries,
+		Sessions:   sessions,
 		Executions: NewExecutionService(executionRuntime, store, store),
-		Registry:   NewRuntimeRegistry(sessionRuntime),
+		Workspaces: workspaces,
+		Registry:   registry,
+		Auth:       NewAuthService(store, store),
+		Audit:      NewAuditService(store),
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/go-go-golems/vm-system/pkg/vmexec"
 	"github.com/go-go-golems/vm-system/pkg/vmmodels"
 	"github.com/go-go-golems/vm-system/pkg/vmpath"
 )
@@ -26,8 +27,11 @@ func NewExecutionService(runtime ExecutionRuntimePort, sessionStore SessionStore
 	}
 }
 
-func (s *ExecutionService) ExecuteREPL(_ context.Context, input ExecuteREPLInput) (*vmmodels.Execution, error) {
-	execution, err := s.runtime.ExecuteREPL(input.SessionID, input.Input)
+func (s *ExecutionService) ExecuteREPL(ctx context.Context, input ExecuteREPLInput) (*vmmodels.Execution, error) {
+	if _, err := s.ownedSession(ctx, input.SessionID); err != nil {
+		return nil, err
+	}
+	execution, err := s.runtime.ExecuteREPL(input.SessionID, input.Input, vmexec.Observe(input.Observer))
 	if err != nil {
 		return nil, err
 	}
@@ -37,8 +41,8 @@ func (s *ExecutionService) ExecuteREPL(_ context.Context, input ExecuteREPLInput
 	return execution, nil
 }
 
-func (s *ExecutionService) ExecuteRunFile(_ context.Context, input ExecuteRunFileInput) (*vmmodels.Execution, error) {
-	session, err := s.sessionStore.GetSession(input.SessionID)
+func (s *ExecutionService) ExecuteRunFile(ctx context.Context, input ExecuteRunFileInput) (*vmmodels.Execution, error) {
+	session, err := s.ownedSession(ctx, input.SessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -47,7 +51,7 @@ func (s *ExecutionService) ExecuteRunFile(_ context.Context, input ExecuteRunFil
 		return nil, err
 	}
 
-	execution, err := s.runtime.ExecuteRunFile(input.SessionID, safePath, input.Args, input.Env)
+	execution, err := s.runtime.ExecuteRunFile(input.SessionID, safePath, input.Args, input.Env, vmexec.Observe(input.Observer))
 	if err != nil {
 		return nil, err
 	}
@@ -57,18 +61,57 @@ func (s *ExecutionService) ExecuteRunFile(_ context.Context, input ExecuteRunFil
 	return execution, nil
 }
 
-func (s *ExecutionService) Get(_ context.Context, executionID string) (*vmmodels.Execution, error) {
-	return s.runtime.GetExecution(executionID)
+func (s *ExecutionService) Get(ctx context.Context, executionID string) (*vmmodels.Execution, error) {
+	return s.owned(ctx, executionID)
+}
+
+// Cancel cancels an execution still waiting in its session queue.
+func (s *ExecutionService) Cancel(ctx context.Context, executionID string) (*vmmodels.Execution, error) {
+	if _, err := s.owned(ctx, executionID); err != nil {
+		return nil, err
+	}
+	return s.runtime.CancelExecution(executionID)
 }
 
-func (s *ExecutionService) List(_ context.Context, sessionID string, limit int) ([]*vmmodels.Execution, error) {
+func (s *ExecutionService) List(ctx context.Context, sessionID string, limit int) ([]*vmmodels.Execution, error) {
+	if _, err := s.ownedSession(ctx, sessionID); err != nil {
+		return nil, err
+	}
 	return s.runtime.ListExecutions(sessionID, limit)
 }
 
-func (s *ExecutionService) Events(_ context.Context, executionID string, afterSeq int) ([]*vmmodels.ExecutionEvent, error) {
+func (s *ExecutionService) Events(ctx context.Context, executionID string, afterSeq int) ([]*vmmodels.ExecutionEvent, error) {
+	if _, err := s.owned(ctx, executionID); err != nil {
+		return nil, err
+	}
 	return s.runtime.GetEvents(executionID, afterSeq)
 }
 
+// owned loads an execution of the caller's workspace. Executions of other
+// workspaces are reported as not found.
+func (s *ExecutionService) owned(ctx context.Context, executionID string) (*vmmodels.Execution, error) {
+	execution, err := s.runtime.GetExecution(executionID)
+	if err != nil {
+		return nil, err
+	}
+	if !ownedBy(ctx, execution.WorkspaceID) {
+		return nil, vmmodels.ErrExecutionNotFound
+	}
+	return execution, nil
+}
+
+// ownedSession loads a session of the caller's workspace.
+func (s *ExecutionService) ownedSession(ctx context.Context, sessionID string) (*vmmodels.VMSession, error) {
+	session, err := s.sessionStore.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !ownedBy(ctx, session.WorkspaceID) {
+		return nil, vmmodels.ErrSessionNotFound
+	}
+	return session, nil
+}
+
 func normalizeRunFilePath(worktreePath, requestedPath string) (string, error) {
 	root, err := vmpath.NewWorktreeRoot(worktreePath)
 	if err != nil {
@@ -0,0 +1,249 @@
+package vmcontrol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+
+	"github.com/go-go-golems/vm-system/pkg/libloader"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+var (
+	libraryNamePattern    = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+	libraryVersionPattern = regexp.MustCompile(`^[0-9A-Za-z][0-9A-Za-z.+-]*$`)
+	globalNamePattern     = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+)
+
+// LibraryService owns the library catalog: the built-in libraries plus those
+// registered by users, whose code lives in the library cache.
+type LibraryService struct {
+	store     LibraryStorePort
+	templates TemplateStorePort
+	cacheDir  string
+}
+
+// NewLibraryService builds a LibraryService caching code under
+// libloader.DefaultCacheDir; Core uses the cache of its data directory.
+func NewLibraryService(store LibraryStorePort, templates TemplateStorePort) *LibraryService {
+	return &LibraryService{
+		store:     store,
+		templates: templates,
+		cacheDir:  libloader.DefaultCacheDir,
+	}
+}
+
+// List returns built-in libraries followed by registered ones.
+func (s *LibraryService) List(_ context.Context) ([]vmmodels.Library, error) {
+	libraries := vmmodels.BuiltinLibraries()
+	registered, err := s.store.ListLibraries()
+	if err != nil {
+		return nil, err
+	}
+	for _, lib := range registered {
+		libraries = append(libraries, *lib)
+	}
+	return libraries, nil
+}
+
+// Get looks up a library by ref.
+func (s *LibraryService) Get(_ context.Context, ref string) (*vmmodels.Library, error) {
+	if lib, ok := vmmodels.FindBuiltinLibrary(ref); ok {
+		return &lib, nil
+	}
+	return s.store.GetLibrary(ref)
+}
+
+// Resolve finds the catalog entry a template reference names.
+func (s *LibraryService) Resolve(ctx context.Context, reference string) (vmmodels.Library, error) {
+	catalog, err := s.List(ctx)
+	if err != nil {
+		return vmmodels.Library{}, err
+	}
+	return vmmodels.ResolveLibrary(reference, catalog)
+}
+
+// LoadOrder checks that template library references form a valid graph and
+// returns the libraries in the order sessions load them.
+func (s *LibraryService) LoadOrder(ctx context.Context, references []string) ([]vmmodels.Library, error) {
+	catalog, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return vmmodels.LibraryLoadOrder(references, catalog)
+}
+
+// Register adds a library to the catalog and stores its code in the cache.
+// The catalog is shared, so callers confined to a workspace cannot change it.
+func (s *LibraryService) Register(ctx context.Context, input RegisterLibraryInput) (*vmmodels.Library, error) {
+	if _, confined := WorkspaceFromContext(ctx); confined {
+		return nil, fmt.Errorf("%w: the library catalog is shared across workspaces", vmmodels.ErrWorkspaceForbidden)
+	}
+	if (input.Content != "") == (len(input.Tarball) > 0) {
+		return nil, fmt.Errorf("%w: exactly one of content or tarball is required", vmmodels.ErrInvalidLibrary)
+	}
+
+	lib := &vmmodels.Library{
+		ID:          strings.TrimSpace(input.Name),
+		Version:     strings.TrimSpace(input.Version),
+		Description: input.Description,
+		Config:      map[string]string{"global": strings.TrimSpace(input.Global)},
+	}
+
+	var code []byte
+	if input.Content != "" {
+		lib.Type = vmmodels.LibraryTypeUpload
+		code = []byte(input.Content)
+	} else {
+		pkg, err := libloader.ExtractTarball(input.Tarball, input.Entry)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", vmmodels.ErrInvalidLibrary, err)
+		}
+		if lib.ID == "" {
+			lib.ID = packageLibraryName(pkg.Name)
+		}
+		if lib.Version == "" {
+			lib.Version = pkg.Version
+		}
+		if lib.Description == "" {
+			lib.Description = pkg.Description
+		}
+		lib.Source = "npm:" + pkg.Name + "@" + pkg.Version
+		lib.Type = vmmodels.LibraryTypeTarball
+		lib.Config["entry"] = pkg.Entry
+		code = pkg.Code
+	}
+
+	lib.Name = lib.ID
+	lib.Ref = vmmodels.LibraryRef(lib.ID, lib.Version)
+	if err := validateLibrary(lib, code); err != nil {
+		return nil, err
+	}
+
+	// Pin dependencies to versions so registering new versions later can't
+	// change what this library loads after.
+	for _, dependency := range input.Dependencies {
+		dep, err := s.Resolve(ctx, strings.TrimSpace(dependency))
+		if err != nil {
+			return nil, err
+		}
+		lib.Dependencies = append(lib.Dependencies, vmmodels.LibraryReference(dep))
+	}
+	if _, err := s.LoadOrder(ctx, lib.Dependencies); err != nil {
+		return nil, err
+	}
+
+	if _, ok := vmmodels.FindBuiltinLibrary(lib.Ref); ok {
+		return nil, fmt.Errorf("%w: %s is a built-in library", vmmodels.ErrLibraryExists, lib.Ref)
+	}
+	if _, err := s.store.GetLibrary(lib.Ref); err == nil {
+		return nil, fmt.Errorf("%w: %s", vmmodels.ErrLibraryExists, lib.Ref)
+	} else if !errors.Is(err, vmmodels.ErrLibraryNotFound) {
+		return nil, err
+	}
+
+	cache, err := libloader.NewLibraryCache(s.cacheDir)
+	if err != nil {
+		return nil, err
+	}
+	sum, err := cache.Store(*lib, code)
+	if err != nil {
+		return nil, err
+	}
+	lib.SHA256 = sum
+
+	now := time.Now()
+	lib.CreatedAt = &now
+	if err := s.store.CreateLibrary(lib); err != nil {
+		_ = cache.Remove(*lib)
+		return nil, err
+	}
+	return lib, nil
+}
+
+// Remove unregisters a library and deletes its cached code. Libraries still
+// referenced by a template cannot be removed.
+func (s *LibraryService) Remove(ctx context.Context, ref string) error {
+	if _, confined := WorkspaceFromContext(ctx); confined {
+		return fmt.Errorf("%w: the library catalog is shared across workspaces", vmmodels.ErrWorkspaceForbidden)
+	}
+	if _, ok := vmmodels.FindBuiltinLibrary(ref); ok {
+		return fmt.Errorf("%w: built-in library %s cannot be removed", vmmodels.ErrInvalidLibrary, ref)
+	}
+	lib, err := s.store.GetLibrary(ref)
+	if err != nil {
+		return err
+	}
+
+	catalog, err := s.store.ListLibraries()
+	if err != nil {
+		return err
+	}
+	var users []string
+	for _, other := range catalog {
+		for _, dependency := range other.Dependencies {
+			if dependency == vmmodels.LibraryReference(*lib) {
+				users = append(users, "library "+vmmodels.LibraryReference(*other))
+				break
+			}
+		}
+	}
+
+	templates, err := s.templates.ListVMs()
+	if err != nil {
+		return err
+	}
+	for _, template := range templates {
+		for _, name := range template.Libraries {
+			if name == ref || name == vmmodels.LibraryReference(*lib) {
+				users = append(users, "template "+template.Name)
+				break
+			}
+		}
+	}
+	if len(users) > 0 {
+		sort.Strings(users)
+		return fmt.Errorf("%w: %s is used by %s", vmmodels.ErrLibraryInUse, ref, strings.Join(users, ", "))
+	}
+
+	if err := s.store.DeleteLibrary(ref); err != nil {
+		return err
+	}
+	cache, err := libloader.NewLibraryCache(s.cacheDir)
+	if err != nil {
+		return err
+	}
+	return cache.Remove(*lib)
+}
+
+func validateLibrary(lib *vmmodels.Library, code []byte) error {
+	if !libraryNamePattern.MatchString(lib.ID) {
+		return fmt.Errorf("%w: name %q must be lowercase letters, digits, '.', '_' or '-'", vmmodels.ErrInvalidLibrary, lib.ID)
+	}
+	if !libraryVersionPattern.MatchString(lib.Version) {
+		return fmt.Errorf("%w: version %q is invalid", vmmodels.ErrInvalidLibrary, lib.Version)
+	}
+	if !globalNamePattern.MatchString(lib.Global()) {
+		return fmt.Errorf("%w: global %q must be a JavaScript identifier", vmmodels.ErrInvalidLibrary, lib.Global())
+	}
+	if len(code) == 0 {
+		return fmt.Errorf("%w: library code is empty", vmmodels.ErrInvalidLibrary)
+	}
+	if _, err := goja.Compile(lib.Ref+".js", string(code), false); err != nil {
+		return fmt.Errorf("%w: %v", vmmodels.ErrInvalidLibrary, err)
+	}
+	return nil
+}
+
+// packageLibraryName turns an npm package name into a library name, e.g.
+// "@scope/pkg" becomes "scope-pkg".
+func packageLibraryName(name string) string {
+	name = strings.TrimPrefix(strings.ToLower(name), "@")
+	return strings.ReplaceAll(name, "/", "-")
+}
@@ -0,0 +1,83 @@
+package vmcontrol
+
+import (
+	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/libloader"
+	"github.com/go-go-golems/vm-system/pkg/vmexec"
+	"github.com/go-go-golems/vm-system/pkg/vmmodules"
+	"github.com/go-go-golems/vm-system/pkg/vmsession"
+)
+
+// Option customizes Core construction for embedding programs.
+type Option func(*coreConfig)
+
+type coreConfig struct {
+	modules *vmmodules.Registry
+	dataDir string
+	queue   vmexec.QueueConfig
+	quotas  vmsession.Quotas
+}
+
+func newCoreConfig(opts []Option) *coreConfig {
+	cfg := &coreConfig{
+		modules: vmmodules.DefaultRegistry(),
+		dataDir: libloader.DefaultDataDir,
+		queue:   vmexec.QueueConfig{Depth: vmexec.DefaultQueueDepth, MaxWait: vmexec.DefaultQueueMaxWait},
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(cfg)
+		}
+	}
+	return cfg
+}
+
+// WithModuleRegistry makes the registry's host modules selectable by
+// templates and installable into sessions.
+func WithModuleRegistry(registry *vmmodules.Registry) Option {
+	return func(cfg *coreConfig) {
+		if registry != nil {
+			cfg.modules = registry
+		}
+	}
+}
+
+// WithDataDir roots daemon-managed files, such as the library cache, at dir
+// instead of the working-directory-relative default.
+func WithDataDir(dir string) Option {
+	return func(cfg *coreConfig) {
+		if dir != "" {
+			cfg.dataDir = dir
+		}
+	}
+}
+
+// WithExecutionQueue bounds the per-session execution queue: depth executions
+// may wait behind the running one, each for at most maxWait (0 waits
+// indefinitely). A depth of 0 rejects concurrent executions with
+// ErrSessionBusy.
+func WithExecutionQueue(depth int, maxWait time.Duration) Option {
+	return func(cfg *coreConfig) {
+		cfg.queue.Depth = depth
+		cfg.queue.MaxWait = maxWait
+	}
+}
+
+// WithMaxConcurrentExecutions caps executions running at once across all
+// sessions. Executions beyond the cap wait for a slot, served round-robin
+// across workspaces; 0 leaves concurrency unbounded.
+func WithMaxConcurrentExecutions(n int) Option {
+	return func(cfg *coreConfig) {
+		cfg.queue.MaxConcurrent = n
+	}
+}
+
+// WithSessionQuotas limits live sessions globally, per template and per
+// workspace. Creating a session beyond a limit fails with one of the
+// vmmodels session quota errors.
+func WithSessionQuotas(quotas vmsession.Quotas) Option {
+	return func(cfg *coreConfig) {
+		cfg.quotas = quotas
+	}
+}
@@ -1,6 +1,9 @@
 package vmcontrol
 
 import (
+	"context"
+	"time"
+
 	"github.com/go-go-golems/vm-system/pkg/vmexec"
 	"github.com/go-go-golems/vm-system/pkg/vmmodels"
 	"github.com/go-go-golems/vm-system/pkg/vmsession"
@@ -17,8 +20,14 @@ type TemplateStorePort interface {
 	GetVMSettings(vmID string) (*vmmodels.VMSettings, error)
 	AddCapability(cap *vmmodels.VMCapability) error
 	ListCapabilities(vmID string) ([]*vmmodels.VMCapability, error)
+	DeleteCapability(id string) error
 	AddStartupFile(file *vmmodels.VMStartupFile) error
 	ListStartupFiles(vmID string) ([]*vmmodels.VMStartupFile, error)
+	UpdateStartupFile(file *vmmodels.VMStartupFile) error
+	DeleteStartupFile(id string) error
+	CreateTemplateRevision(rev *vmmodels.TemplateRevision) error
+	ListTemplateRevisions(vmID string) ([]*vmmodels.TemplateRevision, error)
+	GetTemplateRevision(vmID string, revision int) (*vmmodels.TemplateRevision, error)
 }
 
 // SessionStorePort defines persistent session operations used by the core.
@@ -27,10 +36,69 @@ type SessionStorePort interface {
 	ListSessions(status string) ([]*vmmodels.VMSession, error)
 }
 
-// StorePort combines template and session storage capabilities.
+// LibraryStorePort defines persistent library catalog operations used by the core.
+type LibraryStorePort interface {
+	CreateLibrary(lib *vmmodels.Library) error
+	GetLibrary(ref string) (*vmmodels.Library, error)
+	ListLibraries() ([]*vmmodels.Library, error)
+	DeleteLibrary(ref string) error
+}
+
+// TokenStorePort defines persistent API token operations used by the core.
+type TokenStorePort interface {
+	CreateAPIToken(token *vmmodels.APIToken) error
+	GetAPIToken(id string) (*vmmodels.APIToken, error)
+	GetAPITokenByHash(hash string) (*vmmodels.APIToken, error)
+	GetAPITokenByCertSubject(subject string) (*vmmodels.APIToken, error)
+	ListAPITokens() ([]*vmmodels.APIToken, error)
+	UpdateAPIToken(token *vmmodels.APIToken) error
+}
+
+// WorkspaceStorePort defines persistent workspace operations used by the core.
+type WorkspaceStorePort interface {
+	CreateWorkspace(workspace *vmmodels.Workspace) error
+	EnsureWorkspace(id string) error
+	GetWorkspace(id string) (*vmmodels.Workspace, error)
+	ListWorkspaces() ([]*vmmodels.Workspace, error)
+}
+
+// AuditStorePort defines append-only audit trail operations used by the core.
+type AuditStorePort interface {
+	AppendAuditEvent(event *vmmodels.AuditEvent) error
+	ListAuditEvents(filter vmmodels.AuditFilter) ([]*vmmodels.AuditEvent, error)
+}
+
+// StorePort combines template, library, session, workspace, token and audit
+// storage capabilities.
 type StorePort interface {
 	TemplateStorePort
+	LibraryStorePort
 	SessionStorePort
+	WorkspaceStorePort
+	TokenStorePort
+	AuditStorePort
+}
+
+// RuntimeStorePort defines the session, execution and event records the
+// session manager and executor persist.
+type RuntimeStorePort interface {
+	CreateSession(session *vmmodels.VMSession) error
+	UpdateSession(session *vmmodels.VMSession) error
+	TouchSession(id string, at time.Time) error
+	CreateExecution(exec *vmmodels.Execution) error
+	UpdateExecution(exec *vmmodels.Execution) error
+	GetExecution(id string) (*vmmodels.Execution, error)
+	ListExecutions(sessionID string, limit int) ([]*vmmodels.Execution, error)
+	AddEvent(event *vmmodels.ExecutionEvent) error
+	GetEvents(executionID string, afterSeq int) ([]*vmmodels.ExecutionEvent, error)
+}
+
+// Store is everything NewCore needs from persistence: the StorePort the
+// services use plus the RuntimeStorePort of the runtime it builds.
+// vmstore.VMStore and vmstore.MemoryStore implement it.
+type Store interface {
+	StorePort
+	RuntimeStorePort
 }
 
 // SessionRuntimePort defines runtime session orchestration operations.
@@ -39,15 +107,24 @@ type SessionRuntimePort interface {
 	GetSession(sessionID string) (*vmsession.Session, error)
 	CloseSession(sessionID string) error
 	ListSessions() []*vmsession.Session
+	Programs() *vmsession.ProgramCache
+	WarmPools() error
+	MaintainPools(ctx context.Context, interval time.Duration)
+	PoolStats() []vmsession.PoolStats
+	ExpireSessions(now time.Time) ([]string, error)
+	QuotaUsage() vmsession.QuotaUsage
 }
 
 // ExecutionRuntimePort defines runtime execution orchestration operations.
 type ExecutionRuntimePort interface {
-	ExecuteREPL(sessionID, input string) (*vmmodels.Execution, error)
-	ExecuteRunFile(sessionID, path string, args, env map[string]interface{}) (*vmmodels.Execution, error)
+	ExecuteREPL(sessionID, input string, opts ...vmexec.RunOption) (*vmmodels.Execution, error)
+	ExecuteRunFile(sessionID, path string, args, env map[string]interface{}, opts ...vmexec.RunOption) (*vmmodels.Execution, error)
 	ListExecutions(sessionID string, limit int) ([]*vmmodels.Execution, error)
 	GetExecution(executionID string) (*vmmodels.Execution, error)
 	GetEvents(executionID string, afterSeq int) ([]*vmmodels.ExecutionEvent, error)
+	CancelExecution(executionID string) (*vmmodels.Execution, error)
+	Usage() vmexec.ExecutionUsage
+	WorkspaceUsage(workspaceID string) vmexec.ExecutionUsage
 }
 
 var (
@@ -3,26 +3,87 @@ package vmcontrol
 import (
 	"context"
 	"sort"
+
+	"github.com/go-go-golems/vm-system/pkg/vmsession"
 )
 
 // RuntimeRegistry exposes active in-memory runtime visibility for ops/health.
 type RuntimeRegistry struct {
-	runtime SessionRuntimePort
+	runtime    SessionRuntimePort
+	executions ExecutionRuntimePort
+	templates  TemplateStorePort // nil outside Core: confined callers see no pools
 }
 
-func NewRuntimeRegistry(runtime SessionRuntimePort) *RuntimeRegistry {
-	return &RuntimeRegistry{runtime: runtime}
+func NewRuntimeRegistry(runtime SessionRuntimePort, executions ExecutionRuntimePort) *RuntimeRegistry {
+	return &RuntimeRegistry{runtime: runtime, executions: executions}
 }
 
-func (r *RuntimeRegistry) Summary(_ context.Context) RuntimeSummary {
+// Summary reports the daemon's runtime state. Callers confined to a
+// workspace only see their workspace's sessions, quota usage and executions,
+// and the pools of templates it owns.
+func (r *RuntimeRegistry) Summary(ctx context.Context) RuntimeSummary {
+	caller, confined := WorkspaceFromContext(ctx)
 	active := r.runtime.ListSessions()
+	if confined {
+		active = sessionsIn(active, caller)
+	}
 	sessionIDs := make([]string, 0, len(active))
 	for _, session := range active {
 		sessionIDs = append(sessionIDs, session.ID)
 	}
 	sort.Strings(sessionIDs)
-	return RuntimeSummary{
+	summary := RuntimeSummary{
 		ActiveSessions:  len(active),
 		ActiveSessionID: sessionIDs,
+		ProgramCache:    r.runtime.Programs().Stats(),
+		Pools:           r.runtime.PoolStats(),
+		SessionQuota:    r.runtime.QuotaUsage(),
+	}
+	if !confined {
+		summary.Executions = r.executions.Usage()
+		return summary
+	}
+	summary.Pools = r.ownedPools(summary.Pools, caller)
+	summary.SessionQuota = quotaUsageOf(summary.SessionQuota, active, caller)
+	summary.Executions = r.executions.WorkspaceUsage(caller)
+	return summary
+}
+
+// ownedPools keeps the pools of templates owned by workspaceID.
+func (r *RuntimeRegistry) ownedPools(pools []vmsession.PoolStats, workspaceID string) []vmsession.PoolStats {
+	owned := []vmsession.PoolStats{}
+	if r.templates == nil {
+		return owned
+	}
+	for _, pool := range pools {
+		if vm, err := r.templates.GetVM(pool.TemplateID); err == nil && vm.WorkspaceID == workspaceID {
+			owned = append(owned, pool)
+		}
+	}
+	return owned
+}
+
+func sessionsIn(sessions []*vmsession.Session, workspaceID string) []*vmsession.Session {
+	var in []*vmsession.Session
+	for _, session := range sessions {
+		if session.WorkspaceID == workspaceID {
+			in = append(in, session)
+		}
+	}
+	return in
+}
+
+// quotaUsageOf keeps usage's limits and counts the live sessions of
+// workspaceID in place of the daemon's.
+func quotaUsageOf(usage vmsession.QuotaUsage, sessions []*vmsession.Session, workspaceID string) vmsession.QuotaUsage {
+	usage.Sessions = len(sessions)
+	usage.ByTemplate = map[string]int{}
+	usage.ByWorkspace = map[string]int{}
+	for _, session := range sessions {
+		usage.ByTemplate[session.VMID]++
+	}
+	if len(sessions) > 0 {
+		usage.ByWorkspace[workspaceID] = len(sessions)
 	}
+	return usage
 }
@@ -3,14 +3,18 @@ package vmcontrol
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/go-go-golems/vm-system/pkg/vmmodels"
 )
 
 // SessionService owns lifecycle operations for runtime sessions.
 type SessionService struct {
-	store   SessionStorePort
-	runtime SessionRuntimePort
+	store      SessionStorePort
+	runtime    SessionRuntimePort
+	templates  TemplateStorePort // nil outside Core: template ownership is not checked
+	workspaces *WorkspaceService // nil outside Core: workspaces are not registered
 }
 
 func NewSessionService(store SessionStorePort, runtime SessionRuntimePort) *SessionService {
@@ -20,10 +24,42 @@ func NewSessionService(store SessionStorePort, runtime SessionRuntimePort) *Sess
 	}
 }
 
-func (s *SessionService) Create(_ context.Context, input CreateSessionInput) (*vmmodels.VMSession, error) {
+// Create starts a session in input.WorkspaceID, which callers confined to a
+// workspace may leave empty. Templates owned by a workspace only back
+// sessions in that workspace.
+func (s *SessionService) Create(ctx context.Context, input CreateSessionInput) (*vmmodels.VMSession, error) {
+	requested := strings.TrimSpace(input.WorkspaceID)
+	if caller, confined := WorkspaceFromContext(ctx); confined {
+		if requested != "" && requested != caller {
+			return nil, fmt.Errorf("%w: cannot create sessions in workspace %s", vmmodels.ErrWorkspaceForbidden, requested)
+		}
+		requested = caller
+	}
+	workspaceID, err := vmmodels.NormalizeWorkspaceID(requested)
+	if err != nil {
+		return nil, err
+	}
+	if s.templates != nil {
+		template, err := s.templates.GetVM(input.TemplateID)
+		if err != nil {
+			return nil, err
+		}
+		if !visibleTo(ctx, template.WorkspaceID) {
+			return nil, vmmodels.ErrVMNotFound
+		}
+		if template.WorkspaceID != "" && template.WorkspaceID != workspaceID {
+			return nil, fmt.Errorf("%w: template %s belongs to workspace %s", vmmodels.ErrWorkspaceForbidden, template.ID, template.WorkspaceID)
+		}
+	}
+	if s.workspaces != nil {
+		if err := s.workspaces.ensure(workspaceID); err != nil {
+			return nil, err
+		}
+	}
+
 	session, err := s.runtime.CreateSession(
 		input.TemplateID,
-		input.WorkspaceID,
+		workspaceID,
 		input.BaseCommitOID,
 		input.WorktreePath,
 	)
@@ -35,18 +71,90 @@ func (s *SessionService) Create(_ context.Context, input CreateSessionInput) (*v
 	if err != nil {
 		return nil, fmt.Errorf("session created but could not be loaded from store: %w", err)
 	}
+	s.withExpiry(out, time.Now())
 	return out, nil
 }
 
-func (s *SessionService) Get(_ context.Context, sessionID string) (*vmmodels.VMSession, error) {
-	return s.store.GetSession(sessionID)
+// WarmPools builds the idle runtimes of every pooled template now instead of
+// waiting for the next maintenance pass.
+func (s *SessionService) WarmPools(_ context.Context) error {
+	return s.runtime.WarmPools()
+}
+
+// MaintainPools keeps template pools filled until ctx is done.
+func (s *SessionService) MaintainPools(ctx context.Context, interval time.Duration) {
+	s.runtime.MaintainPools(ctx, interval)
 }
 
-func (s *SessionService) List(_ context.Context, status string) ([]*vmmodels.VMSession, error) {
-	return s.store.ListSessions(status)
+// Expire closes the live sessions whose template idle_timeout or
+// max_lifetime has passed at now and returns their IDs.
+func (s *SessionService) Expire(_ context.Context, now time.Time) ([]string, error) {
+	return s.runtime.ExpireSessions(now)
 }
 
-func (s *SessionService) Close(_ context.Context, sessionID string) (*vmmodels.VMSession, error) {
+func (s *SessionService) Get(ctx context.Context, sessionID string) (*vmmodels.VMSession, error) {
+	session, err := s.owned(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	s.withExpiry(session, time.Now())
+	return session, nil
+}
+
+// List returns the caller's sessions, optionally narrowed to one status and
+// one workspace.
+func (s *SessionService) List(ctx context.Context, status, workspaceID string) ([]*vmmodels.VMSession, error) {
+	sessions, err := s.store.ListSessions(status)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	visible := make([]*vmmodels.VMSession, 0, len(sessions))
+	for _, session := range sessions {
+		if !ownedBy(ctx, session.WorkspaceID) || (workspaceID != "" && session.WorkspaceID != workspaceID) {
+			continue
+		}
+		s.withExpiry(session, now)
+		visible = append(visible, session)
+	}
+	return visible, nil
+}
+
+// owned loads a session of the caller's workspace. Sessions of other
+// workspaces are reported as not found.
+func (s *SessionService) owned(ctx context.Context, sessionID string) (*vmmodels.VMSession, error) {
+	session, err := s.store.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !ownedBy(ctx, session.WorkspaceID) {
+		return nil, vmmodels.ErrSessionNotFound
+	}
+	return session, nil
+}
+
+// withExpiry fills in the time-to-expiry of a live session from its runtime.
+func (s *SessionService) withExpiry(session *vmmodels.VMSession, now time.Time) {
+	live, err := s.runtime.GetSession(session.ID)
+	if err != nil {
+		return
+	}
+	expiresAt, ok := live.ExpiresAt()
+	if !ok {
+		return
+	}
+	remaining := expiresAt.Sub(now).Milliseconds()
+	if remaining < 0 {
+		remaining = 0
+	}
+	session.ExpiresAt = &expiresAt
+	session.ExpiresInMs = &remaining
+}
+
+func (s *SessionService) Close(ctx context.Context, sessionID string) (*vmmodels.VMSession, error) {
+	if _, err := s.owned(ctx, sessionID); err != nil {
+		return nil, err
+	}
 	if err := s.runtime.CloseSession(sessionID); err != nil {
 		return nil, err
 	}
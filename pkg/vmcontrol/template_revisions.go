@@ -0,0 +1,204 @@
+package vmcontrol
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+// recordRevision snapshots the template and stores it as a new revision when
+// it differs from the latest one. It returns the template with its current
+// revision number.
+func (s *TemplateService) recordRevision(ctx context.Context, templateID string) (*vmmodels.VM, error) {
+	s.revisionMu.Lock()
+	defer s.revisionMu.Unlock()
+
+	spec, err := s.Export(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := s.store.ListTemplateRevisions(templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	next := 1
+	if len(revisions) > 0 {
+		latest := revisions[len(revisions)-1]
+		same, err := sameTemplateContent(latest.Spec, spec)
+		if err != nil {
+			return nil, err
+		}
+		if same {
+			return s.store.GetVM(templateID)
+		}
+		next = latest.Revision + 1
+	}
+
+	if err := s.store.CreateTemplateRevision(&vmmodels.TemplateRevision{
+		VMID:      templateID,
+		Revision:  next,
+		Spec:      spec,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return nil, fmt.Errorf("record template revision: %w", err)
+	}
+	return s.store.GetVM(templateID)
+}
+
+// sameTemplateContent compares two snapshots, ignoring the template name.
+func sameTemplateContent(a, b *vmmodels.TemplateSpec) (bool, error) {
+	encode := func(spec *vmmodels.TemplateSpec) (string, error) {
+		copySpec := *spec
+		copySpec.Name = ""
+		data, err := json.Marshal(copySpec)
+		return string(data), err
+	}
+	left, err := encode(a)
+	if err != nil {
+		return false, err
+	}
+	right, err := encode(b)
+	if err != nil {
+		return false, err
+	}
+	return left == right, nil
+}
+
+// ListRevisions returns the template's revision history, oldest first, with
+// each revision's changes relative to its predecessor.
+func (s *TemplateService) ListRevisions(ctx context.Context, templateID string) ([]*TemplateRevisionDiff, error) {
+	if _, err := s.readable(ctx, templateID); err != nil {
+		return nil, err
+	}
+	revisions, err := s.store.ListTemplateRevisions(templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*TemplateRevisionDiff, 0, len(revisions))
+	var previous *vmmodels.TemplateSpec
+	for _, revision := range revisions {
+		changes, err := diffSpecs(previous, revision.Spec)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, &TemplateRevisionDiff{TemplateRevision: revision, Changes: changes})
+		previous = revision.Spec
+	}
+	return out, nil
+}
+
+// GetRevision returns one template revision and its changes relative to its
+// predecessor.
+func (s *TemplateService) GetRevision(ctx context.Context, templateID string, revision int) (*TemplateRevisionDiff, error) {
+	if _, err := s.readable(ctx, templateID); err != nil {
+		return nil, err
+	}
+	current, err := s.store.GetTemplateRevision(templateID, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	var previous *vmmodels.TemplateSpec
+	if revision > 1 {
+		prev, err := s.store.GetTemplateRevision(templateID, revision-1)
+		if err != nil && !errors.Is(err, vmmodels.ErrTemplateRevisionNotFound) {
+			return nil, err
+		}
+		if prev != nil {
+			previous = prev.Spec
+		}
+	}
+
+	changes, err := diffSpecs(previous, current.Spec)
+	if err != nil {
+		return nil, err
+	}
+	return &TemplateRevisionDiff{TemplateRevision: current, Changes: changes}, nil
+}
+
+// diffSpecs lists the changes from previous to next. A nil previous means the
+// template was created at next.
+func diffSpecs(previous, next *vmmodels.TemplateSpec) ([]TemplateChange, error) {
+	if previous == nil {
+		return []TemplateChange{{Action: "create", Resource: "template", Name: next.Name}}, nil
+	}
+
+	changes := []TemplateChange{}
+	if previous.Engine != next.Engine {
+		changes = append(changes, TemplateChange{Action: "update", Resource: "engine", Name: next.Engine})
+	}
+	changes = append(changes, diffNames("module", previous.Modules, next.Modules)...)
+	changes = append(changes, diffNames("library", previous.Libraries, next.Libraries)...)
+
+	previousSettings, err := specSettingsToModel(previous.Settings)
+	if err != nil {
+		return nil, err
+	}
+	_, settingsChanges, err := mergeSettings(previousSettings, next.Settings)
+	if err != nil {
+		return nil, err
+	}
+	changes = append(changes, settingsChanges...)
+
+	previousCapabilities := make([]*vmmodels.VMCapability, 0, len(previous.Capabilities))
+	for _, capability := range previous.Capabilities {
+		previousCapabilities = append(previousCapabilities, specCapabilityModel(capability))
+	}
+	capabilityChanges, _ := diffCapabilities(previousCapabilities, next.Capabilities)
+	changes = append(changes, capabilityChanges...)
+
+	previousStartupFiles := make([]*vmmodels.VMStartupFile, 0, len(previous.StartupFiles))
+	for _, file := range previous.StartupFiles {
+		previousStartupFiles = append(previousStartupFiles, specStartupFileModel(file))
+	}
+	startupChanges, _ := diffStartupFiles(previousStartupFiles, next.StartupFiles)
+	changes = append(changes, startupChanges...)
+
+	return changes, nil
+}
+
+func specSettingsToModel(settings *vmmodels.TemplateSpecSettings) (*vmmodels.VMSettings, error) {
+	out := &vmmodels.VMSettings{
+		Limits:    json.RawMessage("{}"),
+		Resolver:  json.RawMessage("{}"),
+		Runtime:   json.RawMessage("{}"),
+		Pool:      json.RawMessage("{}"),
+		Lifecycle: json.RawMessage("{}"),
+	}
+	if settings == nil {
+		return out, nil
+	}
+	var err error
+	if settings.Limits != nil {
+		if out.Limits, err = json.Marshal(settings.Limits); err != nil {
+			return nil, err
+		}
+	}
+	if settings.Resolver != nil {
+		if out.Resolver, err = json.Marshal(settings.Resolver); err != nil {
+			return nil, err
+		}
+	}
+	if settings.Runtime != nil {
+		if out.Runtime, err = json.Marshal(settings.Runtime); err != nil {
+			return nil, err
+		}
+	}
+	if settings.Pool != nil {
+		if out.Pool, err = json.Marshal(settings.Pool); err != nil {
+			return nil, err
+		}
+	}
+	if settings.Lifecycle != nil {
+		if out.Lifecycle, err = json.Marshal(settings.Lifecycle); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
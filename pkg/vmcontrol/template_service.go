@@ -3,26 +3,104 @@ package vmcontrol
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/go-go-golems/vm-system/pkg/vmmodels"
 	"github.com/go-go-golems/vm-system/pkg/vmmodules"
+	"github.com/go-go-golems/vm-system/pkg/vmnet"
 )
 
 // TemplateService owns template CRUD and policy metadata operations.
 type TemplateService struct {
-	store TemplateStorePort
+	store      TemplateStorePort
+	modules    *vmmodules.Registry
+	libraries  *LibraryService   // nil outside Core: only built-ins are accepted
+	workspaces *WorkspaceService // nil outside Core: workspaces are not registered
+
+	// revisionMu serializes revision numbering.
+	revisionMu sync.Mutex
 }
 
 func NewTemplateService(store TemplateStorePort) *TemplateService {
-	return &TemplateService{store: store}
+	return &TemplateService{
+		store:   store,
+		modules: vmmodules.DefaultRegistry(),
+	}
+}
+
+// Create adds a template owned by input.WorkspaceID, or shared when that is
+// empty. Callers confined to a workspace always create templates in it.
+func (s *TemplateService) Create(ctx context.Context, input CreateTemplateInput) (*vmmodels.VM, error) {
+	workspaceID, err := s.ownerWorkspace(ctx, input.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	input.WorkspaceID = workspaceID
+	vm, err := s.create(input)
+	if err != nil {
+		return nil, err
+	}
+	return s.recordRevision(ctx, vm.ID)
+}
+
+// ownerWorkspace resolves the workspace a new template belongs to and
+// registers it.
+func (s *TemplateService) ownerWorkspace(ctx context.Context, requested string) (string, error) {
+	requested = strings.TrimSpace(requested)
+	if caller, confined := WorkspaceFromContext(ctx); confined {
+		if requested != "" && requested != caller {
+			return "", fmt.Errorf("%w: cannot create templates in workspace %s", vmmodels.ErrWorkspaceForbidden, requested)
+		}
+		requested = caller
+	}
+	if requested == "" {
+		return "", nil
+	}
+	workspaceID, err := vmmodels.NormalizeWorkspaceID(requested)
+	if err != nil {
+		return "", err
+	}
+	if s.workspaces != nil {
+		if err := s.workspaces.ensure(workspaceID); err != nil {
+			return "", err
+		}
+	}
+	return workspaceID, nil
 }
 
-func (s *TemplateService) Create(_ context.Context, input CreateTemplateInput) (*vmmodels.VM, error) {
+// readable loads a template the caller may see: a shared one or one of its
+// workspace. Others are reported as not found.
+func (s *TemplateService) readable(ctx context.Context, templateID string) (*vmmodels.VM, error) {
+	template, err := s.store.GetVM(templateID)
+	if err != nil {
+		return nil, err
+	}
+	if !visibleTo(ctx, template.WorkspaceID) {
+		return nil, vmmodels.ErrVMNotFound
+	}
+	return template, nil
+}
+
+// writable loads a template the caller may change. Shared templates are
+// read-only to callers confined to a workspace.
+func (s *TemplateService) writable(ctx context.Context, templateID string) (*vmmodels.VM, error) {
+	template, err := s.readable(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if !ownedBy(ctx, template.WorkspaceID) {
+		return nil, fmt.Errorf("%w: template %s is shared and read-only to workspace callers", vmmodels.ErrWorkspaceForbidden, templateID)
+	}
+	return template, nil
+}
+
+func (s *TemplateService) create(input CreateTemplateInput) (*vmmodels.VM, error) {
 	engine := input.Engine
 	if engine == "" {
 		engine = "goja"
@@ -30,20 +108,30 @@ func (s *TemplateService) Create(_ context.Context, input CreateTemplateInput) (
 
 	now := time.Now()
 	vm := &vmmodels.VM{
-		ID:        uuid.NewString(),
-		Name:      input.Name,
-		Engine:    engine,
-		IsActive:  true,
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:          uuid.NewString(),
+		Name:        input.Name,
+		Engine:      engine,
+		IsActive:    true,
+		WorkspaceID: input.WorkspaceID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
 	}
 
 	if err := s.store.CreateVM(vm); err != nil {
 		return nil, err
 	}
 
-	settings := &vmmodels.VMSettings{
-		VMID: vm.ID,
+	settings := defaultTemplateSettings(vm.ID)
+	if err := s.store.SetVMSettings(settings); err != nil {
+		return nil, err
+	}
+
+	return vm, nil
+}
+
+func defaultTemplateSettings(templateID string) *vmmodels.VMSettings {
+	return &vmmodels.VMSettings{
+		VMID: templateID,
 		Limits: vmmodels.MarshalJSONWithFallback(vmmodels.LimitsConfig{
 			CPUMs:       2000,
 			WallMs:      5000,
@@ -61,43 +149,239 @@ func (s *TemplateService) Create(_ context.Context, input CreateTemplateInput) (
 			Strict:  true,
 			Console: true,
 		}, json.RawMessage("{}")),
+		Pool:      json.RawMessage("{}"),
+		Lifecycle: json.RawMessage("{}"),
 	}
-	if err := s.store.SetVMSettings(settings); err != nil {
+}
+
+// List returns the templates the caller may see.
+func (s *TemplateService) List(ctx context.Context) ([]*vmmodels.VM, error) {
+	templates, err := s.store.ListVMs()
+	if err != nil {
 		return nil, err
 	}
+	visible := make([]*vmmodels.VM, 0, len(templates))
+	for _, template := range templates {
+		if visibleTo(ctx, template.WorkspaceID) {
+			visible = append(visible, template)
+		}
+	}
+	return visible, nil
+}
 
-	return vm, nil
+func (s *TemplateService) Get(ctx context.Context, templateID string) (*vmmodels.VM, error) {
+	return s.readable(ctx, templateID)
 }
 
-func (s *TemplateService) List(_ context.Context) ([]*vmmodels.VM, error) {
-	return s.store.ListVMs()
+func (s *TemplateService) Delete(ctx context.Context, templateID string) error {
+	if _, err := s.writable(ctx, templateID); err != nil {
+		return err
+	}
+	return s.store.DeleteVM(templateID)
 }
 
-func (s *TemplateService) Get(_ context.Context, templateID string) (*vmmodels.VM, error) {
+// Update renames and/or (de)activates a template. Inactive templates keep
+// their existing sessions but reject new ones.
+func (s *TemplateService) Update(ctx context.Context, templateID string, input UpdateTemplateInput) (*vmmodels.VM, error) {
+	template, err := s.writable(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name != template.Name {
+			if err := s.ensureNameAvailable(name); err != nil {
+				return nil, err
+			}
+			template.Name = name
+		}
+	}
+	if input.IsActive != nil {
+		template.IsActive = *input.IsActive
+	}
+
+	if err := s.store.UpdateVM(template); err != nil {
+		return nil, err
+	}
 	return s.store.GetVM(templateID)
 }
 
-func (s *TemplateService) Delete(_ context.Context, templateID string) error {
-	return s.store.DeleteVM(templateID)
+// Clone copies a template's settings, modules, libraries, capabilities, and
+// startup files into a new active template. The name defaults to
+// "<name>-copy". The clone belongs to the caller's workspace, or to the
+// source's when the caller is not confined to one.
+func (s *TemplateService) Clone(ctx context.Context, templateID string, input CloneTemplateInput) (*vmmodels.VM, error) {
+	source, err := s.readable(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	workspaceID := source.WorkspaceID
+	if caller, confined := WorkspaceFromContext(ctx); confined {
+		workspaceID = caller
+	}
+	settings, err := s.store.GetVMSettings(templateID)
+	if err != nil && !errors.Is(err, vmmodels.ErrVMNotFound) {
+		return nil, err
+	}
+	capabilities, err := s.store.ListCapabilities(templateID)
+	if err != nil {
+		return nil, err
+	}
+	startupFiles, err := s.store.ListStartupFiles(templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		name = source.Name + "-copy"
+	}
+	if err := s.ensureNameAvailable(name); err != nil {
+		return nil, err
+	}
+
+	clone, err := s.create(CreateTemplateInput{Name: name, Engine: source.Engine, WorkspaceID: workspaceID})
+	if err != nil {
+		return nil, err
+	}
+	clone.ExposedModules = append([]string(nil), source.ExposedModules...)
+	clone.Libraries = append([]string(nil), source.Libraries...)
+	if err := s.store.UpdateVM(clone); err != nil {
+		return nil, err
+	}
+	if settings != nil {
+		copied := *settings
+		copied.VMID = clone.ID
+		if err := s.store.SetVMSettings(&copied); err != nil {
+			return nil, err
+		}
+	}
+	for _, capability := range capabilities {
+		copied := *capability
+		copied.ID = uuid.NewString()
+		copied.VMID = clone.ID
+		if err := s.store.AddCapability(&copied); err != nil {
+			return nil, err
+		}
+	}
+	for _, file := range startupFiles {
+		copied := *file
+		copied.ID = uuid.NewString()
+		copied.VMID = clone.ID
+		if err := s.store.AddStartupFile(&copied); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.recordRevision(ctx, clone.ID)
 }
 
-func (s *TemplateService) SetSettings(_ context.Context, settings *vmmodels.VMSettings) error {
-	return s.store.SetVMSettings(settings)
+func (s *TemplateService) ensureNameAvailable(name string) error {
+	existing, err := s.findByName(name)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("%w: %s", vmmodels.ErrTemplateNameConflict, name)
+	}
+	return nil
 }
 
-func (s *TemplateService) GetSettings(_ context.Context, templateID string) (*vmmodels.VMSettings, error) {
+func (s *TemplateService) SetSettings(ctx context.Context, settings *vmmodels.VMSettings) error {
+	if _, err := s.writable(ctx, settings.VMID); err != nil {
+		return err
+	}
+	if err := s.store.SetVMSettings(settings); err != nil {
+		return err
+	}
+	_, err := s.recordRevision(ctx, settings.VMID)
+	return err
+}
+
+func (s *TemplateService) GetSettings(ctx context.Context, templateID string) (*vmmodels.VMSettings, error) {
+	if _, err := s.readable(ctx, templateID); err != nil {
+		return nil, err
+	}
 	return s.store.GetVMSettings(templateID)
 }
 
-func (s *TemplateService) AddCapability(_ context.Context, cap *vmmodels.VMCapability) error {
-	return s.store.AddCapability(cap)
+func (s *TemplateService) AddCapability(ctx context.Context, cap *vmmodels.VMCapability) error {
+	if _, err := s.writable(ctx, cap.VMID); err != nil {
+		return err
+	}
+	if err := validateCapability(cap); err != nil {
+		return err
+	}
+	if err := s.store.AddCapability(cap); err != nil {
+		return err
+	}
+	_, err := s.recordRevision(ctx, cap.VMID)
+	return err
+}
+
+func validateCapability(cap *vmmodels.VMCapability) error {
+	if cap.Kind != vmmodels.CapabilityKindNet {
+		return nil
+	}
+	if cap.Name != vmmodels.CapabilityFetch {
+		return fmt.Errorf("%w: unknown net capability %q", vmmodels.ErrInvalidCapability, cap.Name)
+	}
+	_, err := vmnet.ParseConfig(cap.Config)
+	return err
+}
+
+// DeleteCapability removes one capability from a template.
+func (s *TemplateService) DeleteCapability(ctx context.Context, templateID, capabilityID string) error {
+	if _, err := s.writable(ctx, templateID); err != nil {
+		return err
+	}
+	capabilities, err := s.store.ListCapabilities(templateID)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, capability := range capabilities {
+		if capability.ID == capabilityID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return vmmodels.ErrCapabilityNotFound
+	}
+
+	if err := s.store.DeleteCapability(capabilityID); err != nil {
+		return err
+	}
+	_, err = s.recordRevision(ctx, templateID)
+	return err
 }
 
-func (s *TemplateService) ListCapabilities(_ context.Context, templateID string) ([]*vmmodels.VMCapability, error) {
+func (s *TemplateService) ListCapabilities(ctx context.Context, templateID string) ([]*vmmodels.VMCapability, error) {
+	if _, err := s.readable(ctx, templateID); err != nil {
+		return nil, err
+	}
 	return s.store.ListCapabilities(templateID)
 }
 
-func (s *TemplateService) AddStartupFile(_ context.Context, file *vmmodels.VMStartupFile) error {
+func (s *TemplateService) AddStartupFile(ctx context.Context, file *vmmodels.VMStartupFile) error {
+	if _, err := s.writable(ctx, file.VMID); err != nil {
+		return err
+	}
+	if err := normalizeStartupFile(file); err != nil {
+		return err
+	}
+	if err := s.store.AddStartupFile(file); err != nil {
+		return err
+	}
+	_, err := s.recordRevision(ctx, file.VMID)
+	return err
+}
+
+// normalizeStartupFile applies kind and mode defaults and checks that inline
+// entries carry source and path entries do not.
+func normalizeStartupFile(file *vmmodels.VMStartupFile) error {
 	mode := strings.ToLower(strings.TrimSpace(file.Mode))
 	if mode == "" {
 		mode = "eval"
@@ -106,15 +390,156 @@ func (s *TemplateService) AddStartupFile(_ context.Context, file *vmmodels.VMSta
 		return fmt.Errorf("%w: %s", vmmodels.ErrStartupModeUnsupported, mode)
 	}
 	file.Mode = mode
-	return s.store.AddStartupFile(file)
+
+	kind := strings.ToLower(strings.TrimSpace(file.Kind))
+	switch kind {
+	case "", vmmodels.StartupKindPath:
+		if file.Source != "" {
+			return fmt.Errorf("%w: source is only allowed for inline startup files", vmmodels.ErrInvalidStartupFile)
+		}
+		file.Kind = vmmodels.StartupKindPath
+	case vmmodels.StartupKindInline:
+		if strings.TrimSpace(file.Source) == "" {
+			return fmt.Errorf("%w: inline startup file %s requires source", vmmodels.ErrInvalidStartupFile, file.Path)
+		}
+		file.Kind = vmmodels.StartupKindInline
+	default:
+		return fmt.Errorf("%w: unknown kind %q (expected path or inline)", vmmodels.ErrInvalidStartupFile, file.Kind)
+	}
+	return nil
+}
+
+// UpdateStartupFile edits a startup file in place. Source may only be set on
+// inline entries.
+func (s *TemplateService) UpdateStartupFile(ctx context.Context, templateID, startupFileID string, input UpdateStartupFileInput) (*vmmodels.VMStartupFile, error) {
+	if _, err := s.writable(ctx, templateID); err != nil {
+		return nil, err
+	}
+	files, err := s.store.ListStartupFiles(templateID)
+	if err != nil {
+		return nil, err
+	}
+	var file *vmmodels.VMStartupFile
+	for _, candidate := range files {
+		if candidate.ID == startupFileID {
+			file = candidate
+			break
+		}
+	}
+	if file == nil {
+		return nil, vmmodels.ErrStartupFileNotFound
+	}
+
+	if input.Source != nil {
+		if file.Kind != vmmodels.StartupKindInline {
+			return nil, fmt.Errorf("%w: source is only allowed for inline startup files", vmmodels.ErrInvalidStartupFile)
+		}
+		file.Source = *input.Source
+	}
+	if input.OrderIndex != nil {
+		file.OrderIndex = *input.OrderIndex
+	}
+	if input.Mode != nil {
+		file.Mode = *input.Mode
+	}
+	if err := normalizeStartupFile(file); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.UpdateStartupFile(file); err != nil {
+		return nil, err
+	}
+	if _, err := s.recordRevision(ctx, templateID); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// DeleteStartupFile removes one startup file from a template.
+func (s *TemplateService) DeleteStartupFile(ctx context.Context, templateID, startupFileID string) error {
+	if _, err := s.writable(ctx, templateID); err != nil {
+		return err
+	}
+	files, err := s.store.ListStartupFiles(templateID)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, file := range files {
+		if file.ID == startupFileID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return vmmodels.ErrStartupFileNotFound
+	}
+
+	if err := s.store.DeleteStartupFile(startupFileID); err != nil {
+		return err
+	}
+	_, err = s.recordRevision(ctx, templateID)
+	return err
 }
 
-func (s *TemplateService) ListStartupFiles(_ context.Context, templateID string) ([]*vmmodels.VMStartupFile, error) {
+// ReorderStartupFiles sets the startup order to startupFileIDs, which must
+// list every startup file of the template exactly once. Order indexes are
+// reassigned as 10, 20, 30, ...
+func (s *TemplateService) ReorderStartupFiles(ctx context.Context, templateID string, startupFileIDs []string) ([]*vmmodels.VMStartupFile, error) {
+	if _, err := s.writable(ctx, templateID); err != nil {
+		return nil, err
+	}
+	files, err := s.store.ListStartupFiles(templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*vmmodels.VMStartupFile, len(files))
+	for _, file := range files {
+		byID[file.ID] = file
+	}
+	if len(startupFileIDs) != len(files) {
+		return nil, fmt.Errorf("%w: expected %d startup file ids, got %d", vmmodels.ErrInvalidStartupOrder, len(files), len(startupFileIDs))
+	}
+	seen := make(map[string]bool, len(startupFileIDs))
+	for _, id := range startupFileIDs {
+		if _, ok := byID[id]; !ok {
+			return nil, fmt.Errorf("%w: %s", vmmodels.ErrStartupFileNotFound, id)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("%w: duplicate startup file id %s", vmmodels.ErrInvalidStartupOrder, id)
+		}
+		seen[id] = true
+	}
+
+	ordered := make([]*vmmodels.VMStartupFile, 0, len(startupFileIDs))
+	for i, id := range startupFileIDs {
+		file := byID[id]
+		orderIndex := (i + 1) * 10
+		if file.OrderIndex != orderIndex {
+			file.OrderIndex = orderIndex
+			if err := s.store.UpdateStartupFile(file); err != nil {
+				return nil, err
+			}
+		}
+		ordered = append(ordered, file)
+	}
+
+	if _, err := s.recordRevision(ctx, templateID); err != nil {
+		return nil, err
+	}
+	return ordered, nil
+}
+
+func (s *TemplateService) ListStartupFiles(ctx context.Context, templateID string) ([]*vmmodels.VMStartupFile, error) {
+	if _, err := s.readable(ctx, templateID); err != nil {
+		return nil, err
+	}
 	return s.store.ListStartupFiles(templateID)
 }
 
-func (s *TemplateService) ListModules(_ context.Context, templateID string) ([]string, error) {
-	template, err := s.store.GetVM(templateID)
+func (s *TemplateService) ListModules(ctx context.Context, templateID string) ([]string, error) {
+	template, err := s.readable(ctx, templateID)
 	if err != nil {
 		return nil, err
 	}
@@ -123,13 +548,19 @@ func (s *TemplateService) ListModules(_ context.Context, templateID string) ([]s
 	return modules, nil
 }
 
-func (s *TemplateService) AddModule(_ context.Context, templateID, moduleName string) error {
-	moduleName, err := vmmodules.ValidateConfiguredModuleName(moduleName)
+// ListAvailableModules returns the module catalog templates may select from,
+// including host modules registered by the embedding program.
+func (s *TemplateService) ListAvailableModules(_ context.Context) []vmmodels.ExposedModule {
+	return s.modules.Catalog()
+}
+
+func (s *TemplateService) AddModule(ctx context.Context, templateID, moduleName string) error {
+	moduleName, err := s.modules.Validate(moduleName)
 	if err != nil {
 		return err
 	}
 
-	template, err := s.store.GetVM(templateID)
+	template, err := s.writable(ctx, templateID)
 	if err != nil {
 		return err
 	}
@@ -141,11 +572,11 @@ func (s *TemplateService) AddModule(_ context.Context, templateID, moduleName st
 	}
 
 	template.ExposedModules = append(template.ExposedModules, moduleName)
-	return s.store.UpdateVM(template)
+	return s.updateTemplate(ctx, template)
 }
 
-func (s *TemplateService) RemoveModule(_ context.Context, templateID, moduleName string) error {
-	template, err := s.store.GetVM(templateID)
+func (s *TemplateService) RemoveModule(ctx context.Context, templateID, moduleName string) error {
+	template, err := s.writable(ctx, templateID)
 	if err != nil {
 		return err
 	}
@@ -164,11 +595,11 @@ func (s *TemplateService) RemoveModule(_ context.Context, templateID, moduleName
 	}
 
 	template.ExposedModules = filtered
-	return s.store.UpdateVM(template)
+	return s.updateTemplate(ctx, template)
 }
 
-func (s *TemplateService) ListLibraries(_ context.Context, templateID string) ([]string, error) {
-	template, err := s.store.GetVM(templateID)
+func (s *TemplateService) ListLibraries(ctx context.Context, templateID string) ([]string, error) {
+	template, err := s.readable(ctx, templateID)
 	if err != nil {
 		return nil, err
 	}
@@ -177,8 +608,11 @@ func (s *TemplateService) ListLibraries(_ context.Context, templateID string) ([
 	return libraries, nil
 }
 
-func (s *TemplateService) AddLibrary(_ context.Context, templateID, libraryName string) error {
-	template, err := s.store.GetVM(templateID)
+// AddLibrary adds a library reference to the template. A bare name is pinned
+// to its only version as "name@version". The resulting library set must have
+// a valid load order.
+func (s *TemplateService) AddLibrary(ctx context.Context, templateID, libraryName string) error {
+	template, err := s.writable(ctx, templateID)
 	if err != nil {
 		return err
 	}
@@ -189,15 +623,60 @@ func (s *TemplateService) AddLibrary(_ context.Context, templateID, libraryName
 		}
 	}
 
-	template.Libraries = append(template.Libraries, libraryName)
-	return s.store.UpdateVM(template)
+	catalog, err := s.libraryCatalog(ctx)
+	if err != nil {
+		return err
+	}
+	lib, err := vmmodels.ResolveLibrary(libraryName, catalog)
+	if err != nil {
+		return err
+	}
+	for _, existing := range template.Libraries {
+		if other, err := vmmodels.ResolveLibrary(existing, catalog); err == nil && other.Ref == lib.Ref {
+			return nil
+		}
+	}
+
+	libraries := append(append([]string(nil), template.Libraries...), pinLibraryReference(libraryName, lib))
+	if _, err := vmmodels.LibraryLoadOrder(libraries, catalog); err != nil {
+		return err
+	}
+
+	template.Libraries = libraries
+	return s.updateTemplate(ctx, template)
 }
 
-func (s *TemplateService) RemoveLibrary(_ context.Context, templateID, libraryName string) error {
-	template, err := s.store.GetVM(templateID)
+// libraryCatalog returns the libraries templates may reference. Without a
+// LibraryService (outside Core) only built-ins are known.
+func (s *TemplateService) libraryCatalog(ctx context.Context) ([]vmmodels.Library, error) {
+	if s.libraries != nil {
+		return s.libraries.List(ctx)
+	}
+	return vmmodels.BuiltinLibraries(), nil
+}
+
+// pinLibraryReference keeps versioned references as written and turns a bare
+// name into "name@version".
+func pinLibraryReference(reference string, lib vmmodels.Library) string {
+	if reference == lib.ID {
+		return vmmodels.LibraryReference(lib)
+	}
+	return reference
+}
+
+func (s *TemplateService) RemoveLibrary(ctx context.Context, templateID, libraryName string) error {
+	template, err := s.writable(ctx, templateID)
+	if err != nil {
+		return err
+	}
+
+	// Match other spellings of the same library too, e.g. lodash@4.17.21 for
+	// lodash-4.17.21.
+	catalog, err := s.libraryCatalog(ctx)
 	if err != nil {
 		return err
 	}
+	target, targetErr := vmmodels.ResolveLibrary(libraryName, catalog)
 
 	filtered := make([]string, 0, len(template.Libraries))
 	changed := false
@@ -206,6 +685,12 @@ func (s *TemplateService) RemoveLibrary(_ context.Context, templateID, libraryNa
 			changed = true
 			continue
 		}
+		if targetErr == nil {
+			if lib, err := vmmodels.ResolveLibrary(existing, catalog); err == nil && lib.Ref == target.Ref {
+				changed = true
+				continue
+			}
+		}
 		filtered = append(filtered, existing)
 	}
 	if !changed {
@@ -213,5 +698,13 @@ func (s *TemplateService) RemoveLibrary(_ context.Context, templateID, libraryNa
 	}
 
 	template.Libraries = filtered
-	return s.store.UpdateVM(template)
+	return s.updateTemplate(ctx, template)
+}
+
+func (s *TemplateService) updateTemplate(ctx context.Context, template *vmmodels.VM) error {
+	if err := s.store.UpdateVM(template); err != nil {
+		return err
+	}
+	_, err := s.recordRevision(ctx, template.ID)
+	return err
 }
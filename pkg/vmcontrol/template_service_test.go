@@ -106,8 +106,9 @@ func TestTemplateServiceAddModuleAcceptsRegisteredNativeModule(t *testing.T) {
 }
 
 type templateStoreStub struct {
-	vm       *vmmodels.VM
-	settings *vmmodels.VMSettings
+	vm        *vmmodels.VM
+	settings  *vmmodels.VMSettings
+	revisions []*vmmodels.TemplateRevision
 }
 
 func (s *templateStoreStub) CreateVM(vm *vmmodels.VM) error {
@@ -141,7 +142,10 @@ func (s *templateStoreStub) SetVMSettings(settings *vmmodels.VMSettings) error {
 }
 
 func (s *templateStoreStub) GetVMSettings(vmID string) (*vmmodels.VMSettings, error) {
-	return nil, errors.New("not implemented")
+	if s.settings == nil || s.settings.VMID != vmID {
+		return nil, vmmodels.ErrVMNotFound
+	}
+	return s.settings, nil
 }
 
 func (s *templateStoreStub) AddCapability(cap *vmmodels.VMCapability) error {
@@ -149,7 +153,7 @@ func (s *templateStoreStub) AddCapability(cap *vmmodels.VMCapability) error {
 }
 
 func (s *templateStoreStub) ListCapabilities(vmID string) ([]*vmmodels.VMCapability, error) {
-	return nil, errors.New("not implemented")
+	return nil, nil
 }
 
 func (s *templateStoreStub) AddStartupFile(file *vmmodels.VMStartupFile) error {
@@ -157,5 +161,35 @@ func (s *templateStoreStub) AddStartupFile(file *vmmodels.VMStartupFile) error {
 }
 
 func (s *templateStoreStub) ListStartupFiles(vmID string) ([]*vmmodels.VMStartupFile, error) {
-	return nil, errors.New("not implemented")
+	return nil, nil
+}
+
+func (s *templateStoreStub) DeleteCapability(id string) error {
+	return errors.New("not implemented")
+}
+
+func (s *templateStoreStub) UpdateStartupFile(file *vmmodels.VMStartupFile) error {
+	return errors.New("not implemented")
+}
+
+func (s *templateStoreStub) DeleteStartupFile(id string) error {
+	return errors.New("not implemented")
+}
+
+func (s *templateStoreStub) CreateTemplateRevision(revision *vmmodels.TemplateRevision) error {
+	s.revisions = append(s.revisions, revision)
+	return nil
+}
+
+func (s *templateStoreStub) ListTemplateRevisions(vmID string) ([]*vmmodels.TemplateRevision, error) {
+	return s.revisions, nil
+}
+
+func (s *templateStoreStub) GetTemplateRevision(vmID string, revision int) (*vmmodels.TemplateRevision, error) {
+	for _, r := range s.revisions {
+		if r.VMID == vmID && r.Revision == revision {
+			return r, nil
+		}
+	}
+	return nil, vmmodels.ErrTemplateRevisionNotFound
 }
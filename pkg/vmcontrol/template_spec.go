@@ -0,0 +1,568 @@
+package vmcontrol
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+	"github.com/go-go-golems/vm-system/pkg/vmpath"
+)
+
+// Export renders a template as a declarative spec.
+func (s *TemplateService) Export(ctx context.Context, templateID string) (*vmmodels.TemplateSpec, error) {
+	template, err := s.readable(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := s.store.GetVMSettings(templateID)
+	if err != nil && !errors.Is(err, vmmodels.ErrVMNotFound) {
+		return nil, err
+	}
+	capabilities, err := s.store.ListCapabilities(templateID)
+	if err != nil {
+		return nil, err
+	}
+	startupFiles, err := s.store.ListStartupFiles(templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &vmmodels.TemplateSpec{
+		Name:      template.Name,
+		Engine:    template.Engine,
+		Modules:   append([]string(nil), template.ExposedModules...),
+		Libraries: append([]string(nil), template.Libraries...),
+	}
+	if settings != nil {
+		if spec.Settings, err = settingsToSpec(settings); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(capabilities, func(i, j int) bool {
+		if capabilities[i].Kind != capabilities[j].Kind {
+			return capabilities[i].Kind < capabilities[j].Kind
+		}
+		return capabilities[i].Name < capabilities[j].Name
+	})
+	for _, capability := range capabilities {
+		enabled := capability.Enabled
+		specCapability := vmmodels.TemplateSpecCapability{
+			Kind:    capability.Kind,
+			Name:    capability.Name,
+			Enabled: &enabled,
+		}
+		if len(capability.Config) > 0 {
+			if err := json.Unmarshal(capability.Config, &specCapability.Config); err != nil {
+				return nil, fmt.Errorf("decode capability %s:%s config: %w", capability.Kind, capability.Name, err)
+			}
+			if len(specCapability.Config) == 0 {
+				specCapability.Config = nil
+			}
+		}
+		spec.Capabilities = append(spec.Capabilities, specCapability)
+	}
+
+	for _, file := range startupFiles {
+		specFile := vmmodels.TemplateSpecStartupFile{
+			Path:       file.Path,
+			OrderIndex: file.OrderIndex,
+			Mode:       file.Mode,
+		}
+		if file.Kind == vmmodels.StartupKindInline {
+			specFile.Kind = file.Kind
+			specFile.Source = file.Source
+		}
+		spec.StartupFiles = append(spec.StartupFiles, specFile)
+	}
+
+	return spec, nil
+}
+
+// Apply creates the template named by spec, or updates it in place so it
+// matches spec. Resources absent from spec are removed, except settings
+// sections, which are only replaced when present. With dryRun the changes are
+// computed but not persisted.
+func (s *TemplateService) Apply(ctx context.Context, spec *vmmodels.TemplateSpec, dryRun bool) (*ApplyTemplateResult, error) {
+	desired, err := s.normalizeSpec(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := s.findByName(desired.Name)
+	if err != nil {
+		return nil, err
+	}
+	if template != nil {
+		// Names are unique across workspaces; a template the caller cannot
+		// see still holds its name.
+		if !visibleTo(ctx, template.WorkspaceID) {
+			return nil, fmt.Errorf("%w: %s", vmmodels.ErrTemplateNameConflict, desired.Name)
+		}
+		if template, err = s.writable(ctx, template.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &ApplyTemplateResult{DryRun: dryRun, Changes: []TemplateChange{}}
+	current := &vmmodels.VM{Name: desired.Name, Engine: desired.Engine}
+	settings := defaultTemplateSettings("")
+	var capabilities []*vmmodels.VMCapability
+	var startupFiles []*vmmodels.VMStartupFile
+
+	if template == nil {
+		result.Created = true
+		result.Changes = append(result.Changes, TemplateChange{Action: "create", Resource: "template", Name: desired.Name})
+		if !dryRun {
+			workspaceID, err := s.ownerWorkspace(ctx, "")
+			if err != nil {
+				return nil, err
+			}
+			template, err = s.create(CreateTemplateInput{Name: desired.Name, Engine: desired.Engine, WorkspaceID: workspaceID})
+			if err != nil {
+				return nil, err
+			}
+			current = template
+		}
+	} else {
+		current = template
+		if settings, err = s.store.GetVMSettings(template.ID); err != nil {
+			return nil, err
+		}
+		if capabilities, err = s.store.ListCapabilities(template.ID); err != nil {
+			return nil, err
+		}
+		if startupFiles, err = s.store.ListStartupFiles(template.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Engine, modules, and libraries live on the template row.
+	templateChanged := false
+	if current.Engine != desired.Engine {
+		result.Changes = append(result.Changes, TemplateChange{Action: "update", Resource: "engine", Name: desired.Engine})
+		current.Engine = desired.Engine
+		templateChanged = true
+	}
+	if changes := diffNames("module", current.ExposedModules, desired.Modules); len(changes) > 0 {
+		result.Changes = append(result.Changes, changes...)
+		current.ExposedModules = desired.Modules
+		templateChanged = true
+	}
+	if changes := diffNames("library", current.Libraries, desired.Libraries); len(changes) > 0 {
+		result.Changes = append(result.Changes, changes...)
+		current.Libraries = desired.Libraries
+		templateChanged = true
+	}
+
+	newSettings, settingsChanges, err := mergeSettings(settings, desired.Settings)
+	if err != nil {
+		return nil, err
+	}
+	result.Changes = append(result.Changes, settingsChanges...)
+
+	capabilityChanges, capabilityOps := diffCapabilities(capabilities, desired.Capabilities)
+	result.Changes = append(result.Changes, capabilityChanges...)
+	startupChanges, startupOps := diffStartupFiles(startupFiles, desired.StartupFiles)
+	result.Changes = append(result.Changes, startupChanges...)
+
+	if dryRun {
+		if template != nil {
+			result.Template = template
+		}
+		return result, nil
+	}
+
+	if templateChanged {
+		if err := s.store.UpdateVM(current); err != nil {
+			return nil, err
+		}
+	}
+	if len(settingsChanges) > 0 {
+		newSettings.VMID = current.ID
+		if err := s.store.SetVMSettings(newSettings); err != nil {
+			return nil, err
+		}
+	}
+	for _, op := range capabilityOps {
+		if op.remove != "" {
+			if err := s.store.DeleteCapability(op.remove); err != nil {
+				return nil, err
+			}
+		}
+		if op.add != nil {
+			op.add.VMID = current.ID
+			if err := s.store.AddCapability(op.add); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, op := range startupOps {
+		if op.remove != "" {
+			if err := s.store.DeleteStartupFile(op.remove); err != nil {
+				return nil, err
+			}
+		}
+		if op.add != nil {
+			op.add.VMID = current.ID
+			if err := s.store.AddStartupFile(op.add); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	result.Template, err = s.recordRevision(ctx, current.ID)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *TemplateService) findByName(name string) (*vmmodels.VM, error) {
+	templates, err := s.store.ListVMs()
+	if err != nil {
+		return nil, err
+	}
+	for _, template := range templates {
+		if template.Name == name {
+			return template, nil
+		}
+	}
+	return nil, nil
+}
+
+// normalizeSpec validates spec and returns a copy with defaults applied.
+func (s *TemplateService) normalizeSpec(ctx context.Context, spec *vmmodels.TemplateSpec) (*vmmodels.TemplateSpec, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("%w: spec is required", vmmodels.ErrInvalidTemplateSpec)
+	}
+	out := &vmmodels.TemplateSpec{
+		Name:     strings.TrimSpace(spec.Name),
+		Engine:   strings.TrimSpace(spec.Engine),
+		Settings: spec.Settings,
+	}
+	if out.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", vmmodels.ErrInvalidTemplateSpec)
+	}
+	if out.Engine == "" {
+		out.Engine = "goja"
+	}
+	if out.Settings != nil && out.Settings.Pool != nil {
+		if err := out.Settings.Pool.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %v", vmmodels.ErrInvalidTemplateSpec, err)
+		}
+	}
+	if out.Settings != nil && out.Settings.Lifecycle != nil {
+		if err := out.Settings.Lifecycle.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %v", vmmodels.ErrInvalidTemplateSpec, err)
+		}
+	}
+
+	seen := map[string]struct{}{}
+	for _, name := range spec.Modules {
+		normalized, err := s.modules.Validate(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[normalized]; ok {
+			continue
+		}
+		seen[normalized] = struct{}{}
+		out.Modules = append(out.Modules, normalized)
+	}
+
+	catalog, err := s.libraryCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seen = map[string]struct{}{}
+	for _, name := range spec.Libraries {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: library name is required", vmmodels.ErrInvalidTemplateSpec)
+		}
+		lib, err := vmmodels.ResolveLibrary(name, catalog)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", vmmodels.ErrInvalidTemplateSpec, err)
+		}
+		if _, ok := seen[lib.Ref]; ok {
+			continue
+		}
+		seen[lib.Ref] = struct{}{}
+		out.Libraries = append(out.Libraries, pinLibraryReference(name, lib))
+	}
+	if _, err := vmmodels.LibraryLoadOrder(out.Libraries, catalog); err != nil {
+		return nil, fmt.Errorf("%w: %v", vmmodels.ErrInvalidTemplateSpec, err)
+	}
+
+	seen = map[string]struct{}{}
+	for _, capability := range spec.Capabilities {
+		if capability.Kind == "" || capability.Name == "" {
+			return nil, fmt.Errorf("%w: capability kind and name are required", vmmodels.ErrInvalidTemplateSpec)
+		}
+		key := capability.Kind + ":" + capability.Name
+		if _, ok := seen[key]; ok {
+			return nil, fmt.Errorf("%w: duplicate capability %s", vmmodels.ErrInvalidTemplateSpec, key)
+		}
+		seen[key] = struct{}{}
+		if err := validateCapability(specCapabilityModel(capability)); err != nil {
+			return nil, err
+		}
+		out.Capabilities = append(out.Capabilities, capability)
+	}
+
+	seen = map[string]struct{}{}
+	for _, file := range spec.StartupFiles {
+		relPath, err := vmpath.ParseRelWorktreePath(file.Path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: startup file path %q: %v", vmmodels.ErrInvalidTemplateSpec, file.Path, err)
+		}
+		file.Path = relPath.String()
+		if _, ok := seen[file.Path]; ok {
+			return nil, fmt.Errorf("%w: duplicate startup file %s", vmmodels.ErrInvalidTemplateSpec, file.Path)
+		}
+		seen[file.Path] = struct{}{}
+		model := specStartupFileModel(file)
+		if err := normalizeStartupFile(model); err != nil {
+			if errors.Is(err, vmmodels.ErrInvalidStartupFile) {
+				return nil, fmt.Errorf("%w: %v", vmmodels.ErrInvalidTemplateSpec, err)
+			}
+			return nil, err
+		}
+		file.Mode = model.Mode
+		// Path entries are the default kind and are left implicit.
+		file.Kind = ""
+		if model.Kind == vmmodels.StartupKindInline {
+			file.Kind = model.Kind
+		}
+		out.StartupFiles = append(out.StartupFiles, file)
+	}
+
+	return out, nil
+}
+
+func specCapabilityModel(capability vmmodels.TemplateSpecCapability) *vmmodels.VMCapability {
+	return &vmmodels.VMCapability{
+		ID:      uuid.NewString(),
+		Kind:    capability.Kind,
+		Name:    capability.Name,
+		Enabled: capability.IsEnabled(),
+		Config:  canonicalConfig(capability.Config),
+	}
+}
+
+func canonicalConfig(config map[string]interface{}) json.RawMessage {
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+	return vmmodels.MarshalJSONWithFallback(config, json.RawMessage("{}"))
+}
+
+func canonicalRawConfig(raw json.RawMessage) string {
+	var config map[string]interface{}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &config); err != nil {
+			return string(raw)
+		}
+	}
+	return string(canonicalConfig(config))
+}
+
+func settingsToSpec(settings *vmmodels.VMSettings) (*vmmodels.TemplateSpecSettings, error) {
+	out := &vmmodels.TemplateSpecSettings{
+		Limits:   &vmmodels.LimitsConfig{},
+		Resolver: &vmmodels.ResolverConfig{},
+		Runtime:  &vmmodels.RuntimeConfig{},
+	}
+	if err := json.Unmarshal(settings.Limits, out.Limits); err != nil {
+		return nil, fmt.Errorf("decode limits settings: %w", err)
+	}
+	if err := json.Unmarshal(settings.Resolver, out.Resolver); err != nil {
+		return nil, fmt.Errorf("decode resolver settings: %w", err)
+	}
+	if err := json.Unmarshal(settings.Runtime, out.Runtime); err != nil {
+		return nil, fmt.Errorf("decode runtime settings: %w", err)
+	}
+	pool, err := vmmodels.ParsePoolConfig(settings.Pool)
+	if err != nil {
+		return nil, err
+	}
+	if pool != (vmmodels.PoolConfig{}) {
+		out.Pool = &pool
+	}
+	lifecycle, err := vmmodels.ParseLifecycleConfig(settings.Lifecycle)
+	if err != nil {
+		return nil, err
+	}
+	if lifecycle != (vmmodels.LifecycleConfig{}) {
+		out.Lifecycle = &lifecycle
+	}
+	return out, nil
+}
+
+// mergeSettings overlays the sections present in desired onto current.
+func mergeSettings(current *vmmodels.VMSettings, desired *vmmodels.TemplateSpecSettings) (*vmmodels.VMSettings, []TemplateChange, error) {
+	merged := *current
+	changes := []TemplateChange{}
+	if desired == nil {
+		return &merged, changes, nil
+	}
+
+	overlay := func(name string, value interface{}, target *json.RawMessage) error {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("encode %s settings: %w", name, err)
+		}
+		if canonicalRawConfig(*target) == canonicalRawConfig(data) {
+			return nil
+		}
+		*target = data
+		changes = append(changes, TemplateChange{Action: "update", Resource: "settings", Name: name})
+		return nil
+	}
+	if desired.Limits != nil {
+		if err := overlay("limits", desired.Limits, &merged.Limits); err != nil {
+			return nil, nil, err
+		}
+	}
+	if desired.Resolver != nil {
+		if err := overlay("resolver", desired.Resolver, &merged.Resolver); err != nil {
+			return nil, nil, err
+		}
+	}
+	if desired.Runtime != nil {
+		if err := overlay("runtime", desired.Runtime, &merged.Runtime); err != nil {
+			return nil, nil, err
+		}
+	}
+	if desired.Pool != nil {
+		if err := overlay("pool", desired.Pool, &merged.Pool); err != nil {
+			return nil, nil, err
+		}
+	}
+	if desired.Lifecycle != nil {
+		if err := overlay("lifecycle", desired.Lifecycle, &merged.Lifecycle); err != nil {
+			return nil, nil, err
+		}
+	}
+	return &merged, changes, nil
+}
+
+func diffNames(resource string, current, desired []string) []TemplateChange {
+	changes := []TemplateChange{}
+	currentSet := map[string]struct{}{}
+	for _, name := range current {
+		currentSet[name] = struct{}{}
+	}
+	desiredSet := map[string]struct{}{}
+	for _, name := range desired {
+		desiredSet[name] = struct{}{}
+		if _, ok := currentSet[name]; !ok {
+			changes = append(changes, TemplateChange{Action: "add", Resource: resource, Name: name})
+		}
+	}
+	for _, name := range current {
+		if _, ok := desiredSet[name]; !ok {
+			changes = append(changes, TemplateChange{Action: "remove", Resource: resource, Name: name})
+		}
+	}
+	return changes
+}
+
+type capabilityOp struct {
+	remove string
+	add    *vmmodels.VMCapability
+}
+
+func diffCapabilities(current []*vmmodels.VMCapability, desired []vmmodels.TemplateSpecCapability) ([]TemplateChange, []capabilityOp) {
+	changes := []TemplateChange{}
+	ops := []capabilityOp{}
+	byKey := map[string]*vmmodels.VMCapability{}
+	for _, capability := range current {
+		byKey[capability.Kind+":"+capability.Name] = capability
+	}
+
+	desiredKeys := map[string]struct{}{}
+	for _, capability := range desired {
+		key := capability.Kind + ":" + capability.Name
+		desiredKeys[key] = struct{}{}
+		want := specCapabilityModel(capability)
+		existing, ok := byKey[key]
+		switch {
+		case !ok:
+			changes = append(changes, TemplateChange{Action: "add", Resource: "capability", Name: key})
+			ops = append(ops, capabilityOp{add: want})
+		case existing.Enabled != want.Enabled || canonicalRawConfig(existing.Config) != string(want.Config):
+			want.ID = existing.ID
+			changes = append(changes, TemplateChange{Action: "update", Resource: "capability", Name: key})
+			ops = append(ops, capabilityOp{remove: existing.ID, add: want})
+		}
+	}
+	for _, capability := range current {
+		key := capability.Kind + ":" + capability.Name
+		if _, ok := desiredKeys[key]; !ok {
+			changes = append(changes, TemplateChange{Action: "remove", Resource: "capability", Name: key})
+			ops = append(ops, capabilityOp{remove: capability.ID})
+		}
+	}
+	return changes, ops
+}
+
+type startupFileOp struct {
+	remove string
+	add    *vmmodels.VMStartupFile
+}
+
+func diffStartupFiles(current []*vmmodels.VMStartupFile, desired []vmmodels.TemplateSpecStartupFile) ([]TemplateChange, []startupFileOp) {
+	changes := []TemplateChange{}
+	ops := []startupFileOp{}
+	byPath := map[string]*vmmodels.VMStartupFile{}
+	for _, file := range current {
+		byPath[file.Path] = file
+	}
+
+	desiredPaths := map[string]struct{}{}
+	for _, file := range desired {
+		desiredPaths[file.Path] = struct{}{}
+		want := specStartupFileModel(file)
+		want.ID = uuid.NewString()
+		existing, ok := byPath[file.Path]
+		switch {
+		case !ok:
+			changes = append(changes, TemplateChange{Action: "add", Resource: "startup_file", Name: file.Path})
+			ops = append(ops, startupFileOp{add: want})
+		case existing.OrderIndex != want.OrderIndex || existing.Mode != want.Mode || existing.Kind != want.Kind || existing.Source != want.Source:
+			want.ID = existing.ID
+			changes = append(changes, TemplateChange{Action: "update", Resource: "startup_file", Name: file.Path})
+			ops = append(ops, startupFileOp{remove: existing.ID, add: want})
+		}
+	}
+	for _, file := range current {
+		if _, ok := desiredPaths[file.Path]; !ok {
+			changes = append(changes, TemplateChange{Action: "remove", Resource: "startup_file", Name: file.Path})
+			ops = append(ops, startupFileOp{remove: file.ID})
+		}
+	}
+	return changes, ops
+}
+
+// specStartupFileModel converts a spec startup entry into its stored form.
+func specStartupFileModel(file vmmodels.TemplateSpecStartupFile) *vmmodels.VMStartupFile {
+	kind := file.Kind
+	if kind == "" {
+		kind = vmmodels.StartupKindPath
+	}
+	return &vmmodels.VMStartupFile{
+		Kind:       kind,
+		Path:       file.Path,
+		Source:     file.Source,
+		OrderIndex: file.OrderIndex,
+		Mode:       file.Mode,
+	}
+}
@@ -1,9 +1,52 @@
 package vmcontrol
 
+import (
+	"github.com/go-go-golems/vm-system/pkg/vmexec"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+	"github.com/go-go-golems/vm-system/pkg/vmsession"
+)
+
 // CreateTemplateInput is the public input model for template creation.
 type CreateTemplateInput struct {
-	Name   string
-	Engine string
+	Name        string
+	Engine      string
+	WorkspaceID string // "" for a template shared by all workspaces
+}
+
+// UpdateTemplateInput is the public input model for template updates. Nil
+// fields are left unchanged.
+type UpdateTemplateInput struct {
+	Name     *string
+	IsActive *bool
+}
+
+// CloneTemplateInput is the public input model for template cloning.
+type CloneTemplateInput struct {
+	Name string
+}
+
+// UpdateStartupFileInput is the public input model for startup file edits.
+// Nil fields are left unchanged.
+type UpdateStartupFileInput struct {
+	Source     *string
+	OrderIndex *int
+	Mode       *string
+}
+
+// RegisterLibraryInput is the public input model for library registration.
+// Exactly one of Content and Tarball must be set: the daemon never reads
+// library code from its own filesystem. Name, Version and Description default
+// to the tarball's package.json. Dependencies are library references loaded
+// before this one.
+type RegisterLibraryInput struct {
+	Name         string
+	Version      string
+	Global       string
+	Description  string
+	Content      string
+	Tarball      []byte
+	Entry        string
+	Dependencies []string
 }
 
 // CreateSessionInput is the public input model for session creation.
@@ -14,22 +57,77 @@ type CreateSessionInput struct {
 	WorktreePath  string
 }
 
-// ExecuteREPLInput is the public input model for REPL execution.
+// ExecuteREPLInput is the public input model for REPL execution. Observer,
+// when set, follows the execution while it runs.
 type ExecuteREPLInput struct {
 	SessionID string
 	Input     string
+	Observer  vmexec.Observer
 }
 
 // ExecuteRunFileInput is the public input model for file execution.
+// Observer, when set, follows the execution while it runs.
 type ExecuteRunFileInput struct {
 	SessionID string
 	Path      string
 	Args      map[string]interface{}
 	Env       map[string]interface{}
+	Observer  vmexec.Observer
 }
 
 // RuntimeSummary captures currently active runtime state in daemon memory.
 type RuntimeSummary struct {
-	ActiveSessions  int      `json:"active_sessions"`
-	ActiveSessionID []string `json:"active_session_ids"`
+	ActiveSessions  int                         `json:"active_sessions"`
+	ActiveSessionID []string                    `json:"active_session_ids"`
+	ProgramCache    vmsession.ProgramCacheStats `json:"program_cache"`
+	Pools           []vmsession.PoolStats       `json:"pools"`
+	SessionQuota    vmsession.QuotaUsage        `json:"session_quota"`
+	Executions      vmexec.ExecutionUsage       `json:"executions"`
+}
+
+// TemplateChange describes one difference between a template and a spec.
+type TemplateChange struct {
+	Action   string `json:"action"`   // create, update, add, remove
+	Resource string `json:"resource"` // template, engine, settings, module, library, capability, startup_file
+	Name     string `json:"name"`
+}
+
+// ApplyTemplateResult reports the outcome of applying a template spec. On a
+// dry run Changes lists what would change and nothing is persisted.
+type ApplyTemplateResult struct {
+	Template *vmmodels.VM     `json:"template,omitempty"`
+	Created  bool             `json:"created"`
+	DryRun   bool             `json:"dry_run"`
+	Changes  []TemplateChange `json:"changes"`
+}
+
+// TemplateRevisionDiff is a template revision together with its changes
+// relative to the previous revision.
+type TemplateRevisionDiff struct {
+	*vmmodels.TemplateRevision
+	Changes []TemplateChange `json:"changes"`
+}
+
+// CreateTokenInput is the public input model for API token creation. Scopes
+// default to read only; an empty WorkspaceID leaves the token unconfined. A
+// CertSubject binds the token to a client certificate instead of a secret.
+type CreateTokenInput struct {
+	Name        string
+	Scopes      []string
+	WorkspaceID string
+	CertSubject string
+}
+
+// CreatedToken is an API token together with its secret, which is only
+// available when the token is created. Certificate-bound tokens have none.
+type CreatedToken struct {
+	Token  *vmmodels.APIToken `json:"token"`
+	Secret string             `json:"secret"`
+}
+
+// CreateWorkspaceInput is the public input model for workspace creation.
+// Name defaults to ID.
+type CreateWorkspaceInput struct {
+	ID   string
+	Name string
 }
@@ -0,0 +1,98 @@
+package vmcontrol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+// WorkspaceService owns the workspace registry. Workspaces are created
+// explicitly or registered the first time a session or template names them.
+type WorkspaceService struct {
+	store WorkspaceStorePort
+}
+
+func NewWorkspaceService(store WorkspaceStorePort) *WorkspaceService {
+	return &WorkspaceService{store: store}
+}
+
+// Create registers a workspace. Callers confined to a workspace cannot
+// create others.
+func (s *WorkspaceService) Create(ctx context.Context, input CreateWorkspaceInput) (*vmmodels.Workspace, error) {
+	if _, confined := WorkspaceFromContext(ctx); confined {
+		return nil, fmt.Errorf("%w: workspace-bound tokens cannot create workspaces", vmmodels.ErrWorkspaceForbidden)
+	}
+	id, err := vmmodels.NormalizeWorkspaceID(input.ID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.store.GetWorkspace(id); err == nil {
+		return nil, fmt.Errorf("%w: %s", vmmodels.ErrWorkspaceExists, id)
+	} else if !errors.Is(err, vmmodels.ErrWorkspaceNotFound) {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		name = id
+	}
+	workspace := &vmmodels.Workspace{ID: id, Name: name, CreatedAt: time.Now()}
+	if err := s.store.CreateWorkspace(workspace); err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+// Get returns a workspace the caller may see.
+func (s *WorkspaceService) Get(ctx context.Context, id string) (*vmmodels.Workspace, error) {
+	if !ownedBy(ctx, id) {
+		return nil, vmmodels.ErrWorkspaceNotFound
+	}
+	return s.store.GetWorkspace(id)
+}
+
+// List returns the workspaces the caller may see.
+func (s *WorkspaceService) List(ctx context.Context) ([]*vmmodels.Workspace, error) {
+	workspaces, err := s.store.ListWorkspaces()
+	if err != nil {
+		return nil, err
+	}
+	visible := make([]*vmmodels.Workspace, 0, len(workspaces))
+	for _, workspace := range workspaces {
+		if ownedBy(ctx, workspace.ID) {
+			visible = append(visible, workspace)
+		}
+	}
+	return visible, nil
+}
+
+func (s *WorkspaceService) ensure(id string) error {
+	return s.store.EnsureWorkspace(id)
+}
+
+// WorkspaceFromContext returns the workspace the caller is confined to: that
+// of its API token. Callers without a workspace-bound token see every
+// workspace.
+func WorkspaceFromContext(ctx context.Context) (string, bool) {
+	token, ok := TokenFromContext(ctx)
+	if !ok || token.WorkspaceID == "" {
+		return "", false
+	}
+	return token.WorkspaceID, true
+}
+
+// visibleTo reports whether the caller may read a resource owned by
+// workspaceID; "" marks a shared resource.
+func visibleTo(ctx context.Context, workspaceID string) bool {
+	return workspaceID == "" || ownedBy(ctx, workspaceID)
+}
+
+// ownedBy reports whether the caller may act within workspaceID.
+func ownedBy(ctx context.Context, workspaceID string) bool {
+	caller, confined := WorkspaceFromContext(ctx)
+	return !confined || caller == workspaceID
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"path/filepath"
 	"time"
 
 	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
 	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+	"github.com/go-go-golems/vm-system/pkg/vmsession"
 	"github.com/go-go-golems/vm-system/pkg/vmstore"
 	"github.com/rs/zerolog/log"
 )
@@ -16,25 +19,62 @@ import (
 // App hosts the long-lived runtime process around vmcontrol and HTTP transport.
 type App struct {
 	cfg    Config
-	store  *vmstore.VMStore
+	store  closableStore
 	core   *vmcontrol.Core
 	server *http.Server
 }
 
+// closableStore is the store App owns: a vmstore.VMStore, or a
+// vmstore.MemoryStore when Config.Ephemeral is set.
+type closableStore interface {
+	vmcontrol.Store
+	Close() error
+}
+
 const sessionStartupGCMessage = "garbage collected on daemon startup: runtime state does not survive process restarts"
 
-func New(cfg Config, handler http.Handler) (*App, error) {
-	store, err := vmstore.NewVMStore(cfg.DBPath)
+// New opens the store and builds the core. Embedding programs can pass core
+// options, e.g. vmcontrol.WithModuleRegistry, to extend the runtime.
+func New(cfg Config, handler http.Handler, opts ...vmcontrol.Option) (*App, error) {
+	if cfg.DataDir == "" {
+		cfg.DataDir = DefaultDataDir(cfg.DBPath)
+	}
+	dataDir, err := filepath.Abs(cfg.DataDir)
 	if err != nil {
-		return nil, fmt.Errorf("open store: %w", err)
+		return nil, fmt.Errorf("resolve data dir: %w", err)
+	}
+	cfg.DataDir = dataDir
+	if err := validateQueueMaxWait(cfg); err != nil {
+		return nil, err
+	}
+	tlsConfig, err := serverTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := openStore(cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := closeStaleSessionsOnStartup(store); err != nil {
-		_ = store.Close()
-		return nil, fmt.Errorf("reconcile stale sessions on startup: %w", err)
+	if !cfg.KeepSessions {
+		if err := closeStaleSessionsOnStartup(store); err != nil {
+			_ = store.Close()
+			return nil, fmt.Errorf("reconcile stale sessions on startup: %w", err)
+		}
 	}
 
-	core := vmcontrol.NewCore(store)
+	defaults := []vmcontrol.Option{
+		vmcontrol.WithDataDir(cfg.DataDir),
+		vmcontrol.WithExecutionQueue(cfg.QueueDepth, cfg.QueueMaxWait),
+		vmcontrol.WithMaxConcurrentExecutions(cfg.MaxConcurrentExecutions),
+		vmcontrol.WithSessionQuotas(vmsession.Quotas{
+			MaxSessions:     cfg.MaxSessions,
+			MaxPerTemplate:  cfg.MaxSessionsPerTemplate,
+			MaxPerWorkspace: cfg.MaxSessionsPerWorkspace,
+		}),
+	}
+	core := vmcontrol.NewCore(store, append(defaults, opts...)...)
 	server := &http.Server{
 		Addr:              cfg.ListenAddr,
 		Handler:           handler,
@@ -42,7 +82,14 @@ func New(cfg Config, handler http.Handler) (*App, error) {
 		ReadHeaderTimeout: cfg.ReadHeaderTime,
 		WriteTimeout:      cfg.WriteTimeout,
 		IdleTimeout:       cfg.IdleTimeout,
+		TLSConfig:         tlsConfig,
 	}
+	// gRPC shares the listener and needs HTTP/2, which clients speak in
+	// cleartext with prior knowledge when TLS is off.
+	server.Protocols = new(http.Protocols)
+	server.Protocols.SetHTTP1(true)
+	server.Protocols.SetHTTP2(true)
+	server.Protocols.SetUnencryptedHTTP2(true)
 
 	return &App{
 		cfg:    cfg,
@@ -52,7 +99,33 @@ func New(cfg Config, handler http.Handler) (*App, error) {
 	}, nil
 }
 
-func closeStaleSessionsOnStartup(store *vmstore.VMStore) error {
+// validateQueueMaxWait rejects a queue wait that could outlast the write
+// deadline: the request would then lose its connection instead of getting
+// its result or the cancelled execution.
+func validateQueueMaxWait(cfg Config) error {
+	if cfg.WriteTimeout <= 0 {
+		return nil
+	}
+	if cfg.QueueMaxWait <= 0 || cfg.QueueMaxWait >= cfg.WriteTimeout {
+		return fmt.Errorf("queue max wait %s must be above 0 and shorter than the %s write timeout", cfg.QueueMaxWait, cfg.WriteTimeout)
+	}
+	return nil
+}
+
+// openStore opens the database at DBPath, or an empty in-memory store when
+// the daemon is ephemeral.
+func openStore(cfg Config) (closableStore, error) {
+	if cfg.Ephemeral {
+		return vmstore.NewMemoryStore(), nil
+	}
+	store, err := vmstore.NewVMStore(cfg.DBPath)
+	if err != nil {
+		return nil, fmt.Errorf("open store: %w", err)
+	}
+	return store, nil
+}
+
+func closeStaleSessionsOnStartup(store vmcontrol.Store) error {
 	sessions, err := store.ListSessions("")
 	if err != nil {
 		return err
@@ -99,12 +172,27 @@ func (a *App) SetHandler(handler http.Handler) {
 	a.server.Handler = handler
 }
 
-// Run starts the server and blocks until context cancellation or server error.
+// Run listens on ListenAddr, serves HTTPS when TLS is configured and plain
+// HTTP otherwise, until context cancellation or server error, and removes a
+// Unix socket it created on the way out.
 func (a *App) Run(ctx context.Context) error {
+	listener, err := listen(a.cfg)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", a.cfg.ListenAddr, err)
+	}
 	errCh := make(chan error, 1)
 
+	bgCtx, stopBackground := context.WithCancel(ctx)
+	defer stopBackground()
+	go a.core.Sessions.MaintainPools(bgCtx, a.cfg.PoolInterval)
+	go a.reapSessions(bgCtx)
+
 	go func() {
-		if err := a.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		serve := a.server.Serve
+		if a.server.TLSConfig != nil {
+			serve = func(l net.Listener) error { return a.server.ServeTLS(l, "", "") }
+		}
+		if err := serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			errCh <- err
 		}
 		close(errCh)
@@ -126,6 +214,31 @@ func (a *App) Run(ctx context.Context) error {
 	}
 }
 
+// reapSessions closes sessions past their template's idle_timeout or
+// max_lifetime every ReapInterval until ctx is done.
+func (a *App) reapSessions(ctx context.Context) {
+	if a.cfg.ReapInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(a.cfg.ReapInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			expired, err := a.core.Sessions.Expire(ctx, now)
+			if err != nil {
+				log.Warn().Err(err).Msg("failed to expire sessions")
+			}
+			if len(expired) > 0 {
+				log.Info().Int("expired_sessions", len(expired)).Msg("closed expired sessions")
+			}
+		}
+	}
+}
+
 func (a *App) Close() error {
 	return a.store.Close()
 }
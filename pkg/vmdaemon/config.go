@@ -1,26 +1,62 @@
 package vmdaemon
 
-import "time"
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/vmexec"
+)
 
 // Config controls daemon host runtime behavior.
 type Config struct {
 	DBPath          string
-	ListenAddr      string
+	Ephemeral       bool        // keep all state in memory instead of DBPath; nothing survives a restart
+	KeepSessions    bool        // skip closing persisted starting/ready sessions on start, for processes sharing a running daemon's database
+	DataDir         string      // library cache and other daemon files; made absolute on start
+	ListenAddr      string      // host:port, or unix:///path for a Unix domain socket
+	SocketMode      os.FileMode // permissions of a Unix socket; 0 means DefaultSocketMode
+	SocketOwner     string      // "user[:group]" owning a Unix socket; empty keeps the daemon's
+	TLSCertFile     string      // PEM server certificate; with TLSKeyFile, serves HTTPS
+	TLSKeyFile      string      // PEM private key of TLSCertFile
+	TLSClientCAFile string      // PEM CA bundle; when set, clients must present a certificate it signed
 	ReadTimeout     time.Duration
 	ReadHeaderTime  time.Duration
 	WriteTimeout    time.Duration
 	IdleTimeout     time.Duration
 	ShutdownTimeout time.Duration
+	PoolInterval    time.Duration // how often template session pools are topped up; 0 disables
+	ReapInterval    time.Duration // how often expired sessions are closed; 0 disables
+	QueueDepth      int           // executions that may wait per session; 0 rejects with SESSION_BUSY
+	QueueMaxWait    time.Duration // longest wait in a session queue; must be shorter than WriteTimeout when that is set, 0 waits indefinitely otherwise
+
+	// Quotas; 0 leaves a limit off.
+	MaxSessions             int // live sessions across the daemon
+	MaxSessionsPerTemplate  int // live sessions per template
+	MaxSessionsPerWorkspace int // live sessions per workspace_id
+	MaxConcurrentExecutions int // executions running at once; others wait for a slot
 }
 
 func DefaultConfig(dbPath string) Config {
 	return Config{
 		DBPath:          dbPath,
+		DataDir:         DefaultDataDir(dbPath),
 		ListenAddr:      "127.0.0.1:3210",
+		SocketMode:      DefaultSocketMode,
 		ReadTimeout:     15 * time.Second,
 		ReadHeaderTime:  5 * time.Second,
 		WriteTimeout:    30 * time.Second,
 		IdleTimeout:     60 * time.Second,
 		ShutdownTimeout: 10 * time.Second,
+		PoolInterval:    10 * time.Second,
+		ReapInterval:    15 * time.Second,
+		QueueDepth:      vmexec.DefaultQueueDepth,
+		QueueMaxWait:    vmexec.DefaultQueueMaxWait,
 	}
 }
+
+// DefaultDataDir returns the data directory used when none is configured: a
+// .vm-cache directory next to the database.
+func DefaultDataDir(dbPath string) string {
+	return filepath.Join(filepath.Dir(dbPath), ".vm-cache")
+}
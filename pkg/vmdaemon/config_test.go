@@ -1,11 +1,15 @@
 package vmdaemon
 
 import (
+	"context"
 	"net/http"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
 )
 
 func TestDefaultConfigValues(t *testing.T) {
@@ -15,9 +19,15 @@ func TestDefaultConfigValues(t *testing.T) {
 	if cfg.DBPath != "/tmp/vm-system.db" {
 		t.Fatalf("expected db path to round-trip, got %q", cfg.DBPath)
 	}
+	if cfg.DataDir != "/tmp/.vm-cache" {
+		t.Fatalf("expected data dir next to the database, got %q", cfg.DataDir)
+	}
 	if cfg.ListenAddr != "127.0.0.1:3210" {
 		t.Fatalf("expected default listen addr 127.0.0.1:3210, got %q", cfg.ListenAddr)
 	}
+	if cfg.SocketMode != 0o600 || cfg.SocketOwner != "" {
+		t.Fatalf("expected owner-only socket mode and no socket owner, got %o and %q", cfg.SocketMode, cfg.SocketOwner)
+	}
 	if cfg.ReadTimeout != 15*time.Second {
 		t.Fatalf("expected read timeout 15s, got %s", cfg.ReadTimeout)
 	}
@@ -33,6 +43,15 @@ func TestDefaultConfigValues(t *testing.T) {
 	if cfg.ShutdownTimeout != 10*time.Second {
 		t.Fatalf("expected shutdown timeout 10s, got %s", cfg.ShutdownTimeout)
 	}
+	if cfg.PoolInterval != 10*time.Second {
+		t.Fatalf("expected pool interval 10s, got %s", cfg.PoolInterval)
+	}
+	if cfg.ReapInterval != 15*time.Second {
+		t.Fatalf("expected reap interval 15s, got %s", cfg.ReapInterval)
+	}
+	if cfg.QueueDepth != 16 || cfg.QueueMaxWait != 10*time.Second {
+		t.Fatalf("expected execution queue depth 16 and max wait 10s, got %d and %s", cfg.QueueDepth, cfg.QueueMaxWait)
+	}
 }
 
 func TestNewConfiguresHTTPServerFromConfig(t *testing.T) {
@@ -44,6 +63,7 @@ func TestNewConfiguresHTTPServerFromConfig(t *testing.T) {
 	cfg.ReadHeaderTime = 2 * time.Second
 	cfg.WriteTimeout = 4 * time.Second
 	cfg.IdleTimeout = 5 * time.Second
+	cfg.QueueMaxWait = 2 * time.Second
 
 	handler := http.NewServeMux()
 	app, err := New(cfg, handler)
@@ -87,3 +107,39 @@ func TestNewReturnsErrorWhenDBParentDirMissing(t *testing.T) {
 		t.Fatalf("expected open store failure context, got %v", err)
 	}
 }
+
+func TestNewEphemeralNeverOpensDBPath(t *testing.T) {
+	t.Parallel()
+
+	missingDBPath := filepath.Join(t.TempDir(), "missing", "vm-system.db")
+	cfg := DefaultConfig(missingDBPath)
+	cfg.Ephemeral = true
+	app, err := New(cfg, http.NewServeMux())
+	if err != nil {
+		t.Fatalf("new ephemeral daemon app: %v", err)
+	}
+	defer app.Close()
+
+	template, err := app.Core().Templates.Create(context.Background(), vmcontrol.CreateTemplateInput{Name: "ephemeral"})
+	if err != nil {
+		t.Fatalf("create template in memory: %v", err)
+	}
+	if _, err := app.Core().Templates.Get(context.Background(), template.ID); err != nil {
+		t.Fatalf("get template from memory: %v", err)
+	}
+	if _, err := os.Stat(filepath.Dir(missingDBPath)); !os.IsNotExist(err) {
+		t.Fatalf("expected nothing written next to --db, got %v", err)
+	}
+}
+
+func TestNewRejectsQueueWaitOutlastingWriteTimeout(t *testing.T) {
+	t.Parallel()
+
+	for _, wait := range []time.Duration{0, 30 * time.Second, time.Minute} {
+		cfg := DefaultConfig(filepath.Join(t.TempDir(), "vm-system.db"))
+		cfg.QueueMaxWait = wait
+		if _, err := New(cfg, http.NewServeMux()); err == nil || !strings.Contains(err.Error(), "write timeout") {
+			t.Fatalf("queue max wait %s: expected a write timeout error, got %v", wait, err)
+		}
+	}
+}
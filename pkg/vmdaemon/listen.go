@@ -0,0 +1,152 @@
+package vmdaemon
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// unixScheme prefixes ListenAddr values that name a Unix domain socket.
+const unixScheme = "unix://"
+
+// DefaultSocketMode keeps a daemon socket reachable by its owner only.
+const DefaultSocketMode os.FileMode = 0o600
+
+// UnixSocketPath returns the socket path of a unix:///path listen address.
+func UnixSocketPath(addr string) (string, bool) {
+	if !strings.HasPrefix(addr, unixScheme) {
+		return "", false
+	}
+	return strings.TrimPrefix(addr, unixScheme), true
+}
+
+// listen opens cfg.ListenAddr: a TCP host:port, or a Unix socket given as
+// unix:///path whose file gets cfg.SocketMode and cfg.SocketOwner.
+func listen(cfg Config) (net.Listener, error) {
+	socketPath, ok := UnixSocketPath(cfg.ListenAddr)
+	if !ok {
+		return net.Listen("tcp", cfg.ListenAddr)
+	}
+	if socketPath == "" {
+		return nil, fmt.Errorf("listen address %q has no socket path", cfg.ListenAddr)
+	}
+	if err := removeStaleSocket(socketPath); err != nil {
+		return nil, err
+	}
+
+	// Bind inside a private directory and move the socket into place once
+	// its mode and owner are set, so nobody can connect while the umask's
+	// permissions still apply. Setting the umask instead would affect every
+	// file the process creates meanwhile.
+	privateDir, err := os.MkdirTemp(filepath.Dir(socketPath), ".vm-sock")
+	if err != nil {
+		return nil, fmt.Errorf("create socket directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(privateDir) }()
+	boundPath := filepath.Join(privateDir, "s")
+	listener, err := net.Listen("unix", boundPath)
+	if err != nil {
+		return nil, err
+	}
+	// The socket is unlinked at its final path on close, not where it was
+	// bound.
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := setSocketPermissions(boundPath, cfg); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	if err := os.Rename(boundPath, socketPath); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("move socket into place: %w", err)
+	}
+	return &socketListener{Listener: listener, path: socketPath}, nil
+}
+
+// setSocketPermissions gives the socket at path cfg.SocketMode and
+// cfg.SocketOwner.
+func setSocketPermissions(path string, cfg Config) error {
+	mode := cfg.SocketMode
+	if mode == 0 {
+		mode = DefaultSocketMode
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		return fmt.Errorf("chmod socket: %w", err)
+	}
+	if cfg.SocketOwner != "" {
+		uid, gid, err := lookupOwner(cfg.SocketOwner)
+		if err != nil {
+			return err
+		}
+		if err := os.Chown(path, uid, gid); err != nil {
+			return fmt.Errorf("chown socket: %w", err)
+		}
+	}
+	return nil
+}
+
+// socketListener removes its socket file when closed.
+type socketListener struct {
+	net.Listener
+	path string
+	once sync.Once
+}
+
+func (l *socketListener) Close() error {
+	err := l.Listener.Close()
+	l.once.Do(func() { _ = os.Remove(l.path) })
+	return err
+}
+
+// removeStaleSocket deletes a socket file left behind by a daemon that did
+// not shut down cleanly. A socket something still answers on, or a path that
+// is not a socket, is left alone.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("listen socket %s exists and is not a socket", path)
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("listen socket %s is in use by another process", path)
+	}
+	return os.Remove(path)
+}
+
+// lookupOwner resolves "user[:group]", by name or numeric ID. Without a
+// group the socket keeps its current group.
+func lookupOwner(owner string) (int, int, error) {
+	userName, groupName, _ := strings.Cut(owner, ":")
+	uid, err := strconv.Atoi(userName)
+	if err != nil {
+		u, lookupErr := user.Lookup(userName)
+		if lookupErr != nil {
+			return 0, 0, fmt.Errorf("socket owner: %w", lookupErr)
+		}
+		uid, _ = strconv.Atoi(u.Uid)
+	}
+	gid := -1
+	if groupName != "" {
+		gid, err = strconv.Atoi(groupName)
+		if err != nil {
+			g, lookupErr := user.LookupGroup(groupName)
+			if lookupErr != nil {
+				return 0, 0, fmt.Errorf("socket group: %w", lookupErr)
+			}
+			gid, _ = strconv.Atoi(g.Gid)
+		}
+	}
+	return uid, gid, nil
+}
@@ -0,0 +1,138 @@
+package vmdaemon
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/vmclient"
+	vmhttp "github.com/go-go-golems/vm-system/pkg/vmtransport/http"
+)
+
+func TestRunServesOnUnixSocketWithOwnerOnlyMode(t *testing.T) {
+	// Socket paths are limited to ~100 bytes, which t.TempDir can exceed.
+	socketDir, err := os.MkdirTemp("", "vmd")
+	if err != nil {
+		t.Fatalf("mkdir temp: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(socketDir) })
+	socketPath := filepath.Join(socketDir, "vm.sock")
+
+	// A socket file left behind by a crashed daemon is replaced.
+	stale, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("create stale socket: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = stale.Close()
+
+	cfg := DefaultConfig(filepath.Join(t.TempDir(), "vm-system.db"))
+	cfg.ListenAddr = "unix://" + socketPath
+	app, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("new app: %v", err)
+	}
+	defer app.Close()
+	app.SetHandler(vmhttp.NewHandler(app.Core()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- app.Run(ctx) }()
+
+	client := vmclient.New("unix://"+socketPath, nil)
+	var health map[string]interface{}
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		health, err = client.Health(context.Background())
+		if err == nil || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil || health["status"] != "ok" {
+		t.Fatalf("expected health over the unix socket, got %v (%v)", health, err)
+	}
+
+	info, err := os.Stat(socketPath)
+	if err != nil {
+		t.Fatalf("stat socket: %v", err)
+	}
+	if info.Mode()&os.ModeSocket == 0 || info.Mode().Perm() != DefaultSocketMode {
+		t.Fatalf("expected socket with mode %o, got %s", DefaultSocketMode, info.Mode())
+	}
+
+	// A second daemon must not steal a live socket.
+	second := cfg
+	if _, err := listen(second); err == nil || !strings.Contains(err.Error(), "in use") {
+		t.Fatalf("expected live socket to be refused, got %v", err)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if _, err := os.Stat(socketPath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected socket removed on shutdown, got %v", err)
+	}
+}
+
+func TestListenSetsSocketModeRegardlessOfUmask(t *testing.T) {
+	// Not parallel: the umask is process-wide.
+	defer syscall.Umask(syscall.Umask(0))
+
+	socketDir, err := os.MkdirTemp("", "vmd")
+	if err != nil {
+		t.Fatalf("mkdir temp: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(socketDir) })
+	socketPath := filepath.Join(socketDir, "vm.sock")
+
+	for _, mode := range []os.FileMode{0, 0o660} {
+		cfg := DefaultConfig(filepath.Join(t.TempDir(), "vm-system.db"))
+		cfg.ListenAddr = "unix://" + socketPath
+		cfg.SocketMode = mode
+		listener, err := listen(cfg)
+		if err != nil {
+			t.Fatalf("listen with mode %o: %v", mode, err)
+		}
+		want := mode
+		if want == 0 {
+			want = DefaultSocketMode
+		}
+		info, err := os.Stat(socketPath)
+		if err != nil || info.Mode()&os.ModeSocket == 0 || info.Mode().Perm() != want {
+			t.Fatalf("expected socket with mode %o, got %v (%v)", want, info, err)
+		}
+		if entries, err := os.ReadDir(socketDir); err != nil || len(entries) != 1 {
+			t.Fatalf("expected only the socket next to it, got %v (%v)", entries, err)
+		}
+		if err := listener.Close(); err != nil {
+			t.Fatalf("close listener: %v", err)
+		}
+		if _, err := os.Stat(socketPath); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("expected socket removed on close, got %v", err)
+		}
+	}
+}
+
+func TestListenRejectsNonSocketPath(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "not-a-socket")
+	if err := os.WriteFile(path, []byte("keep me"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	cfg := DefaultConfig(filepath.Join(t.TempDir(), "vm-system.db"))
+	cfg.ListenAddr = "unix://" + path
+	if _, err := listen(cfg); err == nil || !strings.Contains(err.Error(), "not a socket") {
+		t.Fatalf("expected non-socket path to be refused, got %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "keep me" {
+		t.Fatalf("expected existing file untouched, got %q (%v)", data, err)
+	}
+}
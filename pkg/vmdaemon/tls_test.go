@@ -0,0 +1,202 @@
+package vmdaemon
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/vmclient"
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	vmhttp "github.com/go-go-golems/vm-system/pkg/vmtransport/http"
+)
+
+func TestRunServesMutualTLSAndMapsClientCertsToTokens(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, "vm-system test CA")
+	ca.issue(t, dir, "server", pkix.Name{CommonName: "127.0.0.1"}, x509.ExtKeyUsageServerAuth)
+	ca.issue(t, dir, "builder", pkix.Name{CommonName: "build-host", Organization: []string{"ci"}}, x509.ExtKeyUsageClientAuth)
+	ca.issue(t, dir, "stranger", pkix.Name{CommonName: "stranger"}, x509.ExtKeyUsageClientAuth)
+	other := newTestCA(t, "other CA")
+	other.issue(t, dir, "impostor", pkix.Name{CommonName: "build-host", Organization: []string{"ci"}}, x509.ExtKeyUsageClientAuth)
+	caFile := filepath.Join(dir, "ca.pem")
+	writePEM(t, caFile, "CERTIFICATE", ca.cert.Raw)
+
+	cfg := DefaultConfig(filepath.Join(dir, "vm-system.db"))
+	cfg.ListenAddr = freeTCPAddr(t)
+	cfg.TLSCertFile = filepath.Join(dir, "server.pem")
+	cfg.TLSKeyFile = filepath.Join(dir, "server-key.pem")
+	cfg.TLSClientCAFile = caFile
+	app, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("new app: %v", err)
+	}
+	defer app.Close()
+	app.SetHandler(vmhttp.NewHandler(app.Core(), vmhttp.RequireAuth()))
+
+	if _, err := app.Core().Auth.Create(context.Background(), vmcontrol.CreateTokenInput{
+		Name:        "build-host",
+		CertSubject: "CN=build-host,O=ci",
+	}); err != nil {
+		t.Fatalf("create certificate token: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- app.Run(ctx) }()
+
+	serverURL := "https://" + cfg.ListenAddr
+	clientFor := func(name string) *vmclient.Client {
+		return vmclient.New(serverURL, nil, vmclient.WithTLSFiles(caFile, filepath.Join(dir, name+".pem"), filepath.Join(dir, name+"-key.pem")))
+	}
+	builder := clientFor("builder")
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		_, err = builder.Health(context.Background())
+		if err == nil || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("expected health over mutual TLS, got %v", err)
+	}
+
+	// The certificate subject maps to the read-only token bound to it.
+	if _, err := builder.ListTemplates(context.Background()); err != nil {
+		t.Fatalf("expected certificate identity to read templates, got %v", err)
+	}
+	_, err = builder.CreateTemplate(context.Background(), vmclient.CreateTemplateRequest{Name: "from-builder"})
+	expectAPIError(t, err, "INSUFFICIENT_SCOPE")
+
+	// A verified certificate without a bound token is not an identity.
+	_, err = clientFor("stranger").ListTemplates(context.Background())
+	expectAPIError(t, err, "UNAUTHENTICATED")
+
+	// Certificates from other CAs and connections without one fail the handshake.
+	if _, err := clientFor("impostor").Health(context.Background()); err == nil {
+		t.Fatalf("expected a certificate from another CA to be rejected")
+	}
+	if _, err := vmclient.New(serverURL, nil, vmclient.WithTLSFiles(caFile, "", "")).Health(context.Background()); err == nil {
+		t.Fatalf("expected a connection without a client certificate to be rejected")
+	}
+	if _, err := vmclient.New(serverURL, nil).Health(context.Background()); err == nil {
+		t.Fatalf("expected the private CA to be untrusted by default")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+}
+
+func TestNewRejectsIncompleteTLSConfig(t *testing.T) {
+	t.Parallel()
+
+	for name, mutate := range map[string]func(*Config){
+		"cert without key":      func(cfg *Config) { cfg.TLSCertFile = "server.pem" },
+		"client CA without TLS": func(cfg *Config) { cfg.TLSClientCAFile = "ca.pem" },
+		"missing files":         func(cfg *Config) { cfg.TLSCertFile, cfg.TLSKeyFile = "missing.pem", "missing-key.pem" },
+	} {
+		cfg := DefaultConfig(filepath.Join(t.TempDir(), "vm-system.db"))
+		mutate(&cfg)
+		if app, err := New(cfg, nil); err == nil {
+			_ = app.Close()
+			t.Fatalf("%s: expected New to fail", name)
+		}
+	}
+}
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate CA key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create CA certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse CA certificate: %v", err)
+	}
+	return &testCA{cert: cert, key: key}
+}
+
+// issue writes dir/name.pem and dir/name-key.pem, a certificate for subject
+// signed by the CA and valid for 127.0.0.1.
+func (ca *testCA) issue(t *testing.T, dir, name string, subject pkix.Name, usage x509.ExtKeyUsage) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	writePEM(t, filepath.Join(dir, name+".pem"), "CERTIFICATE", der)
+	writePEM(t, filepath.Join(dir, name+"-key.pem"), "EC PRIVATE KEY", keyDER)
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func freeTCPAddr(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+func expectAPIError(t *testing.T, err error, code string) {
+	t.Helper()
+	var apiErr *vmclient.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != code {
+		t.Fatalf("expected API error %s, got %v", code, err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/dop251/goja"
@@ -12,13 +13,16 @@ import (
 
 	"github.com/go-go-golems/vm-system/pkg/vmmodels"
 	"github.com/go-go-golems/vm-system/pkg/vmsession"
-	"github.com/go-go-golems/vm-system/pkg/vmstore"
 )
 
 // Executor executes code in VM sessions
 type Executor struct {
 	store          executionStore
 	sessionManager *vmsession.SessionManager
+	queueConfig    QueueConfig
+	queues         map[string]*sessionQueue
+	slots          slotScheduler
+	queueMu        sync.Mutex // guards queues and slots
 }
 
 type executionStore interface {
@@ -37,10 +41,12 @@ type executionRecordInput struct {
 	path      string
 	argsJSON  json.RawMessage
 	envJSON   json.RawMessage
+	observer  Observer
 }
 
 type eventRecorder struct {
 	store       executionStore
+	observer    Observer
 	executionID string
 	nextSeq     int
 	err         error
@@ -56,28 +62,22 @@ type executionPipelineConfig struct {
 }
 
 // NewExecutor creates a new Executor
-func NewExecutor(store *vmstore.VMStore, sessionManager *vmsession.SessionManager) *Executor {
-	return &Executor{
+func NewExecutor(store executionStore, sessionManager *vmsession.SessionManager, opts ...Option) *Executor {
+	e := &Executor{
 		store:          store,
 		sessionManager: sessionManager,
+		queueConfig:    QueueConfig{Depth: DefaultQueueDepth, MaxWait: DefaultQueueMaxWait},
+		queues:         make(map[string]*sessionQueue),
 	}
-}
-
-func (e *Executor) prepareSession(sessionID string) (*vmsession.Session, func(), error) {
-	session, err := e.sessionManager.GetSession(sessionID)
-	if err != nil {
-		return nil, nil, err
-	}
-	if session.Status != vmmodels.SessionReady {
-		return nil, nil, vmmodels.ErrSessionNotReady
-	}
-	if !session.ExecutionLock.TryLock() {
-		return nil, nil, vmmodels.ErrSessionBusy
+	for _, opt := range opts {
+		if opt != nil {
+			opt(e)
+		}
 	}
-	return session, session.ExecutionLock.Unlock, nil
+	return e
 }
 
-func (e *Executor) newExecutionRecord(in executionRecordInput) *vmmodels.Execution {
+func (e *Executor) newExecutionRecord(session *vmsession.Session, in executionRecordInput) *vmmodels.Execution {
 	argsJSON := in.argsJSON
 	if len(argsJSON) == 0 {
 		argsJSON = json.RawMessage("[]")
@@ -88,22 +88,33 @@ func (e *Executor) newExecutionRecord(in executionRecordInput) *vmmodels.Executi
 	}
 
 	return &vmmodels.Execution{
-		ID:        uuid.New().String(),
-		SessionID: in.sessionID,
-		Kind:      string(in.kind),
-		Input:     in.input,
-		Path:      in.path,
-		Args:      argsJSON,
-		Env:       envJSON,
-		Status:    string(vmmodels.ExecRunning),
-		StartedAt: time.Now(),
-		Metrics:   json.RawMessage("{}"),
+		ID:          uuid.New().String(),
+		SessionID:   in.sessionID,
+		WorkspaceID: session.WorkspaceID,
+		Kind:        string(in.kind),
+		Input:       in.input,
+		Path:        in.path,
+		Args:        argsJSON,
+		Env:         envJSON,
+		Status:      string(vmmodels.ExecRunning),
+		StartedAt:   time.Now(),
+		Metrics:     json.RawMessage("{}"),
 	}
 }
 
-func newEventRecorder(store executionStore, executionID string) *eventRecorder {
+func newRecordInput(in executionRecordInput, opts []RunOption) executionRecordInput {
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&in)
+		}
+	}
+	return in
+}
+
+func newEventRecorder(store executionStore, observer Observer, executionID string) *eventRecorder {
 	return &eventRecorder{
 		store:       store,
+		observer:    observer,
 		executionID: executionID,
 		nextSeq:     1,
 	}
@@ -138,6 +149,7 @@ func (r *eventRecorder) emitRaw(eventType vmmodels.EventType, payload json.RawMe
 	if err := r.store.AddEvent(event); err != nil {
 		return fmt.Errorf("failed to persist event %s seq=%d: %w", eventType, event.Seq, err)
 	}
+	r.observer.event(event)
 	r.nextSeq++
 	return nil
 }
@@ -224,29 +236,59 @@ func valuePayloadJSON(value goja.Value) json.RawMessage {
 	return valueJSON
 }
 
-func (e *Executor) runExecutionPipeline(cfg executionPipelineConfig) (*vmmodels.Execution, error) {
-	session, unlock, err := e.prepareSession(cfg.sessionID)
-	if err != nil {
-		return nil, err
+// settleValue runs pending host work (fetch, ...) to completion and unwraps a
+// settled promise result, so async code reports its resolved value or
+// rejection like synchronous code would.
+func settleValue(session *vmsession.Session, value goja.Value) (goja.Value, error) {
+	session.Loop.Drain()
+
+	if value == nil {
+		return value, nil
+	}
+	promise, ok := value.Export().(*goja.Promise)
+	if !ok {
+		return value, nil
 	}
-	defer unlock()
+	switch promise.State() {
+	case goja.PromiseStateFulfilled:
+		return promise.Result(), nil
+	case goja.PromiseStateRejected:
+		return nil, fmt.Errorf("uncaught promise rejection: %s", promise.Result().String())
+	default:
+		return value, nil
+	}
+}
 
+func (e *Executor) runExecutionPipeline(cfg executionPipelineConfig) (*vmmodels.Execution, error) {
 	recordInput := cfg.recordInput
 	recordInput.sessionID = cfg.sessionID
 
-	exec := e.newExecutionRecord(recordInput)
-	if err := e.store.CreateExecution(exec); err != nil {
-		return nil, fmt.Errorf("failed to create execution: %w", err)
+	session, exec, release, err := e.acquireSession(cfg.sessionID, recordInput)
+	if err != nil {
+		return nil, err
+	}
+	if release == nil {
+		// Cancelled or timed out while queued.
+		return exec, nil
 	}
+	defer release()
 
-	recorder := newEventRecorder(e.store, exec.ID)
+	recorder := newEventRecorder(e.store, recordInput.observer, exec.ID)
 	if cfg.setupRuntime != nil {
 		if err := cfg.setupRuntime(session, recorder); err != nil {
 			return nil, err
 		}
 	}
 
+	session.SetEventSink(func(eventType vmmodels.EventType, payload interface{}) {
+		recorder.recordError(recorder.emit(eventType, payload))
+	})
+	defer session.SetEventSink(nil)
+
 	value, runErr := cfg.run(session, recorder)
+	if runErr == nil {
+		value, runErr = settleValue(session, value)
+	}
 	endedAt := time.Now()
 	if recorder.Err() != nil {
 		return nil, recorder.Err()
@@ -272,13 +314,13 @@ func (e *Executor) runExecutionPipeline(cfg executionPipelineConfig) (*vmmodels.
 }
 
 // ExecuteREPL executes a REPL snippet
-func (e *Executor) ExecuteREPL(sessionID, input string) (*vmmodels.Execution, error) {
+func (e *Executor) ExecuteREPL(sessionID, input string, opts ...RunOption) (*vmmodels.Execution, error) {
 	return e.runExecutionPipeline(executionPipelineConfig{
 		sessionID: sessionID,
-		recordInput: executionRecordInput{
+		recordInput: newRecordInput(executionRecordInput{
 			kind:  vmmodels.ExecREPL,
 			input: input,
-		},
+		}, opts),
 		setupRuntime: func(session *vmsession.Session, recorder *eventRecorder) error {
 			e.installConsoleRecorder(session, recorder)
 			return recorder.emit(vmmodels.EventInputEcho, map[string]string{"text": input})
@@ -304,18 +346,18 @@ func (e *Executor) ExecuteREPL(sessionID, input string) (*vmmodels.Execution, er
 }
 
 // ExecuteRunFile executes a file
-func (e *Executor) ExecuteRunFile(sessionID, path string, args, env map[string]interface{}) (*vmmodels.Execution, error) {
+func (e *Executor) ExecuteRunFile(sessionID, path string, args, env map[string]interface{}, opts ...RunOption) (*vmmodels.Execution, error) {
 	argsJSON, _ := json.Marshal(args)
 	envJSON, _ := json.Marshal(env)
 	var fileContent []byte
 	return e.runExecutionPipeline(executionPipelineConfig{
 		sessionID: sessionID,
-		recordInput: executionRecordInput{
+		recordInput: newRecordInput(executionRecordInput{
 			kind:     vmmodels.ExecRunFile,
 			path:     path,
 			argsJSON: argsJSON,
 			envJSON:  envJSON,
-		},
+		}, opts),
 		setupRuntime: func(session *vmsession.Session, recorder *eventRecorder) error {
 			filePath := filepath.Join(session.WorktreePath, path)
 			if _, err := os.Stat(filePath); err != nil {
@@ -333,7 +375,11 @@ func (e *Executor) ExecuteRunFile(sessionID, path string, args, env map[string]i
 			return nil
 		},
 		run: func(session *vmsession.Session, _ *eventRecorder) (goja.Value, error) {
-			return session.Runtime.RunString(string(fileContent))
+			program, err := e.sessionManager.Programs().Compile(string(fileContent))
+			if err != nil {
+				return nil, err
+			}
+			return session.Runtime.RunProgram(program)
 		},
 		handleError: func(exec *vmmodels.Execution, recorder *eventRecorder, runErr error, endedAt time.Time) error {
 			exceptionJSON := exceptionPayloadJSON(runErr)
@@ -70,6 +70,40 @@ func TestExecuteREPLSuccessPersistsEventOrderAndResult(t *testing.T) {
 	}
 }
 
+func TestExecuteREPLObserverSeesRecordAndEventsAsPersisted(t *testing.T) {
+	fx := newExecutorFixture(t)
+
+	var (
+		started *vmmodels.Execution
+		seen    []string
+	)
+	exec, err := fx.executor.ExecuteREPL(fx.sessionID, "console.log('watched'); 1 + 1", vmexec.Observe(vmexec.Observer{
+		Started: func(exec *vmmodels.Execution) { started = exec },
+		Event: func(event *vmmodels.ExecutionEvent) {
+			persisted, err := fx.executor.GetEvents(event.ExecutionID, event.Seq-1)
+			if err != nil || len(persisted) == 0 || persisted[0].Seq != event.Seq {
+				t.Errorf("expected event seq=%d persisted before it is observed, got %v (%v)", event.Seq, persisted, err)
+			}
+			seen = append(seen, event.Type)
+		},
+	}))
+	if err != nil {
+		t.Fatalf("execute repl: %v", err)
+	}
+	if started == nil || started.ID != exec.ID || started.Status != string(vmmodels.ExecRunning) {
+		t.Fatalf("expected the running record to be observed first, got %+v", started)
+	}
+	want := []string{string(vmmodels.EventInputEcho), string(vmmodels.EventConsole), string(vmmodels.EventValue)}
+	if len(seen) != len(want) {
+		t.Fatalf("expected observed events %v, got %v", want, seen)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Fatalf("expected observed events %v, got %v", want, seen)
+		}
+	}
+}
+
 func TestExecuteREPLErrorPersistsExceptionAndExecutionError(t *testing.T) {
 	fx := newExecutorFixture(t)
 
@@ -216,11 +250,12 @@ func TestExecuteRunFileUndefinedResultUsesFallbackPayload(t *testing.T) {
 
 type executorFixture struct {
 	executor  *vmexec.Executor
+	sessions  *vmsession.SessionManager
 	sessionID string
 	worktree  string
 }
 
-func newExecutorFixture(t *testing.T) executorFixture {
+func newExecutorFixture(t *testing.T, opts ...vmexec.Option) executorFixture {
 	t.Helper()
 
 	tmp := t.TempDir()
@@ -249,7 +284,8 @@ func newExecutorFixture(t *testing.T) executorFixture {
 	}
 
 	return executorFixture{
-		executor:  vmexec.NewExecutor(store, sessionManager),
+		executor:  vmexec.NewExecutor(store, sessionManager, opts...),
+		sessions:  sessionManager,
 		sessionID: session.ID,
 		worktree:  worktree,
 	}
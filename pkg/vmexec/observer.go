@@ -0,0 +1,36 @@
+package vmexec
+
+import "github.com/go-go-golems/vm-system/pkg/vmmodels"
+
+// Observer follows one execution while it runs, so transports can stream it
+// instead of polling the store. Started receives the execution record once it
+// is persisted, queued or running; Event receives each event once it is
+// persisted. Callbacks run on the executing goroutine, so a slow callback
+// holds up the execution. Nil callbacks are skipped.
+type Observer struct {
+	Started func(*vmmodels.Execution)
+	Event   func(*vmmodels.ExecutionEvent)
+}
+
+// RunOption customizes a single execution.
+type RunOption func(*executionRecordInput)
+
+// Observe reports the execution's progress to observer.
+func Observe(observer Observer) RunOption {
+	return func(in *executionRecordInput) {
+		in.observer = observer
+	}
+}
+
+func (o Observer) started(exec *vmmodels.Execution) {
+	if o.Started != nil {
+		snapshot := *exec
+		o.Started(&snapshot)
+	}
+}
+
+func (o Observer) event(event *vmmodels.ExecutionEvent) {
+	if o.Event != nil {
+		o.Event(event)
+	}
+}
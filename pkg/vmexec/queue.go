@@ -0,0 +1,511 @@
+package vmexec
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+	"github.com/go-go-golems/vm-system/pkg/vmsession"
+)
+
+// Defaults for the per-session execution queue. DefaultQueueMaxWait leaves
+// a REST request time to run after waiting, within the daemon's 30s write
+// timeout.
+const (
+	DefaultQueueDepth   = 16
+	DefaultQueueMaxWait = 10 * time.Second
+)
+
+// QueueConfig bounds the per-session execution queue and the number of
+// executions running at once across the daemon.
+type QueueConfig struct {
+	// Depth is how many executions may wait behind the running one. With 0,
+	// a concurrent execution fails with ErrSessionBusy immediately.
+	Depth int
+	// MaxWait is how long an execution may wait for its turn before it is
+	// cancelled. 0 waits indefinitely.
+	MaxWait time.Duration
+	// MaxConcurrent caps executions running at once across all sessions;
+	// others wait for a slot, scheduled round-robin across workspaces. 0
+	// leaves concurrency unbounded.
+	MaxConcurrent int
+}
+
+// ExecutionUsage reports execution slots in use and executions waiting.
+type ExecutionUsage struct {
+	MaxConcurrent int `json:"max_concurrent"`
+	Running       int `json:"running"`
+	Queued        int `json:"queued"`
+}
+
+// Option customizes an Executor.
+type Option func(*Executor)
+
+// WithQueue replaces the default execution queue bounds.
+func WithQueue(cfg QueueConfig) Option {
+	return func(e *Executor) {
+		if cfg.Depth < 0 {
+			cfg.Depth = 0
+		}
+		if cfg.MaxConcurrent < 0 {
+			cfg.MaxConcurrent = 0
+		}
+		e.queueConfig = cfg
+		e.slots.limit = cfg.MaxConcurrent
+	}
+}
+
+// sessionQueue tracks the running execution of one session and the
+// executions waiting behind it, in arrival order. It only exists while the
+// session has work in flight.
+type sessionQueue struct {
+	busy    bool
+	waiting []*queuedExecution
+}
+
+// queuedExecution is an execution waiting first for its session, then for
+// a daemon-wide slot.
+type queuedExecution struct {
+	executionID string
+	sessionID   string
+	workspaceID string
+	turn        chan struct{} // closed when the execution reaches the head of its session queue
+	slot        chan struct{} // closed when the scheduler grants it an execution slot
+	cancelled   chan struct{} // closed by CancelExecution
+	settled     chan struct{} // closed once a cancellation is persisted
+}
+
+// slotScheduler hands out daemon-wide execution slots. Waiters are grouped by
+// workspace and served round-robin, so one workspace with many busy sessions
+// cannot starve the others. Its methods are called with Executor.queueMu held.
+type slotScheduler struct {
+	limit     int
+	running   int
+	runningBy map[string]int // slots held per workspace
+	waiting   map[string][]*queuedExecution
+	ring      []string // workspaces with waiters, in service order
+}
+
+// tryAcquire takes a slot for workspaceID unless none is free or others are
+// already waiting.
+func (s *slotScheduler) tryAcquire(workspaceID string) bool {
+	if s.limit > 0 && (s.running >= s.limit || len(s.ring) > 0) {
+		return false
+	}
+	s.grant(workspaceID)
+	return true
+}
+
+func (s *slotScheduler) grant(workspaceID string) {
+	if s.runningBy == nil {
+		s.runningBy = make(map[string]int)
+	}
+	s.running++
+	s.runningBy[workspaceID]++
+}
+
+func (s *slotScheduler) enqueue(waiter *queuedExecution) {
+	if s.waiting == nil {
+		s.waiting = make(map[string][]*queuedExecution)
+	}
+	if len(s.waiting[waiter.workspaceID]) == 0 {
+		s.ring = append(s.ring, waiter.workspaceID)
+	}
+	s.waiting[waiter.workspaceID] = append(s.waiting[waiter.workspaceID], waiter)
+}
+
+// release frees a slot held by workspaceID and grants free slots to the
+// next workspaces in turn.
+func (s *slotScheduler) release(workspaceID string) {
+	s.running--
+	if s.runningBy[workspaceID]--; s.runningBy[workspaceID] <= 0 {
+		delete(s.runningBy, workspaceID)
+	}
+	for len(s.ring) > 0 && (s.limit <= 0 || s.running < s.limit) {
+		workspaceID := s.ring[0]
+		s.ring = s.ring[1:]
+		queue := s.waiting[workspaceID]
+		next := queue[0]
+		if len(queue) > 1 {
+			s.waiting[workspaceID] = queue[1:]
+			s.ring = append(s.ring, workspaceID)
+		} else {
+			delete(s.waiting, workspaceID)
+		}
+		s.grant(workspaceID)
+		close(next.slot)
+	}
+}
+
+// find returns the waiter of an execution waiting for a slot.
+func (s *slotScheduler) find(executionID string) *queuedExecution {
+	for _, queue := range s.waiting {
+		for _, candidate := range queue {
+			if candidate.executionID == executionID {
+				return candidate
+			}
+		}
+	}
+	return nil
+}
+
+// remove drops waiter, reporting whether it was still waiting for a slot.
+func (s *slotScheduler) remove(waiter *queuedExecution) bool {
+	queue := s.waiting[waiter.workspaceID]
+	for i, candidate := range queue {
+		if candidate != waiter {
+			continue
+		}
+		queue = append(queue[:i], queue[i+1:]...)
+		if len(queue) > 0 {
+			s.waiting[waiter.workspaceID] = queue
+			return true
+		}
+		delete(s.waiting, waiter.workspaceID)
+		for j, workspaceID := range s.ring {
+			if workspaceID == waiter.workspaceID {
+				s.ring = append(s.ring[:j], s.ring[j+1:]...)
+				break
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (s *slotScheduler) queued() int {
+	n := 0
+	for _, queue := range s.waiting {
+		n += len(queue)
+	}
+	return n
+}
+
+// acquireSession waits for the session's turn and an execution slot and
+// returns the session locked, together with the persisted execution record.
+// A nil release means the execution never ran: it was cancelled or timed out
+// while queued, and exec already carries its final cancelled status.
+func (e *Executor) acquireSession(sessionID string, in executionRecordInput) (*vmsession.Session, *vmmodels.Execution, func(), error) {
+	session, err := e.sessionManager.GetSession(sessionID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if session.Status != vmmodels.SessionReady {
+		return nil, nil, nil, vmmodels.ErrSessionNotReady
+	}
+
+	e.queueMu.Lock()
+	if e.queues == nil {
+		e.queues = make(map[string]*sessionQueue)
+	}
+	queue, ok := e.queues[session.ID]
+	if !ok {
+		queue = &sessionQueue{}
+		e.queues[session.ID] = queue
+	}
+	holdsSession := false
+	if !queue.busy && len(queue.waiting) == 0 {
+		if !session.ExecutionLock.TryLock() {
+			// Held outside the queue, e.g. by an embedder; nothing will
+			// hand the turn over, so don't wait for it.
+			delete(e.queues, session.ID)
+			e.queueMu.Unlock()
+			return nil, nil, nil, vmmodels.ErrSessionBusy
+		}
+		queue.busy = true
+		holdsSession = true
+		if e.slots.tryAcquire(session.WorkspaceID) {
+			e.queueMu.Unlock()
+
+			release := e.releaser(session)
+			exec := e.newExecutionRecord(session, in)
+			if err := e.store.CreateExecution(exec); err != nil {
+				release()
+				return nil, nil, nil, fmt.Errorf("failed to create execution: %w", err)
+			}
+			in.observer.started(exec)
+			return session, exec, release, nil
+		}
+	} else if len(queue.waiting) >= e.queueConfig.Depth {
+		e.queueMu.Unlock()
+		return nil, nil, nil, vmmodels.ErrSessionBusy
+	}
+
+	exec := e.newExecutionRecord(session, in)
+	exec.Status = string(vmmodels.ExecQueued)
+	waiter := &queuedExecution{
+		executionID: exec.ID,
+		sessionID:   session.ID,
+		workspaceID: session.WorkspaceID,
+		turn:        make(chan struct{}),
+		slot:        make(chan struct{}),
+		cancelled:   make(chan struct{}),
+		settled:     make(chan struct{}),
+	}
+	if holdsSession {
+		e.slots.enqueue(waiter)
+	} else {
+		queue.waiting = append(queue.waiting, waiter)
+	}
+	e.queueMu.Unlock()
+
+	if err := e.store.CreateExecution(exec); err != nil {
+		e.abandon(session, waiter, holdsSession)
+		return nil, nil, nil, fmt.Errorf("failed to create execution: %w", err)
+	}
+	in.observer.started(exec)
+	return e.awaitTurn(session, exec, waiter, holdsSession)
+}
+
+// awaitTurn waits for a queued execution's session turn, unless it already
+// holds the session, and then for an execution slot.
+func (e *Executor) awaitTurn(session *vmsession.Session, exec *vmmodels.Execution, waiter *queuedExecution, holdsSession bool) (*vmsession.Session, *vmmodels.Execution, func(), error) {
+	var timeout <-chan time.Time
+	if e.queueConfig.MaxWait > 0 {
+		timer := time.NewTimer(e.queueConfig.MaxWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	timedOut := fmt.Sprintf("timed out after %s waiting in session queue", e.queueConfig.MaxWait)
+
+	if !holdsSession {
+		select {
+		case <-waiter.turn:
+		case <-waiter.cancelled:
+			return e.settleCancelled(exec, waiter, "cancelled while queued")
+		case <-timeout:
+			if e.withdraw(waiter) {
+				return e.settleCancelled(exec, waiter, timedOut)
+			}
+			// Handed the turn, or cancelled, while the timer fired.
+			select {
+			case <-waiter.turn:
+			case <-waiter.cancelled:
+				return e.settleCancelled(exec, waiter, "cancelled while queued")
+			}
+		}
+
+		session.ExecutionLock.Lock()
+		if _, err := e.sessionManager.GetSession(session.ID); err != nil {
+			e.releaseSession(session)
+			return nil, exec, nil, e.finalizeExecutionCancelled(exec, "session closed while queued")
+		}
+		e.queueMu.Lock()
+		acquired := e.slots.tryAcquire(session.WorkspaceID)
+		if !acquired {
+			e.slots.enqueue(waiter)
+		}
+		e.queueMu.Unlock()
+		if acquired {
+			return e.startQueued(session, exec)
+		}
+	}
+
+	select {
+	case <-waiter.slot:
+	case <-waiter.cancelled:
+		e.releaseSession(session)
+		return e.settleCancelled(exec, waiter, "cancelled while queued")
+	case <-timeout:
+		if e.withdraw(waiter) {
+			e.releaseSession(session)
+			return e.settleCancelled(exec, waiter, timedOut)
+		}
+		select {
+		case <-waiter.slot:
+		case <-waiter.cancelled:
+			e.releaseSession(session)
+			return e.settleCancelled(exec, waiter, "cancelled while queued")
+		}
+	}
+	return e.startQueued(session, exec)
+}
+
+// startQueued marks a queued execution running once it holds both the
+// session and a slot.
+func (e *Executor) startQueued(session *vmsession.Session, exec *vmmodels.Execution) (*vmsession.Session, *vmmodels.Execution, func(), error) {
+	release := e.releaser(session)
+	if _, err := e.sessionManager.GetSession(session.ID); err != nil {
+		release()
+		return nil, exec, nil, e.finalizeExecutionCancelled(exec, "session closed while queued")
+	}
+	exec.Status = string(vmmodels.ExecRunning)
+	exec.Metrics = vmmodels.MarshalJSONWithFallback(map[string]int64{
+		"queue_wait_ms": time.Since(exec.StartedAt).Milliseconds(),
+	}, exec.Metrics)
+	if err := e.store.UpdateExecution(exec); err != nil {
+		release()
+		return nil, nil, nil, fmt.Errorf("failed to start queued execution %s: %w", exec.ID, err)
+	}
+	return session, exec, release, nil
+}
+
+// abandon gives up a waiter whose execution record could not be created,
+// passing on whatever it was handed in the meantime.
+func (e *Executor) abandon(session *vmsession.Session, waiter *queuedExecution, holdsSession bool) {
+	if e.withdraw(waiter) {
+		if holdsSession {
+			e.releaseSession(session)
+		}
+		return
+	}
+	if holdsSession {
+		select {
+		case <-waiter.slot:
+			e.releaser(session)()
+		case <-waiter.cancelled:
+			close(waiter.settled)
+			e.releaseSession(session)
+		}
+		return
+	}
+	select {
+	case <-waiter.turn:
+		session.ExecutionLock.Lock()
+		e.releaseSession(session)
+	case <-waiter.cancelled:
+		close(waiter.settled)
+	}
+}
+
+// releaser returns the function that frees an execution's slot and session.
+func (e *Executor) releaser(session *vmsession.Session) func() {
+	return func() {
+		e.queueMu.Lock()
+		e.slots.release(session.WorkspaceID)
+		e.queueMu.Unlock()
+		e.releaseSession(session)
+	}
+}
+
+// releaseSession records the session's activity, unlocks it and hands it to
+// the next queued execution, if any.
+func (e *Executor) releaseSession(session *vmsession.Session) {
+	e.sessionManager.RecordActivity(session)
+	session.ExecutionLock.Unlock()
+
+	e.queueMu.Lock()
+	defer e.queueMu.Unlock()
+	queue, ok := e.queues[session.ID]
+	if !ok {
+		return
+	}
+	if len(queue.waiting) == 0 {
+		delete(e.queues, session.ID)
+		return
+	}
+	next := queue.waiting[0]
+	queue.waiting = queue.waiting[1:]
+	close(next.turn)
+}
+
+// withdraw removes waiter from its session queue or the slot scheduler,
+// reporting whether it was still waiting.
+func (e *Executor) withdraw(waiter *queuedExecution) bool {
+	e.queueMu.Lock()
+	defer e.queueMu.Unlock()
+	if e.slots.remove(waiter) {
+		return true
+	}
+	queue, ok := e.queues[waiter.sessionID]
+	if !ok {
+		return false
+	}
+	for i, candidate := range queue.waiting {
+		if candidate == waiter {
+			queue.waiting = append(queue.waiting[:i], queue.waiting[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// CancelExecution cancels an execution that is waiting in its session queue
+// or for an execution slot. Running and finished executions cannot be
+// cancelled.
+func (e *Executor) CancelExecution(executionID string) (*vmmodels.Execution, error) {
+	e.queueMu.Lock()
+	waiter := e.slots.find(executionID)
+	if waiter != nil {
+		e.slots.remove(waiter)
+	} else {
+		for _, queue := range e.queues {
+			for i, candidate := range queue.waiting {
+				if candidate.executionID == executionID {
+					waiter = candidate
+					queue.waiting = append(queue.waiting[:i], queue.waiting[i+1:]...)
+					break
+				}
+			}
+			if waiter != nil {
+				break
+			}
+		}
+	}
+	e.queueMu.Unlock()
+
+	if waiter == nil {
+		if _, err := e.store.GetExecution(executionID); err != nil {
+			return nil, err
+		}
+		return nil, vmmodels.ErrExecutionNotCancellable
+	}
+
+	close(waiter.cancelled)
+	<-waiter.settled
+	return e.store.GetExecution(executionID)
+}
+
+// Usage reports the execution slots in use and the executions waiting in
+// session queues or for a slot.
+func (e *Executor) Usage() ExecutionUsage {
+	e.queueMu.Lock()
+	defer e.queueMu.Unlock()
+
+	queued := e.slots.queued()
+	for _, queue := range e.queues {
+		queued += len(queue.waiting)
+	}
+	return ExecutionUsage{
+		MaxConcurrent: e.slots.limit,
+		Running:       e.slots.running,
+		Queued:        queued,
+	}
+}
+
+// WorkspaceUsage is Usage restricted to the executions of sessions in
+// workspaceID.
+func (e *Executor) WorkspaceUsage(workspaceID string) ExecutionUsage {
+	e.queueMu.Lock()
+	defer e.queueMu.Unlock()
+
+	queued := len(e.slots.waiting[workspaceID])
+	for _, queue := range e.queues {
+		for _, waiter := range queue.waiting {
+			if waiter.workspaceID == workspaceID {
+				queued++
+			}
+		}
+	}
+	return ExecutionUsage{
+		MaxConcurrent: e.slots.limit,
+		Running:       e.slots.runningBy[workspaceID],
+		Queued:        queued,
+	}
+}
+
+func (e *Executor) settleCancelled(exec *vmmodels.Execution, waiter *queuedExecution, message string) (*vmsession.Session, *vmmodels.Execution, func(), error) {
+	defer close(waiter.settled)
+	return nil, exec, nil, e.finalizeExecutionCancelled(exec, message)
+}
+
+func (e *Executor) finalizeExecutionCancelled(exec *vmmodels.Execution, message string) error {
+	endedAt := time.Now()
+	exec.Status = string(vmmodels.ExecCancelled)
+	exec.EndedAt = &endedAt
+	exec.Error = vmmodels.MarshalJSONWithFallback(vmmodels.ExceptionPayload{Message: message}, nil)
+	if err := e.store.UpdateExecution(exec); err != nil {
+		return fmt.Errorf("failed to persist cancelled execution %s: %w", exec.ID, err)
+	}
+	return nil
+}
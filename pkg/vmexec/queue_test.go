@@ -0,0 +1,231 @@
+package vmexec_test
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/vmexec"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+type replOutcome struct {
+	exec *vmmodels.Execution
+	err  error
+}
+
+// blockSession installs a block() global that parks the running execution
+// until the returned function is called.
+func blockSession(t *testing.T, fx executorFixture) func() {
+	t.Helper()
+
+	session, err := fx.sessions.GetSession(fx.sessionID)
+	if err != nil {
+		t.Fatalf("get session: %v", err)
+	}
+	gate := make(chan struct{})
+	session.Runtime.Set("block", func() { <-gate })
+	var once sync.Once
+	return func() { once.Do(func() { close(gate) }) }
+}
+
+func executeAsync(fx executorFixture, input string) <-chan replOutcome {
+	out := make(chan replOutcome, 1)
+	go func() {
+		exec, err := fx.executor.ExecuteREPL(fx.sessionID, input)
+		out <- replOutcome{exec: exec, err: err}
+	}()
+	return out
+}
+
+// waitForStatuses polls the session's executions until their statuses, keyed
+// by input, match want.
+func waitForStatuses(t *testing.T, fx executorFixture, want map[string]vmmodels.ExecutionStatus) map[string]string {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		executions, err := fx.executor.ListExecutions(fx.sessionID, 50)
+		if err != nil {
+			t.Fatalf("list executions: %v", err)
+		}
+		ids := map[string]string{}
+		matched := 0
+		for _, exec := range executions {
+			if status, ok := want[exec.Input]; ok && exec.Status == string(status) {
+				ids[exec.Input] = exec.ID
+				matched++
+			}
+		}
+		if matched == len(want) {
+			return ids
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for execution statuses %v", want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestQueuedExecutionsRunInOrderAndCanBeCancelled(t *testing.T) {
+	fx := newExecutorFixture(t, vmexec.WithQueue(vmexec.QueueConfig{Depth: 2}))
+	if _, err := fx.executor.ExecuteREPL(fx.sessionID, "globalThis.order = []"); err != nil {
+		t.Fatalf("seed order: %v", err)
+	}
+	release := blockSession(t, fx)
+	defer release()
+
+	first := executeAsync(fx, "block(); order.push(1)")
+	waitForStatuses(t, fx, map[string]vmmodels.ExecutionStatus{"block(); order.push(1)": vmmodels.ExecRunning})
+	second := executeAsync(fx, "order.push(2)")
+	waitForStatuses(t, fx, map[string]vmmodels.ExecutionStatus{"order.push(2)": vmmodels.ExecQueued})
+	third := executeAsync(fx, "order.push(3)")
+	ids := waitForStatuses(t, fx, map[string]vmmodels.ExecutionStatus{
+		"block(); order.push(1)": vmmodels.ExecRunning,
+		"order.push(2)":          vmmodels.ExecQueued,
+		"order.push(3)":          vmmodels.ExecQueued,
+	})
+
+	if _, err := fx.executor.ExecuteREPL(fx.sessionID, "order.push(4)"); !errors.Is(err, vmmodels.ErrSessionBusy) {
+		t.Fatalf("expected ErrSessionBusy with a full queue, got %v", err)
+	}
+	if _, err := fx.executor.CancelExecution(ids["block(); order.push(1)"]); !errors.Is(err, vmmodels.ErrExecutionNotCancellable) {
+		t.Fatalf("expected running execution to be non-cancellable, got %v", err)
+	}
+
+	cancelled, err := fx.executor.CancelExecution(ids["order.push(3)"])
+	if err != nil {
+		t.Fatalf("cancel queued execution: %v", err)
+	}
+	if cancelled.Status != string(vmmodels.ExecCancelled) || cancelled.EndedAt == nil {
+		t.Fatalf("expected cancelled execution with ended_at, got %+v", cancelled)
+	}
+	if outcome := <-third; outcome.err != nil || outcome.exec.Status != string(vmmodels.ExecCancelled) {
+		t.Fatalf("expected waiting caller to observe cancellation, got %+v", outcome)
+	}
+
+	release()
+	for i, ch := range []<-chan replOutcome{first, second} {
+		outcome := <-ch
+		if outcome.err != nil || outcome.exec.Status != string(vmmodels.ExecOK) {
+			t.Fatalf("execution %d: expected ok, got %+v", i+1, outcome)
+		}
+	}
+
+	exec, err := fx.executor.ExecuteREPL(fx.sessionID, "order.join(',')")
+	if err != nil {
+		t.Fatalf("read order: %v", err)
+	}
+	if !strings.Contains(string(exec.Result), `"preview":"1,2"`) {
+		t.Fatalf("expected executions to run in arrival order without the cancelled one, got %s", exec.Result)
+	}
+}
+
+func TestQueuedExecutionTimesOutAfterMaxWait(t *testing.T) {
+	fx := newExecutorFixture(t, vmexec.WithQueue(vmexec.QueueConfig{Depth: 1, MaxWait: 20 * time.Millisecond}))
+	release := blockSession(t, fx)
+	defer release()
+
+	first := executeAsync(fx, "block()")
+	waitForStatuses(t, fx, map[string]vmmodels.ExecutionStatus{"block()": vmmodels.ExecRunning})
+
+	exec, err := fx.executor.ExecuteREPL(fx.sessionID, "1 + 1")
+	if err != nil {
+		t.Fatalf("queued execution: %v", err)
+	}
+	if exec.Status != string(vmmodels.ExecCancelled) || !strings.Contains(string(exec.Error), "timed out") {
+		t.Fatalf("expected queue wait timeout, got status=%s error=%s", exec.Status, exec.Error)
+	}
+
+	release()
+	if outcome := <-first; outcome.err != nil || outcome.exec.Status != string(vmmodels.ExecOK) {
+		t.Fatalf("expected blocked execution to finish, got %+v", outcome)
+	}
+}
+
+func TestExecutionSlotsAreSharedFairlyAcrossWorkspaces(t *testing.T) {
+	fx := newExecutorFixture(t, vmexec.WithQueue(vmexec.QueueConfig{Depth: 1, MaxConcurrent: 1}))
+	release := blockSession(t, fx)
+	defer release()
+
+	blocked, err := fx.sessions.GetSession(fx.sessionID)
+	if err != nil {
+		t.Fatalf("get session: %v", err)
+	}
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	newSession := func(workspaceID, name string) string {
+		t.Helper()
+		session, err := fx.sessions.CreateSession(blocked.VMID, workspaceID, "deadbeef", fx.worktree)
+		if err != nil {
+			t.Fatalf("create session: %v", err)
+		}
+		session.Runtime.Set("mark", func() {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+		})
+		return session.ID
+	}
+	// Two more sessions in the blocked session's workspace, then one in
+	// another workspace.
+	busyA := newSession(blocked.WorkspaceID, "a1")
+	busyB := newSession(blocked.WorkspaceID, "a2")
+	other := newSession("workspace-other", "b1")
+
+	first := executeAsync(fx, "block()")
+	waitForStatuses(t, fx, map[string]vmmodels.ExecutionStatus{"block()": vmmodels.ExecRunning})
+
+	waiting := make([]<-chan replOutcome, 0, 3)
+	for i, sessionID := range []string{busyA, busyB, other} {
+		out := make(chan replOutcome, 1)
+		go func(sessionID string) {
+			exec, err := fx.executor.ExecuteREPL(sessionID, "mark()")
+			out <- replOutcome{exec: exec, err: err}
+		}(sessionID)
+		waiting = append(waiting, out)
+		waitForUsage(t, fx, vmexec.ExecutionUsage{MaxConcurrent: 1, Running: 1, Queued: i + 1})
+	}
+	if usage := fx.executor.WorkspaceUsage(blocked.WorkspaceID); usage != (vmexec.ExecutionUsage{MaxConcurrent: 1, Running: 1, Queued: 2}) {
+		t.Fatalf("expected the blocked workspace to hold the slot with two waiting, got %+v", usage)
+	}
+	if usage := fx.executor.WorkspaceUsage("workspace-other"); usage != (vmexec.ExecutionUsage{MaxConcurrent: 1, Queued: 1}) {
+		t.Fatalf("expected the other workspace to have one waiting, got %+v", usage)
+	}
+
+	release()
+	for i, ch := range append([]<-chan replOutcome{first}, waiting...) {
+		outcome := <-ch
+		if outcome.err != nil || outcome.exec.Status != string(vmmodels.ExecOK) {
+			t.Fatalf("execution %d: expected ok, got %+v", i, outcome)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(order, ",") != "a1,b1,a2" {
+		t.Fatalf("expected slots to alternate between workspaces, got %v", order)
+	}
+	if usage := fx.executor.Usage(); usage.Running != 0 || usage.Queued != 0 {
+		t.Fatalf("expected all slots released, got %+v", usage)
+	}
+	if usage := fx.executor.WorkspaceUsage(blocked.WorkspaceID); usage.Running != 0 || usage.Queued != 0 {
+		t.Fatalf("expected the workspace's slots released, got %+v", usage)
+	}
+}
+
+func waitForUsage(t *testing.T, fx executorFixture, want vmexec.ExecutionUsage) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for fx.executor.Usage() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for execution usage %+v, got %+v", want, fx.executor.Usage())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,190 @@
+// Package vmmcp exposes vm-system templates, sessions and executions as
+// Model Context Protocol tools, so LLM agents can drive JavaScript sessions
+// directly.
+package vmmcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/go-go-golems/vm-system/pkg/vmclient"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+// Backend is the part of the vmclient API the tools use. *vmclient.Client
+// and *vmclient.Local implement it.
+type Backend interface {
+	ListTemplates(ctx context.Context) ([]*vmmodels.VM, error)
+	CreateTemplate(ctx context.Context, request vmclient.CreateTemplateRequest) (*vmmodels.VM, error)
+	CreateSession(ctx context.Context, request vmclient.CreateSessionRequest) (*vmmodels.VMSession, error)
+	ExecuteREPL(ctx context.Context, request vmclient.ExecuteREPLRequest) (*vmmodels.Execution, error)
+	ExecuteRunFile(ctx context.Context, request vmclient.ExecuteRunFileRequest) (*vmmodels.Execution, error)
+	ListExecutions(ctx context.Context, sessionID string, limit int) ([]*vmmodels.Execution, error)
+	GetExecution(ctx context.Context, executionID string) (*vmmodels.Execution, error)
+	GetExecutionEvents(ctx context.Context, executionID string, afterSeq int) ([]*vmmodels.ExecutionEvent, error)
+}
+
+// defaultHistoryLimit is how many executions list_executions returns when
+// the caller does not say.
+const defaultHistoryLimit = 20
+
+// outputEvents are the event types an execution tool returns. Input echoes,
+// system and network events are left to get_execution.
+var outputEvents = map[string]bool{
+	string(vmmodels.EventConsole):   true,
+	string(vmmodels.EventValue):     true,
+	string(vmmodels.EventException): true,
+	string(vmmodels.EventStdout):    true,
+	string(vmmodels.EventStderr):    true,
+}
+
+// ExecutionResult is what repl_eval, run_file and get_execution return.
+type ExecutionResult struct {
+	Execution *vmmodels.Execution        `json:"execution"`
+	Events    []*vmmodels.ExecutionEvent `json:"events"`
+}
+
+type listTemplatesInput struct{}
+
+type listExecutionsInput struct {
+	SessionID string `json:"session_id" jsonschema:"ID of the session"`
+	Limit     int    `json:"limit,omitempty" jsonschema:"most recent executions to return (default 20)"`
+}
+
+type getExecutionInput struct {
+	ExecutionID string `json:"execution_id" jsonschema:"ID of the execution"`
+	AfterSeq    int    `json:"after_seq,omitempty" jsonschema:"only return events after this sequence number"`
+}
+
+// NewServer returns an MCP server whose tools call backend. Input schemas
+// are derived from the vmclient request types, and API errors are reported
+// as tool errors carrying the API error code.
+func NewServer(backend Backend) *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "vm-system", Version: "v1"}, nil)
+	tools := &tools{backend: backend}
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "list_templates",
+		Description: "List the templates sessions can be created from.",
+	}, tools.listTemplates)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "create_template",
+		Description: "Create a template with default settings.",
+	}, tools.createTemplate)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "create_session",
+		Description: "Start a JavaScript session from a template, bound to a worktree. Globals persist across repl_eval calls in the session.",
+	}, tools.createSession)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "repl_eval",
+		Description: "Evaluate JavaScript in a session. Returns the execution record with its console, value and exception events.",
+	}, tools.replEval)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "run_file",
+		Description: "Run a JavaScript file from the session worktree. Returns the execution record with its console, value and exception events.",
+	}, tools.runFile)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "list_executions",
+		Description: "List a session's most recent executions, newest first.",
+	}, tools.listExecutions)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_execution",
+		Description: "Get an execution record with all of its events.",
+	}, tools.getExecution)
+	return server
+}
+
+type tools struct {
+	backend Backend
+}
+
+func (t *tools) listTemplates(ctx context.Context, _ *mcp.CallToolRequest, _ listTemplatesInput) (*mcp.CallToolResult, any, error) {
+	templates, err := t.backend.ListTemplates(ctx)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+	return nil, map[string]any{"templates": templates}, nil
+}
+
+func (t *tools) createTemplate(ctx context.Context, _ *mcp.CallToolRequest, in vmclient.CreateTemplateRequest) (*mcp.CallToolResult, any, error) {
+	template, err := t.backend.CreateTemplate(ctx, in)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+	return nil, template, nil
+}
+
+func (t *tools) createSession(ctx context.Context, _ *mcp.CallToolRequest, in vmclient.CreateSessionRequest) (*mcp.CallToolResult, any, error) {
+	session, err := t.backend.CreateSession(ctx, in)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+	return nil, session, nil
+}
+
+func (t *tools) replEval(ctx context.Context, _ *mcp.CallToolRequest, in vmclient.ExecuteREPLRequest) (*mcp.CallToolResult, any, error) {
+	exec, err := t.backend.ExecuteREPL(ctx, in)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+	return t.executionResult(ctx, exec, outputEvents)
+}
+
+func (t *tools) runFile(ctx context.Context, _ *mcp.CallToolRequest, in vmclient.ExecuteRunFileRequest) (*mcp.CallToolResult, any, error) {
+	exec, err := t.backend.ExecuteRunFile(ctx, in)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+	return t.executionResult(ctx, exec, outputEvents)
+}
+
+func (t *tools) listExecutions(ctx context.Context, _ *mcp.CallToolRequest, in listExecutionsInput) (*mcp.CallToolResult, any, error) {
+	limit := in.Limit
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	executions, err := t.backend.ListExecutions(ctx, in.SessionID, limit)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+	return nil, map[string]any{"executions": executions}, nil
+}
+
+func (t *tools) getExecution(ctx context.Context, _ *mcp.CallToolRequest, in getExecutionInput) (*mcp.CallToolResult, any, error) {
+	exec, err := t.backend.GetExecution(ctx, in.ExecutionID)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+	events, err := t.backend.GetExecutionEvents(ctx, exec.ID, in.AfterSeq)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+	return nil, ExecutionResult{Execution: exec, Events: events}, nil
+}
+
+// executionResult loads an execution's events, keeping the types in keep.
+func (t *tools) executionResult(ctx context.Context, exec *vmmodels.Execution, keep map[string]bool) (*mcp.CallToolResult, any, error) {
+	events, err := t.backend.GetExecutionEvents(ctx, exec.ID, 0)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+	result := ExecutionResult{Execution: exec, Events: []*vmmodels.ExecutionEvent{}}
+	for _, event := range events {
+		if keep[event.Type] {
+			result.Events = append(result.Events, event)
+		}
+	}
+	return nil, result, nil
+}
+
+// toolError reports an API error by its code, which agents can act on.
+func toolError(err error) error {
+	var apiErr *vmclient.APIError
+	if errors.As(err, &apiErr) && apiErr.Code != "" {
+		return fmt.Errorf("%s: %s", apiErr.Code, apiErr.Message)
+	}
+	return err
+}
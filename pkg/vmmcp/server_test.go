@@ -0,0 +1,122 @@
+package vmmcp_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/go-go-golems/vm-system/pkg/vmclient"
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmmcp"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+	"github.com/go-go-golems/vm-system/pkg/vmstore"
+	vmhttp "github.com/go-go-golems/vm-system/pkg/vmtransport/http"
+)
+
+func TestToolsDriveSessionsThroughTheDaemon(t *testing.T) {
+	store, err := vmstore.NewVMStore(filepath.Join(t.TempDir(), "vm-system.db"))
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	daemon := httptest.NewServer(vmhttp.NewHandler(vmcontrol.NewCore(store)))
+	defer daemon.Close()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := vmmcp.NewServer(vmclient.New(daemon.URL, daemon.Client())).Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("connect server: %v", err)
+	}
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "v1"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("connect client: %v", err)
+	}
+	defer session.Close()
+
+	call := func(name string, args map[string]any, out any) *mcp.CallToolResult {
+		t.Helper()
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: name, Arguments: args})
+		if err != nil {
+			t.Fatalf("call %s: %v", name, err)
+		}
+		if out != nil && !res.IsError {
+			data, _ := json.Marshal(res.StructuredContent)
+			if err := json.Unmarshal(data, out); err != nil {
+				t.Fatalf("decode %s result: %v", name, err)
+			}
+		}
+		return res
+	}
+
+	template := vmmodels.VM{}
+	call("create_template", map[string]any{"name": "agent-template"}, &template)
+	listed := struct {
+		Templates []vmmodels.VM `json:"templates"`
+	}{}
+	call("list_templates", map[string]any{}, &listed)
+	if len(listed.Templates) != 1 || listed.Templates[0].ID != template.ID {
+		t.Fatalf("expected the created template to be listed, got %+v", listed.Templates)
+	}
+
+	worktree := t.TempDir()
+	if err := os.WriteFile(filepath.Join(worktree, "main.js"), []byte(`console.log("from file"); 7 * 6`), 0o644); err != nil {
+		t.Fatalf("write main.js: %v", err)
+	}
+	created := vmmodels.VMSession{}
+	call("create_session", map[string]any{
+		"template_id":     template.ID,
+		"workspace_id":    "ws-agent",
+		"base_commit_oid": "deadbeef",
+		"worktree_path":   worktree,
+	}, &created)
+	if created.Status != "ready" {
+		t.Fatalf("expected a ready session, got %+v", created)
+	}
+
+	eval := vmmcp.ExecutionResult{}
+	call("repl_eval", map[string]any{"session_id": created.ID, "input": `console.log("hi"); 1 + 1`}, &eval)
+	if eval.Execution.Status != "ok" || eventTypes(eval.Events) != "console,value" {
+		t.Fatalf("expected ok with console and value events, got %s %s", eval.Execution.Status, eventTypes(eval.Events))
+	}
+	run := vmmcp.ExecutionResult{}
+	call("run_file", map[string]any{"session_id": created.ID, "path": "main.js"}, &run)
+	if run.Execution.Status != "ok" || !strings.Contains(string(run.Execution.Result), `"preview":"42"`) {
+		t.Fatalf("unexpected run_file result %+v", run.Execution)
+	}
+
+	history := struct {
+		Executions []vmmodels.Execution `json:"executions"`
+	}{}
+	call("list_executions", map[string]any{"session_id": created.ID}, &history)
+	if len(history.Executions) != 2 {
+		t.Fatalf("expected two executions in history, got %d", len(history.Executions))
+	}
+	full := vmmcp.ExecutionResult{}
+	call("get_execution", map[string]any{"execution_id": eval.Execution.ID}, &full)
+	if eventTypes(full.Events) != "input_echo,console,value" {
+		t.Fatalf("expected get_execution to return every event, got %s", eventTypes(full.Events))
+	}
+
+	res := call("repl_eval", map[string]any{"session_id": "00000000-0000-0000-0000-000000000000", "input": "1"}, nil)
+	if !res.IsError || !strings.HasPrefix(res.Content[0].(*mcp.TextContent).Text, "SESSION_NOT_FOUND: ") {
+		t.Fatalf("expected a SESSION_NOT_FOUND tool error, got %+v", res.Content)
+	}
+	res = call("repl_eval", map[string]any{"session_id": created.ID}, nil)
+	if !res.IsError {
+		t.Fatalf("expected missing input to fail schema validation")
+	}
+}
+
+func eventTypes(events []*vmmodels.ExecutionEvent) string {
+	types := make([]string, 0, len(events))
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	return strings.Join(types, ",")
+}
@@ -0,0 +1,44 @@
+package vmmodels
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audit outcomes.
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// AuditEvent records one control-plane or execution action: who asked for
+// it, what it touched and how it ended. Audit events are append-only.
+type AuditEvent struct {
+	ID           int64           `json:"id"`
+	RequestID    string          `json:"request_id"`
+	Actor        string          `json:"actor"`
+	TokenID      string          `json:"token_id,omitempty"`
+	WorkspaceID  string          `json:"workspace_id,omitempty"`
+	RemoteAddr   string          `json:"remote_addr,omitempty"`
+	Action       string          `json:"action"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   string          `json:"resource_id,omitempty"`
+	Outcome      string          `json:"outcome"`
+	StatusCode   int             `json:"status_code"`
+	ErrorCode    string          `json:"error_code,omitempty"`
+	Details      json.RawMessage `json:"details,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// AuditFilter selects audit events; zero fields match everything. Events
+// come back newest first.
+type AuditFilter struct {
+	Action       string
+	Actor        string
+	ResourceType string
+	ResourceID   string
+	WorkspaceID  string
+	Outcome      string
+	Since        time.Time
+	Limit        int
+}
@@ -1,19 +1,58 @@
 package vmmodels
 
+import "time"
+
 // Library represents a JavaScript library that can be loaded into a VM
 type Library struct {
-	ID          string            `json:"id"`
-	Name        string            `json:"name"`
-	Version     string            `json:"version"`
-	Description string            `json:"description"`
-	Source      string            `json:"source"` // URL or local path to library source
-	Type        string            `json:"type"`   // "builtin", "npm", "url", "local"
-	Config      map[string]string `json:"config"` // Library-specific configuration
+	Ref          string            `json:"ref"` // "<id>-<version>", the name templates use
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	Version      string            `json:"version"`
+	Description  string            `json:"description"`
+	Source       string            `json:"source"` // URL or local path to library source
+	Type         string            `json:"type"`   // "npm", "url", "upload", "tarball"
+	Config       map[string]string `json:"config"` // Library-specific configuration
+	Builtin      bool              `json:"builtin"`
+	Dependencies []string          `json:"dependencies,omitempty"` // references loaded before this library
+	SHA256       string            `json:"sha256,omitempty"`       // expected code hash; empty pins the first download
+	CreatedAt    *time.Time        `json:"created_at,omitempty"`   // set for registered libraries
+}
+
+// LoadedLibrary records the exact library code a session loaded.
+type LoadedLibrary struct {
+	Ref    string `json:"ref"`
+	SHA256 string `json:"sha256"`
+}
+
+// Library types of user-registered libraries.
+const (
+	LibraryTypeUpload  = "upload"  // source uploaded in the request
+	LibraryTypeTarball = "tarball" // extracted from an offline npm .tgz
+)
+
+// LibraryRef returns the name templates use to select a library version.
+func LibraryRef(id, version string) string {
+	return id + "-" + version
+}
+
+// Global returns the global name the library defines.
+func (l Library) Global() string {
+	return l.Config["global"]
+}
+
+// FindBuiltinLibrary looks up a built-in library by ref.
+func FindBuiltinLibrary(ref string) (Library, bool) {
+	for _, lib := range BuiltinLibraries() {
+		if lib.Ref == ref {
+			return lib, true
+		}
+	}
+	return Library{}, false
 }
 
 // BuiltinLibraries returns a list of built-in libraries available for VMs
 func BuiltinLibraries() []Library {
-	return []Library{
+	libraries := []Library{
 		{
 			ID:          "lodash",
 			Name:        "Lodash",
@@ -69,6 +108,11 @@ func BuiltinLibraries() []Library {
 			Config:      map[string]string{"global": "zustand"},
 		},
 	}
+	for i := range libraries {
+		libraries[i].Ref = LibraryRef(libraries[i].ID, libraries[i].Version)
+		libraries[i].Builtin = true
+	}
+	return libraries
 }
 
 // ExposedModule represents a configurable host module exposed to the VM runtime.
@@ -0,0 +1,108 @@
+package vmmodels
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LibraryReference returns the canonical "name@version" reference of lib.
+func LibraryReference(lib Library) string {
+	return lib.ID + "@" + lib.Version
+}
+
+// ResolveLibrary finds the catalog entry a template reference names. A
+// reference is "name@version", a ref ("name-version"), or a bare name, which
+// must match a single version.
+func ResolveLibrary(reference string, catalog []Library) (Library, error) {
+	if i := strings.LastIndex(reference, "@"); i > 0 {
+		id, version := reference[:i], reference[i+1:]
+		for _, lib := range catalog {
+			if lib.ID == id && lib.Version == version {
+				return lib, nil
+			}
+		}
+		return Library{}, fmt.Errorf("%w: unknown library %s", ErrInvalidLibrary, reference)
+	}
+
+	var versions []Library
+	for _, lib := range catalog {
+		if lib.Ref == reference {
+			return lib, nil
+		}
+		if lib.ID == reference {
+			versions = append(versions, lib)
+		}
+	}
+	switch len(versions) {
+	case 0:
+		return Library{}, fmt.Errorf("%w: unknown library %s", ErrInvalidLibrary, reference)
+	case 1:
+		return versions[0], nil
+	default:
+		names := make([]string, 0, len(versions))
+		for _, lib := range versions {
+			names = append(names, LibraryReference(lib))
+		}
+		sort.Strings(names)
+		return Library{}, fmt.Errorf("%w: %s has several versions (%s); use name@version", ErrInvalidLibrary, reference, strings.Join(names, ", "))
+	}
+}
+
+// LibraryLoadOrder resolves template library references and their declared
+// dependencies against catalog and returns the libraries in load order:
+// every library after its dependencies, otherwise in reference order.
+// Dependency cycles and two libraries defining the same global are errors.
+func LibraryLoadOrder(references []string, catalog []Library) ([]Library, error) {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[string]int{}
+	globals := map[string]string{}
+	var order []Library
+	var path []string
+
+	var visit func(reference string) error
+	visit = func(reference string) error {
+		lib, err := ResolveLibrary(reference, catalog)
+		if err != nil {
+			if len(path) > 0 {
+				return fmt.Errorf("%w (dependency of %s)", err, path[len(path)-1])
+			}
+			return err
+		}
+		switch state[lib.Ref] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("%w: dependency cycle %s -> %s", ErrInvalidLibrary, strings.Join(path, " -> "), LibraryReference(lib))
+		}
+
+		state[lib.Ref] = visiting
+		path = append(path, LibraryReference(lib))
+		for _, dependency := range lib.Dependencies {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[lib.Ref] = done
+
+		if global := lib.Global(); global != "" {
+			if other, ok := globals[global]; ok {
+				return fmt.Errorf("%w: %s and %s both define global %s", ErrInvalidLibrary, other, LibraryReference(lib), global)
+			}
+			globals[global] = LibraryReference(lib)
+		}
+		order = append(order, lib)
+		return nil
+	}
+
+	for _, reference := range references {
+		if err := visit(reference); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
@@ -0,0 +1,87 @@
+package vmmodels
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testLibrary(id, version, global string, dependencies ...string) Library {
+	return Library{
+		Ref:          LibraryRef(id, version),
+		ID:           id,
+		Version:      version,
+		Config:       map[string]string{"global": global},
+		Dependencies: dependencies,
+	}
+}
+
+func TestResolveLibraryReferenceForms(t *testing.T) {
+	catalog := []Library{
+		testLibrary("dayjs", "1.11.10", "dayjs"),
+		testLibrary("dayjs", "2.0.0", "dayjs"),
+		testLibrary("ramda", "0.29.0", "R"),
+	}
+
+	for reference, want := range map[string]string{
+		"dayjs@2.0.0":   "dayjs-2.0.0",
+		"dayjs-1.11.10": "dayjs-1.11.10",
+		"ramda":         "ramda-0.29.0",
+	} {
+		lib, err := ResolveLibrary(reference, catalog)
+		if err != nil {
+			t.Fatalf("resolve %s: %v", reference, err)
+		}
+		if lib.Ref != want {
+			t.Fatalf("resolve %s: expected %s, got %s", reference, want, lib.Ref)
+		}
+	}
+
+	for _, reference := range []string{"dayjs", "dayjs@3.0.0", "missing"} {
+		if _, err := ResolveLibrary(reference, catalog); !errors.Is(err, ErrInvalidLibrary) {
+			t.Fatalf("resolve %s: expected ErrInvalidLibrary, got %v", reference, err)
+		}
+	}
+}
+
+func TestLibraryLoadOrderPlacesDependenciesFirst(t *testing.T) {
+	catalog := []Library{
+		testLibrary("dayjs", "1.11.10", "dayjs"),
+		testLibrary("dayjs-utc", "1.0.0", "dayjs_plugin_utc", "dayjs@1.11.10"),
+		testLibrary("ramda", "0.29.0", "R"),
+	}
+
+	order, err := LibraryLoadOrder([]string{"ramda", "dayjs-utc@1.0.0", "dayjs@1.11.10"}, catalog)
+	if err != nil {
+		t.Fatalf("load order: %v", err)
+	}
+	var refs []string
+	for _, lib := range order {
+		refs = append(refs, lib.Ref)
+	}
+	if strings.Join(refs, ",") != "ramda-0.29.0,dayjs-1.11.10,dayjs-utc-1.0.0" {
+		t.Fatalf("unexpected load order %v", refs)
+	}
+}
+
+func TestLibraryLoadOrderRejectsInvalidGraphs(t *testing.T) {
+	catalog := []Library{
+		testLibrary("a", "1.0.0", "a", "b@1.0.0"),
+		testLibrary("b", "1.0.0", "b", "a@1.0.0"),
+		testLibrary("dayjs", "1.11.10", "dayjs"),
+		testLibrary("dayjs", "2.0.0", "dayjs"),
+		testLibrary("plugin", "1.0.0", "plugin", "missing@1.0.0"),
+	}
+
+	cases := map[string][]string{
+		"dependency cycle":     {"a@1.0.0"},
+		"both define global":   {"dayjs@1.11.10", "dayjs@2.0.0"},
+		"dependency of plugin": {"plugin@1.0.0"},
+	}
+	for message, references := range cases {
+		_, err := LibraryLoadOrder(references, catalog)
+		if !errors.Is(err, ErrInvalidLibrary) || !strings.Contains(err.Error(), message) {
+			t.Fatalf("%v: expected ErrInvalidLibrary mentioning %q, got %v", references, message, err)
+		}
+	}
+}
@@ -3,25 +3,49 @@ package vmmodels
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 )
 
 // Common errors
 var (
-	ErrVMNotFound             = errors.New("VM not found")
-	ErrSessionNotFound        = errors.New("session not found")
-	ErrExecutionNotFound      = errors.New("execution not found")
-	ErrSessionNotReady        = errors.New("session not ready")
-	ErrSessionBusy            = errors.New("session busy")
-	ErrPathTraversal          = errors.New("path traversal is not allowed")
-	ErrStartupModeUnsupported = errors.New("startup mode is not supported")
-	ErrModuleNotAllowed       = errors.New("module not allowed")
-	ErrFileNotFound           = errors.New("file not found")
-	ErrImportResolutionFailed = errors.New("import resolution failed")
-	ErrStartupFailed          = errors.New("startup failed")
-	ErrExecTimeout            = errors.New("execution timeout")
-	ErrOutputLimitExceeded    = errors.New("output limit exceeded")
-	ErrInternalVMError        = errors.New("internal VM error")
+	ErrVMNotFound                    = errors.New("VM not found")
+	ErrTemplateNameConflict          = errors.New("template name already in use")
+	ErrTemplateInactive              = errors.New("template is inactive")
+	ErrCapabilityNotFound            = errors.New("capability not found")
+	ErrStartupFileNotFound           = errors.New("startup file not found")
+	ErrInvalidStartupOrder           = errors.New("invalid startup file order")
+	ErrInvalidStartupFile            = errors.New("invalid startup file")
+	ErrLibraryNotFound               = errors.New("library not found")
+	ErrLibraryExists                 = errors.New("library already registered")
+	ErrLibraryInUse                  = errors.New("library is used by templates")
+	ErrInvalidLibrary                = errors.New("invalid library")
+	ErrSessionNotFound               = errors.New("session not found")
+	ErrExecutionNotFound             = errors.New("execution not found")
+	ErrSessionNotReady               = errors.New("session not ready")
+	ErrSessionBusy                   = errors.New("session busy")
+	ErrSessionQuotaExceeded          = errors.New("session quota exceeded")
+	ErrTemplateSessionQuotaExceeded  = errors.New("template session quota exceeded")
+	ErrWorkspaceSessionQuotaExceeded = errors.New("workspace session quota exceeded")
+	ErrExecutionNotCancellable       = errors.New("execution is not queued")
+	ErrPathTraversal                 = errors.New("path traversal is not allowed")
+	ErrStartupModeUnsupported        = errors.New("startup mode is not supported")
+	ErrModuleNotAllowed              = errors.New("module not allowed")
+	ErrInvalidCapability             = errors.New("invalid capability config")
+	ErrFileNotFound                  = errors.New("file not found")
+	ErrImportResolutionFailed        = errors.New("import resolution failed")
+	ErrStartupFailed                 = errors.New("startup failed")
+	ErrExecTimeout                   = errors.New("execution timeout")
+	ErrOutputLimitExceeded           = errors.New("output limit exceeded")
+	ErrInternalVMError               = errors.New("internal VM error")
+	ErrUnauthenticated               = errors.New("missing, invalid or revoked API token")
+	ErrInsufficientScope             = errors.New("API token lacks the required scope")
+	ErrTokenNotFound                 = errors.New("API token not found")
+	ErrInvalidToken                  = errors.New("invalid API token")
+	ErrWorkspaceNotFound             = errors.New("workspace not found")
+	ErrWorkspaceExists               = errors.New("workspace already exists")
+	ErrInvalidWorkspace              = errors.New("invalid workspace")
+	ErrWorkspaceForbidden            = errors.New("resource belongs to another workspace")
 )
 
 // VM represents a VM profile (configuration template)
@@ -32,40 +56,131 @@ type VM struct {
 	IsActive       bool      `json:"is_active"`
 	ExposedModules []string  `json:"exposed_modules"` // IDs of exposed modules
 	Libraries      []string  `json:"libraries"`       // IDs of loaded libraries
+	Revision       int       `json:"revision"`        // latest template revision, 0 if none recorded
+	WorkspaceID    string    `json:"workspace_id"`    // owning workspace, "" for templates shared by all
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 // VMSettings contains VM configuration settings
 type VMSettings struct {
-	VMID     string          `json:"vm_id"`
-	Limits   json.RawMessage `json:"limits"`   // LimitsConfig as JSON
-	Resolver json.RawMessage `json:"resolver"` // ResolverConfig as JSON
-	Runtime  json.RawMessage `json:"runtime"`  // RuntimeConfig as JSON
+	VMID      string          `json:"vm_id"`
+	Limits    json.RawMessage `json:"limits"`    // LimitsConfig as JSON
+	Resolver  json.RawMessage `json:"resolver"`  // ResolverConfig as JSON
+	Runtime   json.RawMessage `json:"runtime"`   // RuntimeConfig as JSON
+	Pool      json.RawMessage `json:"pool"`      // PoolConfig as JSON
+	Lifecycle json.RawMessage `json:"lifecycle"` // LifecycleConfig as JSON
 }
 
 // LimitsConfig defines resource limits
 
 type LimitsConfig struct {
-	CPUMs       int `json:"cpu_ms"`
-	WallMs      int `json:"wall_ms"`
-	MemMB       int `json:"mem_mb"`
-	MaxEvents   int `json:"max_events"`
-	MaxOutputKB int `json:"max_output_kb"`
+	CPUMs       int `json:"cpu_ms" yaml:"cpu_ms"`
+	WallMs      int `json:"wall_ms" yaml:"wall_ms"`
+	MemMB       int `json:"mem_mb" yaml:"mem_mb"`
+	MaxEvents   int `json:"max_events" yaml:"max_events"`
+	MaxOutputKB int `json:"max_output_kb" yaml:"max_output_kb"`
 }
 
 // ResolverConfig defines module resolution settings
 type ResolverConfig struct {
-	Roots                    []string `json:"roots"`
-	Extensions               []string `json:"extensions"`
-	AllowAbsoluteRepoImports bool     `json:"allow_absolute_repo_imports"`
+	Roots                    []string `json:"roots" yaml:"roots"`
+	Extensions               []string `json:"extensions" yaml:"extensions"`
+	AllowAbsoluteRepoImports bool     `json:"allow_absolute_repo_imports" yaml:"allow_absolute_repo_imports"`
 }
 
 // RuntimeConfig defines runtime settings
 type RuntimeConfig struct {
-	ESM     bool `json:"esm"`
-	Strict  bool `json:"strict"`
-	Console bool `json:"console"`
+	ESM     bool `json:"esm" yaml:"esm"`
+	Strict  bool `json:"strict" yaml:"strict"`
+	Console bool `json:"console" yaml:"console"`
+}
+
+// PoolConfig keeps pre-built runtimes ready for a template so session
+// creation skips module, library and startup setup. MinIdle runtimes are kept
+// warm; Max, when positive, caps live plus idle sessions the pool warms for.
+type PoolConfig struct {
+	MinIdle int `json:"min_idle" yaml:"min_idle"`
+	Max     int `json:"max" yaml:"max"`
+}
+
+// Enabled reports whether the pool keeps any runtimes warm.
+func (c PoolConfig) Enabled() bool {
+	return c.MinIdle > 0
+}
+
+// Validate rejects negative sizes and a minimum above the maximum.
+func (c PoolConfig) Validate() error {
+	if c.MinIdle < 0 || c.Max < 0 {
+		return fmt.Errorf("pool sizes must not be negative")
+	}
+	if c.Max > 0 && c.MinIdle > c.Max {
+		return fmt.Errorf("pool min_idle %d exceeds max %d", c.MinIdle, c.Max)
+	}
+	return nil
+}
+
+// ParsePoolConfig decodes VMSettings.Pool; empty input is a disabled pool.
+func ParsePoolConfig(raw json.RawMessage) (PoolConfig, error) {
+	var cfg PoolConfig
+	if len(raw) == 0 {
+		return cfg, nil
+	}
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return cfg, fmt.Errorf("decode pool settings: %w", err)
+	}
+	return cfg, nil
+}
+
+// LifecycleConfig closes sessions of a template automatically. IdleTimeout
+// and MaxLifetime are Go durations such as "15m"; empty or zero disables the
+// corresponding limit.
+type LifecycleConfig struct {
+	IdleTimeout string `json:"idle_timeout,omitempty" yaml:"idle_timeout,omitempty"`
+	MaxLifetime string `json:"max_lifetime,omitempty" yaml:"max_lifetime,omitempty"`
+}
+
+// Durations parses IdleTimeout and MaxLifetime.
+func (c LifecycleConfig) Durations() (idleTimeout, maxLifetime time.Duration, err error) {
+	parse := func(name, value string) (time.Duration, error) {
+		if value == "" {
+			return 0, nil
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid lifecycle %s %q: %w", name, value, err)
+		}
+		if d < 0 {
+			return 0, fmt.Errorf("lifecycle %s must not be negative", name)
+		}
+		return d, nil
+	}
+	if idleTimeout, err = parse("idle_timeout", c.IdleTimeout); err != nil {
+		return 0, 0, err
+	}
+	if maxLifetime, err = parse("max_lifetime", c.MaxLifetime); err != nil {
+		return 0, 0, err
+	}
+	return idleTimeout, maxLifetime, nil
+}
+
+// Validate rejects durations that do not parse or are negative.
+func (c LifecycleConfig) Validate() error {
+	_, _, err := c.Durations()
+	return err
+}
+
+// ParseLifecycleConfig decodes VMSettings.Lifecycle; empty input never
+// expires sessions.
+func ParseLifecycleConfig(raw json.RawMessage) (LifecycleConfig, error) {
+	var cfg LifecycleConfig
+	if len(raw) == 0 {
+		return cfg, nil
+	}
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return cfg, fmt.Errorf("decode lifecycle settings: %w", err)
+	}
+	return cfg, nil
 }
 
 // VMCapability represents a module or global exposure
@@ -78,27 +193,65 @@ type VMCapability struct {
 	Config  json.RawMessage `json:"config"` // capability-specific config
 }
 
+// Capability kinds and names understood by the runtime.
+const (
+	CapabilityKindNet = "net"
+	CapabilityFetch   = "fetch"
+)
+
+// Defaults applied to a net/fetch capability when its config omits them.
+const (
+	DefaultNetTimeoutMs        = 10000
+	DefaultNetMaxResponseBytes = 1 << 20
+)
+
+// NetCapabilityConfig configures the sandboxed fetch() global enabled by a
+// net/fetch capability. An empty allowlist denies every host.
+type NetCapabilityConfig struct {
+	AllowHosts       []string `json:"allow_hosts"` // hostname, host:port, or *.suffix
+	TimeoutMs        int      `json:"timeout_ms"`
+	MaxResponseBytes int64    `json:"max_response_bytes"`
+}
+
 // VMStartupFile represents a file to run at session startup
 type VMStartupFile struct {
 	ID         string `json:"id"`
 	VMID       string `json:"vm_id"`
-	Path       string `json:"path"` // repo path
+	Kind       string `json:"kind"`             // path or inline
+	Path       string `json:"path"`             // repo path; for inline entries, a name used in errors
+	Source     string `json:"source,omitempty"` // script source of inline entries
 	OrderIndex int    `json:"order_index"`
 	Mode       string `json:"mode"` // eval (import is currently unsupported)
 }
 
+// Startup entry kinds. Path entries read a file from the session worktree;
+// inline entries carry their source in the template itself.
+const (
+	StartupKindPath   = "path"
+	StartupKindInline = "inline"
+)
+
 // VMSession represents a VM runtime instance
 type VMSession struct {
-	ID            string          `json:"id"`
-	VMID          string          `json:"vm_id"`
-	WorkspaceID   string          `json:"workspace_id"`
-	BaseCommitOID string          `json:"base_commit_oid"`
-	WorktreePath  string          `json:"worktree_path"`
-	Status        string          `json:"status"` // starting, ready, crashed, closed
-	CreatedAt     time.Time       `json:"created_at"`
-	ClosedAt      *time.Time      `json:"closed_at,omitempty"`
-	LastError     string          `json:"last_error,omitempty"`
-	RuntimeMeta   json.RawMessage `json:"runtime_meta,omitempty"`
+	ID               string          `json:"id"`
+	VMID             string          `json:"vm_id"`
+	WorkspaceID      string          `json:"workspace_id"`
+	BaseCommitOID    string          `json:"base_commit_oid"`
+	WorktreePath     string          `json:"worktree_path"`
+	TemplateRevision int             `json:"template_revision"` // template revision the session was built from
+	Status           string          `json:"status"`            // starting, ready, crashed, closed, expired
+	CreatedAt        time.Time       `json:"created_at"`
+	LastActivityAt   time.Time       `json:"last_activity_at"` // creation or the end of the latest execution
+	ClosedAt         *time.Time      `json:"closed_at,omitempty"`
+	LastError        string          `json:"last_error,omitempty"`
+	RuntimeMeta      json.RawMessage `json:"runtime_meta,omitempty"`
+	ExpiresAt        *time.Time      `json:"expires_at,omitempty"`    // live sessions with a lifecycle limit only; not persisted
+	ExpiresInMs      *int64          `json:"expires_in_ms,omitempty"` // time left until ExpiresAt when the session was read
+}
+
+// SessionRuntimeMeta is the content of VMSession.RuntimeMeta.
+type SessionRuntimeMeta struct {
+	Libraries []LoadedLibrary `json:"libraries,omitempty"`
 }
 
 // SessionStatus represents session states
@@ -109,23 +262,25 @@ const (
 	SessionReady    SessionStatus = "ready"
 	SessionCrashed  SessionStatus = "crashed"
 	SessionClosed   SessionStatus = "closed"
+	SessionExpired  SessionStatus = "expired" // closed by its template's idle_timeout or max_lifetime
 )
 
 // Execution represents a discrete code execution
 type Execution struct {
-	ID        string          `json:"id"`
-	SessionID string          `json:"session_id"`
-	Kind      string          `json:"kind"`            // startup, run_file, repl
-	Input     string          `json:"input,omitempty"` // snippet for repl
-	Path      string          `json:"path,omitempty"`  // entry path for run_file/startup
-	Args      json.RawMessage `json:"args"`
-	Env       json.RawMessage `json:"env"`
-	Status    string          `json:"status"` // running, ok, error, timeout, cancelled
-	StartedAt time.Time       `json:"started_at"`
-	EndedAt   *time.Time      `json:"ended_at,omitempty"`
-	Result    json.RawMessage `json:"result,omitempty"`
-	Error     json.RawMessage `json:"error,omitempty"`
-	Metrics   json.RawMessage `json:"metrics"`
+	ID          string          `json:"id"`
+	SessionID   string          `json:"session_id"`
+	WorkspaceID string          `json:"workspace_id"`    // workspace of the session
+	Kind        string          `json:"kind"`            // startup, run_file, repl
+	Input       string          `json:"input,omitempty"` // snippet for repl
+	Path        string          `json:"path,omitempty"`  // entry path for run_file/startup
+	Args        json.RawMessage `json:"args"`
+	Env         json.RawMessage `json:"env"`
+	Status      string          `json:"status"` // running, ok, error, timeout, cancelled
+	StartedAt   time.Time       `json:"started_at"`
+	EndedAt     *time.Time      `json:"ended_at,omitempty"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	Error       json.RawMessage `json:"error,omitempty"`
+	Metrics     json.RawMessage `json:"metrics"`
 }
 
 // ExecutionKind represents execution types
@@ -141,6 +296,7 @@ const (
 type ExecutionStatus string
 
 const (
+	ExecQueued    ExecutionStatus = "queued"
 	ExecRunning   ExecutionStatus = "running"
 	ExecOK        ExecutionStatus = "ok"
 	ExecError     ExecutionStatus = "error"
@@ -153,7 +309,7 @@ type ExecutionEvent struct {
 	ExecutionID string          `json:"execution_id"`
 	Seq         int             `json:"seq"`
 	Ts          time.Time       `json:"ts"`
-	Type        string          `json:"type"` // stdout, stderr, console, value, exception, system, input_echo
+	Type        string          `json:"type"` // stdout, stderr, console, value, exception, system, input_echo, net
 	Payload     json.RawMessage `json:"payload"`
 }
 
@@ -168,6 +324,7 @@ const (
 	EventException EventType = "exception"
 	EventSystem    EventType = "system"
 	EventInputEcho EventType = "input_echo"
+	EventNet       EventType = "net"
 )
 
 // ConsolePayload represents console event payload
@@ -189,6 +346,16 @@ type ExceptionPayload struct {
 	Stack   string `json:"stack,omitempty"`
 }
 
+// NetPayload summarizes one fetch() request made by the execution
+type NetPayload struct {
+	Method     string `json:"method"`
+	URL        string `json:"url"`
+	Status     int    `json:"status,omitempty"`
+	Bytes      int64  `json:"bytes"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
 // SystemPayload represents system event payload
 type SystemPayload struct {
 	Message string `json:"message"`
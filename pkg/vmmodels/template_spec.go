@@ -0,0 +1,69 @@
+package vmmodels
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrInvalidTemplateSpec is returned when a declarative template spec fails
+	// validation.
+	ErrInvalidTemplateSpec = errors.New("invalid template spec")
+	// ErrTemplateRevisionNotFound is returned for unknown template revisions.
+	ErrTemplateRevisionNotFound = errors.New("template revision not found")
+)
+
+// TemplateSpec is the declarative, reviewable form of a template. It carries
+// everything a session needs from the template but no server-assigned IDs, so
+// the same spec can be applied to any daemon.
+type TemplateSpec struct {
+	Name         string                    `json:"name" yaml:"name"`
+	Engine       string                    `json:"engine,omitempty" yaml:"engine,omitempty"`
+	Settings     *TemplateSpecSettings     `json:"settings,omitempty" yaml:"settings,omitempty"`
+	Modules      []string                  `json:"modules,omitempty" yaml:"modules,omitempty"`
+	Libraries    []string                  `json:"libraries,omitempty" yaml:"libraries,omitempty"`
+	Capabilities []TemplateSpecCapability  `json:"capabilities,omitempty" yaml:"capabilities,omitempty"`
+	StartupFiles []TemplateSpecStartupFile `json:"startup_files,omitempty" yaml:"startup_files,omitempty"`
+}
+
+// TemplateSpecSettings holds template settings. Omitted sections keep their
+// current (or default) values when the spec is applied.
+type TemplateSpecSettings struct {
+	Limits    *LimitsConfig    `json:"limits,omitempty" yaml:"limits,omitempty"`
+	Resolver  *ResolverConfig  `json:"resolver,omitempty" yaml:"resolver,omitempty"`
+	Runtime   *RuntimeConfig   `json:"runtime,omitempty" yaml:"runtime,omitempty"`
+	Pool      *PoolConfig      `json:"pool,omitempty" yaml:"pool,omitempty"`
+	Lifecycle *LifecycleConfig `json:"lifecycle,omitempty" yaml:"lifecycle,omitempty"`
+}
+
+// TemplateSpecCapability declares one capability, keyed by kind and name.
+type TemplateSpecCapability struct {
+	Kind    string                 `json:"kind" yaml:"kind"`
+	Name    string                 `json:"name" yaml:"name"`
+	Enabled *bool                  `json:"enabled,omitempty" yaml:"enabled,omitempty"` // defaults to true
+	Config  map[string]interface{} `json:"config,omitempty" yaml:"config,omitempty"`
+}
+
+// IsEnabled reports whether the capability is enabled, defaulting to true.
+func (c TemplateSpecCapability) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// TemplateSpecStartupFile declares one startup file, keyed by path. Kind
+// defaults to a worktree path; inline entries carry their Source.
+type TemplateSpecStartupFile struct {
+	Kind       string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Path       string `json:"path" yaml:"path"`
+	Source     string `json:"source,omitempty" yaml:"source,omitempty"`
+	OrderIndex int    `json:"order_index" yaml:"order_index"`
+	Mode       string `json:"mode,omitempty" yaml:"mode,omitempty"`
+}
+
+// TemplateRevision is an immutable snapshot of a template, recorded each time
+// its modules, libraries, settings, capabilities, or startup files change.
+type TemplateRevision struct {
+	VMID      string        `json:"template_id"`
+	Revision  int           `json:"revision"`
+	Spec      *TemplateSpec `json:"spec"`
+	CreatedAt time.Time     `json:"created_at"`
+}
@@ -0,0 +1,56 @@
+package vmmodels
+
+import "time"
+
+// API token scopes. Every scope allows read requests, so a token holding
+// only ScopeRead is read-only.
+const (
+	ScopeRead           = "read"
+	ScopeTemplatesWrite = "templates:write"
+	ScopeSessionsCreate = "sessions:create"
+	ScopeExecutionsRun  = "executions:run"
+)
+
+// TokenScopes lists the scopes a token can be granted.
+func TokenScopes() []string {
+	return []string{ScopeRead, ScopeTemplatesWrite, ScopeSessionsCreate, ScopeExecutionsRun}
+}
+
+// IsTokenScope reports whether scope is one of TokenScopes.
+func IsTokenScope(scope string) bool {
+	for _, known := range TokenScopes() {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
+
+// APIToken is a bearer token accepted by the daemon API. Only the SHA-256 of
+// the secret is stored; the secret itself is shown once, at creation. A token
+// bound to a client certificate subject has no usable secret: it is the
+// identity of callers presenting a verified certificate with that subject.
+type APIToken struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Scopes      []string   `json:"scopes"`
+	WorkspaceID string     `json:"workspace_id,omitempty"` // workspace the token is confined to, "" for all
+	CertSubject string     `json:"cert_subject,omitempty"` // client certificate subject, e.g. "CN=build-host,O=ci"
+	Hash        string     `json:"-"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Allows reports whether the token grants scope. Any scope grants ScopeRead.
+func (t *APIToken) Allows(scope string) bool {
+	if scope == ScopeRead && len(t.Scopes) > 0 {
+		return true
+	}
+	for _, granted := range t.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
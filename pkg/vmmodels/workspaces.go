@@ -0,0 +1,33 @@
+package vmmodels
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxWorkspaceIDLength bounds workspace IDs, which appear in URLs and logs.
+const maxWorkspaceIDLength = 128
+
+// Workspace owns templates, sessions and executions. Callers whose API token
+// is bound to a workspace only see that workspace's resources; templates with
+// no workspace are shared and readable by everyone.
+type Workspace struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NormalizeWorkspaceID trims id and checks it can name a workspace.
+func NormalizeWorkspaceID(id string) (string, error) {
+	id = strings.TrimSpace(id)
+	switch {
+	case id == "":
+		return "", fmt.Errorf("%w: workspace id is required", ErrInvalidWorkspace)
+	case len(id) > maxWorkspaceIDLength:
+		return "", fmt.Errorf("%w: workspace id is longer than %d characters", ErrInvalidWorkspace, maxWorkspaceIDLength)
+	case strings.ContainsAny(id, "/?# \t\r\n"):
+		return "", fmt.Errorf("%w: workspace id %q contains whitespace or URL delimiters", ErrInvalidWorkspace, id)
+	}
+	return id, nil
+}
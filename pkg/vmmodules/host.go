@@ -0,0 +1,116 @@
+package vmmodules
+
+import (
+	"sort"
+
+	"github.com/dop251/goja"
+	"github.com/dop251/goja_nodejs/require"
+	gogojamodules "github.com/go-go-golems/go-go-goja/modules"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+// SessionInfo identifies the session a host module is installed into, so
+// embedders can scope per-session host objects (loggers, tenant clients, ...).
+// Templates exposing a host module are never pooled, so every field is set.
+type SessionInfo struct {
+	SessionID    string
+	TemplateID   string
+	WorkspaceID  string
+	WorktreePath string
+}
+
+// HostModule is a template-configurable module contributed by a Go program
+// embedding vm-system. Templates select it by Name exactly like built-in
+// native modules.
+type HostModule struct {
+	Name        string
+	Description string
+	Functions   []string
+
+	// New builds the module instance for one session, so exports and globals
+	// may close over session-scoped state.
+	New func(session SessionInfo) (*HostInstance, error)
+}
+
+// HostInstance is the per-session materialization of a HostModule.
+type HostInstance struct {
+	// Loader backs require(<module name>). Optional.
+	Loader require.ModuleLoader
+	// Globals are installed on the runtime global object. Optional.
+	Globals map[string]interface{}
+}
+
+// NativeHostModule adapts a go-go-goja native module so it can be registered
+// without adding it to the process-wide go-go-goja registry.
+func NativeHostModule(module gogojamodules.NativeModule, functions ...string) HostModule {
+	return HostModule{
+		Name:        module.Name(),
+		Description: module.Doc(),
+		Functions:   functions,
+		New: func(SessionInfo) (*HostInstance, error) {
+			return &HostInstance{Loader: module.Loader}, nil
+		},
+	}
+}
+
+// GlobalFunctions exposes Go functions as runtime globals. The functions are
+// shared by every session that enables the module.
+func GlobalFunctions(name, description string, functions map[string]interface{}) HostModule {
+	names := make([]string, 0, len(functions))
+	for fnName := range functions {
+		names = append(names, fnName)
+	}
+	sort.Strings(names)
+
+	return HostModule{
+		Name:        name,
+		Description: description,
+		Functions:   names,
+		New: func(SessionInfo) (*HostInstance, error) {
+			globals := make(map[string]interface{}, len(functions))
+			for fnName, fn := range functions {
+				globals[fnName] = fn
+			}
+			return &HostInstance{Globals: globals}, nil
+		},
+	}
+}
+
+// SessionObject injects a host object built once per session by factory. The
+// object is the require(name) export and, when globalName is non-empty, also
+// a global.
+func SessionObject(name, description, globalName string, factory func(session SessionInfo) (interface{}, error)) HostModule {
+	return HostModule{
+		Name:        name,
+		Description: description,
+		New: func(session SessionInfo) (*HostInstance, error) {
+			value, err := factory(session)
+			if err != nil {
+				return nil, err
+			}
+			instance := &HostInstance{
+				Loader: func(vm *goja.Runtime, moduleObj *goja.Object) {
+					_ = moduleObj.Set("exports", vm.ToValue(value))
+				},
+			}
+			if globalName != "" {
+				instance.Globals = map[string]interface{}{globalName: value}
+			}
+			return instance, nil
+		},
+	}
+}
+
+func (m HostModule) exposedModule() vmmodels.ExposedModule {
+	functions := make([]string, len(m.Functions))
+	copy(functions, m.Functions)
+	return vmmodels.ExposedModule{
+		ID:          m.Name,
+		Name:        m.Name,
+		Kind:        "host",
+		Description: m.Description,
+		Functions:   functions,
+		Config:      map[string]string{},
+	}
+}
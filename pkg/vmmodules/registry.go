@@ -1,9 +1,11 @@
 package vmmodules
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/dop251/goja"
 	"github.com/dop251/goja_nodejs/require"
@@ -26,6 +28,8 @@ var jsBuiltinModuleSet = map[string]struct{}{
 	"promise": {},
 }
 
+var defaultRegistry = &Registry{hostModules: map[string]HostModule{}}
+
 func normalizeModuleName(name string) string {
 	return strings.ToLower(strings.TrimSpace(name))
 }
@@ -37,20 +41,80 @@ func IsJSBuiltinModule(name string) bool {
 	return ok
 }
 
-// RegisteredModuleNames returns sorted registered go-go-goja module names.
-func RegisteredModuleNames() []string {
-	docs := gogojamodules.DefaultRegistry.GetDocumentation()
-	names := make([]string, 0, len(docs))
-	for name := range docs {
-		names = append(names, name)
+// Registry resolves template-configurable module names against the go-go-goja
+// registry plus any host modules contributed by an embedding program.
+type Registry struct {
+	mu          sync.RWMutex
+	hostModules map[string]HostModule
+}
+
+// NewRegistry builds a registry that exposes the go-go-goja native modules
+// and the provided host modules.
+func NewRegistry(modules ...HostModule) (*Registry, error) {
+	r := &Registry{hostModules: map[string]HostModule{}}
+	for _, module := range modules {
+		if err := r.Register(module); err != nil {
+			return nil, err
+		}
 	}
-	sort.Strings(names)
-	return names
+	return r, nil
 }
 
-// ValidateConfiguredModuleName validates template module input against the
-// go-go-goja registry and policy constraints.
-func ValidateConfiguredModuleName(name string) (string, error) {
+// DefaultRegistry returns the shared registry containing only the go-go-goja
+// native modules.
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// Register adds a host module. Names must not shadow JavaScript built-ins,
+// go-go-goja modules, or previously registered host modules.
+func (r *Registry) Register(module HostModule) error {
+	name := normalizeModuleName(module.Name)
+	if name == "" {
+		return errors.New("host module name is required")
+	}
+	if module.New == nil {
+		return fmt.Errorf("host module %q must define New", name)
+	}
+	if IsJSBuiltinModule(name) {
+		return fmt.Errorf("host module %q shadows a JavaScript built-in", name)
+	}
+	if gogojamodules.GetModule(name) != nil {
+		return fmt.Errorf("host module %q shadows a registered native module", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.hostModules[name]; ok {
+		return fmt.Errorf("host module %q is already registered", name)
+	}
+	module.Name = name
+	r.hostModules[name] = module
+	return nil
+}
+
+// Catalog returns the template-configurable module catalog, built-in native
+// modules first followed by host modules sorted by name.
+func (r *Registry) Catalog() []vmmodels.ExposedModule {
+	catalog := vmmodels.BuiltinModules()
+
+	r.mu.RLock()
+	hostNames := make([]string, 0, len(r.hostModules))
+	for name := range r.hostModules {
+		hostNames = append(hostNames, name)
+	}
+	sort.Strings(hostNames)
+	for _, name := range hostNames {
+		catalog = append(catalog, r.hostModules[name].exposedModule())
+	}
+	r.mu.RUnlock()
+
+	return catalog
+}
+
+// Validate validates template module input against the registry and policy
+// constraints, returning the normalized module name.
+func (r *Registry) Validate(name string) (string, error) {
 	normalized := normalizeModuleName(name)
 	if normalized == "" {
 		return "", fmt.Errorf("%w: module name is required", vmmodels.ErrModuleNotAllowed)
@@ -58,34 +122,103 @@ func ValidateConfiguredModuleName(name string) (string, error) {
 	if IsJSBuiltinModule(normalized) {
 		return "", fmt.Errorf("%w: %q is a JavaScript built-in and cannot be configured per template", vmmodels.ErrModuleNotAllowed, normalized)
 	}
-	if gogojamodules.GetModule(normalized) == nil {
-		return "", fmt.Errorf("%w: %q is not a registered native module", vmmodels.ErrModuleNotAllowed, normalized)
+	if gogojamodules.GetModule(normalized) != nil {
+		return normalized, nil
+	}
+	if _, ok := r.hostModule(normalized); ok {
+		return normalized, nil
 	}
-	return normalized, nil
+	return "", fmt.Errorf("%w: %q is not a registered native module", vmmodels.ErrModuleNotAllowed, normalized)
 }
 
-// EnableConfiguredModules enables template-configured go-go-goja native
-// modules and installs require() for the provided runtime.
-func EnableConfiguredModules(vm *goja.Runtime, configured []string) error {
+// Enable enables template-configured modules for the session runtime: native
+// and host loaders become available through require(), and host globals are
+// installed on the global object.
+func (r *Registry) Enable(vm *goja.Runtime, configured []string, session SessionInfo) error {
 	reg := require.NewRegistry()
 	seen := map[string]struct{}{}
+	globals := map[string]interface{}{}
 
 	for _, rawName := range configured {
-		name, err := ValidateConfiguredModuleName(rawName)
+		name, err := r.Validate(rawName)
 		if err != nil {
 			return err
 		}
 		if _, ok := seen[name]; ok {
 			continue
 		}
-		module := gogojamodules.GetModule(name)
-		if module == nil {
+		seen[name] = struct{}{}
+
+		if module := gogojamodules.GetModule(name); module != nil {
+			reg.RegisterNativeModule(name, module.Loader)
+			continue
+		}
+
+		hostModule, ok := r.hostModule(name)
+		if !ok {
 			return fmt.Errorf("%w: %q is not a registered native module", vmmodels.ErrModuleNotAllowed, name)
 		}
-		reg.RegisterNativeModule(name, module.Loader)
-		seen[name] = struct{}{}
+		instance, err := hostModule.New(session)
+		if err != nil {
+			return fmt.Errorf("initialize host module %q: %w", name, err)
+		}
+		if instance == nil {
+			continue
+		}
+		if instance.Loader != nil {
+			reg.RegisterNativeModule(name, instance.Loader)
+		}
+		for globalName, value := range instance.Globals {
+			globals[globalName] = value
+		}
 	}
 
 	reg.Enable(vm)
+
+	for name, value := range globals {
+		if err := vm.Set(name, value); err != nil {
+			return fmt.Errorf("install host global %q: %w", name, err)
+		}
+	}
 	return nil
 }
+
+// ExposesHostModule reports whether any configured module is a host module.
+func (r *Registry) ExposesHostModule(configured []string) bool {
+	for _, name := range configured {
+		if _, ok := r.hostModule(normalizeModuleName(name)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Registry) hostModule(name string) (HostModule, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	module, ok := r.hostModules[name]
+	return module, ok
+}
+
+// RegisteredModuleNames returns sorted registered go-go-goja module names.
+func RegisteredModuleNames() []string {
+	docs := gogojamodules.DefaultRegistry.GetDocumentation()
+	names := make([]string, 0, len(docs))
+	for name := range docs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateConfiguredModuleName validates template module input against the
+// go-go-goja registry and policy constraints.
+func ValidateConfiguredModuleName(name string) (string, error) {
+	return defaultRegistry.Validate(name)
+}
+
+// EnableConfiguredModules enables template-configured go-go-goja native
+// modules and installs require() for the provided runtime.
+func EnableConfiguredModules(vm *goja.Runtime, configured []string) error {
+	return defaultRegistry.Enable(vm, configured, SessionInfo{})
+}
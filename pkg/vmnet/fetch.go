@@ -0,0 +1,307 @@
+// Package vmnet implements the sandboxed network globals a template can opt
+// into through a net capability.
+package vmnet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+const maxRedirects = 10
+
+// ErrHostNotAllowed is returned when a request targets a host outside the
+// capability allowlist.
+var ErrHostNotAllowed = errors.New("host not allowed")
+
+// Scheduler runs blocking work off the runtime goroutine; the returned
+// completion is run back on the runtime goroutine.
+type Scheduler interface {
+	Go(work func() func())
+}
+
+// Fetch is a Go implementation of a subset of the WHATWG fetch API. Requests
+// are restricted to the configured host allowlist, bounded by a timeout, and
+// capped in response size.
+type Fetch struct {
+	cfg    vmmodels.NetCapabilityConfig
+	client *http.Client
+	sched  Scheduler
+	emit   func(vmmodels.NetPayload)
+}
+
+type fetchRequest struct {
+	method  string
+	url     *url.URL
+	headers http.Header
+	body    string
+}
+
+type fetchResult struct {
+	status     int
+	statusText string
+	url        string
+	headers    http.Header
+	body       []byte
+	err        error
+}
+
+// ParseConfig decodes a net capability config and applies defaults.
+func ParseConfig(raw json.RawMessage) (vmmodels.NetCapabilityConfig, error) {
+	cfg := vmmodels.NetCapabilityConfig{}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &cfg); err != nil {
+			return cfg, fmt.Errorf("%w: %v", vmmodels.ErrInvalidCapability, err)
+		}
+	}
+	if cfg.TimeoutMs < 0 || cfg.MaxResponseBytes < 0 {
+		return cfg, fmt.Errorf("%w: timeout_ms and max_response_bytes must not be negative", vmmodels.ErrInvalidCapability)
+	}
+	for i, host := range cfg.AllowHosts {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" || strings.Contains(host, "/") {
+			return cfg, fmt.Errorf("%w: invalid allow_hosts entry %q", vmmodels.ErrInvalidCapability, cfg.AllowHosts[i])
+		}
+		cfg.AllowHosts[i] = host
+	}
+	if cfg.TimeoutMs == 0 {
+		cfg.TimeoutMs = vmmodels.DefaultNetTimeoutMs
+	}
+	if cfg.MaxResponseBytes == 0 {
+		cfg.MaxResponseBytes = vmmodels.DefaultNetMaxResponseBytes
+	}
+	return cfg, nil
+}
+
+// NewFetch creates a fetch implementation. emit receives one summary per
+// request, on the runtime goroutine; it may be nil.
+func NewFetch(cfg vmmodels.NetCapabilityConfig, sched Scheduler, emit func(vmmodels.NetPayload)) *Fetch {
+	f := &Fetch{
+		cfg:   cfg,
+		sched: sched,
+		emit:  emit,
+	}
+	f.client = &http.Client{
+		Timeout: time.Duration(cfg.TimeoutMs) * time.Millisecond,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return f.checkHost(req.URL)
+		},
+	}
+	return f
+}
+
+// Install sets the fetch global on the runtime.
+func (f *Fetch) Install(vm *goja.Runtime) error {
+	return vm.Set("fetch", f.jsFetch(vm))
+}
+
+func (f *Fetch) checkHost(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	hostname := strings.ToLower(u.Hostname())
+	hostPort := strings.ToLower(u.Host)
+	for _, allowed := range f.cfg.AllowHosts {
+		switch {
+		case allowed == hostname, allowed == hostPort:
+			return nil
+		case strings.HasPrefix(allowed, "*.") && strings.HasSuffix(hostname, allowed[1:]):
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrHostNotAllowed, hostPort)
+}
+
+func (f *Fetch) jsFetch(vm *goja.Runtime) func(goja.FunctionCall) goja.Value {
+	return func(call goja.FunctionCall) goja.Value {
+		promise, resolve, reject := vm.NewPromise()
+
+		req, err := parseRequest(call)
+		if err == nil {
+			err = f.checkHost(req.url)
+		}
+		if err != nil {
+			// Settle asynchronously so rejection behaves like a failed request.
+			summary := vmmodels.NetPayload{Error: err.Error()}
+			if req != nil {
+				summary.Method = req.method
+				summary.URL = req.url.String()
+			}
+			f.sched.Go(func() func() {
+				return func() {
+					f.record(summary)
+					_ = reject(vm.NewTypeError("fetch failed: %s", err.Error()))
+				}
+			})
+			return vm.ToValue(promise)
+		}
+
+		f.sched.Go(func() func() {
+			started := time.Now()
+			result := f.do(req)
+			summary := vmmodels.NetPayload{
+				Method:     req.method,
+				URL:        req.url.String(),
+				Status:     result.status,
+				Bytes:      int64(len(result.body)),
+				DurationMs: time.Since(started).Milliseconds(),
+			}
+			if result.err != nil {
+				summary.Error = result.err.Error()
+			}
+			return func() {
+				f.record(summary)
+				if result.err != nil {
+					_ = reject(vm.NewTypeError("fetch failed: %s", result.err.Error()))
+					return
+				}
+				_ = resolve(newResponse(vm, result))
+			}
+		})
+		return vm.ToValue(promise)
+	}
+}
+
+func (f *Fetch) record(summary vmmodels.NetPayload) {
+	if f.emit != nil {
+		f.emit(summary)
+	}
+}
+
+func (f *Fetch) do(req *fetchRequest) fetchResult {
+	var body io.Reader
+	if req.body != "" {
+		body = strings.NewReader(req.body)
+	}
+	httpReq, err := http.NewRequest(req.method, req.url.String(), body)
+	if err != nil {
+		return fetchResult{err: err}
+	}
+	httpReq.Header = req.headers
+
+	resp, err := f.client.Do(httpReq)
+	if err != nil {
+		return fetchResult{err: err}
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, f.cfg.MaxResponseBytes+1))
+	if err != nil {
+		return fetchResult{status: resp.StatusCode, err: err}
+	}
+	if int64(len(data)) > f.cfg.MaxResponseBytes {
+		return fetchResult{
+			status: resp.StatusCode,
+			body:   data[:f.cfg.MaxResponseBytes],
+			err:    fmt.Errorf("response exceeds max_response_bytes (%d)", f.cfg.MaxResponseBytes),
+		}
+	}
+
+	return fetchResult{
+		status:     resp.StatusCode,
+		statusText: http.StatusText(resp.StatusCode),
+		url:        resp.Request.URL.String(),
+		headers:    resp.Header,
+		body:       data,
+	}
+}
+
+func parseRequest(call goja.FunctionCall) (*fetchRequest, error) {
+	input := call.Argument(0)
+	if goja.IsUndefined(input) || goja.IsNull(input) {
+		return nil, errors.New("fetch requires a URL")
+	}
+	rawURL := input.String()
+	if obj, ok := input.(*goja.Object); ok {
+		if u := obj.Get("url"); u != nil && !goja.IsUndefined(u) {
+			rawURL = u.String()
+		}
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+
+	req := &fetchRequest{
+		method:  http.MethodGet,
+		url:     u,
+		headers: http.Header{},
+	}
+
+	init, ok := call.Argument(1).(*goja.Object)
+	if !ok {
+		return req, nil
+	}
+	if method := init.Get("method"); method != nil && !goja.IsUndefined(method) {
+		req.method = strings.ToUpper(method.String())
+	}
+	if headers, ok := init.Get("headers").(*goja.Object); ok {
+		for _, key := range headers.Keys() {
+			req.headers.Set(key, headers.Get(key).String())
+		}
+	}
+	if body := init.Get("body"); body != nil && !goja.IsUndefined(body) && !goja.IsNull(body) {
+		req.body = body.String()
+	}
+	return req, nil
+}
+
+func newResponse(vm *goja.Runtime, result fetchResult) *goja.Object {
+	headers := vm.NewObject()
+	_ = headers.Set("get", func(name string) interface{} {
+		if values, ok := result.headers[http.CanonicalHeaderKey(name)]; ok {
+			return strings.Join(values, ", ")
+		}
+		return nil
+	})
+	_ = headers.Set("has", func(name string) bool {
+		_, ok := result.headers[http.CanonicalHeaderKey(name)]
+		return ok
+	})
+
+	body := string(result.body)
+	promiseCtor := vm.Get("Promise").ToObject(vm)
+	settled := func(method string, value goja.Value) goja.Value {
+		fn, _ := goja.AssertFunction(promiseCtor.Get(method))
+		promise, err := fn(promiseCtor, value)
+		if err != nil {
+			panic(err)
+		}
+		return promise
+	}
+
+	response := vm.NewObject()
+	_ = response.Set("ok", result.status >= 200 && result.status < 300)
+	_ = response.Set("status", result.status)
+	_ = response.Set("statusText", result.statusText)
+	_ = response.Set("url", result.url)
+	_ = response.Set("headers", headers)
+	_ = response.Set("text", func() goja.Value {
+		return settled("resolve", vm.ToValue(body))
+	})
+	_ = response.Set("json", func() goja.Value {
+		parse, _ := goja.AssertFunction(vm.Get("JSON").ToObject(vm).Get("parse"))
+		value, err := parse(goja.Undefined(), vm.ToValue(body))
+		if err != nil {
+			var exception *goja.Exception
+			if errors.As(err, &exception) {
+				return settled("reject", exception.Value())
+			}
+			return settled("reject", vm.NewGoError(err))
+		}
+		return settled("resolve", value)
+	})
+	return response
+}
@@ -0,0 +1,55 @@
+package vmnet
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+func TestParseConfigAppliesDefaults(t *testing.T) {
+	cfg, err := ParseConfig(json.RawMessage(`{"allow_hosts":[" API.Example.com "]}`))
+	if err != nil {
+		t.Fatalf("parse config: %v", err)
+	}
+	if cfg.TimeoutMs != vmmodels.DefaultNetTimeoutMs || cfg.MaxResponseBytes != vmmodels.DefaultNetMaxResponseBytes {
+		t.Fatalf("expected defaults, got %+v", cfg)
+	}
+	if cfg.AllowHosts[0] != "api.example.com" {
+		t.Fatalf("expected normalized host, got %q", cfg.AllowHosts[0])
+	}
+
+	for _, raw := range []string{`{"allow_hosts":"x"}`, `{"timeout_ms":-1}`, `{"allow_hosts":["http://x/"]}`} {
+		if _, err := ParseConfig(json.RawMessage(raw)); !errors.Is(err, vmmodels.ErrInvalidCapability) {
+			t.Fatalf("expected %s to be rejected, got %v", raw, err)
+		}
+	}
+}
+
+func TestCheckHostMatchesAllowlist(t *testing.T) {
+	f := NewFetch(vmmodels.NetCapabilityConfig{
+		AllowHosts: []string{"api.example.com", "127.0.0.1:8080", "*.internal.test"},
+	}, nil, nil)
+
+	cases := map[string]bool{
+		"https://api.example.com/v1":      true,
+		"https://api.example.com:8443/v1": true,
+		"http://127.0.0.1:8080/":          true,
+		"http://127.0.0.1:9090/":          false,
+		"https://svc.internal.test/":      true,
+		"https://internal.test/":          false,
+		"https://evil.com/":               false,
+		"file:///etc/passwd":              false,
+	}
+	for raw, allowed := range cases {
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatalf("parse %s: %v", raw, err)
+		}
+		if got := f.checkHost(u) == nil; got != allowed {
+			t.Fatalf("checkHost(%s) = %v, want %v", raw, got, allowed)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+// Package vmrun runs one file in a throwaway session, without a daemon. It
+// builds a core over an in-memory store, applies a template spec, starts a
+// session on a worktree and runs the file in it, following the execution
+// while it runs.
+package vmrun
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/libloader"
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmexec"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+	"github.com/go-go-golems/vm-system/pkg/vmsession"
+	"github.com/go-go-golems/vm-system/pkg/vmstore"
+)
+
+const (
+	// WorkspaceID is the workspace run sessions belong to.
+	WorkspaceID = "run"
+	// baseCommitOID labels the session's worktree: run executes whatever is
+	// checked out, not a recorded commit.
+	baseCommitOID = "worktree"
+)
+
+// Outcome classifies how a run ended.
+type Outcome string
+
+const (
+	OutcomeOK        Outcome = "ok"
+	OutcomeException Outcome = "exception"      // the file threw
+	OutcomeTimeout   Outcome = "timeout"        // the run outlived its timeout
+	OutcomeLimit     Outcome = "limit_exceeded" // the file's output broke a template limit
+	// OutcomeInterrupted means the run's context was cancelled, e.g. by
+	// Ctrl-C or a CI job being cancelled.
+	OutcomeInterrupted Outcome = "interrupted"
+)
+
+// Options describes a run.
+type Options struct {
+	Template *vmmodels.TemplateSpec
+	Worktree string // directory the session is bound to
+	Path     string // file to run, relative to Worktree
+	Args     map[string]interface{}
+	Env      map[string]interface{}
+	// Timeout interrupts the file after this long. 0 uses the template's
+	// wall_ms limit, and no limit when that is unset too.
+	Timeout time.Duration
+	// DataDir holds the library cache the session loads libraries from.
+	DataDir string
+	// Observer follows the execution while it runs.
+	Observer vmexec.Observer
+}
+
+// Report is the result of a run.
+type Report struct {
+	Template  string                     `json:"template"`
+	SessionID string                     `json:"session_id"`
+	Outcome   Outcome                    `json:"outcome"`
+	Error     string                     `json:"error,omitempty"`
+	Execution *vmmodels.Execution        `json:"execution,omitempty"`
+	Events    []*vmmodels.ExecutionEvent `json:"events"`
+}
+
+// Failed reports whether the run should fail its caller.
+func (r *Report) Failed() bool {
+	return r.Outcome != OutcomeOK
+}
+
+// Run runs opts.Path and reports how it ended. It returns an error only when
+// the run could not start: an invalid template, worktree or path. A file
+// that throws, times out or breaks a limit is a Report with that Outcome.
+// Cancelling ctx interrupts the file and reports OutcomeInterrupted.
+func Run(ctx context.Context, opts Options) (*Report, error) {
+	if opts.Template == nil {
+		return nil, fmt.Errorf("a template spec is required")
+	}
+	worktree, err := filepath.Abs(opts.Worktree)
+	if err != nil {
+		return nil, fmt.Errorf("resolve worktree: %w", err)
+	}
+
+	store := vmstore.NewMemoryStore()
+
+	dataDir := opts.DataDir
+	if dataDir == "" {
+		dataDir = libloader.DefaultDataDir
+	}
+	sessions := vmsession.NewSessionManager(store, vmsession.WithLibraryDir(libloader.CacheDir(dataDir)))
+	executor := vmexec.NewExecutor(store, sessions)
+	core := vmcontrol.NewCoreWithPorts(store, sessions, executor, vmcontrol.WithDataDir(dataDir))
+
+	applied, err := core.Templates.Apply(ctx, opts.Template, false)
+	if err != nil {
+		return nil, fmt.Errorf("apply template %s: %w", opts.Template.Name, err)
+	}
+	template := applied.Template
+	session, err := core.Sessions.Create(ctx, vmcontrol.CreateSessionInput{
+		TemplateID:    template.ID,
+		WorkspaceID:   WorkspaceID,
+		BaseCommitOID: baseCommitOID,
+		WorktreePath:  worktree,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create session: %w", err)
+	}
+	defer func() { _ = sessions.CloseSession(session.ID) }()
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout, err = wallLimit(store, template.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	report := &Report{Template: template.Name, SessionID: session.ID, Events: []*vmmodels.ExecutionEvent{}}
+	started := opts.Observer.Started
+	opts.Observer.Started = func(exec *vmmodels.Execution) {
+		report.Execution = exec
+		if started != nil {
+			started(exec)
+		}
+	}
+	interrupted := interruptOn(ctx, sessions, session.ID, timeout)
+	exec, runErr := core.Executions.ExecuteRunFile(ctx, vmcontrol.ExecuteRunFileInput{
+		SessionID: session.ID,
+		Path:      opts.Path,
+		Args:      opts.Args,
+		Env:       opts.Env,
+		Observer:  opts.Observer,
+	})
+	stoppedBy := interrupted()
+
+	switch {
+	case runErr == nil:
+		report.Execution = exec
+	case errors.Is(runErr, vmmodels.ErrOutputLimitExceeded) && report.Execution != nil:
+		// The execution finished before its output was checked.
+		if report.Execution, err = core.Executions.Get(ctx, report.Execution.ID); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, runErr
+	}
+	if report.Events, err = core.Executions.Events(ctx, report.Execution.ID, 0); err != nil {
+		return nil, err
+	}
+
+	// An interrupt that lands just after the file finished did not stop it.
+	if report.Execution.Status == string(vmmodels.ExecOK) {
+		stoppedBy = ""
+	}
+	switch {
+	case stoppedBy == OutcomeTimeout:
+		report.Outcome = OutcomeTimeout
+		report.Error = fmt.Sprintf("interrupted after %s", timeout)
+	case stoppedBy == OutcomeInterrupted:
+		report.Outcome = OutcomeInterrupted
+		report.Error = context.Cause(ctx).Error()
+	case runErr != nil:
+		report.Outcome = OutcomeLimit
+		report.Error = runErr.Error()
+	case report.Execution.Status == string(vmmodels.ExecTimeout):
+		report.Outcome = OutcomeTimeout
+	case report.Execution.Status != string(vmmodels.ExecOK):
+		report.Outcome = OutcomeException
+		report.Error = exceptionMessage(report.Execution.Error)
+	default:
+		report.Outcome = OutcomeOK
+	}
+	return report, nil
+}
+
+// wallLimit returns the template's wall_ms limit.
+func wallLimit(store vmcontrol.Store, templateID string) (time.Duration, error) {
+	settings, err := store.GetVMSettings(templateID)
+	if err != nil {
+		return 0, fmt.Errorf("load template settings: %w", err)
+	}
+	limits := vmmodels.LimitsConfig{}
+	if err := json.Unmarshal(settings.Limits, &limits); err != nil {
+		return 0, fmt.Errorf("decode template limits: %w", err)
+	}
+	return time.Duration(limits.WallMs) * time.Millisecond, nil
+}
+
+// interruptOn interrupts the session's runtime once timeout passes or ctx is
+// done. The returned function stops watching and reports which of the two
+// fired: OutcomeTimeout, OutcomeInterrupted, or "" for neither.
+func interruptOn(ctx context.Context, sessions *vmsession.SessionManager, sessionID string, timeout time.Duration) func() Outcome {
+	var (
+		timer   *time.Timer
+		expired <-chan time.Time
+	)
+	if timeout > 0 {
+		timer = time.NewTimer(timeout)
+		expired = timer.C
+	}
+	done := make(chan struct{})
+	fired := make(chan Outcome, 1)
+	go func() {
+		var reason Outcome
+		var cause error
+		select {
+		case <-done:
+			fired <- ""
+			return
+		case <-expired:
+			reason, cause = OutcomeTimeout, vmmodels.ErrExecTimeout
+		case <-ctx.Done():
+			reason, cause = OutcomeInterrupted, context.Cause(ctx)
+		}
+		if session, err := sessions.GetSession(sessionID); err == nil {
+			session.Runtime.Interrupt(cause)
+		}
+		fired <- reason
+	}()
+	return func() Outcome {
+		if timer != nil {
+			timer.Stop()
+		}
+		close(done)
+		return <-fired
+	}
+}
+
+func exceptionMessage(raw json.RawMessage) string {
+	exception := vmmodels.ExceptionPayload{}
+	if err := json.Unmarshal(raw, &exception); err != nil || exception.Message == "" {
+		return string(raw)
+	}
+	return exception.Message
+}
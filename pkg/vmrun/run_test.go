@@ -0,0 +1,120 @@
+package vmrun
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/vmexec"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+func TestRunReportsOutcomes(t *testing.T) {
+	worktree := t.TempDir()
+	files := map[string]string{
+		"ok.js":     `console.log("hello"); console.log("world"); 42`,
+		"throws.js": `console.log("before"); throw new Error("boom")`,
+		"spins.js":  `while (true) {}`,
+		"chatty.js": `for (let i = 0; i < 10; i++) console.log(i)`,
+	}
+	for name, source := range files {
+		if err := os.WriteFile(filepath.Join(worktree, name), []byte(source), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	limited := &vmmodels.TemplateSpec{
+		Name: "limited",
+		Settings: &vmmodels.TemplateSpecSettings{
+			Limits: &vmmodels.LimitsConfig{MaxEvents: 5},
+		},
+	}
+
+	cases := []struct {
+		path     string
+		template *vmmodels.TemplateSpec
+		timeout  time.Duration
+		outcome  Outcome
+		console  string
+		errorHas string
+	}{
+		{"ok.js", nil, 0, OutcomeOK, "hello,world", ""},
+		{"throws.js", nil, 0, OutcomeException, "before", "boom"},
+		{"spins.js", nil, 200 * time.Millisecond, OutcomeTimeout, "", "interrupted after 200ms"},
+		{"chatty.js", limited, 0, OutcomeLimit, "0,1,2,3,4,5,6,7,8,9", "output limit exceeded"},
+	}
+	for _, tc := range cases {
+		template := tc.template
+		if template == nil {
+			template = &vmmodels.TemplateSpec{Name: "default"}
+		}
+		var streamed []string
+		report, err := Run(context.Background(), Options{
+			Template: template,
+			Worktree: worktree,
+			Path:     tc.path,
+			Timeout:  tc.timeout,
+			DataDir:  t.TempDir(),
+			Observer: vmexec.Observer{Event: func(event *vmmodels.ExecutionEvent) {
+				payload := vmmodels.ConsolePayload{}
+				if event.Type == string(vmmodels.EventConsole) && json.Unmarshal(event.Payload, &payload) == nil {
+					streamed = append(streamed, payload.Text)
+				}
+			}},
+		})
+		if err != nil {
+			t.Fatalf("%s: run: %v", tc.path, err)
+		}
+		if report.Outcome != tc.outcome || report.Failed() != (tc.outcome != OutcomeOK) {
+			t.Fatalf("%s: expected outcome %s, got %+v", tc.path, tc.outcome, report)
+		}
+		if !strings.Contains(report.Error, tc.errorHas) {
+			t.Fatalf("%s: expected error containing %q, got %q", tc.path, tc.errorHas, report.Error)
+		}
+		if got := strings.Join(streamed, ","); got != tc.console {
+			t.Fatalf("%s: expected console %q, got %q", tc.path, tc.console, got)
+		}
+		if report.Execution == nil || report.Execution.Path != tc.path || len(report.Events) == 0 {
+			t.Fatalf("%s: expected the execution and its events in the report, got %+v", tc.path, report)
+		}
+	}
+
+	if _, err := Run(context.Background(), Options{
+		Template: &vmmodels.TemplateSpec{Name: "default"},
+		Worktree: worktree,
+		Path:     "../outside.js",
+		DataDir:  t.TempDir(),
+	}); err == nil {
+		t.Fatalf("expected paths outside the worktree to be rejected")
+	}
+}
+
+func TestRunStopsWhenContextIsCancelled(t *testing.T) {
+	worktree := t.TempDir()
+	if err := os.WriteFile(filepath.Join(worktree, "spins.js"), []byte(`while (true) {}`), 0o644); err != nil {
+		t.Fatalf("write spins.js: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	started := time.Now()
+	report, err := Run(ctx, Options{
+		Template: &vmmodels.TemplateSpec{Name: "default"},
+		Worktree: worktree,
+		Path:     "spins.js",
+		Timeout:  time.Minute,
+		DataDir:  t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if report.Outcome != OutcomeInterrupted || !report.Failed() || !strings.Contains(report.Error, "context canceled") {
+		t.Fatalf("expected an interrupted run, got %+v", report)
+	}
+	if elapsed := time.Since(started); elapsed > 10*time.Second {
+		t.Fatalf("expected cancellation to stop the file promptly, took %s", elapsed)
+	}
+}
@@ -0,0 +1,62 @@
+package vmsession
+
+import "sync"
+
+// EventLoop lets host functions run blocking work off the runtime goroutine
+// and hand the result back to it. goja runtimes are not goroutine-safe, so
+// completions are queued and only run from Drain, which the caller invokes
+// while holding the session's ExecutionLock.
+type EventLoop struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending int
+	ready   []func()
+}
+
+// NewEventLoop creates an empty event loop.
+func NewEventLoop() *EventLoop {
+	loop := &EventLoop{}
+	loop.cond = sync.NewCond(&loop.mu)
+	return loop
+}
+
+// Go runs work on its own goroutine. The function it returns, if any, is run
+// on the runtime goroutine during the next Drain.
+func (l *EventLoop) Go(work func() func()) {
+	l.mu.Lock()
+	l.pending++
+	l.mu.Unlock()
+
+	go func() {
+		complete := work()
+		l.mu.Lock()
+		l.pending--
+		if complete != nil {
+			l.ready = append(l.ready, complete)
+		}
+		l.cond.Broadcast()
+		l.mu.Unlock()
+	}()
+}
+
+// Drain runs completions until no work is pending. Completions may schedule
+// further work, which is awaited as well.
+func (l *EventLoop) Drain() {
+	for {
+		l.mu.Lock()
+		for len(l.ready) == 0 && l.pending > 0 {
+			l.cond.Wait()
+		}
+		if len(l.ready) == 0 {
+			l.mu.Unlock()
+			return
+		}
+		ready := l.ready
+		l.ready = nil
+		l.mu.Unlock()
+
+		for _, complete := range ready {
+			complete()
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package vmsession
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+// sessionLifecycle is the activity and expiry state of a live session. Limits
+// are only set once the session is ready, so sessions still running their
+// startup files never expire.
+type sessionLifecycle struct {
+	lastActivity time.Time
+	idleTimeout  time.Duration
+	maxLifetime  time.Duration
+}
+
+// LastActivity returns when the session was created or last finished an
+// execution.
+func (s *Session) LastActivity() time.Time {
+	s.lifecycleMu.Lock()
+	defer s.lifecycleMu.Unlock()
+	return s.lifecycle.lastActivity
+}
+
+// ExpiresAt returns when the session will be closed by its template's
+// idle_timeout or max_lifetime, and false when neither applies.
+func (s *Session) ExpiresAt() (time.Time, bool) {
+	s.lifecycleMu.Lock()
+	defer s.lifecycleMu.Unlock()
+	expiresAt, _ := s.expiryLocked()
+	return expiresAt, !expiresAt.IsZero()
+}
+
+// expiryLocked returns the earliest expiry and the close reason recorded
+// when it is reached.
+func (s *Session) expiryLocked() (time.Time, string) {
+	var (
+		expiresAt time.Time
+		reason    string
+	)
+	if s.lifecycle.idleTimeout > 0 {
+		expiresAt = s.lifecycle.lastActivity.Add(s.lifecycle.idleTimeout)
+		reason = fmt.Sprintf("expired: idle for %s", s.lifecycle.idleTimeout)
+	}
+	if s.lifecycle.maxLifetime > 0 {
+		if end := s.CreatedAt.Add(s.lifecycle.maxLifetime); expiresAt.IsZero() || end.Before(expiresAt) {
+			expiresAt = end
+			reason = fmt.Sprintf("expired: reached max lifetime of %s", s.lifecycle.maxLifetime)
+		}
+	}
+	return expiresAt, reason
+}
+
+func (s *Session) setLimits(idleTimeout, maxLifetime time.Duration) {
+	s.lifecycleMu.Lock()
+	s.lifecycle.idleTimeout = idleTimeout
+	s.lifecycle.maxLifetime = maxLifetime
+	s.lifecycleMu.Unlock()
+}
+
+func (s *Session) touch(at time.Time) {
+	s.lifecycleMu.Lock()
+	s.lifecycle.lastActivity = at
+	s.lifecycleMu.Unlock()
+}
+
+// RecordActivity marks the session active now, pushing back its idle expiry.
+// The executor calls it when an execution finishes, before releasing the
+// session.
+func (sm *SessionManager) RecordActivity(session *Session) {
+	now := time.Now()
+	session.touch(now)
+	if err := sm.store.TouchSession(session.ID, now); err != nil {
+		sm.logger.Warn().Err(err).Str("session_id", session.ID).Msg("failed to persist session activity")
+	}
+}
+
+// ExpireSessions closes every live session whose idle timeout or max lifetime
+// has passed at now, recording status expired and the reason in last_error.
+// Sessions with an execution in flight are left for a later pass. It returns
+// the IDs of the sessions it closed.
+func (sm *SessionManager) ExpireSessions(now time.Time) ([]string, error) {
+	var (
+		expired []string
+		errs    []error
+	)
+	for _, session := range sm.ListSessions() {
+		session.lifecycleMu.Lock()
+		expiresAt, reason := session.expiryLocked()
+		session.lifecycleMu.Unlock()
+		if expiresAt.IsZero() || now.Before(expiresAt) {
+			continue
+		}
+		if !session.ExecutionLock.TryLock() {
+			continue
+		}
+		err := sm.closeSession(session.ID, vmmodels.SessionExpired, reason)
+		session.ExecutionLock.Unlock()
+		if err != nil {
+			if !errors.Is(err, vmmodels.ErrSessionNotFound) {
+				errs = append(errs, fmt.Errorf("expire session %s: %w", session.ID, err))
+			}
+			continue
+		}
+
+		sm.logger.Info().
+			Str("session_id", session.ID).
+			Str("template_id", session.VMID).
+			Str("reason", reason).
+			Msg("expired session")
+		expired = append(expired, session.ID)
+	}
+	return expired, errors.Join(errs...)
+}
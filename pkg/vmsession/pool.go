@@ -0,0 +1,289 @@
+package vmsession
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+// warmRuntime is a pool runtime built up to, but not including, the
+// template's first worktree-dependent startup file. It is not a session yet:
+// it has no database row and is not listed by ListSessions.
+type warmRuntime struct {
+	session     *Session
+	revision    int
+	runtimeMeta json.RawMessage
+	startup     []*vmmodels.VMStartupFile // entries left to run once bound to a worktree
+}
+
+// templatePool holds the idle warm runtimes of one template.
+type templatePool struct {
+	config   vmmodels.PoolConfig
+	idle     []*warmRuntime
+	warming  int
+	claims   uint64
+	misses   uint64
+	failures uint64
+}
+
+// PoolStats reports the warm pool of one template.
+type PoolStats struct {
+	TemplateID string `json:"template_id"`
+	MinIdle    int    `json:"min_idle"`
+	Max        int    `json:"max"`
+	Idle       int    `json:"idle"`
+	Warming    int    `json:"warming"`
+	Claims     uint64 `json:"claims"`
+	Misses     uint64 `json:"misses"`
+	Failures   uint64 `json:"failures"`
+}
+
+// WarmPools brings every template pool to its configured size: stale and
+// surplus idle runtimes are discarded and missing ones are built. Pools of
+// deleted, inactive or unpooled templates are dropped.
+func (sm *SessionManager) WarmPools() error {
+	vms, err := sm.store.ListVMs()
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]struct{}, len(vms))
+	var errs []error
+	for _, vm := range vms {
+		known[vm.ID] = struct{}{}
+		if err := sm.fillPool(vm.ID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	sm.poolsMu.Lock()
+	for templateID := range sm.pools {
+		if _, ok := known[templateID]; !ok {
+			delete(sm.pools, templateID)
+		}
+	}
+	sm.poolsMu.Unlock()
+
+	return errors.Join(errs...)
+}
+
+// MaintainPools runs WarmPools now and then every interval until ctx is done.
+func (sm *SessionManager) MaintainPools(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := sm.WarmPools(); err != nil {
+			sm.logger.Warn().Err(err).Msg("failed to warm session pools")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PoolStats returns the state of every template pool, sorted by template ID.
+func (sm *SessionManager) PoolStats() []PoolStats {
+	sm.poolsMu.Lock()
+	defer sm.poolsMu.Unlock()
+
+	stats := make([]PoolStats, 0, len(sm.pools))
+	for templateID, pool := range sm.pools {
+		stats = append(stats, PoolStats{
+			TemplateID: templateID,
+			MinIdle:    pool.config.MinIdle,
+			Max:        pool.config.Max,
+			Idle:       len(pool.idle),
+			Warming:    pool.warming,
+			Claims:     pool.claims,
+			Misses:     pool.misses,
+			Failures:   pool.failures,
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].TemplateID < stats[j].TemplateID })
+	return stats
+}
+
+// claimWarm hands out an idle runtime built from the template's current
+// revision, or nil when the template is not pooled or its pool is empty. A
+// refill is started in the background either way.
+func (sm *SessionManager) claimWarm(vm *vmmodels.VM, settings *vmmodels.VMSettings) *warmRuntime {
+	config, err := vmmodels.ParsePoolConfig(settings.Pool)
+	if err != nil || !sm.poolable(vm, config) {
+		return nil
+	}
+
+	sm.poolsMu.Lock()
+	pool := sm.poolLocked(vm.ID)
+	pool.config = config
+	var claimed *warmRuntime
+	for len(pool.idle) > 0 && claimed == nil {
+		candidate := pool.idle[0]
+		pool.idle = pool.idle[1:]
+		if candidate.revision == vm.Revision {
+			claimed = candidate
+		}
+	}
+	if claimed != nil {
+		pool.claims++
+	} else {
+		pool.misses++
+	}
+	sm.poolsMu.Unlock()
+
+	go func() {
+		if err := sm.fillPool(vm.ID); err != nil {
+			sm.logger.Warn().Err(err).Str("template_id", vm.ID).Msg("failed to refill session pool")
+		}
+	}()
+	return claimed
+}
+
+// fillPool tops up one template's pool to min_idle, bounded by max.
+func (sm *SessionManager) fillPool(templateID string) error {
+	vm, err := sm.store.GetVM(templateID)
+	if err != nil {
+		if errors.Is(err, vmmodels.ErrVMNotFound) {
+			sm.dropPool(templateID)
+			return nil
+		}
+		return err
+	}
+	settings, err := sm.store.GetVMSettings(templateID)
+	if err != nil {
+		return err
+	}
+	config, err := vmmodels.ParsePoolConfig(settings.Pool)
+	if err != nil {
+		return err
+	}
+	if !vm.IsActive || !sm.poolable(vm, config) {
+		sm.dropPool(templateID)
+		return nil
+	}
+
+	live := 0
+	for _, session := range sm.ListSessions() {
+		if session.VMID == templateID {
+			live++
+		}
+	}
+
+	sm.poolsMu.Lock()
+	pool := sm.poolLocked(templateID)
+	pool.config = config
+	current := pool.idle[:0]
+	for _, warm := range pool.idle {
+		if warm.revision == vm.Revision && len(current) < config.MinIdle {
+			current = append(current, warm)
+		}
+	}
+	pool.idle = current
+	need := config.MinIdle - len(pool.idle) - pool.warming
+	if config.Max > 0 {
+		if room := config.Max - live - len(pool.idle) - pool.warming; room < need {
+			need = room
+		}
+	}
+	if need <= 0 {
+		sm.poolsMu.Unlock()
+		return nil
+	}
+	pool.warming += need
+	sm.poolsMu.Unlock()
+
+	startupFiles, err := sm.store.ListStartupFiles(templateID)
+	var errs []error
+	for i := 0; i < need; i++ {
+		var warm *warmRuntime
+		buildErr := err
+		if buildErr == nil {
+			warm, buildErr = sm.buildWarm(vm, settings, startupFiles)
+		}
+
+		sm.poolsMu.Lock()
+		pool.warming--
+		switch {
+		case buildErr != nil:
+			pool.failures++
+		case sm.pools[templateID] == pool:
+			pool.idle = append(pool.idle, warm)
+		}
+		sm.poolsMu.Unlock()
+
+		if buildErr != nil {
+			errs = append(errs, buildErr)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// buildWarm builds one pool runtime: everything CreateSession does except the
+// startup files from the first path entry on, which read the worktree.
+func (sm *SessionManager) buildWarm(vm *vmmodels.VM, settings *vmmodels.VMSettings, startupFiles []*vmmodels.VMStartupFile) (*warmRuntime, error) {
+	session := &Session{
+		ID:     uuid.New().String(),
+		VMID:   vm.ID,
+		Status: vmmodels.SessionStarting,
+		Loop:   NewEventLoop(),
+	}
+	runtimeMeta, err := sm.initRuntime(session, vm, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	split := 0
+	for split < len(startupFiles) && startupFiles[split].Kind == vmmodels.StartupKindInline {
+		split++
+	}
+	if err := sm.runStartupFiles(session, startupFiles[:split]); err != nil {
+		return nil, err
+	}
+
+	sm.logger.Info().
+		Str("template_id", vm.ID).
+		Int("revision", vm.Revision).
+		Int("deferred_startup_files", len(startupFiles)-split).
+		Msg("warmed pooled runtime")
+
+	return &warmRuntime{
+		session:     session,
+		revision:    vm.Revision,
+		runtimeMeta: runtimeMeta,
+		startup:     startupFiles[split:],
+	}, nil
+}
+
+// poolable reports whether vm's runtimes can be built ahead of a claim. Host
+// modules are handed the session's workspace and worktree when they are
+// installed, which a pooled runtime does not know yet, so templates exposing
+// one are always built cold.
+func (sm *SessionManager) poolable(vm *vmmodels.VM, config vmmodels.PoolConfig) bool {
+	return config.Enabled() && vm.Engine == "goja" && !sm.modules.ExposesHostModule(vm.ExposedModules)
+}
+
+func (sm *SessionManager) poolLocked(templateID string) *templatePool {
+	pool, ok := sm.pools[templateID]
+	if !ok {
+		pool = &templatePool{}
+		sm.pools[templateID] = pool
+	}
+	return pool
+}
+
+func (sm *SessionManager) dropPool(templateID string) {
+	sm.poolsMu.Lock()
+	delete(sm.pools, templateID)
+	sm.poolsMu.Unlock()
+}
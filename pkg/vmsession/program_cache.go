@@ -0,0 +1,107 @@
+package vmsession
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+
+	"github.com/dop251/goja"
+)
+
+// DefaultProgramCacheSize is the number of compiled programs a SessionManager
+// keeps unless configured otherwise.
+const DefaultProgramCacheSize = 256
+
+// ProgramCache shares compiled goja programs across sessions so libraries,
+// startup files and run-files are parsed once per daemon rather than once per
+// session. Programs are keyed by the SHA-256 of their source and evicted least
+// recently used first. A goja.Program is immutable and can run in any runtime.
+type ProgramCache struct {
+	mu        sync.Mutex
+	capacity  int
+	entries   map[[sha256.Size]byte]*list.Element
+	lru       *list.List // front is most recently used
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+type programCacheEntry struct {
+	key     [sha256.Size]byte
+	program *goja.Program
+}
+
+// ProgramCacheStats reports program cache usage.
+type ProgramCacheStats struct {
+	Entries   int    `json:"entries"`
+	Capacity  int    `json:"capacity"`
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+}
+
+// NewProgramCache creates a cache holding up to capacity programs. A
+// non-positive capacity disables caching; every Compile then parses.
+func NewProgramCache(capacity int) *ProgramCache {
+	return &ProgramCache{
+		capacity: capacity,
+		entries:  make(map[[sha256.Size]byte]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// Compile returns the compiled program for source, compiling it on a miss.
+// The program behaves like Runtime.RunString(source). Sources that fail to
+// compile are not cached.
+func (c *ProgramCache) Compile(source string) (*goja.Program, error) {
+	key := sha256.Sum256([]byte(source))
+
+	c.mu.Lock()
+	if element, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(element)
+		c.hits++
+		program := element.Value.(*programCacheEntry).program
+		c.mu.Unlock()
+		return program, nil
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	// Compile outside the lock; concurrent misses on the same source may both
+	// compile, and the later one simply wins.
+	program, err := goja.Compile("", source, false)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.capacity <= 0 {
+		return program, nil
+	}
+	if element, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(element)
+		return element.Value.(*programCacheEntry).program, nil
+	}
+	c.entries[key] = c.lru.PushFront(&programCacheEntry{key: key, program: program})
+	for c.lru.Len() > c.capacity {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*programCacheEntry).key)
+		c.evictions++
+	}
+	return program, nil
+}
+
+// Stats returns a snapshot of cache usage.
+func (c *ProgramCache) Stats() ProgramCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ProgramCacheStats{
+		Entries:   c.lru.Len(),
+		Capacity:  c.capacity,
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+}
@@ -0,0 +1,69 @@
+package vmsession
+
+import (
+	"testing"
+
+	"github.com/dop251/goja"
+)
+
+func TestProgramCacheHitsAndEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewProgramCache(2)
+
+	first, err := cache.Compile("1 + 1")
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	again, err := cache.Compile("1 + 1")
+	if err != nil {
+		t.Fatalf("compile again: %v", err)
+	}
+	if first != again {
+		t.Fatalf("expected identical source to reuse the compiled program")
+	}
+
+	if _, err := cache.Compile("2 + 2"); err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	// Touch "1 + 1" so "2 + 2" is the least recently used entry.
+	if _, err := cache.Compile("1 + 1"); err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if _, err := cache.Compile("3 + 3"); err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+
+	stats := cache.Stats()
+	if stats.Entries != 2 || stats.Hits != 2 || stats.Misses != 3 || stats.Evictions != 1 {
+		t.Fatalf("unexpected stats after eviction: %+v", stats)
+	}
+	if _, err := cache.Compile("1 + 1"); err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if cache.Stats().Hits != 3 {
+		t.Fatalf("expected recently used program to survive eviction: %+v", cache.Stats())
+	}
+
+	// The cached program runs in any runtime.
+	for i := 0; i < 2; i++ {
+		value, err := goja.New().RunProgram(first)
+		if err != nil {
+			t.Fatalf("run program: %v", err)
+		}
+		if value.ToInteger() != 2 {
+			t.Fatalf("expected 2, got %v", value)
+		}
+	}
+}
+
+func TestProgramCacheDoesNotCacheSyntaxErrors(t *testing.T) {
+	cache := NewProgramCache(4)
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.Compile("var x = {"); err == nil {
+			t.Fatalf("expected syntax error")
+		}
+	}
+	if stats := cache.Stats(); stats.Entries != 0 || stats.Hits != 0 || stats.Misses != 2 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
@@ -0,0 +1,114 @@
+package vmsession
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+// Quotas bounds the live sessions a SessionManager holds. Sessions that
+// crashed during startup and warm pool runtimes do not count. Zero leaves a
+// limit off.
+type Quotas struct {
+	MaxSessions     int // across the daemon
+	MaxPerTemplate  int // per template
+	MaxPerWorkspace int // per workspace_id
+}
+
+// QuotaUsage reports the session quotas and the live sessions counted
+// against them.
+type QuotaUsage struct {
+	MaxSessions     int            `json:"max_sessions"`
+	MaxPerTemplate  int            `json:"max_sessions_per_template"`
+	MaxPerWorkspace int            `json:"max_sessions_per_workspace"`
+	Sessions        int            `json:"sessions"`
+	ByTemplate      map[string]int `json:"by_template"`
+	ByWorkspace     map[string]int `json:"by_workspace"`
+}
+
+// WithQuotas limits how many sessions may be live at once.
+func WithQuotas(quotas Quotas) Option {
+	return func(sm *SessionManager) {
+		sm.quotas.limits = quotas
+	}
+}
+
+// quotaTracker counts live sessions per template and workspace. A slot is
+// reserved before a session is built, so concurrent creates cannot overshoot.
+type quotaTracker struct {
+	mu          sync.Mutex
+	limits      Quotas
+	total       int
+	byTemplate  map[string]int
+	byWorkspace map[string]int
+}
+
+// reserve takes a session slot for templateID and workspaceID. The returned
+// release gives it back and may be called more than once.
+func (t *quotaTracker) reserve(templateID, workspaceID string) (func(), error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.byTemplate == nil {
+		t.byTemplate = make(map[string]int)
+		t.byWorkspace = make(map[string]int)
+	}
+	if t.limits.MaxSessions > 0 && t.total >= t.limits.MaxSessions {
+		return nil, fmt.Errorf("%w: %d live sessions", vmmodels.ErrSessionQuotaExceeded, t.limits.MaxSessions)
+	}
+	if t.limits.MaxPerTemplate > 0 && t.byTemplate[templateID] >= t.limits.MaxPerTemplate {
+		return nil, fmt.Errorf("%w: %d live sessions for template %s", vmmodels.ErrTemplateSessionQuotaExceeded, t.limits.MaxPerTemplate, templateID)
+	}
+	if t.limits.MaxPerWorkspace > 0 && t.byWorkspace[workspaceID] >= t.limits.MaxPerWorkspace {
+		return nil, fmt.Errorf("%w: %d live sessions for workspace %s", vmmodels.ErrWorkspaceSessionQuotaExceeded, t.limits.MaxPerWorkspace, workspaceID)
+	}
+	t.total++
+	t.byTemplate[templateID]++
+	t.byWorkspace[workspaceID]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.total--
+			decrement(t.byTemplate, templateID)
+			decrement(t.byWorkspace, workspaceID)
+		})
+	}, nil
+}
+
+func (t *quotaTracker) usage() QuotaUsage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := QuotaUsage{
+		MaxSessions:     t.limits.MaxSessions,
+		MaxPerTemplate:  t.limits.MaxPerTemplate,
+		MaxPerWorkspace: t.limits.MaxPerWorkspace,
+		Sessions:        t.total,
+		ByTemplate:      make(map[string]int, len(t.byTemplate)),
+		ByWorkspace:     make(map[string]int, len(t.byWorkspace)),
+	}
+	for templateID, n := range t.byTemplate {
+		out.ByTemplate[templateID] = n
+	}
+	for workspaceID, n := range t.byWorkspace {
+		out.ByWorkspace[workspaceID] = n
+	}
+	return out
+}
+
+func decrement(counts map[string]int, key string) {
+	if counts[key] <= 1 {
+		delete(counts, key)
+		return
+	}
+	counts[key]--
+}
+
+// QuotaUsage returns the session quotas and current live session counts.
+func (sm *SessionManager) QuotaUsage() QuotaUsage {
+	return sm.quotas.usage()
+}
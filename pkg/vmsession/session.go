@@ -5,29 +5,83 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/dop251/goja"
 	"github.com/google/uuid"
 
+	"github.com/go-go-golems/vm-system/pkg/libloader"
 	"github.com/go-go-golems/vm-system/pkg/vmmodels"
 	"github.com/go-go-golems/vm-system/pkg/vmmodules"
+	"github.com/go-go-golems/vm-system/pkg/vmnet"
 	"github.com/go-go-golems/vm-system/pkg/vmpath"
-	"github.com/go-go-golems/vm-system/pkg/vmstore"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
+// sessionStore is the part of the store a SessionManager reads templates
+// from and records sessions in.
+type sessionStore interface {
+	GetVM(id string) (*vmmodels.VM, error)
+	ListVMs() ([]*vmmodels.VM, error)
+	GetVMSettings(vmID string) (*vmmodels.VMSettings, error)
+	ListCapabilities(vmID string) ([]*vmmodels.VMCapability, error)
+	ListStartupFiles(vmID string) ([]*vmmodels.VMStartupFile, error)
+	ListLibraries() ([]*vmmodels.Library, error)
+	CreateSession(session *vmmodels.VMSession) error
+	GetSession(id string) (*vmmodels.VMSession, error)
+	UpdateSession(session *vmmodels.VMSession) error
+	TouchSession(id string, at time.Time) error
+}
+
 // SessionManager manages VM sessions
 type SessionManager struct {
-	store      *vmstore.VMStore
+	store      sessionStore
+	modules    *vmmodules.Registry
+	libraryDir string
+	programs   *ProgramCache
 	sessions   map[string]*Session
 	sessionsMu sync.RWMutex
+	pools      map[string]*templatePool
+	poolsMu    sync.Mutex
+	quotas     quotaTracker
 	logger     zerolog.Logger
 }
 
+// Option customizes a SessionManager.
+type Option func(*SessionManager)
+
+// WithModuleRegistry resolves template modules against registry instead of
+// the default go-go-goja registry.
+func WithModuleRegistry(registry *vmmodules.Registry) Option {
+	return func(sm *SessionManager) {
+		if registry != nil {
+			sm.modules = registry
+		}
+	}
+}
+
+// WithLibraryDir loads template libraries from the library cache at dir
+// instead of libloader.DefaultCacheDir.
+func WithLibraryDir(dir string) Option {
+	return func(sm *SessionManager) {
+		if dir != "" {
+			sm.libraryDir = dir
+		}
+	}
+}
+
+// WithProgramCache shares cache between this manager and other components
+// that compile session code, such as the executor.
+func WithProgramCache(cache *ProgramCache) Option {
+	return func(sm *SessionManager) {
+		if cache != nil {
+			sm.programs = cache
+		}
+	}
+}
+
 // Session represents an active VM session
 type Session struct {
 	ID            string
@@ -37,60 +91,138 @@ type Session struct {
 	WorktreePath  string
 	Status        vmmodels.SessionStatus
 	Runtime       *goja.Runtime
+	Loop          *EventLoop
 	ExecutionLock sync.Mutex
 	CreatedAt     time.Time
 	LastError     string
+
+	eventSinkMu sync.Mutex
+	eventSink   func(vmmodels.EventType, interface{})
+
+	lifecycleMu sync.Mutex
+	lifecycle   sessionLifecycle
+
+	releaseQuota func()
+}
+
+// SetEventSink routes host-generated events (such as fetch summaries) to the
+// execution currently running in the session. Pass nil to detach.
+func (s *Session) SetEventSink(sink func(vmmodels.EventType, interface{})) {
+	s.eventSinkMu.Lock()
+	s.eventSink = sink
+	s.eventSinkMu.Unlock()
+}
+
+func (s *Session) emitEvent(eventType vmmodels.EventType, payload interface{}) bool {
+	s.eventSinkMu.Lock()
+	sink := s.eventSink
+	s.eventSinkMu.Unlock()
+	if sink == nil {
+		return false
+	}
+	sink(eventType, payload)
+	return true
 }
 
 // NewSessionManager creates a new SessionManager
-func NewSessionManager(store *vmstore.VMStore) *SessionManager {
-	return &SessionManager{
-		store:    store,
-		sessions: make(map[string]*Session),
-		logger:   log.With().Str("component", "session_manager").Logger(),
+func NewSessionManager(store sessionStore, opts ...Option) *SessionManager {
+	sm := &SessionManager{
+		store:      store,
+		modules:    vmmodules.DefaultRegistry(),
+		libraryDir: libloader.DefaultCacheDir,
+		programs:   NewProgramCache(DefaultProgramCacheSize),
+		sessions:   make(map[string]*Session),
+		pools:      make(map[string]*templatePool),
+		logger:     log.With().Str("component", "session_manager").Logger(),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(sm)
+		}
 	}
+	return sm
+}
+
+// Programs returns the compiled program cache shared by this manager's sessions.
+func (sm *SessionManager) Programs() *ProgramCache {
+	return sm.programs
 }
 
-// CreateSession creates a new VM session
+// CreateSession creates a new VM session. When the template has a warm pool,
+// a pre-built runtime is claimed and bound to the workspace and worktree;
+// otherwise the runtime is built from scratch.
 func (sm *SessionManager) CreateSession(vmID, workspaceID, baseCommitOID, worktreePath string) (*Session, error) {
 	// Verify VM exists
 	vm, err := sm.store.GetVM(vmID)
 	if err != nil {
 		return nil, fmt.Errorf("VM not found: %w", err)
 	}
+	if !vm.IsActive {
+		return nil, fmt.Errorf("%w: %s", vmmodels.ErrTemplateInactive, vm.Name)
+	}
 
 	// Get VM settings
 	settings, err := sm.store.GetVMSettings(vmID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get VM settings: %w", err)
 	}
+	lifecycle, err := vmmodels.ParseLifecycleConfig(settings.Lifecycle)
+	if err != nil {
+		return nil, err
+	}
+	idleTimeout, maxLifetime, err := lifecycle.Durations()
+	if err != nil {
+		return nil, err
+	}
 
 	// Verify worktree path exists
 	if _, err := os.Stat(worktreePath); err != nil {
 		return nil, fmt.Errorf("worktree path does not exist: %w", err)
 	}
 
+	releaseQuota, err := sm.quotas.reserve(vmID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	ready := false
+	defer func() {
+		// Sessions that never became ready don't count against quotas.
+		if !ready {
+			releaseQuota()
+		}
+	}()
+
+	warm := sm.claimWarm(vm, settings)
+
 	// Create session record
-	sessionID := uuid.New().String()
 	session := &Session{
-		ID:            sessionID,
-		VMID:          vmID,
-		WorkspaceID:   workspaceID,
-		BaseCommitOID: baseCommitOID,
-		WorktreePath:  worktreePath,
-		Status:        vmmodels.SessionStarting,
-		CreatedAt:     time.Now(),
+		ID:     uuid.New().String(),
+		VMID:   vmID,
+		Status: vmmodels.SessionStarting,
+		Loop:   NewEventLoop(),
+	}
+	if warm != nil {
+		session = warm.session
 	}
+	session.WorkspaceID = workspaceID
+	session.BaseCommitOID = baseCommitOID
+	session.WorktreePath = worktreePath
+	session.CreatedAt = time.Now()
+	session.touch(session.CreatedAt)
+	session.releaseQuota = releaseQuota
+	sessionID := session.ID
 
 	// Store session in database
 	dbSession := &vmmodels.VMSession{
-		ID:            session.ID,
-		VMID:          session.VMID,
-		WorkspaceID:   session.WorkspaceID,
-		BaseCommitOID: session.BaseCommitOID,
-		WorktreePath:  session.WorktreePath,
-		Status:        string(session.Status),
-		CreatedAt:     session.CreatedAt,
+		ID:               session.ID,
+		VMID:             session.VMID,
+		WorkspaceID:      session.WorkspaceID,
+		BaseCommitOID:    session.BaseCommitOID,
+		WorktreePath:     session.WorktreePath,
+		TemplateRevision: vm.Revision,
+		Status:           string(session.Status),
+		CreatedAt:        session.CreatedAt,
+		LastActivityAt:   session.CreatedAt,
 	}
 
 	if err := sm.store.CreateSession(dbSession); err != nil {
@@ -113,37 +245,24 @@ func (sm *SessionManager) CreateSession(vmID, workspaceID, baseCommitOID, worktr
 		return nil, fmt.Errorf("%s: %w", prefix, cause)
 	}
 
-	// Initialize goja runtime
-	if vm.Engine == "goja" {
-		runtime := goja.New()
-		session.Runtime = runtime
-
-		// Parse runtime settings
-		var runtimeConfig vmmodels.RuntimeConfig
-		if err := json.Unmarshal(settings.Runtime, &runtimeConfig); err != nil {
-			return failSessionCreation("failed to parse runtime config", err)
-		}
-
-		if err := vmmodules.EnableConfiguredModules(runtime, vm.ExposedModules); err != nil {
-			return failSessionCreation("failed to enable configured modules", err)
-		}
-
-		// Set up console if enabled
-		if runtimeConfig.Console {
-			console := map[string]interface{}{
-				"log": func(args ...interface{}) {
-					sm.logger.Info().
-						Str("session_id", session.ID).
-						Interface("args", args).
-						Msg("startup console.log")
-				},
+	var startupFiles []*vmmodels.VMStartupFile
+	if warm != nil {
+		dbSession.RuntimeMeta = warm.runtimeMeta
+		startupFiles = warm.startup
+	} else {
+		if vm.Engine == "goja" {
+			runtimeMeta, err := sm.initRuntime(session, vm, settings)
+			if err != nil {
+				var stageErr *runtimeInitError
+				if errors.As(err, &stageErr) {
+					return failSessionCreation(stageErr.stage, stageErr.err)
+				}
+				return failSessionCreation("failed to initialize runtime", err)
 			}
-			runtime.Set("console", console)
+			dbSession.RuntimeMeta = runtimeMeta
 		}
-
-		// Load configured libraries into runtime
-		if err := sm.loadLibraries(runtime, vm, session.ID); err != nil {
-			return failSessionCreation("failed to load libraries", err)
+		if startupFiles, err = sm.store.ListStartupFiles(session.VMID); err != nil {
+			return failSessionCreation("startup failed", err)
 		}
 	}
 
@@ -153,7 +272,7 @@ func (sm *SessionManager) CreateSession(vmID, workspaceID, baseCommitOID, worktr
 	sm.sessionsMu.Unlock()
 
 	// Run startup files
-	if err := sm.runStartupFiles(session); err != nil {
+	if err := sm.runStartupFiles(session, startupFiles); err != nil {
 		return failSessionCreation("startup failed", err)
 	}
 
@@ -163,10 +282,69 @@ func (sm *SessionManager) CreateSession(vmID, workspaceID, baseCommitOID, worktr
 	if err := sm.store.UpdateSession(dbSession); err != nil {
 		return nil, fmt.Errorf("failed to update session status: %w", err)
 	}
+	session.setLimits(idleTimeout, maxLifetime)
+	ready = true
 
 	return session, nil
 }
 
+// runtimeInitError records which runtime setup stage failed, so the
+// session's last_error names it.
+type runtimeInitError struct {
+	stage string
+	err   error
+}
+
+func (e *runtimeInitError) Error() string { return fmt.Sprintf("%s: %v", e.stage, e.err) }
+
+func (e *runtimeInitError) Unwrap() error { return e.err }
+
+// initRuntime builds the goja runtime for session: modules, capabilities,
+// console and libraries. It returns the session runtime_meta to persist.
+func (sm *SessionManager) initRuntime(session *Session, vm *vmmodels.VM, settings *vmmodels.VMSettings) (json.RawMessage, error) {
+	runtime := goja.New()
+	session.Runtime = runtime
+
+	// Parse runtime settings
+	var runtimeConfig vmmodels.RuntimeConfig
+	if err := json.Unmarshal(settings.Runtime, &runtimeConfig); err != nil {
+		return nil, &runtimeInitError{"failed to parse runtime config", err}
+	}
+
+	if err := sm.modules.Enable(runtime, vm.ExposedModules, vmmodules.SessionInfo{
+		SessionID:    session.ID,
+		TemplateID:   session.VMID,
+		WorkspaceID:  session.WorkspaceID,
+		WorktreePath: session.WorktreePath,
+	}); err != nil {
+		return nil, &runtimeInitError{"failed to enable configured modules", err}
+	}
+
+	if err := sm.installCapabilities(session); err != nil {
+		return nil, &runtimeInitError{"failed to install capabilities", err}
+	}
+
+	// Set up console if enabled
+	if runtimeConfig.Console {
+		console := map[string]interface{}{
+			"log": func(args ...interface{}) {
+				sm.logger.Info().
+					Str("session_id", session.ID).
+					Interface("args", args).
+					Msg("startup console.log")
+			},
+		}
+		runtime.Set("console", console)
+	}
+
+	// Load configured libraries into runtime
+	loaded, err := sm.loadLibraries(runtime, vm, session.ID)
+	if err != nil {
+		return nil, &runtimeInitError{"failed to load libraries", err}
+	}
+	return vmmodels.MarshalJSONWithFallback(vmmodels.SessionRuntimeMeta{Libraries: loaded}, nil), nil
+}
+
 // GetSession retrieves an active session
 func (sm *SessionManager) GetSession(sessionID string) (*Session, error) {
 	sm.sessionsMu.RLock()
@@ -182,8 +360,14 @@ func (sm *SessionManager) GetSession(sessionID string) (*Session, error) {
 
 // CloseSession closes a session and releases resources
 func (sm *SessionManager) CloseSession(sessionID string) error {
+	return sm.closeSession(sessionID, vmmodels.SessionClosed, "")
+}
+
+// closeSession drops the session runtime and persists its final status. A
+// non-empty reason is recorded as the session's last error.
+func (sm *SessionManager) closeSession(sessionID string, status vmmodels.SessionStatus, reason string) error {
 	sm.sessionsMu.Lock()
-	_, ok := sm.sessions[sessionID]
+	session, ok := sm.sessions[sessionID]
 	if ok {
 		delete(sm.sessions, sessionID)
 	}
@@ -192,6 +376,9 @@ func (sm *SessionManager) CloseSession(sessionID string) error {
 	if !ok {
 		return vmmodels.ErrSessionNotFound
 	}
+	if session.releaseQuota != nil {
+		session.releaseQuota()
+	}
 
 	// Update database
 	dbSession, err := sm.store.GetSession(sessionID)
@@ -200,62 +387,45 @@ func (sm *SessionManager) CloseSession(sessionID string) error {
 	}
 
 	now := time.Now()
-	dbSession.Status = string(vmmodels.SessionClosed)
+	dbSession.Status = string(status)
 	dbSession.ClosedAt = &now
+	if reason != "" {
+		dbSession.LastError = reason
+	}
 
 	return sm.store.UpdateSession(dbSession)
 }
 
-// runStartupFiles executes startup files for a session
-func (sm *SessionManager) runStartupFiles(session *Session) error {
-	root, err := vmpath.NewWorktreeRoot(session.WorktreePath)
-	if err != nil {
-		return fmt.Errorf("invalid worktree root: %w", err)
-	}
-
-	// Get startup files
-	startupFiles, err := sm.store.ListStartupFiles(session.VMID)
-	if err != nil {
-		return err
-	}
+// runStartupFiles executes startup files for a session, in order. The
+// worktree root is only resolved when a path entry needs it, so warm pool
+// runtimes can run inline entries before they are bound to a worktree.
+func (sm *SessionManager) runStartupFiles(session *Session, startupFiles []*vmmodels.VMStartupFile) error {
+	var root *vmpath.WorktreeRoot
 
 	// Execute each startup file
 	for _, file := range startupFiles {
-		relPath, err := vmpath.ParseRelWorktreePath(file.Path)
-		if err != nil {
-			switch {
-			case errors.Is(err, vmpath.ErrAbsoluteRelativePath), errors.Is(err, vmpath.ErrTraversalRelativePath), errors.Is(err, vmpath.ErrEmptyRelativePath):
-				return fmt.Errorf("%w: startup file path %q", vmmodels.ErrPathTraversal, file.Path)
-			default:
-				return fmt.Errorf("invalid startup file path %q: %w", file.Path, err)
-			}
-		}
-
-		resolvedPath, err := root.Resolve(relPath)
-		if err != nil {
-			if errors.Is(err, vmpath.ErrPathEscapesRoot) {
-				return fmt.Errorf("%w: startup file path %q", vmmodels.ErrPathTraversal, file.Path)
+		if root == nil && file.Kind != vmmodels.StartupKindInline {
+			resolved, err := vmpath.NewWorktreeRoot(session.WorktreePath)
+			if err != nil {
+				return fmt.Errorf("invalid worktree root: %w", err)
 			}
-			return fmt.Errorf("resolve startup path %q: %w", file.Path, err)
-		}
-		filePath := resolvedPath.Absolute()
-
-		// Check if file exists
-		if _, err := os.Stat(filePath); err != nil {
-			return fmt.Errorf("startup file not found: %s: %w", file.Path, err)
+			root = &resolved
 		}
-
-		// Read file content
-		content, err := os.ReadFile(filePath)
+		content, err := startupSource(root, file)
 		if err != nil {
-			return fmt.Errorf("failed to read startup file %s: %w", file.Path, err)
+			return err
 		}
 
 		switch file.Mode {
 		case "", "eval":
-			if _, err := session.Runtime.RunString(string(content)); err != nil {
+			program, err := sm.programs.Compile(content)
+			if err == nil {
+				_, err = session.Runtime.RunProgram(program)
+			}
+			if err != nil {
 				return fmt.Errorf("failed to execute startup file %s: %w", file.Path, err)
 			}
+			session.Loop.Drain()
 		default:
 			return fmt.Errorf("%w: %s", vmmodels.ErrStartupModeUnsupported, file.Mode)
 		}
@@ -264,6 +434,77 @@ func (sm *SessionManager) runStartupFiles(session *Session) error {
 	return nil
 }
 
+// startupSource returns the script of a startup entry: the inline source, or
+// the contents of the referenced worktree file.
+func startupSource(root *vmpath.WorktreeRoot, file *vmmodels.VMStartupFile) (string, error) {
+	if file.Kind == vmmodels.StartupKindInline {
+		return file.Source, nil
+	}
+
+	relPath, err := vmpath.ParseRelWorktreePath(file.Path)
+	if err != nil {
+		switch {
+		case errors.Is(err, vmpath.ErrAbsoluteRelativePath), errors.Is(err, vmpath.ErrTraversalRelativePath), errors.Is(err, vmpath.ErrEmptyRelativePath):
+			return "", fmt.Errorf("%w: startup file path %q", vmmodels.ErrPathTraversal, file.Path)
+		default:
+			return "", fmt.Errorf("invalid startup file path %q: %w", file.Path, err)
+		}
+	}
+
+	resolvedPath, err := root.Resolve(relPath)
+	if err != nil {
+		if errors.Is(err, vmpath.ErrPathEscapesRoot) {
+			return "", fmt.Errorf("%w: startup file path %q", vmmodels.ErrPathTraversal, file.Path)
+		}
+		return "", fmt.Errorf("resolve startup path %q: %w", file.Path, err)
+	}
+	filePath := resolvedPath.Absolute()
+
+	// Check if file exists
+	if _, err := os.Stat(filePath); err != nil {
+		return "", fmt.Errorf("startup file not found: %s: %w", file.Path, err)
+	}
+
+	// Read file content
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read startup file %s: %w", file.Path, err)
+	}
+	return string(content), nil
+}
+
+// installCapabilities installs the runtime globals backing enabled template
+// capabilities.
+func (sm *SessionManager) installCapabilities(session *Session) error {
+	capabilities, err := sm.store.ListCapabilities(session.VMID)
+	if err != nil {
+		return err
+	}
+
+	for _, capability := range capabilities {
+		if !capability.Enabled || capability.Kind != vmmodels.CapabilityKindNet || capability.Name != vmmodels.CapabilityFetch {
+			continue
+		}
+		cfg, err := vmnet.ParseConfig(capability.Config)
+		if err != nil {
+			return err
+		}
+		fetch := vmnet.NewFetch(cfg, session.Loop, func(summary vmmodels.NetPayload) {
+			if !session.emitEvent(vmmodels.EventNet, summary) {
+				sm.logger.Info().
+					Str("session_id", session.ID).
+					Interface("request", summary).
+					Msg("startup fetch")
+			}
+		})
+		if err := fetch.Install(session.Runtime); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // ListSessions lists all active sessions
 func (sm *SessionManager) ListSessions() []*Session {
 	sm.sessionsMu.RLock()
@@ -277,41 +518,59 @@ func (sm *SessionManager) ListSessions() []*Session {
 	return sessions
 }
 
-// loadLibraries loads configured JavaScript libraries into the goja runtime
-func (sm *SessionManager) loadLibraries(runtime *goja.Runtime, vm *vmmodels.VM, sessionID string) error {
+// loadLibraries loads configured JavaScript libraries into the goja runtime,
+// dependencies first, and returns the verified code hashes it loaded.
+func (sm *SessionManager) loadLibraries(runtime *goja.Runtime, vm *vmmodels.VM, sessionID string) ([]vmmodels.LoadedLibrary, error) {
 	if len(vm.Libraries) == 0 {
-		return nil // No libraries to load
+		return nil, nil // No libraries to load
 	}
 
-	// Get library cache directory
-	cacheDir := filepath.Join(".vm-cache", "libraries")
-
-	// Load each configured library
-	for _, libName := range vm.Libraries {
-		libPath := filepath.Join(cacheDir, libName+".js")
+	registered, err := sm.store.ListLibraries()
+	if err != nil {
+		return nil, err
+	}
+	catalog := vmmodels.BuiltinLibraries()
+	for _, lib := range registered {
+		catalog = append(catalog, *lib)
+	}
+	order, err := vmmodels.LibraryLoadOrder(vm.Libraries, catalog)
+	if err != nil {
+		return nil, err
+	}
 
-		// Check if library file exists
-		if _, err := os.Stat(libPath); err != nil {
-			return fmt.Errorf("library %s not found in cache (run 'vm-system libs download' first): %w", libName, err)
-		}
+	cache, err := libloader.NewLibraryCache(sm.libraryDir)
+	if err != nil {
+		return nil, err
+	}
 
-		// Read library content
-		content, err := os.ReadFile(libPath)
+	// Load each library in dependency order
+	loaded := make([]vmmodels.LoadedLibrary, 0, len(order))
+	for _, lib := range order {
+		content, sum, err := cache.Load(lib)
 		if err != nil {
-			return fmt.Errorf("failed to read library %s: %w", libName, err)
+			if errors.Is(err, libloader.ErrChecksumMismatch) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("library %s not found in cache (run 'vm-system libs download' first): %w", lib.Ref, err)
 		}
 
 		// Execute library code in runtime
-		if _, err := runtime.RunString(string(content)); err != nil {
-			return fmt.Errorf("failed to load library %s: %w", libName, err)
+		program, err := sm.programs.Compile(string(content))
+		if err == nil {
+			_, err = runtime.RunProgram(program)
 		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load library %s: %w", lib.Ref, err)
+		}
+		loaded = append(loaded, vmmodels.LoadedLibrary{Ref: lib.Ref, SHA256: sum})
 
 		sm.logger.Info().
 			Str("session_id", sessionID).
 			Str("template_id", vm.ID).
-			Str("library", libName).
+			Str("library", lib.Ref).
+			Str("sha256", sum).
 			Msg("loaded library into runtime session")
 	}
 
-	return nil
+	return loaded, nil
 }
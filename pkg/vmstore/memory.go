@@ -0,0 +1,110 @@
+package vmstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+// Constraint violations reported by MemoryStore, worded like SQLite's.
+var (
+	errUniqueConstraint     = errors.New("UNIQUE constraint failed")
+	errForeignKeyConstraint = errors.New("FOREIGN KEY constraint failed")
+)
+
+// MemoryStore keeps everything VMStore persists in process memory. It
+// follows VMStore's semantics: the same not-found errors, uniqueness and
+// foreign key checks, cascades, orderings, and second-precision timestamps.
+// Records are copied in and out, so callers never share them with the store.
+// Nothing survives the process.
+type MemoryStore struct {
+	mu sync.RWMutex
+	// seq orders records by insertion, breaking ties between equal
+	// timestamps newest first.
+	seq int64
+
+	vms          map[string]*memoryVM
+	settings     map[string]*vmmodels.VMSettings
+	capabilities []*vmmodels.VMCapability
+	startupFiles []*vmmodels.VMStartupFile
+	revisions    map[string][]*memoryRevision
+	libraries    map[string]*vmmodels.Library
+	workspaces   map[string]*vmmodels.Workspace
+	tokens       []*memoryToken
+	audit        []*vmmodels.AuditEvent
+	sessions     map[string]*memorySession
+	executions   map[string]*memoryExecution
+	events       map[string][]*vmmodels.ExecutionEvent
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		vms:        map[string]*memoryVM{},
+		settings:   map[string]*vmmodels.VMSettings{},
+		revisions:  map[string][]*memoryRevision{},
+		libraries:  map[string]*vmmodels.Library{},
+		workspaces: map[string]*vmmodels.Workspace{},
+		sessions:   map[string]*memorySession{},
+		executions: map[string]*memoryExecution{},
+		events:     map[string][]*vmmodels.ExecutionEvent{},
+	}
+}
+
+// Close is a no-op; it lets MemoryStore stand in for VMStore.
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+func (s *MemoryStore) nextSeq() int64 {
+	s.seq++
+	return s.seq
+}
+
+func uniqueViolation(column string) error {
+	return fmt.Errorf("%w: %s", errUniqueConstraint, column)
+}
+
+// newestFirst sorts records by time descending, newest insertion first on
+// equal times, as VMStore's ORDER BY ... DESC queries return them.
+func newestFirst[T any](records []T, at func(T) time.Time, seq func(T) int64) {
+	sort.SliceStable(records, func(i, j int) bool {
+		ti, tj := at(records[i]).Unix(), at(records[j]).Unix()
+		if ti != tj {
+			return ti > tj
+		}
+		return seq(records[i]) > seq(records[j])
+	})
+}
+
+// seconds drops sub-second precision, which VMStore does not keep.
+func seconds(t time.Time) time.Time {
+	return time.Unix(t.Unix(), 0)
+}
+
+func secondsPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	out := seconds(*t)
+	return &out
+}
+
+func cloneRaw(raw json.RawMessage) json.RawMessage {
+	if raw == nil {
+		return nil
+	}
+	return append(json.RawMessage{}, raw...)
+}
+
+func cloneStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	return append([]string{}, values...)
+}
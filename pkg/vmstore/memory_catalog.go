@@ -0,0 +1,280 @@
+package vmstore
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+type memoryToken struct {
+	token vmmodels.APIToken
+	seq   int64
+}
+
+// CreateLibrary registers a library in the catalog.
+func (s *MemoryStore) CreateLibrary(lib *vmmodels.Library) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.libraries[lib.Ref]; ok {
+		return uniqueViolation("library.ref")
+	}
+	createdAt := time.Now()
+	if lib.CreatedAt != nil {
+		createdAt = *lib.CreatedAt
+	}
+	stored := *lib
+	stored.Builtin = false
+	stored.CreatedAt = secondsPtr(&createdAt)
+	stored.Config = cloneConfig(lib.Config)
+	stored.Dependencies = cloneStrings(lib.Dependencies)
+	s.libraries[lib.Ref] = &stored
+	return nil
+}
+
+// GetLibrary retrieves a registered library by ref.
+func (s *MemoryStore) GetLibrary(ref string) (*vmmodels.Library, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stored, ok := s.libraries[ref]
+	if !ok {
+		return nil, vmmodels.ErrLibraryNotFound
+	}
+	return copyLibrary(stored), nil
+}
+
+// ListLibraries lists registered libraries ordered by ref.
+func (s *MemoryStore) ListLibraries() ([]*vmmodels.Library, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var libraries []*vmmodels.Library
+	for _, stored := range s.libraries {
+		libraries = append(libraries, copyLibrary(stored))
+	}
+	sort.Slice(libraries, func(i, j int) bool { return libraries[i].Ref < libraries[j].Ref })
+	return libraries, nil
+}
+
+// DeleteLibrary removes a registered library.
+func (s *MemoryStore) DeleteLibrary(ref string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.libraries[ref]; !ok {
+		return vmmodels.ErrLibraryNotFound
+	}
+	delete(s.libraries, ref)
+	return nil
+}
+
+// CreateWorkspace stores a new workspace.
+func (s *MemoryStore) CreateWorkspace(workspace *vmmodels.Workspace) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.workspaces[workspace.ID]; ok {
+		return uniqueViolation("workspace.id")
+	}
+	s.workspaces[workspace.ID] = &vmmodels.Workspace{ID: workspace.ID, Name: workspace.Name, CreatedAt: seconds(workspace.CreatedAt)}
+	return nil
+}
+
+// EnsureWorkspace registers a workspace named after its ID unless it exists.
+func (s *MemoryStore) EnsureWorkspace(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.workspaces[id]; !ok {
+		s.workspaces[id] = &vmmodels.Workspace{ID: id, Name: id, CreatedAt: seconds(time.Now())}
+	}
+	return nil
+}
+
+// GetWorkspace retrieves a workspace by ID.
+func (s *MemoryStore) GetWorkspace(id string) (*vmmodels.Workspace, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	workspace, ok := s.workspaces[id]
+	if !ok {
+		return nil, vmmodels.ErrWorkspaceNotFound
+	}
+	copied := *workspace
+	return &copied, nil
+}
+
+// ListWorkspaces lists workspaces by ID.
+func (s *MemoryStore) ListWorkspaces() ([]*vmmodels.Workspace, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var workspaces []*vmmodels.Workspace
+	for _, workspace := range s.workspaces {
+		copied := *workspace
+		workspaces = append(workspaces, &copied)
+	}
+	sort.Slice(workspaces, func(i, j int) bool { return workspaces[i].ID < workspaces[j].ID })
+	return workspaces, nil
+}
+
+// CreateAPIToken stores a new API token.
+func (s *MemoryStore) CreateAPIToken(token *vmmodels.APIToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.tokens {
+		if existing.token.ID == token.ID {
+			return uniqueViolation("api_token.id")
+		}
+		if existing.token.Hash == token.Hash {
+			return uniqueViolation("api_token.token_hash")
+		}
+	}
+	stored := *token
+	stored.Scopes = cloneStrings(token.Scopes)
+	stored.CreatedAt = seconds(token.CreatedAt)
+	stored.LastUsedAt = nil
+	stored.RevokedAt = nil
+	s.tokens = append(s.tokens, &memoryToken{token: stored, seq: s.nextSeq()})
+	return nil
+}
+
+// GetAPIToken retrieves a token by ID.
+func (s *MemoryStore) GetAPIToken(id string) (*vmmodels.APIToken, error) {
+	return s.findAPIToken(func(token *vmmodels.APIToken) bool { return token.ID == id })
+}
+
+// GetAPITokenByHash retrieves a token by the SHA-256 of its secret.
+func (s *MemoryStore) GetAPITokenByHash(hash string) (*vmmodels.APIToken, error) {
+	return s.findAPIToken(func(token *vmmodels.APIToken) bool { return token.Hash == hash })
+}
+
+// GetAPITokenByCertSubject retrieves the live token bound to a client
+// certificate subject, the newest when several are.
+func (s *MemoryStore) GetAPITokenByCertSubject(subject string) (*vmmodels.APIToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var live []*memoryToken
+	for _, stored := range s.tokens {
+		if stored.token.CertSubject == subject && stored.token.RevokedAt == nil {
+			live = append(live, stored)
+		}
+	}
+	if len(live) == 0 {
+		return nil, vmmodels.ErrTokenNotFound
+	}
+	newestFirst(live,
+		func(stored *memoryToken) time.Time { return stored.token.CreatedAt },
+		func(stored *memoryToken) int64 { return stored.seq })
+	return copyAPIToken(&live[0].token), nil
+}
+
+// ListAPITokens lists tokens, oldest first, including revoked ones.
+func (s *MemoryStore) ListAPITokens() ([]*vmmodels.APIToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var tokens []*vmmodels.APIToken
+	for _, stored := range s.tokens {
+		tokens = append(tokens, copyAPIToken(&stored.token))
+	}
+	sort.SliceStable(tokens, func(i, j int) bool {
+		if !tokens[i].CreatedAt.Equal(tokens[j].CreatedAt) {
+			return tokens[i].CreatedAt.Before(tokens[j].CreatedAt)
+		}
+		return tokens[i].ID < tokens[j].ID
+	})
+	return tokens, nil
+}
+
+// UpdateAPIToken persists a token's last use and revocation times.
+func (s *MemoryStore) UpdateAPIToken(token *vmmodels.APIToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, stored := range s.tokens {
+		if stored.token.ID == token.ID {
+			stored.token.LastUsedAt = secondsPtr(token.LastUsedAt)
+			stored.token.RevokedAt = secondsPtr(token.RevokedAt)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) findAPIToken(match func(*vmmodels.APIToken) bool) (*vmmodels.APIToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, stored := range s.tokens {
+		if match(&stored.token) {
+			return copyAPIToken(&stored.token), nil
+		}
+	}
+	return nil, vmmodels.ErrTokenNotFound
+}
+
+// AppendAuditEvent stores an audit event and sets its ID.
+func (s *MemoryStore) AppendAuditEvent(event *vmmodels.AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *event
+	stored.ID = int64(len(s.audit) + 1)
+	stored.CreatedAt = seconds(event.CreatedAt)
+	stored.Details = nil
+	if details := string(event.Details); details != "" && details != "{}" {
+		stored.Details = json.RawMessage(details)
+	}
+	s.audit = append(s.audit, &stored)
+	event.ID = stored.ID
+	return nil
+}
+
+// ListAuditEvents lists the audit events matching filter, newest first.
+func (s *MemoryStore) ListAuditEvents(filter vmmodels.AuditFilter) ([]*vmmodels.AuditEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	match := func(value, want string) bool {
+		return want == "" || value == want
+	}
+	var events []*vmmodels.AuditEvent
+	for i := len(s.audit) - 1; i >= 0; i-- {
+		event := s.audit[i]
+		if !match(event.Action, filter.Action) ||
+			!match(event.Actor, filter.Actor) ||
+			!match(event.ResourceType, filter.ResourceType) ||
+			!match(event.ResourceID, filter.ResourceID) ||
+			!match(event.WorkspaceID, filter.WorkspaceID) ||
+			!match(event.Outcome, filter.Outcome) {
+			continue
+		}
+		if !filter.Since.IsZero() && event.CreatedAt.Unix() < filter.Since.Unix() {
+			continue
+		}
+		copied := *event
+		copied.Details = cloneRaw(event.Details)
+		events = append(events, &copied)
+		if filter.Limit > 0 && len(events) == filter.Limit {
+			break
+		}
+	}
+	return events, nil
+}
+
+func copyLibrary(lib *vmmodels.Library) *vmmodels.Library {
+	copied := *lib
+	copied.CreatedAt = secondsPtr(lib.CreatedAt)
+	copied.Config = cloneConfig(lib.Config)
+	copied.Dependencies = cloneStrings(lib.Dependencies)
+	return &copied
+}
+
+func copyAPIToken(token *vmmodels.APIToken) *vmmodels.APIToken {
+	copied := *token
+	copied.Scopes = cloneStrings(token.Scopes)
+	copied.LastUsedAt = secondsPtr(token.LastUsedAt)
+	copied.RevokedAt = secondsPtr(token.RevokedAt)
+	return &copied
+}
+
+func cloneConfig(config map[string]string) map[string]string {
+	if config == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(config))
+	for key, value := range config {
+		copied[key] = value
+	}
+	return copied
+}
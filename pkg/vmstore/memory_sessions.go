@@ -0,0 +1,248 @@
+package vmstore
+
+import (
+	"sort"
+	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+type memorySession struct {
+	session vmmodels.VMSession
+	seq     int64
+}
+
+type memoryExecution struct {
+	exec vmmodels.Execution
+	seq  int64
+}
+
+// CreateSession creates a new VM session.
+func (s *MemoryStore) CreateSession(session *vmmodels.VMSession) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sessions[session.ID]; ok {
+		return uniqueViolation("vm_session.id")
+	}
+	if _, ok := s.vms[session.VMID]; !ok {
+		return errForeignKeyConstraint
+	}
+	stored := vmmodels.VMSession{
+		ID:               session.ID,
+		VMID:             session.VMID,
+		WorkspaceID:      session.WorkspaceID,
+		BaseCommitOID:    session.BaseCommitOID,
+		WorktreePath:     session.WorktreePath,
+		TemplateRevision: session.TemplateRevision,
+		Status:           session.Status,
+		CreatedAt:        seconds(session.CreatedAt),
+		LastActivityAt:   time.Unix(lastActivityUnix(session), 0),
+	}
+	s.sessions[session.ID] = &memorySession{session: stored, seq: s.nextSeq()}
+	return nil
+}
+
+// GetSession retrieves a session by ID.
+func (s *MemoryStore) GetSession(id string) (*vmmodels.VMSession, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stored, ok := s.sessions[id]
+	if !ok {
+		return nil, vmmodels.ErrSessionNotFound
+	}
+	return copySession(&stored.session), nil
+}
+
+// UpdateSession updates a session.
+func (s *MemoryStore) UpdateSession(session *vmmodels.VMSession) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.sessions[session.ID]
+	if !ok {
+		return nil
+	}
+	stored.session.Status = session.Status
+	stored.session.ClosedAt = secondsPtr(session.ClosedAt)
+	stored.session.LastError = session.LastError
+	stored.session.RuntimeMeta = cloneRaw(session.RuntimeMeta)
+	return nil
+}
+
+// TouchSession records activity on a session.
+func (s *MemoryStore) TouchSession(id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if stored, ok := s.sessions[id]; ok {
+		stored.session.LastActivityAt = seconds(at)
+	}
+	return nil
+}
+
+// ListSessions lists sessions with optional status filter, newest first.
+func (s *MemoryStore) ListSessions(status string) ([]*vmmodels.VMSession, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var stored []*memorySession
+	for _, session := range s.sessions {
+		if status == "" || session.session.Status == status {
+			stored = append(stored, session)
+		}
+	}
+	newestFirst(stored,
+		func(session *memorySession) time.Time { return session.session.CreatedAt },
+		func(session *memorySession) int64 { return session.seq })
+
+	var sessions []*vmmodels.VMSession
+	for _, session := range stored {
+		sessions = append(sessions, copySession(&session.session))
+	}
+	return sessions, nil
+}
+
+// CreateExecution creates a new execution.
+func (s *MemoryStore) CreateExecution(exec *vmmodels.Execution) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.executions[exec.ID]; ok {
+		return uniqueViolation("execution.id")
+	}
+	if _, ok := s.sessions[exec.SessionID]; !ok {
+		return errForeignKeyConstraint
+	}
+	stored := vmmodels.Execution{
+		ID:          exec.ID,
+		SessionID:   exec.SessionID,
+		WorkspaceID: exec.WorkspaceID,
+		Kind:        exec.Kind,
+		Input:       exec.Input,
+		Path:        exec.Path,
+		Args:        cloneRaw(exec.Args),
+		Env:         cloneRaw(exec.Env),
+		Status:      exec.Status,
+		StartedAt:   seconds(exec.StartedAt),
+		Metrics:     cloneRaw(exec.Metrics),
+	}
+	s.executions[exec.ID] = &memoryExecution{exec: stored, seq: s.nextSeq()}
+	return nil
+}
+
+// UpdateExecution updates an execution.
+func (s *MemoryStore) UpdateExecution(exec *vmmodels.Execution) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.executions[exec.ID]
+	if !ok {
+		return nil
+	}
+	stored.exec.Status = exec.Status
+	stored.exec.EndedAt = secondsPtr(exec.EndedAt)
+	stored.exec.Result = cloneRaw(exec.Result)
+	stored.exec.Error = cloneRaw(exec.Error)
+	stored.exec.Metrics = cloneRaw(exec.Metrics)
+	return nil
+}
+
+// GetExecution retrieves an execution by ID.
+func (s *MemoryStore) GetExecution(id string) (*vmmodels.Execution, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stored, ok := s.executions[id]
+	if !ok {
+		return nil, vmmodels.ErrExecutionNotFound
+	}
+	return copyExecution(&stored.exec), nil
+}
+
+// ListExecutions lists executions for a session, newest first. A negative
+// limit lists them all.
+func (s *MemoryStore) ListExecutions(sessionID string, limit int) ([]*vmmodels.Execution, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var stored []*memoryExecution
+	for _, exec := range s.executions {
+		if exec.exec.SessionID == sessionID {
+			stored = append(stored, exec)
+		}
+	}
+	newestFirst(stored,
+		func(exec *memoryExecution) time.Time { return exec.exec.StartedAt },
+		func(exec *memoryExecution) int64 { return exec.seq })
+	if limit >= 0 && len(stored) > limit {
+		stored = stored[:limit]
+	}
+
+	var execs []*vmmodels.Execution
+	for _, exec := range stored {
+		execs = append(execs, copyExecution(&exec.exec))
+	}
+	return execs, nil
+}
+
+// AddEvent adds an event to an execution. Events are kept sorted by seq;
+// the executor emits them in order, so the common case is an append.
+func (s *MemoryStore) AddEvent(event *vmmodels.ExecutionEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.executions[event.ExecutionID]; !ok {
+		return errForeignKeyConstraint
+	}
+	stored := copyEvent(event)
+	stored.Ts = seconds(event.Ts)
+
+	events := s.events[event.ExecutionID]
+	if n := len(events); n == 0 || events[n-1].Seq < event.Seq {
+		s.events[event.ExecutionID] = append(events, stored)
+		return nil
+	}
+	i := eventIndexAfter(events, event.Seq-1)
+	if events[i].Seq == event.Seq {
+		return uniqueViolation("execution_event.execution_id, execution_event.seq")
+	}
+	events = append(events, nil)
+	copy(events[i+1:], events[i:])
+	events[i] = stored
+	s.events[event.ExecutionID] = events
+	return nil
+}
+
+// GetEvents retrieves events for an execution.
+func (s *MemoryStore) GetEvents(executionID string, afterSeq int) ([]*vmmodels.ExecutionEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stored := s.events[executionID]
+	var events []*vmmodels.ExecutionEvent
+	for _, event := range stored[eventIndexAfter(stored, afterSeq):] {
+		events = append(events, copyEvent(event))
+	}
+	return events, nil
+}
+
+// eventIndexAfter returns the index of the first event with a seq above
+// afterSeq in events sorted by seq.
+func eventIndexAfter(events []*vmmodels.ExecutionEvent, afterSeq int) int {
+	return sort.Search(len(events), func(i int) bool { return events[i].Seq > afterSeq })
+}
+
+func copySession(session *vmmodels.VMSession) *vmmodels.VMSession {
+	copied := *session
+	copied.ClosedAt = secondsPtr(session.ClosedAt)
+	copied.RuntimeMeta = cloneRaw(session.RuntimeMeta)
+	return &copied
+}
+
+func copyExecution(exec *vmmodels.Execution) *vmmodels.Execution {
+	copied := *exec
+	copied.EndedAt = secondsPtr(exec.EndedAt)
+	copied.Args = cloneRaw(exec.Args)
+	copied.Env = cloneRaw(exec.Env)
+	copied.Result = cloneRaw(exec.Result)
+	copied.Error = cloneRaw(exec.Error)
+	copied.Metrics = cloneRaw(exec.Metrics)
+	return &copied
+}
+
+func copyEvent(event *vmmodels.ExecutionEvent) *vmmodels.ExecutionEvent {
+	copied := *event
+	copied.Payload = cloneRaw(event.Payload)
+	return &copied
+}
@@ -0,0 +1,389 @@
+package vmstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+type memoryVM struct {
+	vm  vmmodels.VM
+	seq int64
+}
+
+type memoryRevision struct {
+	revision  int
+	spec      []byte
+	createdAt time.Time
+}
+
+// CreateVM creates a new VM profile.
+func (s *MemoryStore) CreateVM(vm *vmmodels.VM) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.vms[vm.ID]; ok {
+		return uniqueViolation("vm.id")
+	}
+	if s.vmNameTaken(vm.Name, vm.ID) {
+		return uniqueViolation("vm.name")
+	}
+	stored := *vm
+	stored.ExposedModules = cloneStrings(vm.ExposedModules)
+	stored.Libraries = cloneStrings(vm.Libraries)
+	stored.Revision = 0
+	stored.CreatedAt = seconds(vm.CreatedAt)
+	stored.UpdatedAt = seconds(vm.UpdatedAt)
+	s.vms[vm.ID] = &memoryVM{vm: stored, seq: s.nextSeq()}
+	return nil
+}
+
+// GetVM retrieves a VM by ID.
+func (s *MemoryStore) GetVM(id string) (*vmmodels.VM, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stored, ok := s.vms[id]
+	if !ok {
+		return nil, vmmodels.ErrVMNotFound
+	}
+	return s.vmCopy(stored), nil
+}
+
+// ListVMs lists all VMs, newest first.
+func (s *MemoryStore) ListVMs() ([]*vmmodels.VM, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stored := make([]*memoryVM, 0, len(s.vms))
+	for _, vm := range s.vms {
+		stored = append(stored, vm)
+	}
+	newestFirst(stored,
+		func(vm *memoryVM) time.Time { return vm.vm.CreatedAt },
+		func(vm *memoryVM) int64 { return vm.seq })
+
+	var vms []*vmmodels.VM
+	for _, vm := range stored {
+		vms = append(vms, s.vmCopy(vm))
+	}
+	return vms, nil
+}
+
+// UpdateVM updates a VM profile.
+func (s *MemoryStore) UpdateVM(vm *vmmodels.VM) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vm.UpdatedAt = time.Now()
+	stored, ok := s.vms[vm.ID]
+	if !ok {
+		return nil
+	}
+	if s.vmNameTaken(vm.Name, vm.ID) {
+		return uniqueViolation("vm.name")
+	}
+	stored.vm.Name = vm.Name
+	stored.vm.Engine = vm.Engine
+	stored.vm.IsActive = vm.IsActive
+	stored.vm.ExposedModules = cloneStrings(vm.ExposedModules)
+	stored.vm.Libraries = cloneStrings(vm.Libraries)
+	stored.vm.UpdatedAt = seconds(vm.UpdatedAt)
+	return nil
+}
+
+// DeleteVM deletes a VM profile along with its settings, capabilities,
+// startup files and revisions. It fails while sessions reference the VM.
+func (s *MemoryStore) DeleteVM(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.vms[id]; !ok {
+		return nil
+	}
+	for _, session := range s.sessions {
+		if session.session.VMID == id {
+			return errForeignKeyConstraint
+		}
+	}
+	delete(s.vms, id)
+	delete(s.settings, id)
+	delete(s.revisions, id)
+	s.capabilities = filterCapabilities(s.capabilities, func(cap *vmmodels.VMCapability) bool { return cap.VMID != id })
+	s.startupFiles = filterStartupFiles(s.startupFiles, func(file *vmmodels.VMStartupFile) bool { return file.VMID != id })
+	return nil
+}
+
+// SetVMSettings sets VM settings.
+func (s *MemoryStore) SetVMSettings(settings *vmmodels.VMSettings) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.vms[settings.VMID]; !ok {
+		return errForeignKeyConstraint
+	}
+	stored := &vmmodels.VMSettings{
+		VMID:      settings.VMID,
+		Limits:    cloneRaw(settings.Limits),
+		Resolver:  cloneRaw(settings.Resolver),
+		Runtime:   cloneRaw(settings.Runtime),
+		Pool:      cloneRaw(settings.Pool),
+		Lifecycle: cloneRaw(settings.Lifecycle),
+	}
+	if len(stored.Pool) == 0 {
+		stored.Pool = json.RawMessage("{}")
+	}
+	if len(stored.Lifecycle) == 0 {
+		stored.Lifecycle = json.RawMessage("{}")
+	}
+	s.settings[settings.VMID] = stored
+	return nil
+}
+
+// GetVMSettings retrieves VM settings.
+func (s *MemoryStore) GetVMSettings(vmID string) (*vmmodels.VMSettings, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stored, ok := s.settings[vmID]
+	if !ok {
+		return nil, vmmodels.ErrVMNotFound
+	}
+	return &vmmodels.VMSettings{
+		VMID:      stored.VMID,
+		Limits:    cloneRaw(stored.Limits),
+		Resolver:  cloneRaw(stored.Resolver),
+		Runtime:   cloneRaw(stored.Runtime),
+		Pool:      cloneRaw(stored.Pool),
+		Lifecycle: cloneRaw(stored.Lifecycle),
+	}, nil
+}
+
+// AddCapability adds a capability to a VM.
+func (s *MemoryStore) AddCapability(cap *vmmodels.VMCapability) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.vms[cap.VMID]; !ok {
+		return errForeignKeyConstraint
+	}
+	for _, existing := range s.capabilities {
+		if existing.ID == cap.ID {
+			return uniqueViolation("vm_capability.id")
+		}
+		if existing.VMID == cap.VMID && existing.Kind == cap.Kind && existing.Name == cap.Name {
+			return uniqueViolation("vm_capability.vm_id, vm_capability.kind, vm_capability.name")
+		}
+	}
+	s.capabilities = append(s.capabilities, copyCapability(cap))
+	return nil
+}
+
+// ListCapabilities lists all capabilities for a VM.
+func (s *MemoryStore) ListCapabilities(vmID string) ([]*vmmodels.VMCapability, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var caps []*vmmodels.VMCapability
+	for _, cap := range s.capabilities {
+		if cap.VMID == vmID {
+			caps = append(caps, copyCapability(cap))
+		}
+	}
+	return caps, nil
+}
+
+// GetCapability retrieves a specific capability.
+func (s *MemoryStore) GetCapability(vmID, kind, name string) (*vmmodels.VMCapability, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, cap := range s.capabilities {
+		if cap.VMID == vmID && cap.Kind == kind && cap.Name == name {
+			return copyCapability(cap), nil
+		}
+	}
+	return nil, vmmodels.ErrModuleNotAllowed
+}
+
+// DeleteCapability deletes a capability.
+func (s *MemoryStore) DeleteCapability(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.capabilities = filterCapabilities(s.capabilities, func(cap *vmmodels.VMCapability) bool { return cap.ID != id })
+	return nil
+}
+
+// AddStartupFile adds a startup file to a VM.
+func (s *MemoryStore) AddStartupFile(file *vmmodels.VMStartupFile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.vms[file.VMID]; !ok {
+		return errForeignKeyConstraint
+	}
+	for _, existing := range s.startupFiles {
+		if existing.ID == file.ID {
+			return uniqueViolation("vm_startup_file.id")
+		}
+	}
+	if s.startupPathTaken(file.VMID, file.Path, file.ID) {
+		return uniqueViolation("vm_startup_file.vm_id, vm_startup_file.path")
+	}
+	stored := *file
+	stored.Kind = startupKindOrDefault(file.Kind)
+	s.startupFiles = append(s.startupFiles, &stored)
+	return nil
+}
+
+// ListStartupFiles lists all startup files for a VM.
+func (s *MemoryStore) ListStartupFiles(vmID string) ([]*vmmodels.VMStartupFile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var files []*vmmodels.VMStartupFile
+	for _, file := range s.startupFiles {
+		if file.VMID == vmID {
+			copied := *file
+			files = append(files, &copied)
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].OrderIndex < files[j].OrderIndex })
+	return files, nil
+}
+
+// UpdateStartupFile updates a startup file's path, source, order, and mode.
+func (s *MemoryStore) UpdateStartupFile(file *vmmodels.VMStartupFile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, stored := range s.startupFiles {
+		if stored.ID != file.ID {
+			continue
+		}
+		if s.startupPathTaken(stored.VMID, file.Path, file.ID) {
+			return uniqueViolation("vm_startup_file.vm_id, vm_startup_file.path")
+		}
+		stored.Path = file.Path
+		stored.Source = file.Source
+		stored.OrderIndex = file.OrderIndex
+		stored.Mode = file.Mode
+	}
+	return nil
+}
+
+// DeleteStartupFile deletes a startup file.
+func (s *MemoryStore) DeleteStartupFile(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.startupFiles = filterStartupFiles(s.startupFiles, func(file *vmmodels.VMStartupFile) bool { return file.ID != id })
+	return nil
+}
+
+// CreateTemplateRevision records an immutable template revision.
+func (s *MemoryStore) CreateTemplateRevision(rev *vmmodels.TemplateRevision) error {
+	specJSON, err := json.Marshal(rev.Spec)
+	if err != nil {
+		return fmt.Errorf("marshal template revision spec: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.vms[rev.VMID]; !ok {
+		return errForeignKeyConstraint
+	}
+	for _, existing := range s.revisions[rev.VMID] {
+		if existing.revision == rev.Revision {
+			return uniqueViolation("vm_revision.vm_id, vm_revision.revision")
+		}
+	}
+	s.revisions[rev.VMID] = append(s.revisions[rev.VMID], &memoryRevision{
+		revision:  rev.Revision,
+		spec:      specJSON,
+		createdAt: seconds(rev.CreatedAt),
+	})
+	sort.Slice(s.revisions[rev.VMID], func(i, j int) bool {
+		return s.revisions[rev.VMID][i].revision < s.revisions[rev.VMID][j].revision
+	})
+	return nil
+}
+
+// ListTemplateRevisions lists revisions for a VM, oldest first.
+func (s *MemoryStore) ListTemplateRevisions(vmID string) ([]*vmmodels.TemplateRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var revisions []*vmmodels.TemplateRevision
+	for _, stored := range s.revisions[vmID] {
+		rev, err := stored.templateRevision(vmID)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, nil
+}
+
+// GetTemplateRevision retrieves one revision of a VM.
+func (s *MemoryStore) GetTemplateRevision(vmID string, revision int) (*vmmodels.TemplateRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, stored := range s.revisions[vmID] {
+		if stored.revision == revision {
+			return stored.templateRevision(vmID)
+		}
+	}
+	return nil, vmmodels.ErrTemplateRevisionNotFound
+}
+
+// templateRevision decodes a fresh copy of the revision's spec.
+func (r *memoryRevision) templateRevision(vmID string) (*vmmodels.TemplateRevision, error) {
+	rev := &vmmodels.TemplateRevision{VMID: vmID, Revision: r.revision, CreatedAt: r.createdAt, Spec: &vmmodels.TemplateSpec{}}
+	if err := json.Unmarshal(r.spec, rev.Spec); err != nil {
+		return nil, fmt.Errorf("unmarshal template revision %d spec: %w", r.revision, err)
+	}
+	return rev, nil
+}
+
+// vmCopy returns a copy of the VM with its latest revision filled in.
+func (s *MemoryStore) vmCopy(stored *memoryVM) *vmmodels.VM {
+	vm := stored.vm
+	vm.ExposedModules = cloneStrings(stored.vm.ExposedModules)
+	vm.Libraries = cloneStrings(stored.vm.Libraries)
+	if revisions := s.revisions[vm.ID]; len(revisions) > 0 {
+		vm.Revision = revisions[len(revisions)-1].revision
+	}
+	return &vm
+}
+
+func (s *MemoryStore) vmNameTaken(name, exceptID string) bool {
+	for id, vm := range s.vms {
+		if id != exceptID && vm.vm.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *MemoryStore) startupPathTaken(vmID, path, exceptID string) bool {
+	for _, file := range s.startupFiles {
+		if file.ID != exceptID && file.VMID == vmID && file.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+func copyCapability(cap *vmmodels.VMCapability) *vmmodels.VMCapability {
+	copied := *cap
+	copied.Config = cloneRaw(cap.Config)
+	return &copied
+}
+
+func filterCapabilities(caps []*vmmodels.VMCapability, keep func(*vmmodels.VMCapability) bool) []*vmmodels.VMCapability {
+	kept := caps[:0]
+	for _, cap := range caps {
+		if keep(cap) {
+			kept = append(kept, cap)
+		}
+	}
+	return kept
+}
+
+func filterStartupFiles(files []*vmmodels.VMStartupFile, keep func(*vmmodels.VMStartupFile) bool) []*vmmodels.VMStartupFile {
+	kept := files[:0]
+	for _, file := range files {
+		if keep(file) {
+			kept = append(kept, file)
+		}
+	}
+	return kept
+}
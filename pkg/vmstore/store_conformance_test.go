@@ -0,0 +1,515 @@
+package vmstore_test
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+	"github.com/go-go-golems/vm-system/pkg/vmstore"
+)
+
+var (
+	_ vmcontrol.Store = (*vmstore.VMStore)(nil)
+	_ vmcontrol.Store = (*vmstore.MemoryStore)(nil)
+)
+
+type conformanceStore interface {
+	vmcontrol.Store
+	Close() error
+}
+
+func TestVMStoreConformance(t *testing.T) {
+	runStoreConformance(t, func(t *testing.T) conformanceStore {
+		store, err := vmstore.NewVMStore(filepath.Join(t.TempDir(), "vm-system.db"))
+		if err != nil {
+			t.Fatalf("new store: %v", err)
+		}
+		return store
+	})
+}
+
+func TestMemoryStoreConformance(t *testing.T) {
+	runStoreConformance(t, func(*testing.T) conformanceStore {
+		return vmstore.NewMemoryStore()
+	})
+}
+
+// runStoreConformance checks the behavior the services rely on. Every store
+// must pass it, so the SQLite and in-memory stores stay interchangeable.
+func runStoreConformance(t *testing.T, newStore func(*testing.T) conformanceStore) {
+	// Sub-second precision is dropped by every store.
+	base := time.Date(2026, 3, 1, 12, 0, 0, 500_000_000, time.UTC)
+	at := func(seconds int) time.Time { return base.Add(time.Duration(seconds) * time.Second) }
+
+	open := func(t *testing.T) conformanceStore {
+		store := newStore(t)
+		t.Cleanup(func() { _ = store.Close() })
+		return store
+	}
+	createVM := func(t *testing.T, store conformanceStore, id, name string, created time.Time) {
+		t.Helper()
+		if err := store.CreateVM(&vmmodels.VM{ID: id, Name: name, Engine: "goja", IsActive: true, ExposedModules: []string{"console"}, WorkspaceID: "ws-a", CreatedAt: created, UpdatedAt: created}); err != nil {
+			t.Fatalf("create vm %s: %v", id, err)
+		}
+	}
+	createSession := func(t *testing.T, store conformanceStore, id, vmID, status string, created time.Time) {
+		t.Helper()
+		if err := store.CreateSession(&vmmodels.VMSession{ID: id, VMID: vmID, WorkspaceID: "ws-a", BaseCommitOID: "deadbeef", WorktreePath: "/tmp/wt", Status: status, CreatedAt: created}); err != nil {
+			t.Fatalf("create session %s: %v", id, err)
+		}
+	}
+
+	t.Run("templates", func(t *testing.T) {
+		store := open(t)
+		createVM(t, store, "vm-1", "first", at(0))
+		createVM(t, store, "vm-2", "second", at(1))
+
+		if err := store.CreateVM(&vmmodels.VM{ID: "vm-3", Name: "first", CreatedAt: at(2), UpdatedAt: at(2)}); err == nil {
+			t.Fatalf("expected duplicate template names to be rejected")
+		}
+		if _, err := store.GetVM("missing"); !errors.Is(err, vmmodels.ErrVMNotFound) {
+			t.Fatalf("expected ErrVMNotFound, got %v", err)
+		}
+
+		vm, err := store.GetVM("vm-1")
+		if err != nil {
+			t.Fatalf("get vm: %v", err)
+		}
+		if !vm.CreatedAt.Equal(base.Truncate(time.Second)) || vm.Revision != 0 || !reflect.DeepEqual(vm.ExposedModules, []string{"console"}) || vm.WorkspaceID != "ws-a" {
+			t.Fatalf("unexpected vm %+v", vm)
+		}
+		// Records returned by the store are copies.
+		vm.ExposedModules[0] = "mutated"
+		if again, _ := store.GetVM("vm-1"); again.ExposedModules[0] != "console" {
+			t.Fatalf("expected the stored vm to be unaffected by callers, got %+v", again)
+		}
+
+		vm.Name = "renamed"
+		vm.WorkspaceID = "ws-b"
+		vm.UpdatedAt = time.Time{}
+		if err := store.UpdateVM(vm); err != nil {
+			t.Fatalf("update vm: %v", err)
+		}
+		if vm.UpdatedAt.IsZero() {
+			t.Fatalf("expected UpdateVM to stamp UpdatedAt")
+		}
+		if updated, _ := store.GetVM("vm-1"); updated.Name != "renamed" || updated.WorkspaceID != "ws-a" {
+			t.Fatalf("expected name updated and workspace kept, got %+v", updated)
+		}
+		vm.Name = "second"
+		if err := store.UpdateVM(vm); err == nil {
+			t.Fatalf("expected renaming onto a taken name to be rejected")
+		}
+
+		for revision := 1; revision <= 2; revision++ {
+			if err := store.CreateTemplateRevision(&vmmodels.TemplateRevision{VMID: "vm-1", Revision: revision, Spec: &vmmodels.TemplateSpec{Name: "renamed", Modules: []string{"console"}}, CreatedAt: at(revision)}); err != nil {
+				t.Fatalf("create revision %d: %v", revision, err)
+			}
+		}
+		if err := store.CreateTemplateRevision(&vmmodels.TemplateRevision{VMID: "vm-1", Revision: 2, Spec: &vmmodels.TemplateSpec{}, CreatedAt: at(3)}); err == nil {
+			t.Fatalf("expected duplicate revisions to be rejected")
+		}
+		if err := store.CreateTemplateRevision(&vmmodels.TemplateRevision{VMID: "missing", Revision: 1, Spec: &vmmodels.TemplateSpec{}, CreatedAt: at(3)}); err == nil {
+			t.Fatalf("expected revisions of unknown templates to be rejected")
+		}
+		revisions, err := store.ListTemplateRevisions("vm-1")
+		if err != nil || len(revisions) != 2 || revisions[0].Revision != 1 || revisions[1].Spec.Modules[0] != "console" {
+			t.Fatalf("unexpected revisions %+v (%v)", revisions, err)
+		}
+		if _, err := store.GetTemplateRevision("vm-1", 9); !errors.Is(err, vmmodels.ErrTemplateRevisionNotFound) {
+			t.Fatalf("expected ErrTemplateRevisionNotFound, got %v", err)
+		}
+
+		vms, err := store.ListVMs()
+		if err != nil || len(vms) != 2 || vms[0].ID != "vm-2" || vms[1].ID != "vm-1" || vms[1].Revision != 2 {
+			t.Fatalf("expected templates newest first with their latest revision, got %+v (%v)", vms, err)
+		}
+	})
+
+	t.Run("template settings and policy", func(t *testing.T) {
+		store := open(t)
+		createVM(t, store, "vm-1", "policy", at(0))
+
+		if _, err := store.GetVMSettings("vm-1"); !errors.Is(err, vmmodels.ErrVMNotFound) {
+			t.Fatalf("expected ErrVMNotFound before settings exist, got %v", err)
+		}
+		settings := &vmmodels.VMSettings{VMID: "vm-1", Limits: json.RawMessage(`{"wall_ms":100}`), Resolver: json.RawMessage(`{}`), Runtime: json.RawMessage(`{}`)}
+		if err := store.SetVMSettings(settings); err != nil {
+			t.Fatalf("set settings: %v", err)
+		}
+		settings.Limits = json.RawMessage(`{"wall_ms":200}`)
+		if err := store.SetVMSettings(settings); err != nil {
+			t.Fatalf("replace settings: %v", err)
+		}
+		got, err := store.GetVMSettings("vm-1")
+		if err != nil || string(got.Limits) != `{"wall_ms":200}` || string(got.Pool) != "{}" || string(got.Lifecycle) != "{}" {
+			t.Fatalf("unexpected settings %+v (%v)", got, err)
+		}
+		if err := store.SetVMSettings(&vmmodels.VMSettings{VMID: "missing", Limits: json.RawMessage(`{}`), Resolver: json.RawMessage(`{}`), Runtime: json.RawMessage(`{}`)}); err == nil {
+			t.Fatalf("expected settings of unknown templates to be rejected")
+		}
+
+		for _, cap := range []*vmmodels.VMCapability{
+			{ID: "cap-1", VMID: "vm-1", Kind: "module", Name: "fs", Enabled: true, Config: json.RawMessage(`{}`)},
+			{ID: "cap-2", VMID: "vm-1", Kind: "net", Name: "fetch", Enabled: true, Config: json.RawMessage(`{"allow":["a"]}`)},
+		} {
+			if err := store.AddCapability(cap); err != nil {
+				t.Fatalf("add capability %s: %v", cap.ID, err)
+			}
+		}
+		if err := store.AddCapability(&vmmodels.VMCapability{ID: "cap-3", VMID: "vm-1", Kind: "module", Name: "fs", Config: json.RawMessage(`{}`)}); err == nil {
+			t.Fatalf("expected duplicate capabilities to be rejected")
+		}
+		if err := store.AddCapability(&vmmodels.VMCapability{ID: "cap-4", VMID: "missing", Kind: "module", Name: "fs", Config: json.RawMessage(`{}`)}); err == nil {
+			t.Fatalf("expected capabilities of unknown templates to be rejected")
+		}
+		if err := store.DeleteCapability("cap-1"); err != nil {
+			t.Fatalf("delete capability: %v", err)
+		}
+		if err := store.DeleteCapability("cap-1"); err != nil {
+			t.Fatalf("expected deleting a missing capability to succeed, got %v", err)
+		}
+		caps, err := store.ListCapabilities("vm-1")
+		if err != nil || len(caps) != 1 || caps[0].ID != "cap-2" || string(caps[0].Config) != `{"allow":["a"]}` {
+			t.Fatalf("unexpected capabilities %+v (%v)", caps, err)
+		}
+
+		for _, file := range []*vmmodels.VMStartupFile{
+			{ID: "sf-1", VMID: "vm-1", Path: "b.js", OrderIndex: 20, Mode: "eval"},
+			{ID: "sf-2", VMID: "vm-1", Kind: vmmodels.StartupKindInline, Path: "a", Source: "1", OrderIndex: 10, Mode: "eval"},
+		} {
+			if err := store.AddStartupFile(file); err != nil {
+				t.Fatalf("add startup file %s: %v", file.ID, err)
+			}
+		}
+		if err := store.AddStartupFile(&vmmodels.VMStartupFile{ID: "sf-3", VMID: "vm-1", Path: "b.js", Mode: "eval"}); err == nil {
+			t.Fatalf("expected duplicate startup paths to be rejected")
+		}
+		if err := store.UpdateStartupFile(&vmmodels.VMStartupFile{ID: "sf-1", Path: "c.js", OrderIndex: 5, Mode: "eval"}); err != nil {
+			t.Fatalf("update startup file: %v", err)
+		}
+		files, err := store.ListStartupFiles("vm-1")
+		if err != nil || len(files) != 2 || files[0].ID != "sf-1" || files[0].Path != "c.js" || files[0].Kind != vmmodels.StartupKindPath || files[1].Kind != vmmodels.StartupKindInline {
+			t.Fatalf("unexpected startup files %+v (%v)", files, err)
+		}
+		if err := store.DeleteStartupFile("sf-2"); err != nil {
+			t.Fatalf("delete startup file: %v", err)
+		}
+		if files, _ := store.ListStartupFiles("vm-1"); len(files) != 1 {
+			t.Fatalf("expected one startup file left, got %+v", files)
+		}
+	})
+
+	t.Run("template deletion", func(t *testing.T) {
+		store := open(t)
+		createVM(t, store, "vm-1", "doomed", at(0))
+		createVM(t, store, "vm-2", "in-use", at(1))
+		if err := store.SetVMSettings(&vmmodels.VMSettings{VMID: "vm-1", Limits: json.RawMessage(`{}`), Resolver: json.RawMessage(`{}`), Runtime: json.RawMessage(`{}`)}); err != nil {
+			t.Fatalf("set settings: %v", err)
+		}
+		if err := store.AddCapability(&vmmodels.VMCapability{ID: "cap-1", VMID: "vm-1", Kind: "module", Name: "fs", Config: json.RawMessage(`{}`)}); err != nil {
+			t.Fatalf("add capability: %v", err)
+		}
+		if err := store.AddStartupFile(&vmmodels.VMStartupFile{ID: "sf-1", VMID: "vm-1", Path: "init.js", Mode: "eval"}); err != nil {
+			t.Fatalf("add startup file: %v", err)
+		}
+		if err := store.CreateTemplateRevision(&vmmodels.TemplateRevision{VMID: "vm-1", Revision: 1, Spec: &vmmodels.TemplateSpec{}, CreatedAt: at(0)}); err != nil {
+			t.Fatalf("create revision: %v", err)
+		}
+		createSession(t, store, "session-1", "vm-2", "ready", at(2))
+
+		if err := store.DeleteVM("vm-2"); err == nil {
+			t.Fatalf("expected deleting a template with sessions to be rejected")
+		}
+		if err := store.DeleteVM("vm-1"); err != nil {
+			t.Fatalf("delete vm: %v", err)
+		}
+		if _, err := store.GetVMSettings("vm-1"); !errors.Is(err, vmmodels.ErrVMNotFound) {
+			t.Fatalf("expected settings deleted with the template, got %v", err)
+		}
+		caps, _ := store.ListCapabilities("vm-1")
+		files, _ := store.ListStartupFiles("vm-1")
+		revisions, _ := store.ListTemplateRevisions("vm-1")
+		if len(caps) != 0 || len(files) != 0 || len(revisions) != 0 {
+			t.Fatalf("expected policy and revisions deleted with the template, got %+v %+v %+v", caps, files, revisions)
+		}
+		// A template with the same name can be created again.
+		createVM(t, store, "vm-3", "doomed", at(3))
+	})
+
+	t.Run("sessions", func(t *testing.T) {
+		store := open(t)
+		createVM(t, store, "vm-1", "sessions", at(0))
+		createSession(t, store, "session-1", "vm-1", "ready", at(1))
+		createSession(t, store, "session-2", "vm-1", "closed", at(2))
+
+		if err := store.CreateSession(&vmmodels.VMSession{ID: "session-3", VMID: "missing", Status: "ready", CreatedAt: at(3)}); err == nil {
+			t.Fatalf("expected sessions of unknown templates to be rejected")
+		}
+		if _, err := store.GetSession("missing"); !errors.Is(err, vmmodels.ErrSessionNotFound) {
+			t.Fatalf("expected ErrSessionNotFound, got %v", err)
+		}
+
+		session, err := store.GetSession("session-1")
+		if err != nil || !session.LastActivityAt.Equal(session.CreatedAt) || session.ClosedAt != nil || session.LastError != "" || session.RuntimeMeta != nil {
+			t.Fatalf("unexpected new session %+v (%v)", session, err)
+		}
+
+		closedAt := at(5)
+		session.Status = "crashed"
+		session.ClosedAt = &closedAt
+		session.LastError = "boom"
+		session.RuntimeMeta = json.RawMessage(`{"libraries":[]}`)
+		session.WorktreePath = "/elsewhere"
+		if err := store.UpdateSession(session); err != nil {
+			t.Fatalf("update session: %v", err)
+		}
+		if err := store.TouchSession("session-1", at(4)); err != nil {
+			t.Fatalf("touch session: %v", err)
+		}
+		updated, err := store.GetSession("session-1")
+		if err != nil || updated.Status != "crashed" || !updated.ClosedAt.Equal(closedAt.Truncate(time.Second)) || updated.LastError != "boom" ||
+			string(updated.RuntimeMeta) != `{"libraries":[]}` || updated.WorktreePath != "/tmp/wt" || !updated.LastActivityAt.Equal(at(4).Truncate(time.Second)) {
+			t.Fatalf("unexpected updated session %+v (%v)", updated, err)
+		}
+
+		all, err := store.ListSessions("")
+		if err != nil || len(all) != 2 || all[0].ID != "session-2" {
+			t.Fatalf("expected sessions newest first, got %+v (%v)", all, err)
+		}
+		closed, err := store.ListSessions("closed")
+		if err != nil || len(closed) != 1 || closed[0].ID != "session-2" {
+			t.Fatalf("expected status filter, got %+v (%v)", closed, err)
+		}
+		if none, err := store.ListSessions("starting"); err != nil || none != nil {
+			t.Fatalf("expected a nil list, got %+v (%v)", none, err)
+		}
+	})
+
+	t.Run("executions and events", func(t *testing.T) {
+		store := open(t)
+		createVM(t, store, "vm-1", "executions", at(0))
+		createSession(t, store, "session-1", "vm-1", "ready", at(0))
+
+		for i, id := range []string{"exec-1", "exec-2", "exec-3"} {
+			if err := store.CreateExecution(&vmmodels.Execution{ID: id, SessionID: "session-1", WorkspaceID: "ws-a", Kind: "repl", Input: "1+1", Args: json.RawMessage(`{}`), Env: json.RawMessage(`{}`), Status: "running", StartedAt: at(i), Metrics: json.RawMessage(`{}`)}); err != nil {
+				t.Fatalf("create execution %s: %v", id, err)
+			}
+		}
+		if err := store.CreateExecution(&vmmodels.Execution{ID: "exec-4", SessionID: "missing", Kind: "repl", Status: "running", StartedAt: at(4)}); err == nil {
+			t.Fatalf("expected executions of unknown sessions to be rejected")
+		}
+		if _, err := store.GetExecution("missing"); !errors.Is(err, vmmodels.ErrExecutionNotFound) {
+			t.Fatalf("expected ErrExecutionNotFound, got %v", err)
+		}
+
+		endedAt := at(9)
+		if err := store.UpdateExecution(&vmmodels.Execution{ID: "exec-1", Status: "ok", EndedAt: &endedAt, Result: json.RawMessage(`{"type":"number"}`), Metrics: json.RawMessage(`{"duration_ms":3}`), Input: "ignored"}); err != nil {
+			t.Fatalf("update execution: %v", err)
+		}
+		exec, err := store.GetExecution("exec-1")
+		if err != nil || exec.Status != "ok" || !exec.EndedAt.Equal(endedAt.Truncate(time.Second)) || string(exec.Result) != `{"type":"number"}` ||
+			exec.Error != nil || exec.Input != "1+1" || string(exec.Metrics) != `{"duration_ms":3}` || !exec.StartedAt.Equal(base.Truncate(time.Second)) {
+			t.Fatalf("unexpected execution %+v (%v)", exec, err)
+		}
+
+		limited, err := store.ListExecutions("session-1", 2)
+		if err != nil || len(limited) != 2 || limited[0].ID != "exec-3" || limited[1].ID != "exec-2" {
+			t.Fatalf("expected the two newest executions, got %+v (%v)", limited, err)
+		}
+		if all, _ := store.ListExecutions("session-1", -1); len(all) != 3 {
+			t.Fatalf("expected a negative limit to list everything, got %+v", all)
+		}
+		if none, _ := store.ListExecutions("session-1", 0); len(none) != 0 {
+			t.Fatalf("expected a zero limit to list nothing, got %+v", none)
+		}
+
+		for seq := 1; seq <= 3; seq++ {
+			if err := store.AddEvent(&vmmodels.ExecutionEvent{ExecutionID: "exec-1", Seq: seq, Ts: at(seq), Type: "console", Payload: json.RawMessage(`{"text":"x"}`)}); err != nil {
+				t.Fatalf("add event %d: %v", seq, err)
+			}
+		}
+		if err := store.AddEvent(&vmmodels.ExecutionEvent{ExecutionID: "exec-1", Seq: 2, Ts: at(4), Type: "console", Payload: json.RawMessage(`{}`)}); err == nil {
+			t.Fatalf("expected duplicate event sequence numbers to be rejected")
+		}
+		if err := store.AddEvent(&vmmodels.ExecutionEvent{ExecutionID: "missing", Seq: 1, Ts: at(4), Type: "console", Payload: json.RawMessage(`{}`)}); err == nil {
+			t.Fatalf("expected events of unknown executions to be rejected")
+		}
+		// An event arriving out of order is still listed in seq order.
+		for _, seq := range []int{6, 5} {
+			if err := store.AddEvent(&vmmodels.ExecutionEvent{ExecutionID: "exec-1", Seq: seq, Ts: at(seq), Type: "console", Payload: json.RawMessage(`{}`)}); err != nil {
+				t.Fatalf("add event %d: %v", seq, err)
+			}
+		}
+		if err := store.AddEvent(&vmmodels.ExecutionEvent{ExecutionID: "exec-1", Seq: 5, Ts: at(7), Type: "console", Payload: json.RawMessage(`{}`)}); err == nil {
+			t.Fatalf("expected a duplicate out-of-order sequence number to be rejected")
+		}
+		if tail, err := store.GetEvents("exec-1", 3); err != nil || len(tail) != 2 || tail[0].Seq != 5 || tail[1].Seq != 6 {
+			t.Fatalf("expected events 5 and 6 in order, got %+v (%v)", tail, err)
+		}
+		events, err := store.GetEvents("exec-1", 1)
+		if err != nil || len(events) != 4 || events[0].Seq != 2 || events[1].Seq != 3 || !events[0].Ts.Equal(at(2).Truncate(time.Second)) {
+			t.Fatalf("expected events after seq 1 in order, got %+v (%v)", events, err)
+		}
+	})
+
+	t.Run("libraries and workspaces", func(t *testing.T) {
+		store := open(t)
+		created := at(0)
+		for _, lib := range []*vmmodels.Library{
+			{Ref: "zod-3", ID: "zod", Name: "Zod", Version: "3", Type: "npm", Config: map[string]string{"cdn": "x"}, Dependencies: []string{"base-1"}, Builtin: true, CreatedAt: &created},
+			{Ref: "base-1", ID: "base", Name: "Base", Version: "1", Type: "url"},
+		} {
+			if err := store.CreateLibrary(lib); err != nil {
+				t.Fatalf("create library %s: %v", lib.Ref, err)
+			}
+		}
+		if err := store.CreateLibrary(&vmmodels.Library{Ref: "base-1"}); err == nil {
+			t.Fatalf("expected duplicate library refs to be rejected")
+		}
+		lib, err := store.GetLibrary("zod-3")
+		if err != nil || lib.Builtin || lib.CreatedAt == nil || !lib.CreatedAt.Equal(created.Truncate(time.Second)) || lib.Config["cdn"] != "x" || !reflect.DeepEqual(lib.Dependencies, []string{"base-1"}) {
+			t.Fatalf("unexpected library %+v (%v)", lib, err)
+		}
+		libraries, err := store.ListLibraries()
+		if err != nil || len(libraries) != 2 || libraries[0].Ref != "base-1" || libraries[0].CreatedAt == nil {
+			t.Fatalf("expected libraries by ref, got %+v (%v)", libraries, err)
+		}
+		if err := store.DeleteLibrary("base-1"); err != nil {
+			t.Fatalf("delete library: %v", err)
+		}
+		if err := store.DeleteLibrary("base-1"); !errors.Is(err, vmmodels.ErrLibraryNotFound) {
+			t.Fatalf("expected ErrLibraryNotFound deleting twice, got %v", err)
+		}
+		if _, err := store.GetLibrary("base-1"); !errors.Is(err, vmmodels.ErrLibraryNotFound) {
+			t.Fatalf("expected ErrLibraryNotFound, got %v", err)
+		}
+
+		if err := store.CreateWorkspace(&vmmodels.Workspace{ID: "team-b", Name: "Team B", CreatedAt: at(1)}); err != nil {
+			t.Fatalf("create workspace: %v", err)
+		}
+		if err := store.CreateWorkspace(&vmmodels.Workspace{ID: "team-b", Name: "Again", CreatedAt: at(2)}); err == nil {
+			t.Fatalf("expected duplicate workspaces to be rejected")
+		}
+		for _, id := range []string{"team-a", "team-b"} {
+			if err := store.EnsureWorkspace(id); err != nil {
+				t.Fatalf("ensure workspace %s: %v", id, err)
+			}
+		}
+		workspaces, err := store.ListWorkspaces()
+		if err != nil || len(workspaces) != 2 || workspaces[0].ID != "team-a" || workspaces[0].Name != "team-a" || workspaces[1].Name != "Team B" {
+			t.Fatalf("unexpected workspaces %+v (%v)", workspaces, err)
+		}
+		if _, err := store.GetWorkspace("team-c"); !errors.Is(err, vmmodels.ErrWorkspaceNotFound) {
+			t.Fatalf("expected ErrWorkspaceNotFound, got %v", err)
+		}
+	})
+
+	t.Run("tokens", func(t *testing.T) {
+		store := open(t)
+		for i, token := range []*vmmodels.APIToken{
+			{ID: "tok-b", Name: "old cert", Hash: "hash-b", Scopes: []string{"read"}, CertSubject: "CN=ci", CreatedAt: at(0)},
+			{ID: "tok-a", Name: "new cert", Hash: "hash-a", Scopes: []string{"read", "execute"}, CertSubject: "CN=ci", CreatedAt: at(1)},
+			{ID: "tok-c", Name: "secret", Hash: "hash-c", Scopes: []string{"admin"}, WorkspaceID: "team-a", CreatedAt: at(1)},
+		} {
+			if err := store.CreateAPIToken(token); err != nil {
+				t.Fatalf("create token %d: %v", i, err)
+			}
+		}
+		if err := store.CreateAPIToken(&vmmodels.APIToken{ID: "tok-d", Hash: "hash-a", CreatedAt: at(2)}); err == nil {
+			t.Fatalf("expected duplicate token hashes to be rejected")
+		}
+
+		if token, err := store.GetAPITokenByHash("hash-c"); err != nil || token.ID != "tok-c" || token.WorkspaceID != "team-a" {
+			t.Fatalf("unexpected token by hash %+v (%v)", token, err)
+		}
+		if _, err := store.GetAPIToken("missing"); !errors.Is(err, vmmodels.ErrTokenNotFound) {
+			t.Fatalf("expected ErrTokenNotFound, got %v", err)
+		}
+		if token, err := store.GetAPITokenByCertSubject("CN=ci"); err != nil || token.ID != "tok-a" {
+			t.Fatalf("expected the newest live token for the subject, got %+v (%v)", token, err)
+		}
+
+		revoked, err := store.GetAPIToken("tok-a")
+		if err != nil {
+			t.Fatalf("get token: %v", err)
+		}
+		revokedAt, usedAt := at(5), at(4)
+		revoked.RevokedAt, revoked.LastUsedAt = &revokedAt, &usedAt
+		revoked.Name = "ignored"
+		if err := store.UpdateAPIToken(revoked); err != nil {
+			t.Fatalf("update token: %v", err)
+		}
+		if token, err := store.GetAPITokenByCertSubject("CN=ci"); err != nil || token.ID != "tok-b" {
+			t.Fatalf("expected revoked tokens to be skipped, got %+v (%v)", token, err)
+		}
+		tokens, err := store.ListAPITokens()
+		if err != nil || len(tokens) != 3 || tokens[0].ID != "tok-b" || tokens[1].ID != "tok-a" || tokens[2].ID != "tok-c" {
+			t.Fatalf("expected tokens by creation then id, got %+v (%v)", tokens, err)
+		}
+		if tokens[1].Name != "new cert" || !tokens[1].RevokedAt.Equal(revokedAt.Truncate(time.Second)) || !reflect.DeepEqual(tokens[1].Scopes, []string{"read", "execute"}) {
+			t.Fatalf("unexpected updated token %+v", tokens[1])
+		}
+	})
+
+	t.Run("audit", func(t *testing.T) {
+		store := open(t)
+		for i, event := range []*vmmodels.AuditEvent{
+			{RequestID: "r1", Actor: "alice", Action: "template.create", ResourceType: "template", Outcome: "success", StatusCode: 201, CreatedAt: at(0)},
+			{RequestID: "r2", Actor: "bob", Action: "session.create", ResourceType: "session", Outcome: "denied", StatusCode: 403, Details: json.RawMessage(`{"reason":"scope"}`), CreatedAt: at(10)},
+			{RequestID: "r3", Actor: "alice", Action: "session.create", ResourceType: "session", WorkspaceID: "team-a", Outcome: "success", StatusCode: 201, CreatedAt: at(20)},
+		} {
+			if err := store.AppendAuditEvent(event); err != nil {
+				t.Fatalf("append audit event %d: %v", i, err)
+			}
+			if event.ID != int64(i+1) {
+				t.Fatalf("expected audit event id %d, got %d", i+1, event.ID)
+			}
+		}
+
+		all, err := store.ListAuditEvents(vmmodels.AuditFilter{})
+		if err != nil || len(all) != 3 || all[0].RequestID != "r3" || all[2].Details != nil || string(all[1].Details) != `{"reason":"scope"}` {
+			t.Fatalf("expected audit events newest first, got %+v (%v)", all, err)
+		}
+		cases := []struct {
+			filter vmmodels.AuditFilter
+			want   []string
+		}{
+			{vmmodels.AuditFilter{Actor: "alice"}, []string{"r3", "r1"}},
+			{vmmodels.AuditFilter{Action: "session.create", Outcome: "success"}, []string{"r3"}},
+			{vmmodels.AuditFilter{WorkspaceID: "team-a"}, []string{"r3"}},
+			{vmmodels.AuditFilter{Since: at(10)}, []string{"r3", "r2"}},
+			{vmmodels.AuditFilter{Limit: 1}, []string{"r3"}},
+		}
+		for _, tc := range cases {
+			events, err := store.ListAuditEvents(tc.filter)
+			if err != nil {
+				t.Fatalf("list audit events %+v: %v", tc.filter, err)
+			}
+			var got []string
+			for _, event := range events {
+				got = append(got, event.RequestID)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("filter %+v: expected %v, got %v", tc.filter, tc.want, got)
+			}
+		}
+	})
+
+	t.Run("empty lists", func(t *testing.T) {
+		store := open(t)
+		vms, _ := store.ListVMs()
+		libraries, _ := store.ListLibraries()
+		workspaces, _ := store.ListWorkspaces()
+		tokens, _ := store.ListAPITokens()
+		events, _ := store.ListAuditEvents(vmmodels.AuditFilter{})
+		if vms != nil || libraries != nil || workspaces != nil || tokens != nil || events != nil {
+			t.Fatalf("expected nil lists from an empty store")
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package vmstore
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+// AppendAuditEvent stores an audit event and sets its ID. The table rejects
+// updates and deletes.
+func (s *VMStore) AppendAuditEvent(event *vmmodels.AuditEvent) error {
+	details := string(event.Details)
+	if details == "" {
+		details = "{}"
+	}
+	result, err := s.db.Exec(`
+		INSERT INTO audit_event (request_id, actor, token_id, workspace_id, remote_addr, action, resource_type, resource_id, outcome, status_code, error_code, details_json, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, event.RequestID, event.Actor, event.TokenID, event.WorkspaceID, event.RemoteAddr, event.Action, event.ResourceType,
+		event.ResourceID, event.Outcome, event.StatusCode, event.ErrorCode, details, event.CreatedAt.Unix())
+	if err != nil {
+		return err
+	}
+	event.ID, err = result.LastInsertId()
+	return err
+}
+
+// ListAuditEvents lists the audit events matching filter, newest first.
+func (s *VMStore) ListAuditEvents(filter vmmodels.AuditFilter) ([]*vmmodels.AuditEvent, error) {
+	var (
+		where []string
+		args  []interface{}
+	)
+	match := func(column, value string) {
+		if value != "" {
+			where = append(where, column+" = ?")
+			args = append(args, value)
+		}
+	}
+	match("action", filter.Action)
+	match("actor", filter.Actor)
+	match("resource_type", filter.ResourceType)
+	match("resource_id", filter.ResourceID)
+	match("workspace_id", filter.WorkspaceID)
+	match("outcome", filter.Outcome)
+	if !filter.Since.IsZero() {
+		where = append(where, "created_at >= ?")
+		args = append(args, filter.Since.Unix())
+	}
+
+	query := `
+		SELECT id, request_id, actor, token_id, workspace_id, remote_addr, action, resource_type, resource_id, outcome, status_code, error_code, details_json, created_at
+		FROM audit_event`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*vmmodels.AuditEvent
+	for rows.Next() {
+		event, err := scanAuditEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func scanAuditEvent(row rowScanner) (*vmmodels.AuditEvent, error) {
+	var (
+		event     vmmodels.AuditEvent
+		details   sql.NullString
+		createdAt int64
+	)
+	if err := row.Scan(&event.ID, &event.RequestID, &event.Actor, &event.TokenID, &event.WorkspaceID, &event.RemoteAddr,
+		&event.Action, &event.ResourceType, &event.ResourceID, &event.Outcome, &event.StatusCode, &event.ErrorCode,
+		&details, &createdAt); err != nil {
+		return nil, err
+	}
+	if details.Valid && details.String != "{}" {
+		event.Details = []byte(details.String)
+	}
+	event.CreatedAt = time.Unix(createdAt, 0)
+	return &event, nil
+}
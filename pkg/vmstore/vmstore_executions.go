@@ -11,9 +11,9 @@ import (
 // CreateExecution creates a new execution.
 func (s *VMStore) CreateExecution(exec *vmmodels.Execution) error {
 	_, err := s.db.Exec(`
-		INSERT INTO execution (id, session_id, kind, input, path, args_json, env_json, status, started_at, metrics_json)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, exec.ID, exec.SessionID, exec.Kind, exec.Input, exec.Path, exec.Args, exec.Env, exec.Status, exec.StartedAt.Unix(), exec.Metrics)
+		INSERT INTO execution (id, session_id, workspace_id, kind, input, path, args_json, env_json, status, started_at, metrics_json)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, exec.ID, exec.SessionID, exec.WorkspaceID, exec.Kind, exec.Input, exec.Path, exec.Args, exec.Env, exec.Status, exec.StartedAt.Unix(), exec.Metrics)
 	return err
 }
 
@@ -40,9 +40,9 @@ func (s *VMStore) GetExecution(id string) (*vmmodels.Execution, error) {
 	var result, errorJSON sql.NullString
 
 	err := s.db.QueryRow(`
-		SELECT id, session_id, kind, input, path, args_json, env_json, status, started_at, ended_at, result_json, error_json, metrics_json
+		SELECT id, session_id, workspace_id, kind, input, path, args_json, env_json, status, started_at, ended_at, result_json, error_json, metrics_json
 		FROM execution WHERE id = ?
-	`, id).Scan(&exec.ID, &exec.SessionID, &exec.Kind, &input, &path, &exec.Args, &exec.Env, &exec.Status, &startedAt, &endedAt, &result, &errorJSON, &exec.Metrics)
+	`, id).Scan(&exec.ID, &exec.SessionID, &exec.WorkspaceID, &exec.Kind, &input, &path, &exec.Args, &exec.Env, &exec.Status, &startedAt, &endedAt, &result, &errorJSON, &exec.Metrics)
 
 	if err == sql.ErrNoRows {
 		return nil, vmmodels.ErrExecutionNotFound
@@ -75,7 +75,7 @@ func (s *VMStore) GetExecution(id string) (*vmmodels.Execution, error) {
 // ListExecutions lists executions for a session.
 func (s *VMStore) ListExecutions(sessionID string, limit int) ([]*vmmodels.Execution, error) {
 	rows, err := s.db.Query(`
-		SELECT id, session_id, kind, input, path, args_json, env_json, status, started_at, ended_at, result_json, error_json, metrics_json
+		SELECT id, session_id, workspace_id, kind, input, path, args_json, env_json, status, started_at, ended_at, result_json, error_json, metrics_json
 		FROM execution WHERE session_id = ? ORDER BY started_at DESC LIMIT ?
 	`, sessionID, limit)
 	if err != nil {
@@ -91,7 +91,7 @@ func (s *VMStore) ListExecutions(sessionID string, limit int) ([]*vmmodels.Execu
 		var input, path sql.NullString
 		var result, errorJSON sql.NullString
 
-		if err := rows.Scan(&exec.ID, &exec.SessionID, &exec.Kind, &input, &path, &exec.Args, &exec.Env, &exec.Status, &startedAt, &endedAt, &result, &errorJSON, &exec.Metrics); err != nil {
+		if err := rows.Scan(&exec.ID, &exec.SessionID, &exec.WorkspaceID, &exec.Kind, &input, &path, &exec.Args, &exec.Env, &exec.Status, &startedAt, &endedAt, &result, &errorJSON, &exec.Metrics); err != nil {
 			return nil, err
 		}
 
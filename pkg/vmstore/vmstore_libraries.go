@@ -0,0 +1,100 @@
+package vmstore
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+// CreateLibrary registers a library in the catalog.
+func (s *VMStore) CreateLibrary(lib *vmmodels.Library) error {
+	configJSON, err := json.Marshal(lib.Config)
+	if err != nil {
+		return fmt.Errorf("marshal library config: %w", err)
+	}
+	dependenciesJSON, err := json.Marshal(lib.Dependencies)
+	if err != nil {
+		return fmt.Errorf("marshal library dependencies: %w", err)
+	}
+	createdAt := time.Now()
+	if lib.CreatedAt != nil {
+		createdAt = *lib.CreatedAt
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO library (ref, id, name, version, description, source, type, config_json, sha256, dependencies_json, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, lib.Ref, lib.ID, lib.Name, lib.Version, lib.Description, lib.Source, lib.Type, string(configJSON), lib.SHA256, string(dependenciesJSON), createdAt.Unix())
+	return err
+}
+
+// GetLibrary retrieves a registered library by ref.
+func (s *VMStore) GetLibrary(ref string) (*vmmodels.Library, error) {
+	lib, err := scanLibrary(s.db.QueryRow(`
+		SELECT ref, id, name, version, description, source, type, config_json, sha256, dependencies_json, created_at
+		FROM library WHERE ref = ?
+	`, ref))
+	if err == sql.ErrNoRows {
+		return nil, vmmodels.ErrLibraryNotFound
+	}
+	return lib, err
+}
+
+// ListLibraries lists registered libraries ordered by ref.
+func (s *VMStore) ListLibraries() ([]*vmmodels.Library, error) {
+	rows, err := s.db.Query(`
+		SELECT ref, id, name, version, description, source, type, config_json, sha256, dependencies_json, created_at
+		FROM library ORDER BY ref
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var libraries []*vmmodels.Library
+	for rows.Next() {
+		lib, err := scanLibrary(rows)
+		if err != nil {
+			return nil, err
+		}
+		libraries = append(libraries, lib)
+	}
+
+	return libraries, rows.Err()
+}
+
+// DeleteLibrary removes a registered library.
+func (s *VMStore) DeleteLibrary(ref string) error {
+	result, err := s.db.Exec("DELETE FROM library WHERE ref = ?", ref)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return vmmodels.ErrLibraryNotFound
+	}
+	return nil
+}
+
+func scanLibrary(row rowScanner) (*vmmodels.Library, error) {
+	var lib vmmodels.Library
+	var configJSON, dependenciesJSON string
+	var createdAt int64
+	if err := row.Scan(&lib.Ref, &lib.ID, &lib.Name, &lib.Version, &lib.Description, &lib.Source, &lib.Type, &configJSON, &lib.SHA256, &dependenciesJSON, &createdAt); err != nil {
+		return nil, err
+	}
+	created := time.Unix(createdAt, 0)
+	lib.CreatedAt = &created
+	if err := json.Unmarshal([]byte(configJSON), &lib.Config); err != nil {
+		return nil, fmt.Errorf("unmarshal library %s config: %w", lib.Ref, err)
+	}
+	if err := json.Unmarshal([]byte(dependenciesJSON), &lib.Dependencies); err != nil {
+		return nil, fmt.Errorf("unmarshal library %s dependencies: %w", lib.Ref, err)
+	}
+	return &lib, nil
+}
@@ -1,5 +1,7 @@
 package vmstore
 
+import "fmt"
+
 // initSchema creates the database schema.
 func (s *VMStore) initSchema() error {
 	schema := `
@@ -20,7 +22,9 @@ func (s *VMStore) initSchema() error {
 		vm_id TEXT PRIMARY KEY REFERENCES vm(id) ON DELETE CASCADE,
 		limits_json TEXT NOT NULL,
 		resolver_json TEXT NOT NULL,
-		runtime_json TEXT NOT NULL
+		runtime_json TEXT NOT NULL,
+		pool_json TEXT NOT NULL DEFAULT '{}',
+		lifecycle_json TEXT NOT NULL DEFAULT '{}'
 	);
 
 	-- VM capabilities (module exposure allowlist)
@@ -38,7 +42,9 @@ func (s *VMStore) initSchema() error {
 	CREATE TABLE IF NOT EXISTS vm_startup_file (
 		id TEXT PRIMARY KEY,
 		vm_id TEXT NOT NULL REFERENCES vm(id) ON DELETE CASCADE,
+		kind TEXT NOT NULL DEFAULT 'path',
 		path TEXT NOT NULL,
+		source TEXT NOT NULL DEFAULT '',
 		order_index INTEGER NOT NULL,
 		mode TEXT NOT NULL,
 		UNIQUE(vm_id, path)
@@ -46,6 +52,79 @@ func (s *VMStore) initSchema() error {
 
 	CREATE INDEX IF NOT EXISTS idx_startup_file_order ON vm_startup_file(vm_id, order_index);
 
+	-- Immutable template revisions
+	CREATE TABLE IF NOT EXISTS vm_revision (
+		vm_id TEXT NOT NULL REFERENCES vm(id) ON DELETE CASCADE,
+		revision INTEGER NOT NULL,
+		spec_json TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		PRIMARY KEY(vm_id, revision)
+	);
+
+	-- User-registered libraries
+	CREATE TABLE IF NOT EXISTS library (
+		ref TEXT PRIMARY KEY,
+		id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		version TEXT NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		source TEXT NOT NULL DEFAULT '',
+		type TEXT NOT NULL,
+		config_json TEXT NOT NULL DEFAULT '{}',
+		sha256 TEXT NOT NULL DEFAULT '',
+		dependencies_json TEXT NOT NULL DEFAULT '[]',
+		created_at INTEGER NOT NULL
+	);
+
+	-- Workspaces owning templates, sessions and executions
+	CREATE TABLE IF NOT EXISTS workspace (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		created_at INTEGER NOT NULL
+	);
+
+	-- API tokens; only the SHA-256 of each secret is kept
+	CREATE TABLE IF NOT EXISTS api_token (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		token_hash TEXT UNIQUE NOT NULL,
+		scopes_json TEXT NOT NULL DEFAULT '[]',
+		created_at INTEGER NOT NULL,
+		last_used_at INTEGER,
+		revoked_at INTEGER
+	);
+
+	-- Append-only audit trail of control-plane and execution actions
+	CREATE TABLE IF NOT EXISTS audit_event (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		request_id TEXT NOT NULL,
+		actor TEXT NOT NULL,
+		token_id TEXT NOT NULL DEFAULT '',
+		workspace_id TEXT NOT NULL DEFAULT '',
+		remote_addr TEXT NOT NULL DEFAULT '',
+		action TEXT NOT NULL,
+		resource_type TEXT NOT NULL,
+		resource_id TEXT NOT NULL DEFAULT '',
+		outcome TEXT NOT NULL,
+		status_code INTEGER NOT NULL,
+		error_code TEXT NOT NULL DEFAULT '',
+		details_json TEXT NOT NULL DEFAULT '{}',
+		created_at INTEGER NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_audit_event_created ON audit_event(created_at);
+	CREATE INDEX IF NOT EXISTS idx_audit_event_resource ON audit_event(resource_id);
+
+	CREATE TRIGGER IF NOT EXISTS audit_event_no_update BEFORE UPDATE ON audit_event
+	BEGIN
+		SELECT RAISE(ABORT, 'audit_event is append-only');
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS audit_event_no_delete BEFORE DELETE ON audit_event
+	BEGIN
+		SELECT RAISE(ABORT, 'audit_event is append-only');
+	END;
+
 	-- VM sessions
 	CREATE TABLE IF NOT EXISTS vm_session (
 		id TEXT PRIMARY KEY,
@@ -53,8 +132,10 @@ func (s *VMStore) initSchema() error {
 		workspace_id TEXT NOT NULL,
 		base_commit_oid TEXT NOT NULL,
 		worktree_path TEXT NOT NULL,
+		template_revision INTEGER NOT NULL DEFAULT 0,
 		status TEXT NOT NULL,
 		created_at INTEGER NOT NULL,
+		last_activity_at INTEGER NOT NULL DEFAULT 0,
 		closed_at INTEGER,
 		last_error_json TEXT,
 		runtime_meta_json TEXT
@@ -90,6 +171,76 @@ func (s *VMStore) initSchema() error {
 	);
 	`
 
-	_, err := s.db.Exec(schema)
+	if _, err := s.db.Exec(schema); err != nil {
+		return err
+	}
+
+	// Columns added after the initial schema; CREATE TABLE IF NOT EXISTS does
+	// not add them to existing databases.
+	columns := []struct{ table, column, definition string }{
+		{"vm_session", "template_revision", "INTEGER NOT NULL DEFAULT 0"},
+		{"vm_startup_file", "kind", "TEXT NOT NULL DEFAULT 'path'"},
+		{"vm_startup_file", "source", "TEXT NOT NULL DEFAULT ''"},
+		{"library", "sha256", "TEXT NOT NULL DEFAULT ''"},
+		{"library", "dependencies_json", "TEXT NOT NULL DEFAULT '[]'"},
+		{"vm_settings", "pool_json", "TEXT NOT NULL DEFAULT '{}'"},
+		{"vm_settings", "lifecycle_json", "TEXT NOT NULL DEFAULT '{}'"},
+		{"vm_session", "last_activity_at", "INTEGER NOT NULL DEFAULT 0"},
+		{"vm", "workspace_id", "TEXT NOT NULL DEFAULT ''"},
+		{"execution", "workspace_id", "TEXT NOT NULL DEFAULT ''"},
+		{"api_token", "workspace_id", "TEXT NOT NULL DEFAULT ''"},
+		{"api_token", "cert_subject", "TEXT NOT NULL DEFAULT ''"},
+	}
+	for _, c := range columns {
+		if err := s.addColumnIfMissing(c.table, c.column, c.definition); err != nil {
+			return err
+		}
+	}
+
+	// Sessions predating workspaces name theirs in a free-form workspace_id;
+	// register those workspaces and hand their executions to them.
+	backfill := `
+	INSERT OR IGNORE INTO workspace (id, name, created_at)
+		SELECT workspace_id, workspace_id, MIN(created_at) FROM vm_session WHERE workspace_id != '' GROUP BY workspace_id;
+	UPDATE execution SET workspace_id = (SELECT workspace_id FROM vm_session WHERE vm_session.id = execution.session_id)
+		WHERE workspace_id = '';
+	CREATE INDEX IF NOT EXISTS idx_vm_session_workspace ON vm_session(workspace_id);
+	CREATE INDEX IF NOT EXISTS idx_api_token_cert_subject ON api_token(cert_subject);
+	`
+	if _, err := s.db.Exec(backfill); err != nil {
+		return err
+	}
+	return nil
+}
+
+// addColumnIfMissing adds column to table unless it already exists.
+func (s *VMStore) addColumnIfMissing(table, column, definition string) error {
+	rows, err := s.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue interface{}
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
 	return err
 }
@@ -0,0 +1,77 @@
+package vmstore
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+// CreateTemplateRevision records an immutable template revision.
+func (s *VMStore) CreateTemplateRevision(rev *vmmodels.TemplateRevision) error {
+	specJSON, err := json.Marshal(rev.Spec)
+	if err != nil {
+		return fmt.Errorf("marshal template revision spec: %w", err)
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO vm_revision (vm_id, revision, spec_json, created_at)
+		VALUES (?, ?, ?, ?)
+	`, rev.VMID, rev.Revision, string(specJSON), rev.CreatedAt.Unix())
+	return err
+}
+
+// ListTemplateRevisions lists revisions for a VM, oldest first.
+func (s *VMStore) ListTemplateRevisions(vmID string) ([]*vmmodels.TemplateRevision, error) {
+	rows, err := s.db.Query(`
+		SELECT vm_id, revision, spec_json, created_at
+		FROM vm_revision WHERE vm_id = ? ORDER BY revision
+	`, vmID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []*vmmodels.TemplateRevision
+	for rows.Next() {
+		rev, err := scanTemplateRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+
+	return revisions, rows.Err()
+}
+
+// GetTemplateRevision retrieves one revision of a VM.
+func (s *VMStore) GetTemplateRevision(vmID string, revision int) (*vmmodels.TemplateRevision, error) {
+	rev, err := scanTemplateRevision(s.db.QueryRow(`
+		SELECT vm_id, revision, spec_json, created_at
+		FROM vm_revision WHERE vm_id = ? AND revision = ?
+	`, vmID, revision))
+	if err == sql.ErrNoRows {
+		return nil, vmmodels.ErrTemplateRevisionNotFound
+	}
+	return rev, err
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTemplateRevision(row rowScanner) (*vmmodels.TemplateRevision, error) {
+	var rev vmmodels.TemplateRevision
+	var specJSON string
+	var createdAt int64
+	if err := row.Scan(&rev.VMID, &rev.Revision, &specJSON, &createdAt); err != nil {
+		return nil, err
+	}
+	rev.CreatedAt = time.Unix(createdAt, 0)
+	rev.Spec = &vmmodels.TemplateSpec{}
+	if err := json.Unmarshal([]byte(specJSON), rev.Spec); err != nil {
+		return nil, fmt.Errorf("unmarshal template revision %d spec: %w", rev.Revision, err)
+	}
+	return &rev, nil
+}
@@ -11,24 +11,24 @@ import (
 // CreateSession creates a new VM session.
 func (s *VMStore) CreateSession(session *vmmodels.VMSession) error {
 	_, err := s.db.Exec(`
-		INSERT INTO vm_session (id, vm_id, workspace_id, base_commit_oid, worktree_path, status, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
-	`, session.ID, session.VMID, session.WorkspaceID, session.BaseCommitOID, session.WorktreePath, session.Status, session.CreatedAt.Unix())
+		INSERT INTO vm_session (id, vm_id, workspace_id, base_commit_oid, worktree_path, template_revision, status, created_at, last_activity_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, session.ID, session.VMID, session.WorkspaceID, session.BaseCommitOID, session.WorktreePath, session.TemplateRevision, session.Status, session.CreatedAt.Unix(), lastActivityUnix(session))
 	return err
 }
 
 // GetSession retrieves a session by ID.
 func (s *VMStore) GetSession(id string) (*vmmodels.VMSession, error) {
 	var session vmmodels.VMSession
-	var createdAt int64
+	var createdAt, lastActivityAt int64
 	var closedAt sql.NullInt64
 	var lastError sql.NullString
 	var runtimeMeta sql.NullString
 
 	err := s.db.QueryRow(`
-		SELECT id, vm_id, workspace_id, base_commit_oid, worktree_path, status, created_at, closed_at, last_error_json, runtime_meta_json
+		SELECT id, vm_id, workspace_id, base_commit_oid, worktree_path, template_revision, status, created_at, last_activity_at, closed_at, last_error_json, runtime_meta_json
 		FROM vm_session WHERE id = ?
-	`, id).Scan(&session.ID, &session.VMID, &session.WorkspaceID, &session.BaseCommitOID, &session.WorktreePath, &session.Status, &createdAt, &closedAt, &lastError, &runtimeMeta)
+	`, id).Scan(&session.ID, &session.VMID, &session.WorkspaceID, &session.BaseCommitOID, &session.WorktreePath, &session.TemplateRevision, &session.Status, &createdAt, &lastActivityAt, &closedAt, &lastError, &runtimeMeta)
 
 	if err == sql.ErrNoRows {
 		return nil, vmmodels.ErrSessionNotFound
@@ -38,6 +38,7 @@ func (s *VMStore) GetSession(id string) (*vmmodels.VMSession, error) {
 	}
 
 	session.CreatedAt = time.Unix(createdAt, 0)
+	session.LastActivityAt = activityTime(createdAt, lastActivityAt)
 	if closedAt.Valid {
 		t := time.Unix(closedAt.Int64, 0)
 		session.ClosedAt = &t
@@ -66,9 +67,15 @@ func (s *VMStore) UpdateSession(session *vmmodels.VMSession) error {
 	return err
 }
 
+// TouchSession records activity on a session.
+func (s *VMStore) TouchSession(id string, at time.Time) error {
+	_, err := s.db.Exec("UPDATE vm_session SET last_activity_at = ? WHERE id = ?", at.Unix(), id)
+	return err
+}
+
 // ListSessions lists sessions with optional status filter.
 func (s *VMStore) ListSessions(status string) ([]*vmmodels.VMSession, error) {
-	query := "SELECT id, vm_id, workspace_id, base_commit_oid, worktree_path, status, created_at, closed_at, last_error_json, runtime_meta_json FROM vm_session"
+	query := "SELECT id, vm_id, workspace_id, base_commit_oid, worktree_path, template_revision, status, created_at, last_activity_at, closed_at, last_error_json, runtime_meta_json FROM vm_session"
 	args := []interface{}{}
 
 	if status != "" {
@@ -87,16 +94,17 @@ func (s *VMStore) ListSessions(status string) ([]*vmmodels.VMSession, error) {
 	var sessions []*vmmodels.VMSession
 	for rows.Next() {
 		var session vmmodels.VMSession
-		var createdAt int64
+		var createdAt, lastActivityAt int64
 		var closedAt sql.NullInt64
 		var lastError sql.NullString
 		var runtimeMeta sql.NullString
 
-		if err := rows.Scan(&session.ID, &session.VMID, &session.WorkspaceID, &session.BaseCommitOID, &session.WorktreePath, &session.Status, &createdAt, &closedAt, &lastError, &runtimeMeta); err != nil {
+		if err := rows.Scan(&session.ID, &session.VMID, &session.WorkspaceID, &session.BaseCommitOID, &session.WorktreePath, &session.TemplateRevision, &session.Status, &createdAt, &lastActivityAt, &closedAt, &lastError, &runtimeMeta); err != nil {
 			return nil, err
 		}
 
 		session.CreatedAt = time.Unix(createdAt, 0)
+		session.LastActivityAt = activityTime(createdAt, lastActivityAt)
 		if closedAt.Valid {
 			t := time.Unix(closedAt.Int64, 0)
 			session.ClosedAt = &t
@@ -113,3 +121,19 @@ func (s *VMStore) ListSessions(status string) ([]*vmmodels.VMSession, error) {
 
 	return sessions, rows.Err()
 }
+
+func lastActivityUnix(session *vmmodels.VMSession) int64 {
+	if session.LastActivityAt.IsZero() {
+		return session.CreatedAt.Unix()
+	}
+	return session.LastActivityAt.Unix()
+}
+
+// activityTime falls back to the creation time for rows written before
+// last_activity_at existed.
+func activityTime(createdAt, lastActivityAt int64) time.Time {
+	if lastActivityAt == 0 {
+		return time.Unix(createdAt, 0)
+	}
+	return time.Unix(lastActivityAt, 0)
+}
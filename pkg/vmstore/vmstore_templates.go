@@ -11,9 +11,9 @@ import (
 // CreateVM creates a new VM profile.
 func (s *VMStore) CreateVM(vm *vmmodels.VM) error {
 	_, err := s.db.Exec(`
-		INSERT INTO vm (id, name, engine, is_active, exposed_modules_json, libraries_json, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-	`, vm.ID, vm.Name, vm.Engine, vm.IsActive, string(vmmodels.MarshalJSONWithFallback(vm.ExposedModules, json.RawMessage("[]"))), string(vmmodels.MarshalJSONWithFallback(vm.Libraries, json.RawMessage("[]"))), vm.CreatedAt.Unix(), vm.UpdatedAt.Unix())
+		INSERT INTO vm (id, name, engine, is_active, exposed_modules_json, libraries_json, created_at, updated_at, workspace_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, vm.ID, vm.Name, vm.Engine, vm.IsActive, string(vmmodels.MarshalJSONWithFallback(vm.ExposedModules, json.RawMessage("[]"))), string(vmmodels.MarshalJSONWithFallback(vm.Libraries, json.RawMessage("[]"))), vm.CreatedAt.Unix(), vm.UpdatedAt.Unix(), vm.WorkspaceID)
 	return err
 }
 
@@ -24,9 +24,10 @@ func (s *VMStore) GetVM(id string) (*vmmodels.VM, error) {
 	var exposedModulesJSON, librariesJSON string
 
 	err := s.db.QueryRow(`
-		SELECT id, name, engine, is_active, exposed_modules_json, libraries_json, created_at, updated_at
+		SELECT id, name, engine, is_active, exposed_modules_json, libraries_json, created_at, updated_at, workspace_id,
+			(SELECT COALESCE(MAX(revision), 0) FROM vm_revision WHERE vm_revision.vm_id = vm.id)
 		FROM vm WHERE id = ?
-	`, id).Scan(&vm.ID, &vm.Name, &vm.Engine, &vm.IsActive, &exposedModulesJSON, &librariesJSON, &createdAt, &updatedAt)
+	`, id).Scan(&vm.ID, &vm.Name, &vm.Engine, &vm.IsActive, &exposedModulesJSON, &librariesJSON, &createdAt, &updatedAt, &vm.WorkspaceID, &vm.Revision)
 
 	if err == sql.ErrNoRows {
 		return nil, vmmodels.ErrVMNotFound
@@ -48,7 +49,8 @@ func (s *VMStore) GetVM(id string) (*vmmodels.VM, error) {
 // ListVMs lists all VMs.
 func (s *VMStore) ListVMs() ([]*vmmodels.VM, error) {
 	rows, err := s.db.Query(`
-		SELECT id, name, engine, is_active, exposed_modules_json, libraries_json, created_at, updated_at
+		SELECT id, name, engine, is_active, exposed_modules_json, libraries_json, created_at, updated_at, workspace_id,
+			(SELECT COALESCE(MAX(revision), 0) FROM vm_revision WHERE vm_revision.vm_id = vm.id)
 		FROM vm ORDER BY created_at DESC
 	`)
 	if err != nil {
@@ -62,7 +64,7 @@ func (s *VMStore) ListVMs() ([]*vmmodels.VM, error) {
 		var createdAt, updatedAt int64
 
 		var exposedModulesJSON, librariesJSON string
-		if err := rows.Scan(&vm.ID, &vm.Name, &vm.Engine, &vm.IsActive, &exposedModulesJSON, &librariesJSON, &createdAt, &updatedAt); err != nil {
+		if err := rows.Scan(&vm.ID, &vm.Name, &vm.Engine, &vm.IsActive, &exposedModulesJSON, &librariesJSON, &createdAt, &updatedAt, &vm.WorkspaceID, &vm.Revision); err != nil {
 			return nil, err
 		}
 
@@ -94,10 +96,18 @@ func (s *VMStore) DeleteVM(id string) error {
 
 // SetVMSettings sets VM settings.
 func (s *VMStore) SetVMSettings(settings *vmmodels.VMSettings) error {
+	pool := settings.Pool
+	if len(pool) == 0 {
+		pool = json.RawMessage("{}")
+	}
+	lifecycle := settings.Lifecycle
+	if len(lifecycle) == 0 {
+		lifecycle = json.RawMessage("{}")
+	}
 	_, err := s.db.Exec(`
-		INSERT OR REPLACE INTO vm_settings (vm_id, limits_json, resolver_json, runtime_json)
-		VALUES (?, ?, ?, ?)
-	`, settings.VMID, settings.Limits, settings.Resolver, settings.Runtime)
+		INSERT OR REPLACE INTO vm_settings (vm_id, limits_json, resolver_json, runtime_json, pool_json, lifecycle_json)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, settings.VMID, settings.Limits, settings.Resolver, settings.Runtime, pool, lifecycle)
 	return err
 }
 
@@ -105,9 +115,9 @@ func (s *VMStore) SetVMSettings(settings *vmmodels.VMSettings) error {
 func (s *VMStore) GetVMSettings(vmID string) (*vmmodels.VMSettings, error) {
 	var settings vmmodels.VMSettings
 	err := s.db.QueryRow(`
-		SELECT vm_id, limits_json, resolver_json, runtime_json
+		SELECT vm_id, limits_json, resolver_json, runtime_json, pool_json, lifecycle_json
 		FROM vm_settings WHERE vm_id = ?
-	`, vmID).Scan(&settings.VMID, &settings.Limits, &settings.Resolver, &settings.Runtime)
+	`, vmID).Scan(&settings.VMID, &settings.Limits, &settings.Resolver, &settings.Runtime, &settings.Pool, &settings.Lifecycle)
 
 	if err == sql.ErrNoRows {
 		return nil, vmmodels.ErrVMNotFound
@@ -170,16 +180,16 @@ func (s *VMStore) DeleteCapability(id string) error {
 // AddStartupFile adds a startup file to a VM.
 func (s *VMStore) AddStartupFile(file *vmmodels.VMStartupFile) error {
 	_, err := s.db.Exec(`
-		INSERT INTO vm_startup_file (id, vm_id, path, order_index, mode)
-		VALUES (?, ?, ?, ?, ?)
-	`, file.ID, file.VMID, file.Path, file.OrderIndex, file.Mode)
+		INSERT INTO vm_startup_file (id, vm_id, kind, path, source, order_index, mode)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, file.ID, file.VMID, startupKindOrDefault(file.Kind), file.Path, file.Source, file.OrderIndex, file.Mode)
 	return err
 }
 
 // ListStartupFiles lists all startup files for a VM.
 func (s *VMStore) ListStartupFiles(vmID string) ([]*vmmodels.VMStartupFile, error) {
 	rows, err := s.db.Query(`
-		SELECT id, vm_id, path, order_index, mode
+		SELECT id, vm_id, kind, path, source, order_index, mode
 		FROM vm_startup_file WHERE vm_id = ? ORDER BY order_index
 	`, vmID)
 	if err != nil {
@@ -190,7 +200,7 @@ func (s *VMStore) ListStartupFiles(vmID string) ([]*vmmodels.VMStartupFile, erro
 	var files []*vmmodels.VMStartupFile
 	for rows.Next() {
 		var file vmmodels.VMStartupFile
-		if err := rows.Scan(&file.ID, &file.VMID, &file.Path, &file.OrderIndex, &file.Mode); err != nil {
+		if err := rows.Scan(&file.ID, &file.VMID, &file.Kind, &file.Path, &file.Source, &file.OrderIndex, &file.Mode); err != nil {
 			return nil, err
 		}
 		files = append(files, &file)
@@ -199,6 +209,22 @@ func (s *VMStore) ListStartupFiles(vmID string) ([]*vmmodels.VMStartupFile, erro
 	return files, rows.Err()
 }
 
+// UpdateStartupFile updates a startup file's path, source, order, and mode.
+func (s *VMStore) UpdateStartupFile(file *vmmodels.VMStartupFile) error {
+	_, err := s.db.Exec(`
+		UPDATE vm_startup_file SET path = ?, source = ?, order_index = ?, mode = ?
+		WHERE id = ?
+	`, file.Path, file.Source, file.OrderIndex, file.Mode, file.ID)
+	return err
+}
+
+func startupKindOrDefault(kind string) string {
+	if kind == "" {
+		return vmmodels.StartupKindPath
+	}
+	return kind
+}
+
 // DeleteStartupFile deletes a startup file.
 func (s *VMStore) DeleteStartupFile(id string) error {
 	_, err := s.db.Exec("DELETE FROM vm_startup_file WHERE id = ?", id)
@@ -0,0 +1,123 @@
+package vmstore
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+// CreateAPIToken stores a new API token.
+func (s *VMStore) CreateAPIToken(token *vmmodels.APIToken) error {
+	scopesJSON, err := json.Marshal(token.Scopes)
+	if err != nil {
+		return fmt.Errorf("marshal token scopes: %w", err)
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO api_token (id, name, token_hash, scopes_json, workspace_id, cert_subject, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, token.ID, token.Name, token.Hash, string(scopesJSON), token.WorkspaceID, token.CertSubject, token.CreatedAt.Unix())
+	return err
+}
+
+// GetAPIToken retrieves a token by ID.
+func (s *VMStore) GetAPIToken(id string) (*vmmodels.APIToken, error) {
+	token, err := scanAPIToken(s.db.QueryRow(`
+		SELECT id, name, token_hash, scopes_json, workspace_id, cert_subject, created_at, last_used_at, revoked_at
+		FROM api_token WHERE id = ?
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, vmmodels.ErrTokenNotFound
+	}
+	return token, err
+}
+
+// GetAPITokenByHash retrieves a token by the SHA-256 of its secret.
+func (s *VMStore) GetAPITokenByHash(hash string) (*vmmodels.APIToken, error) {
+	token, err := scanAPIToken(s.db.QueryRow(`
+		SELECT id, name, token_hash, scopes_json, workspace_id, cert_subject, created_at, last_used_at, revoked_at
+		FROM api_token WHERE token_hash = ?
+	`, hash))
+	if err == sql.ErrNoRows {
+		return nil, vmmodels.ErrTokenNotFound
+	}
+	return token, err
+}
+
+// GetAPITokenByCertSubject retrieves the live token bound to a client
+// certificate subject.
+func (s *VMStore) GetAPITokenByCertSubject(subject string) (*vmmodels.APIToken, error) {
+	token, err := scanAPIToken(s.db.QueryRow(`
+		SELECT id, name, token_hash, scopes_json, workspace_id, cert_subject, created_at, last_used_at, revoked_at
+		FROM api_token WHERE cert_subject = ? AND revoked_at IS NULL
+		ORDER BY created_at DESC LIMIT 1
+	`, subject))
+	if err == sql.ErrNoRows {
+		return nil, vmmodels.ErrTokenNotFound
+	}
+	return token, err
+}
+
+// ListAPITokens lists tokens, oldest first, including revoked ones.
+func (s *VMStore) ListAPITokens() ([]*vmmodels.APIToken, error) {
+	rows, err := s.db.Query(`
+		SELECT id, name, token_hash, scopes_json, workspace_id, cert_subject, created_at, last_used_at, revoked_at
+		FROM api_token ORDER BY created_at, id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []*vmmodels.APIToken
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// UpdateAPIToken persists a token's last use and revocation times.
+func (s *VMStore) UpdateAPIToken(token *vmmodels.APIToken) error {
+	var lastUsedAt, revokedAt interface{}
+	if token.LastUsedAt != nil {
+		lastUsedAt = token.LastUsedAt.Unix()
+	}
+	if token.RevokedAt != nil {
+		revokedAt = token.RevokedAt.Unix()
+	}
+	_, err := s.db.Exec(`
+		UPDATE api_token SET last_used_at = ?, revoked_at = ? WHERE id = ?
+	`, lastUsedAt, revokedAt, token.ID)
+	return err
+}
+
+func scanAPIToken(row rowScanner) (*vmmodels.APIToken, error) {
+	var token vmmodels.APIToken
+	var scopesJSON string
+	var createdAt int64
+	var lastUsedAt, revokedAt sql.NullInt64
+
+	if err := row.Scan(&token.ID, &token.Name, &token.Hash, &scopesJSON, &token.WorkspaceID, &token.CertSubject, &createdAt, &lastUsedAt, &revokedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(scopesJSON), &token.Scopes); err != nil {
+		return nil, fmt.Errorf("decode token scopes: %w", err)
+	}
+	token.CreatedAt = time.Unix(createdAt, 0)
+	if lastUsedAt.Valid {
+		t := time.Unix(lastUsedAt.Int64, 0)
+		token.LastUsedAt = &t
+	}
+	if revokedAt.Valid {
+		t := time.Unix(revokedAt.Int64, 0)
+		token.RevokedAt = &t
+	}
+	return &token, nil
+}
@@ -0,0 +1,64 @@
+package vmstore
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+// CreateWorkspace stores a new workspace.
+func (s *VMStore) CreateWorkspace(workspace *vmmodels.Workspace) error {
+	_, err := s.db.Exec(`
+		INSERT INTO workspace (id, name, created_at) VALUES (?, ?, ?)
+	`, workspace.ID, workspace.Name, workspace.CreatedAt.Unix())
+	return err
+}
+
+// EnsureWorkspace registers a workspace named after its ID unless it exists.
+func (s *VMStore) EnsureWorkspace(id string) error {
+	_, err := s.db.Exec(`
+		INSERT OR IGNORE INTO workspace (id, name, created_at) VALUES (?, ?, ?)
+	`, id, id, time.Now().Unix())
+	return err
+}
+
+// GetWorkspace retrieves a workspace by ID.
+func (s *VMStore) GetWorkspace(id string) (*vmmodels.Workspace, error) {
+	workspace, err := scanWorkspace(s.db.QueryRow(`
+		SELECT id, name, created_at FROM workspace WHERE id = ?
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, vmmodels.ErrWorkspaceNotFound
+	}
+	return workspace, err
+}
+
+// ListWorkspaces lists workspaces by ID.
+func (s *VMStore) ListWorkspaces() ([]*vmmodels.Workspace, error) {
+	rows, err := s.db.Query(`SELECT id, name, created_at FROM workspace ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var workspaces []*vmmodels.Workspace
+	for rows.Next() {
+		workspace, err := scanWorkspace(rows)
+		if err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, workspace)
+	}
+	return workspaces, rows.Err()
+}
+
+func scanWorkspace(row rowScanner) (*vmmodels.Workspace, error) {
+	var workspace vmmodels.Workspace
+	var createdAt int64
+	if err := row.Scan(&workspace.ID, &workspace.Name, &createdAt); err != nil {
+		return nil, err
+	}
+	workspace.CreatedAt = time.Unix(createdAt, 0)
+	return &workspace, nil
+}
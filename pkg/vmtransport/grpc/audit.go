@@ -0,0 +1,158 @@
+package vmgrpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stdhttp "net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+// interceptor gives every call a request ID, authenticates it when auth is
+// required, and records mutating calls in the audit trail.
+type interceptor struct {
+	core        *vmcontrol.Core
+	requireAuth bool
+}
+
+type auditNoteKey struct{}
+
+// auditNote collects what a method knows about the resource it acted on.
+type auditNote struct {
+	resourceID  string
+	workspaceID string
+	details     map[string]interface{}
+}
+
+// auditResource records the ID and workspace of the resource a method
+// created or acted on.
+func auditResource(ctx context.Context, resourceID, workspaceID string) {
+	if note, ok := ctx.Value(auditNoteKey{}).(*auditNote); ok {
+		note.resourceID = resourceID
+		note.workspaceID = workspaceID
+	}
+}
+
+// auditDetail adds key to the audit event's details.
+func auditDetail(ctx context.Context, key string, value interface{}) {
+	if note, ok := ctx.Value(auditNoteKey{}).(*auditNote); ok {
+		note.details[key] = value
+	}
+}
+
+// auditHash returns the hex SHA-256 of s, so audit events can identify
+// execution input without storing it.
+func auditHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func (i *interceptor) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	requestID := uuid.NewString()
+	_ = grpc.SetHeader(ctx, metadata.Pairs("x-request-id", requestID))
+	ctx, note, err := i.begin(ctx, requestID, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := handler(ctx, req)
+	i.record(ctx, requestID, info.FullMethod, note, err)
+	return resp, err
+}
+
+func (i *interceptor) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	requestID := uuid.NewString()
+	_ = ss.SetHeader(metadata.Pairs("x-request-id", requestID))
+	ctx, note, err := i.begin(ss.Context(), requestID, info.FullMethod)
+	if err != nil {
+		return err
+	}
+	err = handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	i.record(ctx, requestID, info.FullMethod, note, err)
+	return err
+}
+
+// begin authenticates a call and attaches its audit note. A mutating call
+// rejected here is audited as a failure, as REST requests rejected by
+// authentication are.
+func (i *interceptor) begin(ctx context.Context, requestID, method string) (context.Context, *auditNote, error) {
+	note := &auditNote{details: map[string]interface{}{}}
+	if i.requireAuth {
+		var err error
+		ctx, err = authenticate(ctx, i.core.Auth, policyFor(method).scope)
+		if err != nil {
+			i.record(ctx, requestID, method, note, err)
+			return ctx, nil, err
+		}
+	}
+	return context.WithValue(ctx, auditNoteKey{}, note), note, nil
+}
+
+// record appends the audit event of a mutating call.
+func (i *interceptor) record(ctx context.Context, requestID, method string, note *auditNote, callErr error) {
+	action := policyFor(method).action
+	if action == "" {
+		return
+	}
+	resourceType, _, _ := strings.Cut(action, ".")
+	event := &vmmodels.AuditEvent{
+		RequestID:    requestID,
+		Actor:        "anonymous",
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   note.resourceID,
+		WorkspaceID:  note.workspaceID,
+		Outcome:      vmmodels.AuditOutcomeSuccess,
+		StatusCode:   stdhttp.StatusOK,
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		event.RemoteAddr = p.Addr.String()
+	}
+	if subject, ok := clientCertSubject(ctx); ok {
+		event.Actor = "cert:" + subject
+	}
+	if token, ok := vmcontrol.TokenFromContext(ctx); ok {
+		event.Actor = "token:" + token.Name
+		event.TokenID = token.ID
+		if token.WorkspaceID != "" {
+			event.WorkspaceID = token.WorkspaceID
+		}
+	}
+	if callErr != nil {
+		event.Outcome = vmmodels.AuditOutcomeFailure
+		event.StatusCode = httpStatusOf(callErr)
+		event.ErrorCode = ErrorCode(callErr)
+	}
+	if len(note.details) > 0 {
+		if data, err := json.Marshal(note.details); err == nil {
+			event.Details = data
+		}
+	}
+
+	if err := i.core.Audit.Record(ctx, event); err != nil {
+		log.Warn().Err(err).
+			Str("request_id", requestID).
+			Str("action", action).
+			Msg("failed to record audit event")
+	}
+}
+
+// contextStream is a ServerStream whose context carries what the
+// interceptor attached.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,123 @@
+package vmgrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	stdhttp "net/http"
+	"strings"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+	"github.com/go-go-golems/vm-system/pkg/vmtransport/grpc/vmsystemv1"
+)
+
+// methodPolicy is the token scope a method needs and, for mutating methods,
+// the audit action it is recorded under.
+type methodPolicy struct {
+	scope  string
+	action string
+}
+
+// methodPolicies mirrors the scopes and audit actions of the REST routes.
+// Methods not listed are reads.
+var methodPolicies = map[string]methodPolicy{
+	vmsystemv1.TemplateService_CreateTemplate_FullMethodName:   {vmmodels.ScopeTemplatesWrite, "template.create"},
+	vmsystemv1.TemplateService_DeleteTemplate_FullMethodName:   {vmmodels.ScopeTemplatesWrite, "template.delete"},
+	vmsystemv1.SessionService_CreateSession_FullMethodName:     {vmmodels.ScopeSessionsCreate, "session.create"},
+	vmsystemv1.SessionService_CloseSession_FullMethodName:      {vmmodels.ScopeSessionsCreate, "session.close"},
+	vmsystemv1.ExecutionService_ExecuteREPL_FullMethodName:     {vmmodels.ScopeExecutionsRun, "execution.repl"},
+	vmsystemv1.ExecutionService_StreamREPL_FullMethodName:      {vmmodels.ScopeExecutionsRun, "execution.repl"},
+	vmsystemv1.ExecutionService_ExecuteRunFile_FullMethodName:  {vmmodels.ScopeExecutionsRun, "execution.run_file"},
+	vmsystemv1.ExecutionService_StreamRunFile_FullMethodName:   {vmmodels.ScopeExecutionsRun, "execution.run_file"},
+	vmsystemv1.ExecutionService_CancelExecution_FullMethodName: {vmmodels.ScopeExecutionsRun, "execution.cancel"},
+}
+
+func policyFor(method string) methodPolicy {
+	if policy, ok := methodPolicies[method]; ok {
+		return policy
+	}
+	return methodPolicy{scope: vmmodels.ScopeRead}
+}
+
+// authenticate resolves the caller's token from the authorization metadata
+// or, failing that, the verified TLS client certificate, and checks it grants
+// scope. A token lacking scope is still returned on the context, so the
+// rejection can be attributed to it.
+func authenticate(ctx context.Context, auth *vmcontrol.AuthService, scope string) (context.Context, error) {
+	var (
+		token   *vmmodels.APIToken
+		err     error
+		message string
+	)
+	if secret, ok := bearerToken(ctx); ok {
+		token, err = auth.Authenticate(ctx, secret)
+		message = "Invalid or revoked API token"
+	} else if subject, ok := clientCertSubject(ctx); ok {
+		token, err = auth.AuthenticateCertificate(ctx, subject)
+		message = fmt.Sprintf("No API token is bound to client certificate subject %q", subject)
+	} else {
+		return ctx, newError(stdhttp.StatusUnauthorized, "UNAUTHENTICATED", "Missing bearer token")
+	}
+	if err != nil {
+		if errors.Is(err, vmmodels.ErrUnauthenticated) {
+			return ctx, newError(stdhttp.StatusUnauthorized, "UNAUTHENTICATED", message)
+		}
+		return ctx, coreError(err)
+	}
+	if !token.Allows(scope) {
+		return vmcontrol.ContextWithToken(ctx, token), newError(stdhttp.StatusForbidden, "INSUFFICIENT_SCOPE", fmt.Sprintf("API token lacks the required scope %s", scope))
+	}
+	return vmcontrol.ContextWithToken(ctx, token), nil
+}
+
+func bearerToken(ctx context.Context) (string, bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, header := range md.Get("authorization") {
+		scheme, token, ok := strings.Cut(header, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			continue
+		}
+		if token = strings.TrimSpace(token); token != "" {
+			return token, true
+		}
+	}
+	return "", false
+}
+
+// clientCertSubject returns the subject of the client certificate the TLS
+// handshake verified, in RFC 2253 notation.
+func clientCertSubject(ctx context.Context) (string, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+	return info.State.VerifiedChains[0][0].Subject.String(), true
+}
+
+// TokenCredentials sends token as a bearer token on every call. Like the REST
+// client, it also sends it over connections without TLS, e.g. Unix sockets.
+func TokenCredentials(token string) credentials.PerRPCCredentials {
+	return tokenCredentials(strings.TrimSpace(token))
+}
+
+type tokenCredentials string
+
+func (t tokenCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	if t == "" {
+		return nil, nil
+	}
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
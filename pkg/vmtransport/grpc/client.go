@@ -0,0 +1,38 @@
+package vmgrpc
+
+import (
+	"google.golang.org/grpc"
+
+	"github.com/go-go-golems/vm-system/pkg/vmtransport/grpc/vmsystemv1"
+)
+
+// Client is a gRPC connection to a vm-system daemon with a stub per service.
+type Client struct {
+	conn *grpc.ClientConn
+
+	Templates  vmsystemv1.TemplateServiceClient
+	Sessions   vmsystemv1.SessionServiceClient
+	Executions vmsystemv1.ExecutionServiceClient
+}
+
+// NewClient connects to the daemon at target, e.g. "127.0.0.1:3210" or
+// "unix:///run/vm-system.sock". Pass transport credentials (insecure or TLS)
+// and, when the daemon requires auth, grpc.WithPerRPCCredentials with
+// TokenCredentials.
+func NewClient(target string, opts ...grpc.DialOption) (*Client, error) {
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		conn:       conn,
+		Templates:  vmsystemv1.NewTemplateServiceClient(conn),
+		Sessions:   vmsystemv1.NewSessionServiceClient(conn),
+		Executions: vmsystemv1.NewExecutionServiceClient(conn),
+	}, nil
+}
+
+// Close closes the underlying connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,101 @@
+package vmgrpc
+
+import (
+	"encoding/json"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+	"github.com/go-go-golems/vm-system/pkg/vmtransport/grpc/vmsystemv1"
+)
+
+func toTemplate(template *vmmodels.VM) *vmsystemv1.Template {
+	return &vmsystemv1.Template{
+		Id:             template.ID,
+		Name:           template.Name,
+		Engine:         template.Engine,
+		IsActive:       template.IsActive,
+		ExposedModules: template.ExposedModules,
+		Libraries:      template.Libraries,
+		Revision:       int32(template.Revision),
+		WorkspaceId:    template.WorkspaceID,
+		CreatedAt:      timestamp(template.CreatedAt),
+		UpdatedAt:      timestamp(template.UpdatedAt),
+	}
+}
+
+func toSession(session *vmmodels.VMSession) *vmsystemv1.Session {
+	return &vmsystemv1.Session{
+		Id:               session.ID,
+		TemplateId:       session.VMID,
+		WorkspaceId:      session.WorkspaceID,
+		BaseCommitOid:    session.BaseCommitOID,
+		WorktreePath:     session.WorktreePath,
+		TemplateRevision: int32(session.TemplateRevision),
+		Status:           session.Status,
+		CreatedAt:        timestamp(session.CreatedAt),
+		LastActivityAt:   timestamp(session.LastActivityAt),
+		ClosedAt:         optionalTimestamp(session.ClosedAt),
+		LastError:        session.LastError,
+		ExpiresAt:        optionalTimestamp(session.ExpiresAt),
+	}
+}
+
+func toExecution(exec *vmmodels.Execution) *vmsystemv1.Execution {
+	return &vmsystemv1.Execution{
+		Id:          exec.ID,
+		SessionId:   exec.SessionID,
+		WorkspaceId: exec.WorkspaceID,
+		Kind:        exec.Kind,
+		Input:       exec.Input,
+		Path:        exec.Path,
+		Args:        jsonValue(exec.Args),
+		Env:         jsonValue(exec.Env),
+		Status:      exec.Status,
+		StartedAt:   timestamp(exec.StartedAt),
+		EndedAt:     optionalTimestamp(exec.EndedAt),
+		Result:      jsonValue(exec.Result),
+		Error:       jsonValue(exec.Error),
+		Metrics:     jsonValue(exec.Metrics),
+	}
+}
+
+func toExecutionEvent(event *vmmodels.ExecutionEvent) *vmsystemv1.ExecutionEvent {
+	return &vmsystemv1.ExecutionEvent{
+		ExecutionId: event.ExecutionID,
+		Seq:         int32(event.Seq),
+		Ts:          timestamp(event.Ts),
+		Type:        event.Type,
+		Payload:     jsonValue(event.Payload),
+	}
+}
+
+// jsonValue converts a stored JSON document to a protobuf Value, and nil for
+// an empty one.
+func jsonValue(raw json.RawMessage) *structpb.Value {
+	if len(raw) == 0 {
+		return nil
+	}
+	value := &structpb.Value{}
+	if err := protojson.Unmarshal(raw, value); err != nil {
+		return structpb.NewStringValue(string(raw))
+	}
+	return value
+}
+
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func optionalTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamp(*t)
+}
@@ -0,0 +1,94 @@
+package vmgrpc
+
+import (
+	"errors"
+	stdhttp "net/http"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	vmhttp "github.com/go-go-golems/vm-system/pkg/vmtransport/http"
+)
+
+// errorDomain names vm-system in the ErrorInfo detail of error statuses.
+const errorDomain = "vm-system"
+
+// coreError converts an error returned by vmcontrol to a gRPC status that
+// carries the REST API error code and HTTP status in an ErrorInfo detail.
+func coreError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	httpStatus, code, message := vmhttp.ErrorStatus(err)
+	return newError(httpStatus, code, message)
+}
+
+func validationError(message string) error {
+	return newError(stdhttp.StatusBadRequest, "VALIDATION_ERROR", message)
+}
+
+func newError(httpStatus int, code, message string) error {
+	st := status.New(grpcCode(httpStatus), message)
+	withInfo, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   code,
+		Domain:   errorDomain,
+		Metadata: map[string]string{"http_status": strconv.Itoa(httpStatus)},
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return withInfo.Err()
+}
+
+// ErrorCode returns the API error code of an error returned by a vm-system
+// gRPC call, e.g. "SESSION_NOT_FOUND", and "" when it carries none.
+func ErrorCode(err error) string {
+	if info := errorInfo(err); info != nil {
+		return info.GetReason()
+	}
+	return ""
+}
+
+// httpStatusOf returns the HTTP status the REST API reports for err.
+func httpStatusOf(err error) int {
+	if info := errorInfo(err); info != nil {
+		if httpStatus, convErr := strconv.Atoi(info.GetMetadata()["http_status"]); convErr == nil {
+			return httpStatus
+		}
+	}
+	return stdhttp.StatusInternalServerError
+}
+
+func errorInfo(err error) *errdetails.ErrorInfo {
+	var withStatus interface{ GRPCStatus() *status.Status }
+	if !errors.As(err, &withStatus) {
+		return nil
+	}
+	for _, detail := range withStatus.GRPCStatus().Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.GetDomain() == errorDomain {
+			return info
+		}
+	}
+	return nil
+}
+
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case stdhttp.StatusBadRequest, stdhttp.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case stdhttp.StatusUnauthorized:
+		return codes.Unauthenticated
+	case stdhttp.StatusForbidden:
+		return codes.PermissionDenied
+	case stdhttp.StatusNotFound:
+		return codes.NotFound
+	case stdhttp.StatusConflict:
+		return codes.FailedPrecondition
+	case stdhttp.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	default:
+		return codes.Internal
+	}
+}
@@ -0,0 +1,6 @@
+// Package vmgrpc serves the vmcontrol core over gRPC, alongside the REST API
+// of vmtransport/http. The protobuf definitions live in proto/ and the
+// generated messages and service stubs in vmsystemv1.
+package vmgrpc
+
+//go:generate buf generate
@@ -0,0 +1,58 @@
+package vmgrpc
+
+import (
+	stdhttp "net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmtransport/grpc/vmsystemv1"
+)
+
+// ServerOption configures the gRPC server built by NewServer.
+type ServerOption func(*serverConfig)
+
+type serverConfig struct {
+	requireAuth bool
+}
+
+// RequireAuth rejects calls that do not carry a live bearer token, or a
+// verified client certificate bound to one, with the scope the method needs.
+func RequireAuth() ServerOption {
+	return func(cfg *serverConfig) {
+		cfg.requireAuth = true
+	}
+}
+
+// NewServer returns a gRPC server exposing core's templates, sessions and
+// executions. Calls are authenticated like REST requests and mutating calls
+// are recorded in the audit trail under the same actions.
+func NewServer(core *vmcontrol.Core, opts ...ServerOption) *grpc.Server {
+	cfg := serverConfig{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	calls := &interceptor{core: core, requireAuth: cfg.requireAuth}
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(calls.unary),
+		grpc.ChainStreamInterceptor(calls.stream),
+	)
+	vmsystemv1.RegisterTemplateServiceServer(server, &templateServer{core: core})
+	vmsystemv1.RegisterSessionServiceServer(server, &sessionServer{core: core})
+	vmsystemv1.RegisterExecutionServiceServer(server, &executionServer{core: core})
+	return server
+}
+
+// Handler serves gRPC calls with grpcServer and every other request with
+// next, so both share the daemon's listener. gRPC runs over HTTP/2: with TLS,
+// or in cleartext with prior knowledge.
+func Handler(grpcServer *grpc.Server, next stdhttp.Handler) stdhttp.Handler {
+	return stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			grpcServer.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,196 @@
+package vmgrpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmexec"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+	"github.com/go-go-golems/vm-system/pkg/vmtransport/grpc/vmsystemv1"
+)
+
+type executionServer struct {
+	vmsystemv1.UnimplementedExecutionServiceServer
+	core *vmcontrol.Core
+}
+
+func (s *executionServer) ExecuteREPL(ctx context.Context, req *vmsystemv1.ExecuteREPLRequest) (*vmsystemv1.Execution, error) {
+	exec, err := s.executeREPL(ctx, req, vmexec.Observer{})
+	if err != nil {
+		return nil, err
+	}
+	return toExecution(exec), nil
+}
+
+func (s *executionServer) ExecuteRunFile(ctx context.Context, req *vmsystemv1.ExecuteRunFileRequest) (*vmsystemv1.Execution, error) {
+	exec, err := s.executeRunFile(ctx, req, vmexec.Observer{})
+	if err != nil {
+		return nil, err
+	}
+	return toExecution(exec), nil
+}
+
+func (s *executionServer) StreamREPL(req *vmsystemv1.ExecuteREPLRequest, stream grpc.ServerStreamingServer[vmsystemv1.ExecutionUpdate]) error {
+	return streamExecution(stream, func(observer vmexec.Observer) (*vmmodels.Execution, error) {
+		return s.executeREPL(stream.Context(), req, observer)
+	})
+}
+
+func (s *executionServer) StreamRunFile(req *vmsystemv1.ExecuteRunFileRequest, stream grpc.ServerStreamingServer[vmsystemv1.ExecutionUpdate]) error {
+	return streamExecution(stream, func(observer vmexec.Observer) (*vmmodels.Execution, error) {
+		return s.executeRunFile(stream.Context(), req, observer)
+	})
+}
+
+// streamExecution runs an execution, sending its record when it starts, each
+// event as it is persisted and the settled record last. Once the client is
+// gone the execution still runs to completion, as a REST call would.
+func streamExecution(stream grpc.ServerStreamingServer[vmsystemv1.ExecutionUpdate], run func(vmexec.Observer) (*vmmodels.Execution, error)) error {
+	var sendErr error
+	send := func(update *vmsystemv1.ExecutionUpdate) {
+		if sendErr == nil {
+			sendErr = stream.Send(update)
+		}
+	}
+	exec, err := run(vmexec.Observer{
+		Started: func(exec *vmmodels.Execution) {
+			send(&vmsystemv1.ExecutionUpdate{Update: &vmsystemv1.ExecutionUpdate_Started{Started: toExecution(exec)}})
+		},
+		Event: func(event *vmmodels.ExecutionEvent) {
+			send(&vmsystemv1.ExecutionUpdate{Update: &vmsystemv1.ExecutionUpdate_Event{Event: toExecutionEvent(event)}})
+		},
+	})
+	if err != nil {
+		return err
+	}
+	send(&vmsystemv1.ExecutionUpdate{Update: &vmsystemv1.ExecutionUpdate_Finished{Finished: toExecution(exec)}})
+	return sendErr
+}
+
+func (s *executionServer) executeREPL(ctx context.Context, req *vmsystemv1.ExecuteREPLRequest, observer vmexec.Observer) (*vmmodels.Execution, error) {
+	if req.GetSessionId() == "" || req.GetInput() == "" {
+		return nil, validationError("session_id and input are required")
+	}
+	sessionID, err := parseSessionID(req.GetSessionId())
+	if err != nil {
+		return nil, err
+	}
+	auditDetail(ctx, "session_id", sessionID)
+	auditDetail(ctx, "input_sha256", auditHash(req.GetInput()))
+
+	exec, err := s.core.Executions.ExecuteREPL(ctx, vmcontrol.ExecuteREPLInput{
+		SessionID: sessionID,
+		Input:     req.GetInput(),
+		Observer:  observer,
+	})
+	if err != nil {
+		return nil, coreError(err)
+	}
+	auditResource(ctx, exec.ID, exec.WorkspaceID)
+	return exec, nil
+}
+
+func (s *executionServer) executeRunFile(ctx context.Context, req *vmsystemv1.ExecuteRunFileRequest, observer vmexec.Observer) (*vmmodels.Execution, error) {
+	if req.GetSessionId() == "" || req.GetPath() == "" {
+		return nil, validationError("session_id and path are required")
+	}
+	sessionID, err := parseSessionID(req.GetSessionId())
+	if err != nil {
+		return nil, err
+	}
+	args := req.GetArgs().AsMap()
+	env := req.GetEnv().AsMap()
+	auditDetail(ctx, "session_id", sessionID)
+	auditDetail(ctx, "path", req.GetPath())
+
+	exec, err := s.core.Executions.ExecuteRunFile(ctx, vmcontrol.ExecuteRunFileInput{
+		SessionID: sessionID,
+		Path:      req.GetPath(),
+		Args:      args,
+		Env:       env,
+		Observer:  observer,
+	})
+	if err != nil {
+		return nil, coreError(err)
+	}
+	auditResource(ctx, exec.ID, exec.WorkspaceID)
+	return exec, nil
+}
+
+func (s *executionServer) GetExecution(ctx context.Context, req *vmsystemv1.GetExecutionRequest) (*vmsystemv1.Execution, error) {
+	executionID, err := parseExecutionID(req.GetExecutionId())
+	if err != nil {
+		return nil, err
+	}
+	exec, err := s.core.Executions.Get(ctx, executionID)
+	if err != nil {
+		return nil, coreError(err)
+	}
+	return toExecution(exec), nil
+}
+
+func (s *executionServer) ListExecutions(ctx context.Context, req *vmsystemv1.ListExecutionsRequest) (*vmsystemv1.ListExecutionsResponse, error) {
+	sessionID, err := parseSessionID(req.GetSessionId())
+	if err != nil {
+		return nil, err
+	}
+	limit := int(req.GetLimit())
+	if limit < 0 {
+		return nil, validationError("limit must be a positive integer")
+	}
+	if limit == 0 {
+		limit = 50
+	}
+	execs, err := s.core.Executions.List(ctx, sessionID, limit)
+	if err != nil {
+		return nil, coreError(err)
+	}
+	resp := &vmsystemv1.ListExecutionsResponse{}
+	for _, exec := range execs {
+		resp.Executions = append(resp.Executions, toExecution(exec))
+	}
+	return resp, nil
+}
+
+func (s *executionServer) ListExecutionEvents(ctx context.Context, req *vmsystemv1.ListExecutionEventsRequest) (*vmsystemv1.ListExecutionEventsResponse, error) {
+	executionID, err := parseExecutionID(req.GetExecutionId())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetAfterSeq() < 0 {
+		return nil, validationError("after_seq must be a non-negative integer")
+	}
+	events, err := s.core.Executions.Events(ctx, executionID, int(req.GetAfterSeq()))
+	if err != nil {
+		return nil, coreError(err)
+	}
+	resp := &vmsystemv1.ListExecutionEventsResponse{}
+	for _, event := range events {
+		resp.Events = append(resp.Events, toExecutionEvent(event))
+	}
+	return resp, nil
+}
+
+func (s *executionServer) CancelExecution(ctx context.Context, req *vmsystemv1.CancelExecutionRequest) (*vmsystemv1.Execution, error) {
+	executionID, err := parseExecutionID(req.GetExecutionId())
+	if err != nil {
+		return nil, err
+	}
+	auditResource(ctx, executionID, "")
+	exec, err := s.core.Executions.Cancel(ctx, executionID)
+	if err != nil {
+		return nil, coreError(err)
+	}
+	auditResource(ctx, exec.ID, exec.WorkspaceID)
+	return toExecution(exec), nil
+}
+
+func parseExecutionID(raw string) (string, error) {
+	id, err := vmmodels.ParseExecutionID(raw)
+	if err != nil {
+		return "", validationError("execution_id must be a valid UUID")
+	}
+	return id.String(), nil
+}
@@ -0,0 +1,221 @@
+package vmgrpc_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+	"github.com/go-go-golems/vm-system/pkg/vmstore"
+	vmgrpc "github.com/go-go-golems/vm-system/pkg/vmtransport/grpc"
+	"github.com/go-go-golems/vm-system/pkg/vmtransport/grpc/vmsystemv1"
+	vmhttp "github.com/go-go-golems/vm-system/pkg/vmtransport/http"
+)
+
+// serveBoth serves gRPC and REST on one cleartext listener, as the daemon
+// does, and returns its address.
+func serveBoth(t *testing.T, core *vmcontrol.Core, opts ...vmgrpc.ServerOption) string {
+	t.Helper()
+	grpcServer := vmgrpc.NewServer(core, opts...)
+	server := &http.Server{Handler: vmgrpc.Handler(grpcServer, vmhttp.NewHandler(core))}
+	server.Protocols = new(http.Protocols)
+	server.Protocols.SetHTTP1(true)
+	server.Protocols.SetUnencryptedHTTP2(true)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(func() {
+		grpcServer.Stop()
+		_ = server.Close()
+	})
+	return listener.Addr().String()
+}
+
+func newTestCore(t *testing.T) *vmcontrol.Core {
+	t.Helper()
+	store, err := vmstore.NewVMStore(filepath.Join(t.TempDir(), "vm-system.db"))
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return vmcontrol.NewCore(store)
+}
+
+func dial(t *testing.T, addr string, opts ...grpc.DialOption) *vmgrpc.Client {
+	t.Helper()
+	client, err := vmgrpc.NewClient(addr, append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, opts...)...)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func expectCode(t *testing.T, err error, code codes.Code, errorCode string) {
+	t.Helper()
+	if status.Code(err) != code || vmgrpc.ErrorCode(err) != errorCode {
+		t.Fatalf("expected %s/%s, got %s/%q (%v)", code, errorCode, status.Code(err), vmgrpc.ErrorCode(err), err)
+	}
+}
+
+func TestStreamREPLSharesListenerAndStreamsEvents(t *testing.T) {
+	core := newTestCore(t)
+	addr := serveBoth(t, core)
+	client := dial(t, addr)
+	ctx := context.Background()
+
+	health, err := http.Get("http://" + addr + "/api/v1/health")
+	if err != nil {
+		t.Fatalf("rest health: %v", err)
+	}
+	_ = health.Body.Close()
+	if health.StatusCode != http.StatusOK {
+		t.Fatalf("expected REST health on the gRPC listener, got %d", health.StatusCode)
+	}
+
+	template, err := client.Templates.CreateTemplate(ctx, &vmsystemv1.CreateTemplateRequest{Name: "grpc-template"})
+	if err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	worktree := filepath.Join(t.TempDir(), "worktree")
+	if err := os.MkdirAll(worktree, 0o755); err != nil {
+		t.Fatalf("mkdir worktree: %v", err)
+	}
+	session, err := client.Sessions.CreateSession(ctx, &vmsystemv1.CreateSessionRequest{
+		TemplateId:    template.GetId(),
+		WorkspaceId:   "ws-grpc",
+		BaseCommitOid: "deadbeef",
+		WorktreePath:  worktree,
+	})
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	if session.GetStatus() != "ready" {
+		t.Fatalf("expected ready session, got %q", session.GetStatus())
+	}
+
+	stream, err := client.Executions.StreamREPL(ctx, &vmsystemv1.ExecuteREPLRequest{
+		SessionId: session.GetId(),
+		Input:     `console.log("hi"); 40 + 2`,
+	})
+	if err != nil {
+		t.Fatalf("stream repl: %v", err)
+	}
+	var (
+		started  *vmsystemv1.Execution
+		finished *vmsystemv1.Execution
+		types    []string
+	)
+	for {
+		update, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("recv: %v", err)
+		}
+		switch {
+		case update.GetStarted() != nil:
+			started = update.GetStarted()
+		case update.GetEvent() != nil:
+			if started == nil || finished != nil {
+				t.Fatalf("event %s outside started/finished", update.GetEvent().GetType())
+			}
+			types = append(types, update.GetEvent().GetType())
+		case update.GetFinished() != nil:
+			finished = update.GetFinished()
+		}
+	}
+	if started == nil || finished == nil || started.GetId() != finished.GetId() {
+		t.Fatalf("expected started and finished for one execution, got %v / %v", started, finished)
+	}
+	if finished.GetStatus() != "ok" || finished.GetResult().GetStructValue().AsMap()["preview"] != "42" {
+		t.Fatalf("unexpected finished execution %v", finished)
+	}
+	if len(types) != 3 || types[0] != "input_echo" || types[1] != "console" || types[2] != "value" {
+		t.Fatalf("unexpected streamed event types %v", types)
+	}
+
+	events, err := client.Executions.ListExecutionEvents(ctx, &vmsystemv1.ListExecutionEventsRequest{ExecutionId: finished.GetId()})
+	if err != nil {
+		t.Fatalf("list events: %v", err)
+	}
+	if len(events.GetEvents()) != len(types) {
+		t.Fatalf("expected %d persisted events, got %d", len(types), len(events.GetEvents()))
+	}
+
+	_, err = client.Executions.ExecuteREPL(ctx, &vmsystemv1.ExecuteREPLRequest{
+		SessionId: "00000000-0000-0000-0000-000000000000",
+		Input:     "1",
+	})
+	expectCode(t, err, codes.NotFound, "SESSION_NOT_FOUND")
+	_, err = client.Executions.ExecuteREPL(ctx, &vmsystemv1.ExecuteREPLRequest{SessionId: session.GetId()})
+	expectCode(t, err, codes.InvalidArgument, "VALIDATION_ERROR")
+}
+
+func TestRequireAuthChecksScopesAndAuditsCalls(t *testing.T) {
+	core := newTestCore(t)
+	addr := serveBoth(t, core, vmgrpc.RequireAuth())
+	ctx := context.Background()
+
+	createToken := func(name string, scopes ...string) string {
+		t.Helper()
+		created, err := core.Auth.Create(ctx, vmcontrol.CreateTokenInput{Name: name, Scopes: scopes})
+		if err != nil {
+			t.Fatalf("create token: %v", err)
+		}
+		return created.Secret
+	}
+	reader := dial(t, addr, grpc.WithPerRPCCredentials(vmgrpc.TokenCredentials(createToken("reader", vmmodels.ScopeRead))))
+	writer := dial(t, addr, grpc.WithPerRPCCredentials(vmgrpc.TokenCredentials(createToken("writer", vmmodels.ScopeRead, vmmodels.ScopeTemplatesWrite))))
+
+	_, err := dial(t, addr).Templates.ListTemplates(ctx, &vmsystemv1.ListTemplatesRequest{})
+	expectCode(t, err, codes.Unauthenticated, "UNAUTHENTICATED")
+	if _, err := reader.Templates.ListTemplates(ctx, &vmsystemv1.ListTemplatesRequest{}); err != nil {
+		t.Fatalf("list templates with read scope: %v", err)
+	}
+	_, err = reader.Templates.CreateTemplate(ctx, &vmsystemv1.CreateTemplateRequest{Name: "denied"})
+	expectCode(t, err, codes.PermissionDenied, "INSUFFICIENT_SCOPE")
+
+	template, err := writer.Templates.CreateTemplate(ctx, &vmsystemv1.CreateTemplateRequest{Name: "allowed"})
+	if err != nil {
+		t.Fatalf("create template with templates:write: %v", err)
+	}
+
+	events, err := core.Audit.List(ctx, vmmodels.AuditFilter{Action: "template.create"})
+	if err != nil {
+		t.Fatalf("list audit events: %v", err)
+	}
+	outcomes := map[string]string{}
+	for _, event := range events {
+		outcomes[event.Actor] = event.Outcome
+		if event.Actor == "token:writer" && event.ResourceID != template.GetId() {
+			t.Fatalf("expected audit resource %s, got %s", template.GetId(), event.ResourceID)
+		}
+		if event.Actor == "token:reader" && (event.StatusCode != http.StatusForbidden || event.ErrorCode != "INSUFFICIENT_SCOPE" || event.RequestID == "") {
+			t.Fatalf("expected the denied create audited as a 403, got %+v", event)
+		}
+	}
+	// Mutating calls rejected by authentication are audited as failures, as
+	// with REST; rejected reads are not audited.
+	if len(outcomes) != 2 || outcomes["token:writer"] != vmmodels.AuditOutcomeSuccess || outcomes["token:reader"] != vmmodels.AuditOutcomeFailure {
+		t.Fatalf("expected a successful template.create by token:writer and a failed one by token:reader, got %v", outcomes)
+	}
+	if all, err := core.Audit.List(ctx, vmmodels.AuditFilter{}); err != nil || len(all) != 2 {
+		t.Fatalf("expected only the two template.create events, got %d (%v)", len(all), err)
+	}
+}
@@ -0,0 +1,85 @@
+package vmgrpc
+
+import (
+	"context"
+
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+	"github.com/go-go-golems/vm-system/pkg/vmtransport/grpc/vmsystemv1"
+)
+
+type sessionServer struct {
+	vmsystemv1.UnimplementedSessionServiceServer
+	core *vmcontrol.Core
+}
+
+func (s *sessionServer) CreateSession(ctx context.Context, req *vmsystemv1.CreateSessionRequest) (*vmsystemv1.Session, error) {
+	// A workspace-bound token implies its workspace.
+	_, confined := vmcontrol.WorkspaceFromContext(ctx)
+	if req.GetTemplateId() == "" || (req.GetWorkspaceId() == "" && !confined) || req.GetBaseCommitOid() == "" || req.GetWorktreePath() == "" {
+		return nil, validationError("template_id, workspace_id, base_commit_oid, and worktree_path are required")
+	}
+	templateID, err := parseTemplateID(req.GetTemplateId())
+	if err != nil {
+		return nil, err
+	}
+	auditDetail(ctx, "template_id", templateID)
+
+	session, err := s.core.Sessions.Create(ctx, vmcontrol.CreateSessionInput{
+		TemplateID:    templateID,
+		WorkspaceID:   req.GetWorkspaceId(),
+		BaseCommitOID: req.GetBaseCommitOid(),
+		WorktreePath:  req.GetWorktreePath(),
+	})
+	if err != nil {
+		return nil, coreError(err)
+	}
+	auditResource(ctx, session.ID, session.WorkspaceID)
+	return toSession(session), nil
+}
+
+func (s *sessionServer) ListSessions(ctx context.Context, req *vmsystemv1.ListSessionsRequest) (*vmsystemv1.ListSessionsResponse, error) {
+	sessions, err := s.core.Sessions.List(ctx, req.GetStatus(), req.GetWorkspaceId())
+	if err != nil {
+		return nil, coreError(err)
+	}
+	resp := &vmsystemv1.ListSessionsResponse{}
+	for _, session := range sessions {
+		resp.Sessions = append(resp.Sessions, toSession(session))
+	}
+	return resp, nil
+}
+
+func (s *sessionServer) GetSession(ctx context.Context, req *vmsystemv1.GetSessionRequest) (*vmsystemv1.Session, error) {
+	sessionID, err := parseSessionID(req.GetSessionId())
+	if err != nil {
+		return nil, err
+	}
+	session, err := s.core.Sessions.Get(ctx, sessionID)
+	if err != nil {
+		return nil, coreError(err)
+	}
+	return toSession(session), nil
+}
+
+func (s *sessionServer) CloseSession(ctx context.Context, req *vmsystemv1.CloseSessionRequest) (*vmsystemv1.Session, error) {
+	sessionID, err := parseSessionID(req.GetSessionId())
+	if err != nil {
+		return nil, err
+	}
+	auditResource(ctx, sessionID, "")
+	session, err := s.core.Sessions.Close(ctx, sessionID)
+	if err != nil {
+		return nil, coreError(err)
+	}
+	auditResource(ctx, session.ID, session.WorkspaceID)
+	return toSession(session), nil
+}
+
+func parseSessionID(raw string) (string, error) {
+	id, err := vmmodels.ParseSessionID(raw)
+	if err != nil {
+		return "", validationError("session_id must be a valid UUID")
+	}
+	return id.String(), nil
+}
@@ -0,0 +1,76 @@
+package vmgrpc
+
+import (
+	"context"
+
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+	"github.com/go-go-golems/vm-system/pkg/vmtransport/grpc/vmsystemv1"
+)
+
+type templateServer struct {
+	vmsystemv1.UnimplementedTemplateServiceServer
+	core *vmcontrol.Core
+}
+
+func (s *templateServer) CreateTemplate(ctx context.Context, req *vmsystemv1.CreateTemplateRequest) (*vmsystemv1.Template, error) {
+	if req.GetName() == "" {
+		return nil, validationError("name is required")
+	}
+	auditDetail(ctx, "name", req.GetName())
+
+	template, err := s.core.Templates.Create(ctx, vmcontrol.CreateTemplateInput{
+		Name:        req.GetName(),
+		Engine:      req.GetEngine(),
+		WorkspaceID: req.GetWorkspaceId(),
+	})
+	if err != nil {
+		return nil, coreError(err)
+	}
+	auditResource(ctx, template.ID, template.WorkspaceID)
+	return toTemplate(template), nil
+}
+
+func (s *templateServer) ListTemplates(ctx context.Context, _ *vmsystemv1.ListTemplatesRequest) (*vmsystemv1.ListTemplatesResponse, error) {
+	templates, err := s.core.Templates.List(ctx)
+	if err != nil {
+		return nil, coreError(err)
+	}
+	resp := &vmsystemv1.ListTemplatesResponse{}
+	for _, template := range templates {
+		resp.Templates = append(resp.Templates, toTemplate(template))
+	}
+	return resp, nil
+}
+
+func (s *templateServer) GetTemplate(ctx context.Context, req *vmsystemv1.GetTemplateRequest) (*vmsystemv1.Template, error) {
+	templateID, err := parseTemplateID(req.GetTemplateId())
+	if err != nil {
+		return nil, err
+	}
+	template, err := s.core.Templates.Get(ctx, templateID)
+	if err != nil {
+		return nil, coreError(err)
+	}
+	return toTemplate(template), nil
+}
+
+func (s *templateServer) DeleteTemplate(ctx context.Context, req *vmsystemv1.DeleteTemplateRequest) (*vmsystemv1.DeleteTemplateResponse, error) {
+	templateID, err := parseTemplateID(req.GetTemplateId())
+	if err != nil {
+		return nil, err
+	}
+	auditResource(ctx, templateID, "")
+	if err := s.core.Templates.Delete(ctx, templateID); err != nil {
+		return nil, coreError(err)
+	}
+	return &vmsystemv1.DeleteTemplateResponse{}, nil
+}
+
+func parseTemplateID(raw string) (string, error) {
+	id, err := vmmodels.ParseTemplateID(raw)
+	if err != nil {
+		return "", validationError("template_id must be a valid UUID")
+	}
+	return id.String(), nil
+}
@@ -0,0 +1,1809 @@
+// gRPC API of the vm-system daemon. It mirrors the REST API under /api/v1 for
+// templates, sessions and executions, and adds server-streaming RPCs that
+// deliver execution events as they are produced.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: vmsystem/v1/vmsystem.proto
+
+package vmsystemv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Template struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name           string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Engine         string                 `protobuf:"bytes,3,opt,name=engine,proto3" json:"engine,omitempty"`
+	IsActive       bool                   `protobuf:"varint,4,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	ExposedModules []string               `protobuf:"bytes,5,rep,name=exposed_modules,json=exposedModules,proto3" json:"exposed_modules,omitempty"`
+	Libraries      []string               `protobuf:"bytes,6,rep,name=libraries,proto3" json:"libraries,omitempty"`
+	Revision       int32                  `protobuf:"varint,7,opt,name=revision,proto3" json:"revision,omitempty"`
+	// Owning workspace, empty for templates shared by all workspaces.
+	WorkspaceId   string                 `protobuf:"bytes,8,opt,name=workspace_id,json=workspaceId,proto3" json:"workspace_id,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Template) Reset() {
+	*x = Template{}
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Template) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Template) ProtoMessage() {}
+
+func (x *Template) ProtoReflect() protoreflect.Message {
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Template.ProtoReflect.Descriptor instead.
+func (*Template) Descriptor() ([]byte, []int) {
+	return file_vmsystem_v1_vmsystem_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Template) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Template) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Template) GetEngine() string {
+	if x != nil {
+		return x.Engine
+	}
+	return ""
+}
+
+func (x *Template) GetIsActive() bool {
+	if x != nil {
+		return x.IsActive
+	}
+	return false
+}
+
+func (x *Template) GetExposedModules() []string {
+	if x != nil {
+		return x.ExposedModules
+	}
+	return nil
+}
+
+func (x *Template) GetLibraries() []string {
+	if x != nil {
+		return x.Libraries
+	}
+	return nil
+}
+
+func (x *Template) GetRevision() int32 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *Template) GetWorkspaceId() string {
+	if x != nil {
+		return x.WorkspaceId
+	}
+	return ""
+}
+
+func (x *Template) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Template) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type CreateTemplateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Defaults to goja.
+	Engine        string `protobuf:"bytes,2,opt,name=engine,proto3" json:"engine,omitempty"`
+	WorkspaceId   string `protobuf:"bytes,3,opt,name=workspace_id,json=workspaceId,proto3" json:"workspace_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTemplateRequest) Reset() {
+	*x = CreateTemplateRequest{}
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTemplateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTemplateRequest) ProtoMessage() {}
+
+func (x *CreateTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTemplateRequest.ProtoReflect.Descriptor instead.
+func (*CreateTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_vmsystem_v1_vmsystem_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateTemplateRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateTemplateRequest) GetEngine() string {
+	if x != nil {
+		return x.Engine
+	}
+	return ""
+}
+
+func (x *CreateTemplateRequest) GetWorkspaceId() string {
+	if x != nil {
+		return x.WorkspaceId
+	}
+	return ""
+}
+
+type ListTemplatesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTemplatesRequest) Reset() {
+	*x = ListTemplatesRequest{}
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTemplatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTemplatesRequest) ProtoMessage() {}
+
+func (x *ListTemplatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTemplatesRequest.ProtoReflect.Descriptor instead.
+func (*ListTemplatesRequest) Descriptor() ([]byte, []int) {
+	return file_vmsystem_v1_vmsystem_proto_rawDescGZIP(), []int{2}
+}
+
+type ListTemplatesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Templates     []*Template            `protobuf:"bytes,1,rep,name=templates,proto3" json:"templates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTemplatesResponse) Reset() {
+	*x = ListTemplatesResponse{}
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTemplatesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTemplatesResponse) ProtoMessage() {}
+
+func (x *ListTemplatesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTemplatesResponse.ProtoReflect.Descriptor instead.
+func (*ListTemplatesResponse) Descriptor() ([]byte, []int) {
+	return file_vmsystem_v1_vmsystem_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListTemplatesResponse) GetTemplates() []*Template {
+	if x != nil {
+		return x.Templates
+	}
+	return nil
+}
+
+type GetTemplateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TemplateId    string                 `protobuf:"bytes,1,opt,name=template_id,json=templateId,proto3" json:"template_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTemplateRequest) Reset() {
+	*x = GetTemplateRequest{}
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTemplateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTemplateRequest) ProtoMessage() {}
+
+func (x *GetTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTemplateRequest.ProtoReflect.Descriptor instead.
+func (*GetTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_vmsystem_v1_vmsystem_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetTemplateRequest) GetTemplateId() string {
+	if x != nil {
+		return x.TemplateId
+	}
+	return ""
+}
+
+type DeleteTemplateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TemplateId    string                 `protobuf:"bytes,1,opt,name=template_id,json=templateId,proto3" json:"template_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTemplateRequest) Reset() {
+	*x = DeleteTemplateRequest{}
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTemplateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTemplateRequest) ProtoMessage() {}
+
+func (x *DeleteTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTemplateRequest.ProtoReflect.Descriptor instead.
+func (*DeleteTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_vmsystem_v1_vmsystem_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DeleteTemplateRequest) GetTemplateId() string {
+	if x != nil {
+		return x.TemplateId
+	}
+	return ""
+}
+
+type DeleteTemplateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTemplateResponse) Reset() {
+	*x = DeleteTemplateResponse{}
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTemplateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTemplateResponse) ProtoMessage() {}
+
+func (x *DeleteTemplateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTemplateResponse.ProtoReflect.Descriptor instead.
+func (*DeleteTemplateResponse) Descriptor() ([]byte, []int) {
+	return file_vmsystem_v1_vmsystem_proto_rawDescGZIP(), []int{6}
+}
+
+type Session struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TemplateId       string                 `protobuf:"bytes,2,opt,name=template_id,json=templateId,proto3" json:"template_id,omitempty"`
+	WorkspaceId      string                 `protobuf:"bytes,3,opt,name=workspace_id,json=workspaceId,proto3" json:"workspace_id,omitempty"`
+	BaseCommitOid    string                 `protobuf:"bytes,4,opt,name=base_commit_oid,json=baseCommitOid,proto3" json:"base_commit_oid,omitempty"`
+	WorktreePath     string                 `protobuf:"bytes,5,opt,name=worktree_path,json=worktreePath,proto3" json:"worktree_path,omitempty"`
+	TemplateRevision int32                  `protobuf:"varint,6,opt,name=template_revision,json=templateRevision,proto3" json:"template_revision,omitempty"`
+	// starting, ready, crashed, closed or expired.
+	Status         string                 `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	LastActivityAt *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=last_activity_at,json=lastActivityAt,proto3" json:"last_activity_at,omitempty"`
+	ClosedAt       *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=closed_at,json=closedAt,proto3" json:"closed_at,omitempty"`
+	LastError      string                 `protobuf:"bytes,11,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	// Set for live sessions whose template has an idle timeout or max lifetime.
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Session) Reset() {
+	*x = Session{}
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Session) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Session) ProtoMessage() {}
+
+func (x *Session) ProtoReflect() protoreflect.Message {
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Session.ProtoReflect.Descriptor instead.
+func (*Session) Descriptor() ([]byte, []int) {
+	return file_vmsystem_v1_vmsystem_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Session) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Session) GetTemplateId() string {
+	if x != nil {
+		return x.TemplateId
+	}
+	return ""
+}
+
+func (x *Session) GetWorkspaceId() string {
+	if x != nil {
+		return x.WorkspaceId
+	}
+	return ""
+}
+
+func (x *Session) GetBaseCommitOid() string {
+	if x != nil {
+		return x.BaseCommitOid
+	}
+	return ""
+}
+
+func (x *Session) GetWorktreePath() string {
+	if x != nil {
+		return x.WorktreePath
+	}
+	return ""
+}
+
+func (x *Session) GetTemplateRevision() int32 {
+	if x != nil {
+		return x.TemplateRevision
+	}
+	return 0
+}
+
+func (x *Session) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Session) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Session) GetLastActivityAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastActivityAt
+	}
+	return nil
+}
+
+func (x *Session) GetClosedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ClosedAt
+	}
+	return nil
+}
+
+func (x *Session) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *Session) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type CreateSessionRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	TemplateId string                 `protobuf:"bytes,1,opt,name=template_id,json=templateId,proto3" json:"template_id,omitempty"`
+	// Optional for callers whose token is bound to a workspace.
+	WorkspaceId   string `protobuf:"bytes,2,opt,name=workspace_id,json=workspaceId,proto3" json:"workspace_id,omitempty"`
+	BaseCommitOid string `protobuf:"bytes,3,opt,name=base_commit_oid,json=baseCommitOid,proto3" json:"base_commit_oid,omitempty"`
+	WorktreePath  string `protobuf:"bytes,4,opt,name=worktree_path,json=worktreePath,proto3" json:"worktree_path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSessionRequest) Reset() {
+	*x = CreateSessionRequest{}
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSessionRequest) ProtoMessage() {}
+
+func (x *CreateSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSessionRequest.ProtoReflect.Descriptor instead.
+func (*CreateSessionRequest) Descriptor() ([]byte, []int) {
+	return file_vmsystem_v1_vmsystem_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CreateSessionRequest) GetTemplateId() string {
+	if x != nil {
+		return x.TemplateId
+	}
+	return ""
+}
+
+func (x *CreateSessionRequest) GetWorkspaceId() string {
+	if x != nil {
+		return x.WorkspaceId
+	}
+	return ""
+}
+
+func (x *CreateSessionRequest) GetBaseCommitOid() string {
+	if x != nil {
+		return x.BaseCommitOid
+	}
+	return ""
+}
+
+func (x *CreateSessionRequest) GetWorktreePath() string {
+	if x != nil {
+		return x.WorktreePath
+	}
+	return ""
+}
+
+type ListSessionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	WorkspaceId   string                 `protobuf:"bytes,2,opt,name=workspace_id,json=workspaceId,proto3" json:"workspace_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionsRequest) Reset() {
+	*x = ListSessionsRequest{}
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsRequest) ProtoMessage() {}
+
+func (x *ListSessionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSessionsRequest.ProtoReflect.Descriptor instead.
+func (*ListSessionsRequest) Descriptor() ([]byte, []int) {
+	return file_vmsystem_v1_vmsystem_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListSessionsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListSessionsRequest) GetWorkspaceId() string {
+	if x != nil {
+		return x.WorkspaceId
+	}
+	return ""
+}
+
+type ListSessionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sessions      []*Session             `protobuf:"bytes,1,rep,name=sessions,proto3" json:"sessions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionsResponse) Reset() {
+	*x = ListSessionsResponse{}
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsResponse) ProtoMessage() {}
+
+func (x *ListSessionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSessionsResponse.ProtoReflect.Descriptor instead.
+func (*ListSessionsResponse) Descriptor() ([]byte, []int) {
+	return file_vmsystem_v1_vmsystem_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListSessionsResponse) GetSessions() []*Session {
+	if x != nil {
+		return x.Sessions
+	}
+	return nil
+}
+
+type GetSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSessionRequest) Reset() {
+	*x = GetSessionRequest{}
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSessionRequest) ProtoMessage() {}
+
+func (x *GetSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSessionRequest.ProtoReflect.Descriptor instead.
+func (*GetSessionRequest) Descriptor() ([]byte, []int) {
+	return file_vmsystem_v1_vmsystem_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetSessionRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type CloseSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloseSessionRequest) Reset() {
+	*x = CloseSessionRequest{}
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloseSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloseSessionRequest) ProtoMessage() {}
+
+func (x *CloseSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloseSessionRequest.ProtoReflect.Descriptor instead.
+func (*CloseSessionRequest) Descriptor() ([]byte, []int) {
+	return file_vmsystem_v1_vmsystem_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CloseSessionRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type Execution struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	SessionId   string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	WorkspaceId string                 `protobuf:"bytes,3,opt,name=workspace_id,json=workspaceId,proto3" json:"workspace_id,omitempty"`
+	// startup, run_file or repl.
+	Kind  string          `protobuf:"bytes,4,opt,name=kind,proto3" json:"kind,omitempty"`
+	Input string          `protobuf:"bytes,5,opt,name=input,proto3" json:"input,omitempty"`
+	Path  string          `protobuf:"bytes,6,opt,name=path,proto3" json:"path,omitempty"`
+	Args  *structpb.Value `protobuf:"bytes,7,opt,name=args,proto3" json:"args,omitempty"`
+	Env   *structpb.Value `protobuf:"bytes,8,opt,name=env,proto3" json:"env,omitempty"`
+	// queued, running, ok, error, timeout or cancelled.
+	Status        string                 `protobuf:"bytes,9,opt,name=status,proto3" json:"status,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	EndedAt       *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=ended_at,json=endedAt,proto3" json:"ended_at,omitempty"`
+	Result        *structpb.Value        `protobuf:"bytes,12,opt,name=result,proto3" json:"result,omitempty"`
+	Error         *structpb.Value        `protobuf:"bytes,13,opt,name=error,proto3" json:"error,omitempty"`
+	Metrics       *structpb.Value        `protobuf:"bytes,14,opt,name=metrics,proto3" json:"metrics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Execution) Reset() {
+	*x = Execution{}
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Execution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Execution) ProtoMessage() {}
+
+func (x *Execution) ProtoReflect() protoreflect.Message {
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Execution.ProtoReflect.Descriptor instead.
+func (*Execution) Descriptor() ([]byte, []int) {
+	return file_vmsystem_v1_vmsystem_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Execution) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Execution) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *Execution) GetWorkspaceId() string {
+	if x != nil {
+		return x.WorkspaceId
+	}
+	return ""
+}
+
+func (x *Execution) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Execution) GetInput() string {
+	if x != nil {
+		return x.Input
+	}
+	return ""
+}
+
+func (x *Execution) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Execution) GetArgs() *structpb.Value {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *Execution) GetEnv() *structpb.Value {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+func (x *Execution) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Execution) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Execution) GetEndedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndedAt
+	}
+	return nil
+}
+
+func (x *Execution) GetResult() *structpb.Value {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *Execution) GetError() *structpb.Value {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+func (x *Execution) GetMetrics() *structpb.Value {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+type ExecutionEvent struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ExecutionId string                 `protobuf:"bytes,1,opt,name=execution_id,json=executionId,proto3" json:"execution_id,omitempty"`
+	Seq         int32                  `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	Ts          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=ts,proto3" json:"ts,omitempty"`
+	// stdout, stderr, console, value, exception, system, input_echo or net.
+	Type          string          `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	Payload       *structpb.Value `protobuf:"bytes,5,opt,name=payload,proto3" json:"payload,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecutionEvent) Reset() {
+	*x = ExecutionEvent{}
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecutionEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecutionEvent) ProtoMessage() {}
+
+func (x *ExecutionEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecutionEvent.ProtoReflect.Descriptor instead.
+func (*ExecutionEvent) Descriptor() ([]byte, []int) {
+	return file_vmsystem_v1_vmsystem_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ExecutionEvent) GetExecutionId() string {
+	if x != nil {
+		return x.ExecutionId
+	}
+	return ""
+}
+
+func (x *ExecutionEvent) GetSeq() int32 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *ExecutionEvent) GetTs() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Ts
+	}
+	return nil
+}
+
+func (x *ExecutionEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ExecutionEvent) GetPayload() *structpb.Value {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+// ExecutionUpdate is one message of an execution stream.
+type ExecutionUpdate struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Update:
+	//
+	//	*ExecutionUpdate_Started
+	//	*ExecutionUpdate_Event
+	//	*ExecutionUpdate_Finished
+	Update        isExecutionUpdate_Update `protobuf_oneof:"update"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecutionUpdate) Reset() {
+	*x = ExecutionUpdate{}
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecutionUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecutionUpdate) ProtoMessage() {}
+
+func (x *ExecutionUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecutionUpdate.ProtoReflect.Descriptor instead.
+func (*ExecutionUpdate) Descriptor() ([]byte, []int) {
+	return file_vmsystem_v1_vmsystem_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ExecutionUpdate) GetUpdate() isExecutionUpdate_Update {
+	if x != nil {
+		return x.Update
+	}
+	return nil
+}
+
+func (x *ExecutionUpdate) GetStarted() *Execution {
+	if x != nil {
+		if x, ok := x.Update.(*ExecutionUpdate_Started); ok {
+			return x.Started
+		}
+	}
+	return nil
+}
+
+func (x *ExecutionUpdate) GetEvent() *ExecutionEvent {
+	if x != nil {
+		if x, ok := x.Update.(*ExecutionUpdate_Event); ok {
+			return x.Event
+		}
+	}
+	return nil
+}
+
+func (x *ExecutionUpdate) GetFinished() *Execution {
+	if x != nil {
+		if x, ok := x.Update.(*ExecutionUpdate_Finished); ok {
+			return x.Finished
+		}
+	}
+	return nil
+}
+
+type isExecutionUpdate_Update interface {
+	isExecutionUpdate_Update()
+}
+
+type ExecutionUpdate_Started struct {
+	// The execution record once it is persisted, queued or running.
+	Started *Execution `protobuf:"bytes,1,opt,name=started,proto3,oneof"`
+}
+
+type ExecutionUpdate_Event struct {
+	// An event, once it is persisted.
+	Event *ExecutionEvent `protobuf:"bytes,2,opt,name=event,proto3,oneof"`
+}
+
+type ExecutionUpdate_Finished struct {
+	// The settled execution; always the last message.
+	Finished *Execution `protobuf:"bytes,3,opt,name=finished,proto3,oneof"`
+}
+
+func (*ExecutionUpdate_Started) isExecutionUpdate_Update() {}
+
+func (*ExecutionUpdate_Event) isExecutionUpdate_Update() {}
+
+func (*ExecutionUpdate_Finished) isExecutionUpdate_Update() {}
+
+type ExecuteREPLRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Input         string                 `protobuf:"bytes,2,opt,name=input,proto3" json:"input,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteREPLRequest) Reset() {
+	*x = ExecuteREPLRequest{}
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteREPLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteREPLRequest) ProtoMessage() {}
+
+func (x *ExecuteREPLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteREPLRequest.ProtoReflect.Descriptor instead.
+func (*ExecuteREPLRequest) Descriptor() ([]byte, []int) {
+	return file_vmsystem_v1_vmsystem_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ExecuteREPLRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *ExecuteREPLRequest) GetInput() string {
+	if x != nil {
+		return x.Input
+	}
+	return ""
+}
+
+type ExecuteRunFileRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SessionId string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	// Path relative to the session worktree.
+	Path          string           `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Args          *structpb.Struct `protobuf:"bytes,3,opt,name=args,proto3" json:"args,omitempty"`
+	Env           *structpb.Struct `protobuf:"bytes,4,opt,name=env,proto3" json:"env,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteRunFileRequest) Reset() {
+	*x = ExecuteRunFileRequest{}
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteRunFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteRunFileRequest) ProtoMessage() {}
+
+func (x *ExecuteRunFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteRunFileRequest.ProtoReflect.Descriptor instead.
+func (*ExecuteRunFileRequest) Descriptor() ([]byte, []int) {
+	return file_vmsystem_v1_vmsystem_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ExecuteRunFileRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *ExecuteRunFileRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ExecuteRunFileRequest) GetArgs() *structpb.Struct {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *ExecuteRunFileRequest) GetEnv() *structpb.Struct {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+type GetExecutionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExecutionId   string                 `protobuf:"bytes,1,opt,name=execution_id,json=executionId,proto3" json:"execution_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetExecutionRequest) Reset() {
+	*x = GetExecutionRequest{}
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetExecutionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetExecutionRequest) ProtoMessage() {}
+
+func (x *GetExecutionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetExecutionRequest.ProtoReflect.Descriptor instead.
+func (*GetExecutionRequest) Descriptor() ([]byte, []int) {
+	return file_vmsystem_v1_vmsystem_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetExecutionRequest) GetExecutionId() string {
+	if x != nil {
+		return x.ExecutionId
+	}
+	return ""
+}
+
+type ListExecutionsRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SessionId string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	// Defaults to 50.
+	Limit         int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListExecutionsRequest) Reset() {
+	*x = ListExecutionsRequest{}
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListExecutionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListExecutionsRequest) ProtoMessage() {}
+
+func (x *ListExecutionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListExecutionsRequest.ProtoReflect.Descriptor instead.
+func (*ListExecutionsRequest) Descriptor() ([]byte, []int) {
+	return file_vmsystem_v1_vmsystem_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListExecutionsRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *ListExecutionsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListExecutionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Executions    []*Execution           `protobuf:"bytes,1,rep,name=executions,proto3" json:"executions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListExecutionsResponse) Reset() {
+	*x = ListExecutionsResponse{}
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListExecutionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListExecutionsResponse) ProtoMessage() {}
+
+func (x *ListExecutionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListExecutionsResponse.ProtoReflect.Descriptor instead.
+func (*ListExecutionsResponse) Descriptor() ([]byte, []int) {
+	return file_vmsystem_v1_vmsystem_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListExecutionsResponse) GetExecutions() []*Execution {
+	if x != nil {
+		return x.Executions
+	}
+	return nil
+}
+
+type ListExecutionEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExecutionId   string                 `protobuf:"bytes,1,opt,name=execution_id,json=executionId,proto3" json:"execution_id,omitempty"`
+	AfterSeq      int32                  `protobuf:"varint,2,opt,name=after_seq,json=afterSeq,proto3" json:"after_seq,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListExecutionEventsRequest) Reset() {
+	*x = ListExecutionEventsRequest{}
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListExecutionEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListExecutionEventsRequest) ProtoMessage() {}
+
+func (x *ListExecutionEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListExecutionEventsRequest.ProtoReflect.Descriptor instead.
+func (*ListExecutionEventsRequest) Descriptor() ([]byte, []int) {
+	return file_vmsystem_v1_vmsystem_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListExecutionEventsRequest) GetExecutionId() string {
+	if x != nil {
+		return x.ExecutionId
+	}
+	return ""
+}
+
+func (x *ListExecutionEventsRequest) GetAfterSeq() int32 {
+	if x != nil {
+		return x.AfterSeq
+	}
+	return 0
+}
+
+type ListExecutionEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*ExecutionEvent      `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListExecutionEventsResponse) Reset() {
+	*x = ListExecutionEventsResponse{}
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListExecutionEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListExecutionEventsResponse) ProtoMessage() {}
+
+func (x *ListExecutionEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListExecutionEventsResponse.ProtoReflect.Descriptor instead.
+func (*ListExecutionEventsResponse) Descriptor() ([]byte, []int) {
+	return file_vmsystem_v1_vmsystem_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ListExecutionEventsResponse) GetEvents() []*ExecutionEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type CancelExecutionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExecutionId   string                 `protobuf:"bytes,1,opt,name=execution_id,json=executionId,proto3" json:"execution_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelExecutionRequest) Reset() {
+	*x = CancelExecutionRequest{}
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelExecutionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelExecutionRequest) ProtoMessage() {}
+
+func (x *CancelExecutionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vmsystem_v1_vmsystem_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelExecutionRequest.ProtoReflect.Descriptor instead.
+func (*CancelExecutionRequest) Descriptor() ([]byte, []int) {
+	return file_vmsystem_v1_vmsystem_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *CancelExecutionRequest) GetExecutionId() string {
+	if x != nil {
+		return x.ExecutionId
+	}
+	return ""
+}
+
+var File_vmsystem_v1_vmsystem_proto protoreflect.FileDescriptor
+
+const file_vmsystem_v1_vmsystem_proto_rawDesc = "" +
+	"\n" +
+	"\x1avmsystem/v1/vmsystem.proto\x12\vvmsystem.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xdf\x02\n" +
+	"\bTemplate\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06engine\x18\x03 \x01(\tR\x06engine\x12\x1b\n" +
+	"\tis_active\x18\x04 \x01(\bR\bisActive\x12'\n" +
+	"\x0fexposed_modules\x18\x05 \x03(\tR\x0eexposedModules\x12\x1c\n" +
+	"\tlibraries\x18\x06 \x03(\tR\tlibraries\x12\x1a\n" +
+	"\brevision\x18\a \x01(\x05R\brevision\x12!\n" +
+	"\fworkspace_id\x18\b \x01(\tR\vworkspaceId\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"f\n" +
+	"\x15CreateTemplateRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06engine\x18\x02 \x01(\tR\x06engine\x12!\n" +
+	"\fworkspace_id\x18\x03 \x01(\tR\vworkspaceId\"\x16\n" +
+	"\x14ListTemplatesRequest\"L\n" +
+	"\x15ListTemplatesResponse\x123\n" +
+	"\ttemplates\x18\x01 \x03(\v2\x15.vmsystem.v1.TemplateR\ttemplates\"5\n" +
+	"\x12GetTemplateRequest\x12\x1f\n" +
+	"\vtemplate_id\x18\x01 \x01(\tR\n" +
+	"templateId\"8\n" +
+	"\x15DeleteTemplateRequest\x12\x1f\n" +
+	"\vtemplate_id\x18\x01 \x01(\tR\n" +
+	"templateId\"\x18\n" +
+	"\x16DeleteTemplateResponse\"\x83\x04\n" +
+	"\aSession\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
+	"\vtemplate_id\x18\x02 \x01(\tR\n" +
+	"templateId\x12!\n" +
+	"\fworkspace_id\x18\x03 \x01(\tR\vworkspaceId\x12&\n" +
+	"\x0fbase_commit_oid\x18\x04 \x01(\tR\rbaseCommitOid\x12#\n" +
+	"\rworktree_path\x18\x05 \x01(\tR\fworktreePath\x12+\n" +
+	"\x11template_revision\x18\x06 \x01(\x05R\x10templateRevision\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06status\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12D\n" +
+	"\x10last_activity_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\x0elastActivityAt\x127\n" +
+	"\tclosed_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\bclosedAt\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\v \x01(\tR\tlastError\x129\n" +
+	"\n" +
+	"expires_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"\xa7\x01\n" +
+	"\x14CreateSessionRequest\x12\x1f\n" +
+	"\vtemplate_id\x18\x01 \x01(\tR\n" +
+	"templateId\x12!\n" +
+	"\fworkspace_id\x18\x02 \x01(\tR\vworkspaceId\x12&\n" +
+	"\x0fbase_commit_oid\x18\x03 \x01(\tR\rbaseCommitOid\x12#\n" +
+	"\rworktree_path\x18\x04 \x01(\tR\fworktreePath\"P\n" +
+	"\x13ListSessionsRequest\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12!\n" +
+	"\fworkspace_id\x18\x02 \x01(\tR\vworkspaceId\"H\n" +
+	"\x14ListSessionsResponse\x120\n" +
+	"\bsessions\x18\x01 \x03(\v2\x14.vmsystem.v1.SessionR\bsessions\"2\n" +
+	"\x11GetSessionRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"4\n" +
+	"\x13CloseSessionRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"\x8b\x04\n" +
+	"\tExecution\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\x12!\n" +
+	"\fworkspace_id\x18\x03 \x01(\tR\vworkspaceId\x12\x12\n" +
+	"\x04kind\x18\x04 \x01(\tR\x04kind\x12\x14\n" +
+	"\x05input\x18\x05 \x01(\tR\x05input\x12\x12\n" +
+	"\x04path\x18\x06 \x01(\tR\x04path\x12*\n" +
+	"\x04args\x18\a \x01(\v2\x16.google.protobuf.ValueR\x04args\x12(\n" +
+	"\x03env\x18\b \x01(\v2\x16.google.protobuf.ValueR\x03env\x12\x16\n" +
+	"\x06status\x18\t \x01(\tR\x06status\x129\n" +
+	"\n" +
+	"started_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x125\n" +
+	"\bended_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\aendedAt\x12.\n" +
+	"\x06result\x18\f \x01(\v2\x16.google.protobuf.ValueR\x06result\x12,\n" +
+	"\x05error\x18\r \x01(\v2\x16.google.protobuf.ValueR\x05error\x120\n" +
+	"\ametrics\x18\x0e \x01(\v2\x16.google.protobuf.ValueR\ametrics\"\xb7\x01\n" +
+	"\x0eExecutionEvent\x12!\n" +
+	"\fexecution_id\x18\x01 \x01(\tR\vexecutionId\x12\x10\n" +
+	"\x03seq\x18\x02 \x01(\x05R\x03seq\x12*\n" +
+	"\x02ts\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x02ts\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x120\n" +
+	"\apayload\x18\x05 \x01(\v2\x16.google.protobuf.ValueR\apayload\"\xba\x01\n" +
+	"\x0fExecutionUpdate\x122\n" +
+	"\astarted\x18\x01 \x01(\v2\x16.vmsystem.v1.ExecutionH\x00R\astarted\x123\n" +
+	"\x05event\x18\x02 \x01(\v2\x1b.vmsystem.v1.ExecutionEventH\x00R\x05event\x124\n" +
+	"\bfinished\x18\x03 \x01(\v2\x16.vmsystem.v1.ExecutionH\x00R\bfinishedB\b\n" +
+	"\x06update\"I\n" +
+	"\x12ExecuteREPLRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x14\n" +
+	"\x05input\x18\x02 \x01(\tR\x05input\"\xa2\x01\n" +
+	"\x15ExecuteRunFileRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12+\n" +
+	"\x04args\x18\x03 \x01(\v2\x17.google.protobuf.StructR\x04args\x12)\n" +
+	"\x03env\x18\x04 \x01(\v2\x17.google.protobuf.StructR\x03env\"8\n" +
+	"\x13GetExecutionRequest\x12!\n" +
+	"\fexecution_id\x18\x01 \x01(\tR\vexecutionId\"L\n" +
+	"\x15ListExecutionsRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"P\n" +
+	"\x16ListExecutionsResponse\x126\n" +
+	"\n" +
+	"executions\x18\x01 \x03(\v2\x16.vmsystem.v1.ExecutionR\n" +
+	"executions\"\\\n" +
+	"\x1aListExecutionEventsRequest\x12!\n" +
+	"\fexecution_id\x18\x01 \x01(\tR\vexecutionId\x12\x1b\n" +
+	"\tafter_seq\x18\x02 \x01(\x05R\bafterSeq\"R\n" +
+	"\x1bListExecutionEventsResponse\x123\n" +
+	"\x06events\x18\x01 \x03(\v2\x1b.vmsystem.v1.ExecutionEventR\x06events\";\n" +
+	"\x16CancelExecutionRequest\x12!\n" +
+	"\fexecution_id\x18\x01 \x01(\tR\vexecutionId2\xd8\x02\n" +
+	"\x0fTemplateService\x12K\n" +
+	"\x0eCreateTemplate\x12\".vmsystem.v1.CreateTemplateRequest\x1a\x15.vmsystem.v1.Template\x12V\n" +
+	"\rListTemplates\x12!.vmsystem.v1.ListTemplatesRequest\x1a\".vmsystem.v1.ListTemplatesResponse\x12E\n" +
+	"\vGetTemplate\x12\x1f.vmsystem.v1.GetTemplateRequest\x1a\x15.vmsystem.v1.Template\x12Y\n" +
+	"\x0eDeleteTemplate\x12\".vmsystem.v1.DeleteTemplateRequest\x1a#.vmsystem.v1.DeleteTemplateResponse2\xbb\x02\n" +
+	"\x0eSessionService\x12H\n" +
+	"\rCreateSession\x12!.vmsystem.v1.CreateSessionRequest\x1a\x14.vmsystem.v1.Session\x12S\n" +
+	"\fListSessions\x12 .vmsystem.v1.ListSessionsRequest\x1a!.vmsystem.v1.ListSessionsResponse\x12B\n" +
+	"\n" +
+	"GetSession\x12\x1e.vmsystem.v1.GetSessionRequest\x1a\x14.vmsystem.v1.Session\x12F\n" +
+	"\fCloseSession\x12 .vmsystem.v1.CloseSessionRequest\x1a\x14.vmsystem.v1.Session2\xab\x05\n" +
+	"\x10ExecutionService\x12F\n" +
+	"\vExecuteREPL\x12\x1f.vmsystem.v1.ExecuteREPLRequest\x1a\x16.vmsystem.v1.Execution\x12L\n" +
+	"\x0eExecuteRunFile\x12\".vmsystem.v1.ExecuteRunFileRequest\x1a\x16.vmsystem.v1.Execution\x12M\n" +
+	"\n" +
+	"StreamREPL\x12\x1f.vmsystem.v1.ExecuteREPLRequest\x1a\x1c.vmsystem.v1.ExecutionUpdate0\x01\x12S\n" +
+	"\rStreamRunFile\x12\".vmsystem.v1.ExecuteRunFileRequest\x1a\x1c.vmsystem.v1.ExecutionUpdate0\x01\x12H\n" +
+	"\fGetExecution\x12 .vmsystem.v1.GetExecutionRequest\x1a\x16.vmsystem.v1.Execution\x12Y\n" +
+	"\x0eListExecutions\x12\".vmsystem.v1.ListExecutionsRequest\x1a#.vmsystem.v1.ListExecutionsResponse\x12h\n" +
+	"\x13ListExecutionEvents\x12'.vmsystem.v1.ListExecutionEventsRequest\x1a(.vmsystem.v1.ListExecutionEventsResponse\x12N\n" +
+	"\x0fCancelExecution\x12#.vmsystem.v1.CancelExecutionRequest\x1a\x16.vmsystem.v1.ExecutionBNZLgithub.com/go-go-golems/vm-system/pkg/vmtransport/grpc/vmsystemv1;vmsystemv1b\x06proto3"
+
+var (
+	file_vmsystem_v1_vmsystem_proto_rawDescOnce sync.Once
+	file_vmsystem_v1_vmsystem_proto_rawDescData []byte
+)
+
+func file_vmsystem_v1_vmsystem_proto_rawDescGZIP() []byte {
+	file_vmsystem_v1_vmsystem_proto_rawDescOnce.Do(func() {
+		file_vmsystem_v1_vmsystem_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_vmsystem_v1_vmsystem_proto_rawDesc), len(file_vmsystem_v1_vmsystem_proto_rawDesc)))
+	})
+	return file_vmsystem_v1_vmsystem_proto_rawDescData
+}
+
+var file_vmsystem_v1_vmsystem_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
+var file_vmsystem_v1_vmsystem_proto_goTypes = []any{
+	(*Template)(nil),                    // 0: vmsystem.v1.Template
+	(*CreateTemplateRequest)(nil),       // 1: vmsystem.v1.CreateTemplateRequest
+	(*ListTemplatesRequest)(nil),        // 2: vmsystem.v1.ListTemplatesRequest
+	(*ListTemplatesResponse)(nil),       // 3: vmsystem.v1.ListTemplatesResponse
+	(*GetTemplateRequest)(nil),          // 4: vmsystem.v1.GetTemplateRequest
+	(*DeleteTemplateRequest)(nil),       // 5: vmsystem.v1.DeleteTemplateRequest
+	(*DeleteTemplateResponse)(nil),      // 6: vmsystem.v1.DeleteTemplateResponse
+	(*Session)(nil),                     // 7: vmsystem.v1.Session
+	(*CreateSessionRequest)(nil),        // 8: vmsystem.v1.CreateSessionRequest
+	(*ListSessionsRequest)(nil),         // 9: vmsystem.v1.ListSessionsRequest
+	(*ListSessionsResponse)(nil),        // 10: vmsystem.v1.ListSessionsResponse
+	(*GetSessionRequest)(nil),           // 11: vmsystem.v1.GetSessionRequest
+	(*CloseSessionRequest)(nil),         // 12: vmsystem.v1.CloseSessionRequest
+	(*Execution)(nil),                   // 13: vmsystem.v1.Execution
+	(*ExecutionEvent)(nil),              // 14: vmsystem.v1.ExecutionEvent
+	(*ExecutionUpdate)(nil),             // 15: vmsystem.v1.ExecutionUpdate
+	(*ExecuteREPLRequest)(nil),          // 16: vmsystem.v1.ExecuteREPLRequest
+	(*ExecuteRunFileRequest)(nil),       // 17: vmsystem.v1.ExecuteRunFileRequest
+	(*GetExecutionRequest)(nil),         // 18: vmsystem.v1.GetExecutionRequest
+	(*ListExecutionsRequest)(nil),       // 19: vmsystem.v1.ListExecutionsRequest
+	(*ListExecutionsResponse)(nil),      // 20: vmsystem.v1.ListExecutionsResponse
+	(*ListExecutionEventsRequest)(nil),  // 21: vmsystem.v1.ListExecutionEventsRequest
+	(*ListExecutionEventsResponse)(nil), // 22: vmsystem.v1.ListExecutionEventsResponse
+	(*CancelExecutionRequest)(nil),      // 23: vmsystem.v1.CancelExecutionRequest
+	(*timestamppb.Timestamp)(nil),       // 24: google.protobuf.Timestamp
+	(*structpb.Value)(nil),              // 25: google.protobuf.Value
+	(*structpb.Struct)(nil),             // 26: google.protobuf.Struct
+}
+var file_vmsystem_v1_vmsystem_proto_depIdxs = []int32{
+	24, // 0: vmsystem.v1.Template.created_at:type_name -> google.protobuf.Timestamp
+	24, // 1: vmsystem.v1.Template.updated_at:type_name -> google.protobuf.Timestamp
+	0,  // 2: vmsystem.v1.ListTemplatesResponse.templates:type_name -> vmsystem.v1.Template
+	24, // 3: vmsystem.v1.Session.created_at:type_name -> google.protobuf.Timestamp
+	24, // 4: vmsystem.v1.Session.last_activity_at:type_name -> google.protobuf.Timestamp
+	24, // 5: vmsystem.v1.Session.closed_at:type_name -> google.protobuf.Timestamp
+	24, // 6: vmsystem.v1.Session.expires_at:type_name -> google.protobuf.Timestamp
+	7,  // 7: vmsystem.v1.ListSessionsResponse.sessions:type_name -> vmsystem.v1.Session
+	25, // 8: vmsystem.v1.Execution.args:type_name -> google.protobuf.Value
+	25, // 9: vmsystem.v1.Execution.env:type_name -> google.protobuf.Value
+	24, // 10: vmsystem.v1.Execution.started_at:type_name -> google.protobuf.Timestamp
+	24, // 11: vmsystem.v1.Execution.ended_at:type_name -> google.protobuf.Timestamp
+	25, // 12: vmsystem.v1.Execution.result:type_name -> google.protobuf.Value
+	25, // 13: vmsystem.v1.Execution.error:type_name -> google.protobuf.Value
+	25, // 14: vmsystem.v1.Execution.metrics:type_name -> google.protobuf.Value
+	24, // 15: vmsystem.v1.ExecutionEvent.ts:type_name -> google.protobuf.Timestamp
+	25, // 16: vmsystem.v1.ExecutionEvent.payload:type_name -> google.protobuf.Value
+	13, // 17: vmsystem.v1.ExecutionUpdate.started:type_name -> vmsystem.v1.Execution
+	14, // 18: vmsystem.v1.ExecutionUpdate.event:type_name -> vmsystem.v1.ExecutionEvent
+	13, // 19: vmsystem.v1.ExecutionUpdate.finished:type_name -> vmsystem.v1.Execution
+	26, // 20: vmsystem.v1.ExecuteRunFileRequest.args:type_name -> google.protobuf.Struct
+	26, // 21: vmsystem.v1.ExecuteRunFileRequest.env:type_name -> google.protobuf.Struct
+	13, // 22: vmsystem.v1.ListExecutionsResponse.executions:type_name -> vmsystem.v1.Execution
+	14, // 23: vmsystem.v1.ListExecutionEventsResponse.events:type_name -> vmsystem.v1.ExecutionEvent
+	1,  // 24: vmsystem.v1.TemplateService.CreateTemplate:input_type -> vmsystem.v1.CreateTemplateRequest
+	2,  // 25: vmsystem.v1.TemplateService.ListTemplates:input_type -> vmsystem.v1.ListTemplatesRequest
+	4,  // 26: vmsystem.v1.TemplateService.GetTemplate:input_type -> vmsystem.v1.GetTemplateRequest
+	5,  // 27: vmsystem.v1.TemplateService.DeleteTemplate:input_type -> vmsystem.v1.DeleteTemplateRequest
+	8,  // 28: vmsystem.v1.SessionService.CreateSession:input_type -> vmsystem.v1.CreateSessionRequest
+	9,  // 29: vmsystem.v1.SessionService.ListSessions:input_type -> vmsystem.v1.ListSessionsRequest
+	11, // 30: vmsystem.v1.SessionService.GetSession:input_type -> vmsystem.v1.GetSessionRequest
+	12, // 31: vmsystem.v1.SessionService.CloseSession:input_type -> vmsystem.v1.CloseSessionRequest
+	16, // 32: vmsystem.v1.ExecutionService.ExecuteREPL:input_type -> vmsystem.v1.ExecuteREPLRequest
+	17, // 33: vmsystem.v1.ExecutionService.ExecuteRunFile:input_type -> vmsystem.v1.ExecuteRunFileRequest
+	16, // 34: vmsystem.v1.ExecutionService.StreamREPL:input_type -> vmsystem.v1.ExecuteREPLRequest
+	17, // 35: vmsystem.v1.ExecutionService.StreamRunFile:input_type -> vmsystem.v1.ExecuteRunFileRequest
+	18, // 36: vmsystem.v1.ExecutionService.GetExecution:input_type -> vmsystem.v1.GetExecutionRequest
+	19, // 37: vmsystem.v1.ExecutionService.ListExecutions:input_type -> vmsystem.v1.ListExecutionsRequest
+	21, // 38: vmsystem.v1.ExecutionService.ListExecutionEvents:input_type -> vmsystem.v1.ListExecutionEventsRequest
+	23, // 39: vmsystem.v1.ExecutionService.CancelExecution:input_type -> vmsystem.v1.CancelExecutionRequest
+	0,  // 40: vmsystem.v1.TemplateService.CreateTemplate:output_type -> vmsystem.v1.Template
+	3,  // 41: vmsystem.v1.TemplateService.ListTemplates:output_type -> vmsystem.v1.ListTemplatesResponse
+	0,  // 42: vmsystem.v1.TemplateService.GetTemplate:output_type -> vmsystem.v1.Template
+	6,  // 43: vmsystem.v1.TemplateService.DeleteTemplate:output_type -> vmsystem.v1.DeleteTemplateResponse
+	7,  // 44: vmsystem.v1.SessionService.CreateSession:output_type -> vmsystem.v1.Session
+	10, // 45: vmsystem.v1.SessionService.ListSessions:output_type -> vmsystem.v1.ListSessionsResponse
+	7,  // 46: vmsystem.v1.SessionService.GetSession:output_type -> vmsystem.v1.Session
+	7,  // 47: vmsystem.v1.SessionService.CloseSession:output_type -> vmsystem.v1.Session
+	13, // 48: vmsystem.v1.ExecutionService.ExecuteREPL:output_type -> vmsystem.v1.Execution
+	13, // 49: vmsystem.v1.ExecutionService.ExecuteRunFile:output_type -> vmsystem.v1.Execution
+	15, // 50: vmsystem.v1.ExecutionService.StreamREPL:output_type -> vmsystem.v1.ExecutionUpdate
+	15, // 51: vmsystem.v1.ExecutionService.StreamRunFile:output_type -> vmsystem.v1.ExecutionUpdate
+	13, // 52: vmsystem.v1.ExecutionService.GetExecution:output_type -> vmsystem.v1.Execution
+	20, // 53: vmsystem.v1.ExecutionService.ListExecutions:output_type -> vmsystem.v1.ListExecutionsResponse
+	22, // 54: vmsystem.v1.ExecutionService.ListExecutionEvents:output_type -> vmsystem.v1.ListExecutionEventsResponse
+	13, // 55: vmsystem.v1.ExecutionService.CancelExecution:output_type -> vmsystem.v1.Execution
+	40, // [40:56] is the sub-list for method output_type
+	24, // [24:40] is the sub-list for method input_type
+	24, // [24:24] is the sub-list for extension type_name
+	24, // [24:24] is the sub-list for extension extendee
+	0,  // [0:24] is the sub-list for field type_name
+}
+
+func init() { file_vmsystem_v1_vmsystem_proto_init() }
+func file_vmsystem_v1_vmsystem_proto_init() {
+	if File_vmsystem_v1_vmsystem_proto != nil {
+		return
+	}
+	file_vmsystem_v1_vmsystem_proto_msgTypes[15].OneofWrappers = []any{
+		(*ExecutionUpdate_Started)(nil),
+		(*ExecutionUpdate_Event)(nil),
+		(*ExecutionUpdate_Finished)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_vmsystem_v1_vmsystem_proto_rawDesc), len(file_vmsystem_v1_vmsystem_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   24,
+			NumExtensions: 0,
+			NumServices:   3,
+		},
+		GoTypes:           file_vmsystem_v1_vmsystem_proto_goTypes,
+		DependencyIndexes: file_vmsystem_v1_vmsystem_proto_depIdxs,
+		MessageInfos:      file_vmsystem_v1_vmsystem_proto_msgTypes,
+	}.Build()
+	File_vmsystem_v1_vmsystem_proto = out.File
+	file_vmsystem_v1_vmsystem_proto_goTypes = nil
+	file_vmsystem_v1_vmsystem_proto_depIdxs = nil
+}
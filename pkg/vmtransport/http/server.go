@@ -1,6 +1,7 @@
 package vmhttp
 
 import (
+	"context"
 	stdhttp "net/http"
 
 	"github.com/google/uuid"
@@ -12,7 +13,11 @@ type Server struct {
 	core *vmcontrol.Core
 }
 
-func NewHandler(core *vmcontrol.Core) stdhttp.Handler {
+func NewHandler(core *vmcontrol.Core, opts ...HandlerOption) stdhttp.Handler {
+	cfg := handlerConfig{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 	s := &Server{core: core}
 	mux := stdhttp.NewServeMux()
 
@@ -20,44 +25,75 @@ func NewHandler(core *vmcontrol.Core) stdhttp.Handler {
 	mux.HandleFunc("GET /api/v1/health", s.handleHealth)
 	mux.HandleFunc("GET /api/v1/runtime/summary", s.handleRuntimeSummary)
 
+	// Catalog APIs.
+	mux.HandleFunc("GET /api/v1/modules", s.handleModuleCatalog)
+	mux.HandleFunc("GET /api/v1/libraries", s.handleLibraryList)
+	mux.Handle("POST /api/v1/libraries", s.audited("library.register", s.handleLibraryRegister))
+	mux.HandleFunc("GET /api/v1/libraries/{library_ref}", s.handleLibraryGet)
+	mux.Handle("DELETE /api/v1/libraries/{library_ref}", s.audited("library.remove", s.handleLibraryDelete))
+
 	// Template APIs.
 	mux.HandleFunc("GET /api/v1/templates", s.handleTemplateList)
-	mux.HandleFunc("POST /api/v1/templates", s.handleTemplateCreate)
+	mux.Handle("POST /api/v1/templates", s.audited("template.create", s.handleTemplateCreate))
+	mux.Handle("POST /api/v1/templates:apply", s.audited("template.apply", s.handleTemplateApply))
 	mux.HandleFunc("GET /api/v1/templates/{template_id}", s.handleTemplateGet)
-	mux.HandleFunc("DELETE /api/v1/templates/{template_id}", s.handleTemplateDelete)
+	mux.Handle("PATCH /api/v1/templates/{template_id}", s.audited("template.update", s.handleTemplateUpdate))
+	mux.Handle("DELETE /api/v1/templates/{template_id}", s.audited("template.delete", s.handleTemplateDelete))
+	mux.Handle("POST /api/v1/templates/{template_id}/clone", s.audited("template.clone", s.handleTemplateClone))
+	mux.HandleFunc("GET /api/v1/templates/{template_id}/export", s.handleTemplateExport)
+	mux.HandleFunc("GET /api/v1/templates/{template_id}/revisions", s.handleTemplateListRevisions)
+	mux.HandleFunc("GET /api/v1/templates/{template_id}/revisions/{revision}", s.handleTemplateGetRevision)
 	mux.HandleFunc("GET /api/v1/templates/{template_id}/capabilities", s.handleTemplateListCapabilities)
-	mux.HandleFunc("POST /api/v1/templates/{template_id}/capabilities", s.handleTemplateAddCapability)
+	mux.Handle("POST /api/v1/templates/{template_id}/capabilities", s.audited("template.capability.add", s.handleTemplateAddCapability))
+	mux.Handle("DELETE /api/v1/templates/{template_id}/capabilities/{capability_id}", s.audited("template.capability.remove", s.handleTemplateDeleteCapability))
 	mux.HandleFunc("GET /api/v1/templates/{template_id}/modules", s.handleTemplateListModules)
-	mux.HandleFunc("POST /api/v1/templates/{template_id}/modules", s.handleTemplateAddModule)
-	mux.HandleFunc("DELETE /api/v1/templates/{template_id}/modules/{module_name}", s.handleTemplateRemoveModule)
+	mux.Handle("POST /api/v1/templates/{template_id}/modules", s.audited("template.module.add", s.handleTemplateAddModule))
+	mux.Handle("DELETE /api/v1/templates/{template_id}/modules/{module_name}", s.audited("template.module.remove", s.handleTemplateRemoveModule))
 	mux.HandleFunc("GET /api/v1/templates/{template_id}/libraries", s.handleTemplateListLibraries)
-	mux.HandleFunc("POST /api/v1/templates/{template_id}/libraries", s.handleTemplateAddLibrary)
-	mux.HandleFunc("DELETE /api/v1/templates/{template_id}/libraries/{library_name}", s.handleTemplateRemoveLibrary)
+	mux.Handle("POST /api/v1/templates/{template_id}/libraries", s.audited("template.library.add", s.handleTemplateAddLibrary))
+	mux.Handle("DELETE /api/v1/templates/{template_id}/libraries/{library_name}", s.audited("template.library.remove", s.handleTemplateRemoveLibrary))
 	mux.HandleFunc("GET /api/v1/templates/{template_id}/startup-files", s.handleTemplateListStartupFiles)
-	mux.HandleFunc("POST /api/v1/templates/{template_id}/startup-files", s.handleTemplateAddStartupFile)
+	mux.Handle("POST /api/v1/templates/{template_id}/startup-files", s.audited("template.startup_file.add", s.handleTemplateAddStartupFile))
+	mux.Handle("POST /api/v1/templates/{template_id}/startup-files:reorder", s.audited("template.startup_file.reorder", s.handleTemplateReorderStartupFiles))
+	mux.Handle("PATCH /api/v1/templates/{template_id}/startup-files/{startup_file_id}", s.audited("template.startup_file.update", s.handleTemplateUpdateStartupFile))
+	mux.Handle("DELETE /api/v1/templates/{template_id}/startup-files/{startup_file_id}", s.audited("template.startup_file.remove", s.handleTemplateDeleteStartupFile))
+
+	// Workspace APIs.
+	mux.HandleFunc("GET /api/v1/workspaces", s.handleWorkspaceList)
+	mux.Handle("POST /api/v1/workspaces", s.audited("workspace.create", s.handleWorkspaceCreate))
+	mux.HandleFunc("GET /api/v1/workspaces/{workspace_id}", s.handleWorkspaceGet)
 
 	// Session APIs.
 	mux.HandleFunc("GET /api/v1/sessions", s.handleSessionList)
-	mux.HandleFunc("POST /api/v1/sessions", s.handleSessionCreate)
+	mux.Handle("POST /api/v1/sessions", s.audited("session.create", s.handleSessionCreate))
 	mux.HandleFunc("GET /api/v1/sessions/{session_id}", s.handleSessionGet)
-	mux.HandleFunc("POST /api/v1/sessions/{session_id}/close", s.handleSessionClose)
-	mux.HandleFunc("DELETE /api/v1/sessions/{session_id}", s.handleSessionDelete)
+	mux.Handle("POST /api/v1/sessions/{session_id}/close", s.audited("session.close", s.handleSessionClose))
+	mux.Handle("DELETE /api/v1/sessions/{session_id}", s.audited("session.close", s.handleSessionDelete))
+	mux.HandleFunc("GET /api/v1/sessions/{session_id}/ws", s.handleSessionSocket)
 
 	// Execution APIs.
 	mux.HandleFunc("GET /api/v1/executions", s.handleExecutionList)
-	mux.HandleFunc("POST /api/v1/executions/repl", s.handleExecutionREPL)
-	mux.HandleFunc("POST /api/v1/executions/run-file", s.handleExecutionRunFile)
+	mux.Handle("POST /api/v1/executions/repl", s.audited("execution.repl", s.handleExecutionREPL))
+	mux.Handle("POST /api/v1/executions/run-file", s.audited("execution.run_file", s.handleExecutionRunFile))
 	mux.HandleFunc("GET /api/v1/executions/{execution_id}", s.handleExecutionGet)
 	mux.HandleFunc("GET /api/v1/executions/{execution_id}/events", s.handleExecutionEvents)
+	mux.Handle("POST /api/v1/executions/{execution_id}/cancel", s.audited("execution.cancel", s.handleExecutionCancel))
+
+	// Audit trail.
+	mux.HandleFunc("GET /api/v1/audit", s.handleAuditList)
 
-	return withRequestID(mux)
+	var handler stdhttp.Handler = mux
+	if cfg.requireAuth {
+		handler = withAuth(core.Auth, handler, s.auditRejected(mux))
+	}
+	return withRequestID(handler)
 }
 
 func withRequestID(next stdhttp.Handler) stdhttp.Handler {
 	return stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
 		requestID := uuid.NewString()
 		w.Header().Set("X-Request-Id", requestID)
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, requestID)))
 	})
 }
 
@@ -70,3 +106,7 @@ func (s *Server) handleHealth(w stdhttp.ResponseWriter, _ *stdhttp.Request) {
 func (s *Server) handleRuntimeSummary(w stdhttp.ResponseWriter, r *stdhttp.Request) {
 	writeJSON(w, stdhttp.StatusOK, s.core.Registry.Summary(r.Context()))
 }
+
+func (s *Server) handleModuleCatalog(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	writeJSON(w, stdhttp.StatusOK, s.core.Templates.ListAvailableModules(r.Context()))
+}
@@ -0,0 +1,293 @@
+package vmhttp
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stdhttp "net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+// maxAuditErrorBody bounds how much of an error response is kept to read its
+// error code.
+const maxAuditErrorBody = 4096
+
+type requestIDKey struct{}
+
+func requestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+type auditNoteKey struct{}
+
+// auditNote collects what a handler knows about the resource it acted on,
+// beyond what the route pattern names.
+type auditNote struct {
+	resourceID  string
+	workspaceID string
+	details     map[string]interface{}
+}
+
+// auditResource records the ID and workspace of the resource a handler
+// created or acted on.
+func auditResource(r *stdhttp.Request, resourceID, workspaceID string) {
+	if note, ok := r.Context().Value(auditNoteKey{}).(*auditNote); ok {
+		note.resourceID = resourceID
+		note.workspaceID = workspaceID
+	}
+}
+
+// auditDetail adds key to the audit event's details.
+func auditDetail(r *stdhttp.Request, key string, value interface{}) {
+	if note, ok := r.Context().Value(auditNoteKey{}).(*auditNote); ok {
+		note.details[key] = value
+	}
+}
+
+// auditHash returns the hex SHA-256 of a string's bytes, or of the JSON
+// encoding of any other value, so audit events can identify execution input
+// without storing it.
+func auditHash(v interface{}) string {
+	data, ok := v.(string)
+	if !ok {
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		data = string(encoded)
+	}
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// auditedRoute is a route handler whose requests append an audit event
+// named action.
+type auditedRoute struct {
+	server *Server
+	action string
+	next   stdhttp.HandlerFunc
+}
+
+// audited wraps a route handler so every request to it appends an audit
+// event named action. The route's first path wildcard is the resource ID
+// unless the handler records one; the other wildcards become details.
+func (s *Server) audited(action string, next stdhttp.HandlerFunc) *auditedRoute {
+	return &auditedRoute{server: s, action: action, next: next}
+}
+
+func (route *auditedRoute) ServeHTTP(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	note := &auditNote{details: map[string]interface{}{}}
+	recorder := &auditRecorder{ResponseWriter: w, status: stdhttp.StatusOK}
+	route.next(recorder, r.WithContext(context.WithValue(r.Context(), auditNoteKey{}, note)))
+
+	for i, name := range patternWildcards(r.Pattern) {
+		if i == 0 && note.resourceID == "" {
+			note.resourceID = r.PathValue(name)
+			continue
+		}
+		note.details[name] = r.PathValue(name)
+	}
+	errorCode := ""
+	if recorder.status >= stdhttp.StatusBadRequest {
+		errorCode = recorder.errorCode()
+	}
+	route.server.recordAudit(r, route.action, note, recorder.status, errorCode)
+}
+
+// auditRejected returns the hook withAuth calls when it turns a request
+// away. A rejected request never reaches its route, so the hook looks the
+// route up in mux and records a failure when the route is audited.
+func (s *Server) auditRejected(mux *stdhttp.ServeMux) func(*stdhttp.Request, int, string) {
+	return func(r *stdhttp.Request, status int, errorCode string) {
+		handler, pattern := mux.Handler(r)
+		route, ok := handler.(*auditedRoute)
+		if !ok {
+			return
+		}
+		note := &auditNote{details: map[string]interface{}{}}
+		for i, value := range patternValues(pattern, r.URL.Path) {
+			if i == 0 {
+				note.resourceID = value.value
+				continue
+			}
+			note.details[value.name] = value.value
+		}
+		s.recordAudit(r, route.action, note, status, errorCode)
+	}
+}
+
+// recordAudit appends the audit event of an action taken on behalf of r's
+// caller. A status of 400 or above records a failure.
+func (s *Server) recordAudit(r *stdhttp.Request, action string, note *auditNote, status int, errorCode string) {
+	resourceType, _, _ := strings.Cut(action, ".")
+	event := &vmmodels.AuditEvent{
+		RequestID:    requestIDFromContext(r.Context()),
+		Actor:        "anonymous",
+		RemoteAddr:   r.RemoteAddr,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   note.resourceID,
+		WorkspaceID:  note.workspaceID,
+		Outcome:      vmmodels.AuditOutcomeSuccess,
+		StatusCode:   status,
+	}
+	if subject, ok := clientCertSubject(r); ok {
+		event.Actor = "cert:" + subject
+	}
+	if token, ok := vmcontrol.TokenFromContext(r.Context()); ok {
+		event.Actor = "token:" + token.Name
+		event.TokenID = token.ID
+		if token.WorkspaceID != "" {
+			event.WorkspaceID = token.WorkspaceID
+		}
+	}
+	if status >= stdhttp.StatusBadRequest {
+		event.Outcome = vmmodels.AuditOutcomeFailure
+		event.ErrorCode = errorCode
+	}
+	if len(note.details) > 0 {
+		if data, err := json.Marshal(note.details); err == nil {
+			event.Details = data
+		}
+	}
+
+	if err := s.core.Audit.Record(r.Context(), event); err != nil {
+		log.Warn().Err(err).
+			Str("request_id", event.RequestID).
+			Str("action", action).
+			Msg("failed to record audit event")
+	}
+}
+
+// patternWildcards returns the wildcard names of a ServeMux pattern in order.
+func patternWildcards(pattern string) []string {
+	var names []string
+	for {
+		start := strings.IndexByte(pattern, '{')
+		if start < 0 {
+			return names
+		}
+		end := strings.IndexByte(pattern[start:], '}')
+		if end < 0 {
+			return names
+		}
+		names = append(names, strings.TrimSuffix(pattern[start+1:start+end], "..."))
+		pattern = pattern[start+end+1:]
+	}
+}
+
+type pathValue struct {
+	name  string
+	value string
+}
+
+// patternValues matches path against a ServeMux pattern the mux already
+// chose for it and returns its wildcard values in order.
+func patternValues(pattern, path string) []pathValue {
+	if _, rest, ok := strings.Cut(pattern, " "); ok {
+		pattern = rest
+	}
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	var values []pathValue
+	for i, segment := range patternSegments {
+		if i >= len(pathSegments) {
+			break
+		}
+		if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+			continue
+		}
+		name := segment[1 : len(segment)-1]
+		value := pathSegments[i]
+		if trimmed, ok := strings.CutSuffix(name, "..."); ok {
+			name = trimmed
+			value = strings.Join(pathSegments[i:], "/")
+		}
+		values = append(values, pathValue{name: name, value: value})
+	}
+	return values
+}
+
+// auditRecorder remembers a response's status and the start of an error
+// body.
+type auditRecorder struct {
+	stdhttp.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *auditRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *auditRecorder) Write(p []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(stdhttp.StatusOK)
+	}
+	if r.status >= stdhttp.StatusBadRequest && r.body.Len() < maxAuditErrorBody {
+		r.body.Write(p[:min(len(p), maxAuditErrorBody-r.body.Len())])
+	}
+	return r.ResponseWriter.Write(p)
+}
+
+func (r *auditRecorder) errorCode() string {
+	var env errorEnvelope
+	if err := json.Unmarshal(r.body.Bytes(), &env); err != nil {
+		return ""
+	}
+	return env.Error.Code
+}
+
+func (s *Server) handleAuditList(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	query := r.URL.Query()
+	filter := vmmodels.AuditFilter{
+		Action:       query.Get("action"),
+		Actor:        query.Get("actor"),
+		ResourceType: query.Get("resource_type"),
+		ResourceID:   query.Get("resource_id"),
+		WorkspaceID:  query.Get("workspace_id"),
+		Outcome:      query.Get("outcome"),
+	}
+	if filter.Outcome != "" && filter.Outcome != vmmodels.AuditOutcomeSuccess && filter.Outcome != vmmodels.AuditOutcomeFailure {
+		writeError(w, stdhttp.StatusBadRequest, "VALIDATION_ERROR", "outcome must be success or failure", nil)
+		return
+	}
+	if raw := query.Get("since"); raw != "" {
+		since, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			writeError(w, stdhttp.StatusBadRequest, "VALIDATION_ERROR", "since must be an RFC 3339 timestamp", map[string]string{"since": raw})
+			return
+		}
+		filter.Since = since
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			writeError(w, stdhttp.StatusBadRequest, "VALIDATION_ERROR", "limit must be a positive integer", nil)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	events, err := s.core.Audit.List(r.Context(), filter)
+	if err != nil {
+		writeCoreError(w, err, nil)
+		return
+	}
+	writeJSON(w, stdhttp.StatusOK, events)
+}
@@ -0,0 +1,210 @@
+package vmhttp_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-go-golems/vm-system/pkg/vmclient"
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+	"github.com/go-go-golems/vm-system/pkg/vmstore"
+	vmhttp "github.com/go-go-golems/vm-system/pkg/vmtransport/http"
+)
+
+func TestAuditTrailRecordsWhoChangedWhat(t *testing.T) {
+	store, err := vmstore.NewVMStore(filepath.Join(t.TempDir(), "vm-system.db"))
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	core := vmcontrol.NewCore(store)
+	server := httptest.NewServer(vmhttp.NewHandler(core, vmhttp.RequireAuth()))
+	defer server.Close()
+	ctx := context.Background()
+
+	created, err := core.Auth.Create(ctx, vmcontrol.CreateTokenInput{
+		Name:   "security-admin",
+		Scopes: []string{vmmodels.ScopeTemplatesWrite, vmmodels.ScopeSessionsCreate, vmmodels.ScopeExecutionsRun},
+	})
+	if err != nil {
+		t.Fatalf("create token: %v", err)
+	}
+	client := vmclient.New(server.URL, server.Client(), vmclient.WithToken(created.Secret))
+
+	template, err := client.CreateTemplate(ctx, vmclient.CreateTemplateRequest{Name: "audited-template"})
+	if err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+
+	// Enable exec through a raw request to compare the request ID.
+	body, _ := json.Marshal(map[string]string{"name": "exec"})
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/api/v1/templates/%s/modules", server.URL, template.ID), bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+created.Secret)
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("add module: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201 adding exec module, got %d", resp.StatusCode)
+	}
+	requestID := resp.Header.Get("X-Request-Id")
+
+	if _, err := client.AddTemplateModule(ctx, template.ID, vmclient.AddTemplateModuleRequest{Name: "json"}); err == nil {
+		t.Fatalf("expected adding the json built-in as a module to fail")
+	}
+
+	worktree := filepath.Join(t.TempDir(), "worktree")
+	mustMkdirAll(t, worktree)
+	session, err := client.CreateSession(ctx, vmclient.CreateSessionRequest{
+		TemplateID:    template.ID,
+		WorkspaceID:   "ws-audit",
+		BaseCommitOID: "deadbeef",
+		WorktreePath:  worktree,
+	})
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	input := "const secret = 42; secret"
+	execution, err := client.ExecuteREPL(ctx, vmclient.ExecuteREPLRequest{SessionID: session.ID, Input: input})
+	if err != nil {
+		t.Fatalf("execute repl: %v", err)
+	}
+	if _, err := client.CloseSession(ctx, session.ID); err != nil {
+		t.Fatalf("close session: %v", err)
+	}
+
+	// Reads are not audited.
+	if _, err := client.ListTemplates(ctx); err != nil {
+		t.Fatalf("list templates: %v", err)
+	}
+
+	events, err := client.ListAuditEvents(ctx, vmmodels.AuditFilter{})
+	if err != nil {
+		t.Fatalf("list audit events: %v", err)
+	}
+	actions := []string{}
+	for _, event := range events {
+		actions = append(actions, event.Action)
+	}
+	expected := []string{"session.close", "execution.repl", "session.create", "template.module.add", "template.module.add", "template.create"}
+	if fmt.Sprint(actions) != fmt.Sprint(expected) {
+		t.Fatalf("expected audit actions %v newest first, got %v", expected, actions)
+	}
+	for _, event := range events {
+		if event.Actor != "token:security-admin" || event.TokenID != created.Token.ID {
+			t.Fatalf("expected every event attributed to the token, got %+v", event)
+		}
+		if event.RequestID == "" {
+			t.Fatalf("expected request id on %s", event.Action)
+		}
+	}
+
+	// Who enabled exec on the template, and in which request?
+	grants, err := client.ListAuditEvents(ctx, vmmodels.AuditFilter{Action: "template.module.add", ResourceID: template.ID, Outcome: vmmodels.AuditOutcomeSuccess})
+	if err != nil {
+		t.Fatalf("list module grants: %v", err)
+	}
+	if len(grants) != 1 {
+		t.Fatalf("expected one successful module grant, got %d", len(grants))
+	}
+	grant := grants[0]
+	var grantDetails map[string]interface{}
+	if err := json.Unmarshal(grant.Details, &grantDetails); err != nil {
+		t.Fatalf("decode grant details: %v", err)
+	}
+	if grant.RequestID != requestID || grant.ResourceType != "template" || grant.StatusCode != http.StatusCreated || grantDetails["module_name"] != "exec" {
+		t.Fatalf("unexpected exec grant event %+v (details %v)", grant, grantDetails)
+	}
+
+	failures, err := client.ListAuditEvents(ctx, vmmodels.AuditFilter{Outcome: vmmodels.AuditOutcomeFailure})
+	if err != nil {
+		t.Fatalf("list failures: %v", err)
+	}
+	if len(failures) != 1 || failures[0].StatusCode != http.StatusUnprocessableEntity || failures[0].ErrorCode != "MODULE_NOT_ALLOWED" {
+		t.Fatalf("expected the rejected json module grant as the only failure, got %+v", failures)
+	}
+
+	// Executions record a hash of their input, not the input itself.
+	runs, err := client.ListAuditEvents(ctx, vmmodels.AuditFilter{Action: "execution.repl"})
+	if err != nil {
+		t.Fatalf("list executions: %v", err)
+	}
+	sum := sha256.Sum256([]byte(input))
+	var runDetails map[string]interface{}
+	if err := json.Unmarshal(runs[0].Details, &runDetails); err != nil {
+		t.Fatalf("decode execution details: %v", err)
+	}
+	if runs[0].ResourceID != execution.ID || runs[0].WorkspaceID != "ws-audit" || runDetails["input_sha256"] != hex.EncodeToString(sum[:]) {
+		t.Fatalf("unexpected execution audit event %+v (details %v)", runs[0], runDetails)
+	}
+	if bytes.Contains(runs[0].Details, []byte("secret")) {
+		t.Fatalf("expected execution input to stay out of the audit trail, got %s", runs[0].Details)
+	}
+
+	if limited, err := client.ListAuditEvents(ctx, vmmodels.AuditFilter{Limit: 2}); err != nil || len(limited) != 2 {
+		t.Fatalf("expected limit to cap results at 2, got %d (%v)", len(limited), err)
+	}
+
+	// Requests auth turns away never reach their route, but are audited too.
+	reader, err := core.Auth.Create(ctx, vmcontrol.CreateTokenInput{Name: "auditor", Scopes: []string{vmmodels.ScopeRead}})
+	if err != nil {
+		t.Fatalf("create read token: %v", err)
+	}
+	rejectedGrant := func(secret string) *http.Response {
+		body, _ := json.Marshal(map[string]string{"name": "exec"})
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/api/v1/templates/%s/modules", server.URL, template.ID), bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("new request: %v", err)
+		}
+		if secret != "" {
+			req.Header.Set("Authorization", "Bearer "+secret)
+		}
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("add module: %v", err)
+		}
+		_ = resp.Body.Close()
+		return resp
+	}
+	forbidden := rejectedGrant(reader.Secret)
+	unauthenticated := rejectedGrant("")
+	if forbidden.StatusCode != http.StatusForbidden || unauthenticated.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 403 and 401 for the rejected grants, got %d and %d", forbidden.StatusCode, unauthenticated.StatusCode)
+	}
+	if resp, err := server.Client().Get(server.URL + "/api/v1/templates"); err != nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 listing templates without a token, got %v (%v)", resp, err)
+	}
+
+	rejected, err := client.ListAuditEvents(ctx, vmmodels.AuditFilter{Action: "template.module.add", Outcome: vmmodels.AuditOutcomeFailure})
+	if err != nil {
+		t.Fatalf("list rejected grants: %v", err)
+	}
+	if len(rejected) != 3 {
+		t.Fatalf("expected the two auth rejections next to the json grant, got %+v", rejected)
+	}
+	anonymous, denied := rejected[0], rejected[1]
+	if anonymous.Actor != "anonymous" || anonymous.TokenID != "" || anonymous.StatusCode != http.StatusUnauthorized ||
+		anonymous.ErrorCode != "UNAUTHENTICATED" || anonymous.RequestID != unauthenticated.Header.Get("X-Request-Id") || anonymous.ResourceID != template.ID {
+		t.Fatalf("unexpected unauthenticated grant event %+v", anonymous)
+	}
+	if denied.Actor != "token:auditor" || denied.TokenID != reader.Token.ID || denied.StatusCode != http.StatusForbidden ||
+		denied.ErrorCode != "INSUFFICIENT_SCOPE" || denied.RequestID != forbidden.Header.Get("X-Request-Id") || denied.ResourceID != template.ID {
+		t.Fatalf("unexpected forbidden grant event %+v", denied)
+	}
+	if failures, err := client.ListAuditEvents(ctx, vmmodels.AuditFilter{Outcome: vmmodels.AuditOutcomeFailure}); err != nil || len(failures) != 3 {
+		t.Fatalf("expected rejected reads to stay out of the audit trail, got %d failures (%v)", len(failures), err)
+	}
+}
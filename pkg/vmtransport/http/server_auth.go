@@ -0,0 +1,136 @@
+package vmhttp
+
+import (
+	"errors"
+	"fmt"
+	stdhttp "net/http"
+	"strings"
+
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+// HandlerOption configures the API handler built by NewHandler.
+type HandlerOption func(*handlerConfig)
+
+type handlerConfig struct {
+	requireAuth bool
+}
+
+// RequireAuth rejects API requests that do not carry a live bearer token
+// with the scope the route needs. Over TLS, a verified client certificate
+// whose subject is bound to a token stands in for the bearer token. The
+// health endpoint stays public.
+func RequireAuth() HandlerOption {
+	return func(cfg *handlerConfig) {
+		cfg.requireAuth = true
+	}
+}
+
+// requiredScope maps a request to the token scope it needs, and "" for
+// public endpoints. Reads need ScopeRead, which every scope grants; writes
+// need the scope of the resource they change. A session socket runs REPL
+// inputs, so it needs ScopeExecutionsRun.
+func requiredScope(r *stdhttp.Request) string {
+	path := r.URL.Path
+	if path == "/api/v1/health" {
+		return ""
+	}
+	if hasPathPrefix(path, "/api/v1/sessions") && strings.HasSuffix(path, "/ws") {
+		return vmmodels.ScopeExecutionsRun
+	}
+	if r.Method == stdhttp.MethodGet || r.Method == stdhttp.MethodHead {
+		return vmmodels.ScopeRead
+	}
+	switch {
+	case hasPathPrefix(path, "/api/v1/templates"), hasPathPrefix(path, "/api/v1/libraries"), hasPathPrefix(path, "/api/v1/workspaces"):
+		return vmmodels.ScopeTemplatesWrite
+	case hasPathPrefix(path, "/api/v1/sessions"):
+		return vmmodels.ScopeSessionsCreate
+	case hasPathPrefix(path, "/api/v1/executions"):
+		return vmmodels.ScopeExecutionsRun
+	default:
+		return vmmodels.ScopeRead
+	}
+}
+
+// hasPathPrefix matches prefix itself, its subpaths and its :action forms.
+func hasPathPrefix(path, prefix string) bool {
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	rest := path[len(prefix):]
+	return rest == "" || rest[0] == '/' || rest[0] == ':'
+}
+
+// withAuth authenticates requests before next sees them. rejected is told
+// the status and error code of every request it turns away.
+func withAuth(auth *vmcontrol.AuthService, next stdhttp.Handler, rejected func(*stdhttp.Request, int, string)) stdhttp.Handler {
+	return stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		scope := requiredScope(r)
+		if scope == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var (
+			token   *vmmodels.APIToken
+			err     error
+			message string
+		)
+		if secret, ok := bearerToken(r); ok {
+			token, err = auth.Authenticate(r.Context(), secret)
+			message = "Invalid or revoked API token"
+		} else if subject, ok := clientCertSubject(r); ok {
+			token, err = auth.AuthenticateCertificate(r.Context(), subject)
+			message = fmt.Sprintf("No API token is bound to client certificate subject %q", subject)
+		} else {
+			writeUnauthenticated(w, "Missing bearer token")
+			rejected(r, stdhttp.StatusUnauthorized, "UNAUTHENTICATED")
+			return
+		}
+		if err != nil {
+			if errors.Is(err, vmmodels.ErrUnauthenticated) {
+				writeUnauthenticated(w, message)
+				rejected(r, stdhttp.StatusUnauthorized, "UNAUTHENTICATED")
+				return
+			}
+			writeCoreError(w, err, nil)
+			return
+		}
+		if !token.Allows(scope) {
+			writeError(w, stdhttp.StatusForbidden, "INSUFFICIENT_SCOPE", "API token lacks the required scope", map[string]interface{}{
+				"required_scope": scope,
+				"token_scopes":   token.Scopes,
+			})
+			rejected(r.WithContext(vmcontrol.ContextWithToken(r.Context(), token)), stdhttp.StatusForbidden, "INSUFFICIENT_SCOPE")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(vmcontrol.ContextWithToken(r.Context(), token)))
+	})
+}
+
+func bearerToken(r *stdhttp.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// clientCertSubject returns the subject of the client certificate the TLS
+// handshake verified, in RFC 2253 notation.
+func clientCertSubject(r *stdhttp.Request) (string, bool) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+	return r.TLS.VerifiedChains[0][0].Subject.String(), true
+}
+
+func writeUnauthenticated(w stdhttp.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="vm-system"`)
+	writeError(w, stdhttp.StatusUnauthorized, "UNAUTHENTICATED", message, nil)
+}
@@ -0,0 +1,149 @@
+package vmhttp_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-go-golems/vm-system/pkg/vmclient"
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+	"github.com/go-go-golems/vm-system/pkg/vmstore"
+	vmhttp "github.com/go-go-golems/vm-system/pkg/vmtransport/http"
+)
+
+func TestRequireAuthEnforcesBearerTokensAndScopes(t *testing.T) {
+	store, err := vmstore.NewVMStore(filepath.Join(t.TempDir(), "vm-system.db"))
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	core := vmcontrol.NewCore(store)
+	server := httptest.NewServer(vmhttp.NewHandler(core, vmhttp.RequireAuth()))
+	defer server.Close()
+	ctx := context.Background()
+
+	issue := func(name string, scopes ...string) string {
+		created, err := core.Auth.Create(ctx, vmcontrol.CreateTokenInput{Name: name, Scopes: scopes})
+		if err != nil {
+			t.Fatalf("create token %s: %v", name, err)
+		}
+		return created.Secret
+	}
+	admin := vmclient.New(server.URL, server.Client(), vmclient.WithToken(issue("admin",
+		vmmodels.ScopeTemplatesWrite, vmmodels.ScopeSessionsCreate, vmmodels.ScopeExecutionsRun)))
+	reader := vmclient.New(server.URL, server.Client(), vmclient.WithToken(issue("reader")))
+	runner := vmclient.New(server.URL, server.Client(), vmclient.WithToken(issue("runner", vmmodels.ScopeExecutionsRun)))
+	anonymous := vmclient.New(server.URL, server.Client())
+
+	expectAPIError := func(err error, status int, code string) {
+		t.Helper()
+		var apiErr *vmclient.APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("expected API error %s, got %v", code, err)
+		}
+		if apiErr.StatusCode != status || apiErr.Code != code {
+			t.Fatalf("expected %d %s, got %d %s", status, code, apiErr.StatusCode, apiErr.Code)
+		}
+	}
+
+	// Health stays public so load balancers can probe it.
+	if _, err := anonymous.Health(ctx); err != nil {
+		t.Fatalf("expected public health endpoint, got %v", err)
+	}
+	_, err = anonymous.ListTemplates(ctx)
+	expectAPIError(err, http.StatusUnauthorized, "UNAUTHENTICATED")
+	_, err = vmclient.New(server.URL, server.Client(), vmclient.WithToken("vms_not-a-real-token")).ListTemplates(ctx)
+	expectAPIError(err, http.StatusUnauthorized, "UNAUTHENTICATED")
+
+	resp, err := server.Client().Get(server.URL + "/api/v1/templates")
+	if err != nil {
+		t.Fatalf("get templates: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.Header.Get("WWW-Authenticate") == "" {
+		t.Fatalf("expected WWW-Authenticate challenge on 401")
+	}
+
+	template, err := admin.CreateTemplate(ctx, vmclient.CreateTemplateRequest{Name: "auth-template"})
+	if err != nil {
+		t.Fatalf("admin create template: %v", err)
+	}
+
+	// Every scope reads; writes need the resource's scope.
+	if _, err := reader.ListTemplates(ctx); err != nil {
+		t.Fatalf("read-only token list templates: %v", err)
+	}
+	if _, err := runner.GetTemplate(ctx, template.ID); err != nil {
+		t.Fatalf("runner token get template: %v", err)
+	}
+	_, err = reader.CreateTemplate(ctx, vmclient.CreateTemplateRequest{Name: "reader-template"})
+	expectAPIError(err, http.StatusForbidden, "INSUFFICIENT_SCOPE")
+
+	worktree := filepath.Join(t.TempDir(), "worktree")
+	mustMkdirAll(t, worktree)
+	sessionRequest := vmclient.CreateSessionRequest{
+		TemplateID:    template.ID,
+		WorkspaceID:   "ws-auth",
+		BaseCommitOID: "deadbeef",
+		WorktreePath:  worktree,
+	}
+	_, err = runner.CreateSession(ctx, sessionRequest)
+	expectAPIError(err, http.StatusForbidden, "INSUFFICIENT_SCOPE")
+	session, err := admin.CreateSession(ctx, sessionRequest)
+	if err != nil {
+		t.Fatalf("admin create session: %v", err)
+	}
+
+	_, err = reader.ExecuteREPL(ctx, vmclient.ExecuteREPLRequest{SessionID: session.ID, Input: "1 + 1"})
+	expectAPIError(err, http.StatusForbidden, "INSUFFICIENT_SCOPE")
+	if _, err := runner.ExecuteREPL(ctx, vmclient.ExecuteREPLRequest{SessionID: session.ID, Input: "1 + 1"}); err != nil {
+		t.Fatalf("runner execute repl: %v", err)
+	}
+
+	// Revocation takes effect on the next request.
+	tokens, err := core.Auth.List(ctx)
+	if err != nil {
+		t.Fatalf("list tokens: %v", err)
+	}
+	var readerToken *vmmodels.APIToken
+	for _, token := range tokens {
+		if token.Name == "reader" {
+			readerToken = token
+		}
+	}
+	if readerToken == nil || readerToken.LastUsedAt == nil {
+		t.Fatalf("expected reader token with last_used_at set, got %+v", readerToken)
+	}
+	if _, err := core.Auth.Revoke(ctx, readerToken.ID); err != nil {
+		t.Fatalf("revoke reader token: %v", err)
+	}
+	_, err = reader.ListTemplates(ctx)
+	expectAPIError(err, http.StatusUnauthorized, "UNAUTHENTICATED")
+}
+
+func TestTokenCreateRejectsUnknownScopes(t *testing.T) {
+	store, err := vmstore.NewVMStore(filepath.Join(t.TempDir(), "vm-system.db"))
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	auth := vmcontrol.NewAuthService(store, store)
+
+	if _, err := auth.Create(context.Background(), vmcontrol.CreateTokenInput{Name: "bad", Scopes: []string{"admin"}}); !errors.Is(err, vmmodels.ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken for unknown scope, got %v", err)
+	}
+	created, err := auth.Create(context.Background(), vmcontrol.CreateTokenInput{Name: "default"})
+	if err != nil {
+		t.Fatalf("create token: %v", err)
+	}
+	if len(created.Token.Scopes) != 1 || created.Token.Scopes[0] != vmmodels.ScopeRead {
+		t.Fatalf("expected read-only default scopes, got %v", created.Token.Scopes)
+	}
+	if _, err := auth.Revoke(context.Background(), "missing"); !errors.Is(err, vmmodels.ErrTokenNotFound) {
+		t.Fatalf("expected ErrTokenNotFound, got %v", err)
+	}
+}
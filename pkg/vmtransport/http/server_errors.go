@@ -44,33 +44,87 @@ type errorEnvelope struct {
 	} `json:"error"`
 }
 
-func writeCoreError(w stdhttp.ResponseWriter, err error, details interface{}) {
+// ErrorStatus maps an error returned by vmcontrol to the HTTP status, API
+// error code and message the REST API reports for it. Other transports use it
+// to report the same codes.
+func ErrorStatus(err error) (int, string, string) {
 	switch {
 	case errors.Is(err, vmmodels.ErrVMNotFound):
-		writeError(w, stdhttp.StatusNotFound, "TEMPLATE_NOT_FOUND", "Template not found", details)
+		return stdhttp.StatusNotFound, "TEMPLATE_NOT_FOUND", "Template not found"
+	case errors.Is(err, vmmodels.ErrTemplateRevisionNotFound):
+		return stdhttp.StatusNotFound, "TEMPLATE_REVISION_NOT_FOUND", "Template revision not found"
+	case errors.Is(err, vmmodels.ErrCapabilityNotFound):
+		return stdhttp.StatusNotFound, "CAPABILITY_NOT_FOUND", "Capability not found"
+	case errors.Is(err, vmmodels.ErrStartupFileNotFound):
+		return stdhttp.StatusNotFound, "STARTUP_FILE_NOT_FOUND", "Startup file not found"
+	case errors.Is(err, vmmodels.ErrTemplateNameConflict):
+		return stdhttp.StatusConflict, "TEMPLATE_NAME_CONFLICT", err.Error()
+	case errors.Is(err, vmmodels.ErrTemplateInactive):
+		return stdhttp.StatusConflict, "TEMPLATE_INACTIVE", "Template is inactive"
+	case errors.Is(err, vmmodels.ErrLibraryNotFound):
+		return stdhttp.StatusNotFound, "LIBRARY_NOT_FOUND", "Library not found"
+	case errors.Is(err, vmmodels.ErrLibraryExists):
+		return stdhttp.StatusConflict, "LIBRARY_EXISTS", err.Error()
+	case errors.Is(err, vmmodels.ErrLibraryInUse):
+		return stdhttp.StatusConflict, "LIBRARY_IN_USE", err.Error()
 	case errors.Is(err, vmmodels.ErrSessionNotFound):
-		writeError(w, stdhttp.StatusNotFound, "SESSION_NOT_FOUND", "Session not found", details)
+		return stdhttp.StatusNotFound, "SESSION_NOT_FOUND", "Session not found"
 	case errors.Is(err, vmmodels.ErrExecutionNotFound):
-		writeError(w, stdhttp.StatusNotFound, "EXECUTION_NOT_FOUND", "Execution not found", details)
+		return stdhttp.StatusNotFound, "EXECUTION_NOT_FOUND", "Execution not found"
 	case errors.Is(err, vmmodels.ErrSessionNotReady):
-		writeError(w, stdhttp.StatusConflict, "SESSION_NOT_READY", "Session is not ready", details)
+		return stdhttp.StatusConflict, "SESSION_NOT_READY", "Session is not ready"
 	case errors.Is(err, vmmodels.ErrSessionBusy):
-		writeError(w, stdhttp.StatusConflict, "SESSION_BUSY", "Session is busy", details)
+		return stdhttp.StatusConflict, "SESSION_BUSY", "Session is busy and its execution queue is full"
+	case errors.Is(err, vmmodels.ErrSessionQuotaExceeded):
+		return stdhttp.StatusTooManyRequests, "SESSION_QUOTA_EXCEEDED", err.Error()
+	case errors.Is(err, vmmodels.ErrTemplateSessionQuotaExceeded):
+		return stdhttp.StatusTooManyRequests, "TEMPLATE_SESSION_QUOTA_EXCEEDED", err.Error()
+	case errors.Is(err, vmmodels.ErrWorkspaceSessionQuotaExceeded):
+		return stdhttp.StatusTooManyRequests, "WORKSPACE_SESSION_QUOTA_EXCEEDED", err.Error()
+	case errors.Is(err, vmmodels.ErrExecutionNotCancellable):
+		return stdhttp.StatusConflict, "EXECUTION_NOT_CANCELLABLE", "Only queued executions can be cancelled"
 	case errors.Is(err, vmmodels.ErrPathTraversal):
-		writeError(w, stdhttp.StatusUnprocessableEntity, "INVALID_PATH", "Path escapes allowed worktree", details)
+		return stdhttp.StatusUnprocessableEntity, "INVALID_PATH", "Path escapes allowed worktree"
 	case errors.Is(err, vmmodels.ErrOutputLimitExceeded):
-		writeError(w, stdhttp.StatusUnprocessableEntity, "OUTPUT_LIMIT_EXCEEDED", "Execution exceeded configured output/event limits", details)
+		return stdhttp.StatusUnprocessableEntity, "OUTPUT_LIMIT_EXCEEDED", "Execution exceeded configured output/event limits"
 	case errors.Is(err, vmmodels.ErrStartupModeUnsupported):
-		writeError(w, stdhttp.StatusUnprocessableEntity, "STARTUP_MODE_UNSUPPORTED", "Only startup mode 'eval' is currently supported", details)
+		return stdhttp.StatusUnprocessableEntity, "STARTUP_MODE_UNSUPPORTED", "Only startup mode 'eval' is currently supported"
 	case errors.Is(err, vmmodels.ErrModuleNotAllowed):
-		writeError(w, stdhttp.StatusUnprocessableEntity, "MODULE_NOT_ALLOWED", "Module is not allowed for template configuration", details)
+		return stdhttp.StatusUnprocessableEntity, "MODULE_NOT_ALLOWED", "Module is not allowed for template configuration"
+	case errors.Is(err, vmmodels.ErrInvalidCapability):
+		return stdhttp.StatusUnprocessableEntity, "INVALID_CAPABILITY", err.Error()
+	case errors.Is(err, vmmodels.ErrInvalidStartupFile):
+		return stdhttp.StatusUnprocessableEntity, "INVALID_STARTUP_FILE", err.Error()
+	case errors.Is(err, vmmodels.ErrInvalidStartupOrder):
+		return stdhttp.StatusUnprocessableEntity, "INVALID_STARTUP_ORDER", err.Error()
+	case errors.Is(err, vmmodels.ErrInvalidLibrary):
+		return stdhttp.StatusUnprocessableEntity, "INVALID_LIBRARY", err.Error()
+	case errors.Is(err, vmmodels.ErrInvalidTemplateSpec):
+		return stdhttp.StatusUnprocessableEntity, "INVALID_TEMPLATE_SPEC", err.Error()
+	case errors.Is(err, vmmodels.ErrTokenNotFound):
+		return stdhttp.StatusNotFound, "TOKEN_NOT_FOUND", "API token not found"
+	case errors.Is(err, vmmodels.ErrInvalidToken):
+		return stdhttp.StatusUnprocessableEntity, "INVALID_TOKEN", err.Error()
+	case errors.Is(err, vmmodels.ErrWorkspaceNotFound):
+		return stdhttp.StatusNotFound, "WORKSPACE_NOT_FOUND", "Workspace not found"
+	case errors.Is(err, vmmodels.ErrWorkspaceExists):
+		return stdhttp.StatusConflict, "WORKSPACE_EXISTS", err.Error()
+	case errors.Is(err, vmmodels.ErrInvalidWorkspace):
+		return stdhttp.StatusUnprocessableEntity, "INVALID_WORKSPACE", err.Error()
+	case errors.Is(err, vmmodels.ErrWorkspaceForbidden):
+		return stdhttp.StatusForbidden, "WORKSPACE_FORBIDDEN", err.Error()
 	case errors.Is(err, vmmodels.ErrFileNotFound):
-		writeError(w, stdhttp.StatusNotFound, "FILE_NOT_FOUND", "File not found", details)
+		return stdhttp.StatusNotFound, "FILE_NOT_FOUND", "File not found"
 	default:
-		writeError(w, stdhttp.StatusInternalServerError, "INTERNAL", err.Error(), details)
+		return stdhttp.StatusInternalServerError, "INTERNAL", err.Error()
 	}
 }
 
+func writeCoreError(w stdhttp.ResponseWriter, err error, details interface{}) {
+	status, code, message := ErrorStatus(err)
+	writeError(w, status, code, message, details)
+}
+
 func writeError(w stdhttp.ResponseWriter, status int, code, message string, details interface{}) {
 	env := errorEnvelope{}
 	env.Error.Code = code
@@ -29,6 +29,9 @@ func (s *Server) handleExecutionREPL(w stdhttp.ResponseWriter, r *stdhttp.Reques
 		return
 	}
 
+	auditDetail(r, "session_id", sessionID.String())
+	auditDetail(r, "input_sha256", auditHash(req.Input))
+
 	exec, err := s.core.Executions.ExecuteREPL(r.Context(), vmcontrol.ExecuteREPLInput{
 		SessionID: sessionID.String(),
 		Input:     req.Input,
@@ -37,6 +40,7 @@ func (s *Server) handleExecutionREPL(w stdhttp.ResponseWriter, r *stdhttp.Reques
 		writeCoreError(w, err, map[string]string{"session_id": sessionID.String()})
 		return
 	}
+	auditResource(r, exec.ID, exec.WorkspaceID)
 	writeJSON(w, stdhttp.StatusCreated, exec)
 }
 
@@ -63,6 +67,10 @@ func (s *Server) handleExecutionRunFile(w stdhttp.ResponseWriter, r *stdhttp.Req
 		return
 	}
 
+	auditDetail(r, "session_id", sessionID.String())
+	auditDetail(r, "path", req.Path)
+	auditDetail(r, "input_sha256", auditHash(req))
+
 	exec, err := s.core.Executions.ExecuteRunFile(r.Context(), vmcontrol.ExecuteRunFileInput{
 		SessionID: sessionID.String(),
 		Path:      req.Path,
@@ -73,6 +81,7 @@ func (s *Server) handleExecutionRunFile(w stdhttp.ResponseWriter, r *stdhttp.Req
 		writeCoreError(w, err, map[string]string{"session_id": sessionID.String()})
 		return
 	}
+	auditResource(r, exec.ID, exec.WorkspaceID)
 	writeJSON(w, stdhttp.StatusCreated, exec)
 }
 
@@ -89,6 +98,19 @@ func (s *Server) handleExecutionGet(w stdhttp.ResponseWriter, r *stdhttp.Request
 	writeJSON(w, stdhttp.StatusOK, exec)
 }
 
+func (s *Server) handleExecutionCancel(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	executionID, ok := parseExecutionIDOrWriteValidationError(w, r.PathValue("execution_id"))
+	if !ok {
+		return
+	}
+	exec, err := s.core.Executions.Cancel(r.Context(), executionID.String())
+	if err != nil {
+		writeCoreError(w, err, map[string]string{"execution_id": executionID.String()})
+		return
+	}
+	writeJSON(w, stdhttp.StatusOK, exec)
+}
+
 func (s *Server) handleExecutionList(w stdhttp.ResponseWriter, r *stdhttp.Request) {
 	sessionID := r.URL.Query().Get("session_id")
 	if sessionID == "" {
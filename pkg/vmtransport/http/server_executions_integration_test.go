@@ -80,6 +80,14 @@ func TestExecutionEndpointsLifecycle(t *testing.T) {
 	doRequest(t, client, http.MethodGet, fmt.Sprintf("%s/api/v1/executions/%s", server.URL, "00000000-0000-0000-0000-000000000001"), nil, http.StatusNotFound, map[string]string{
 		"code": "EXECUTION_NOT_FOUND",
 	})
+
+	// Only queued executions can be cancelled.
+	doRequest(t, client, http.MethodPost, fmt.Sprintf("%s/api/v1/executions/%s/cancel", server.URL, replExec.ID), map[string]interface{}{}, http.StatusConflict, map[string]string{
+		"code": "EXECUTION_NOT_CANCELLABLE",
+	})
+	doRequest(t, client, http.MethodPost, fmt.Sprintf("%s/api/v1/executions/%s/cancel", server.URL, "00000000-0000-0000-0000-000000000001"), map[string]interface{}{}, http.StatusNotFound, map[string]string{
+		"code": "EXECUTION_NOT_FOUND",
+	})
 }
 
 func createSessionForTest(t *testing.T, client *http.Client, baseURL, templateID, worktree, workspaceID string) string {
@@ -0,0 +1,127 @@
+package vmhttp_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFetchCapabilityGatesNetworkAccessAndRecordsRequests(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/greeting":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `{"message":"hello","method":%q,"token":%q}`, r.Method, r.Header.Get("X-Token"))
+		case "/large":
+			_, _ = w.Write([]byte(strings.Repeat("x", 2048)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer upstream.Close()
+	upstreamURL, err := url.Parse(upstream.URL)
+	if err != nil {
+		t.Fatalf("parse upstream url: %v", err)
+	}
+
+	server, client := newIntegrationTestServer(t)
+	defer server.Close()
+
+	worktree := filepath.Join(t.TempDir(), "worktree")
+	mustMkdirAll(t, worktree)
+
+	templateID := createTemplateForTest(t, client, server.URL, "fetch-template")
+	doRequest(t, client, http.MethodPost, fmt.Sprintf("%s/api/v1/templates/%s/capabilities", server.URL, templateID), map[string]interface{}{
+		"kind":    "net",
+		"name":    "fetch",
+		"enabled": true,
+		"config":  map[string]interface{}{"allow_hosts": "not-a-list"},
+	}, http.StatusUnprocessableEntity, map[string]string{"code": "INVALID_CAPABILITY"})
+	postJSON(t, client, fmt.Sprintf("%s/api/v1/templates/%s/capabilities", server.URL, templateID), map[string]interface{}{
+		"kind":    "net",
+		"name":    "fetch",
+		"enabled": true,
+		"config": map[string]interface{}{
+			"allow_hosts":        []string{upstreamURL.Host},
+			"timeout_ms":         2000,
+			"max_response_bytes": 1024,
+		},
+	}, &map[string]interface{}{})
+
+	sessionID := createSessionForTest(t, client, server.URL, templateID, worktree, "ws-fetch")
+
+	exec := executionResponse{}
+	postJSON(t, client, server.URL+"/api/v1/executions/repl", map[string]interface{}{
+		"session_id": sessionID,
+		"input": fmt.Sprintf(`(async () => {
+			const res = await fetch(%q, { method: "post", headers: { "X-Token": "t1" }, body: "{}" });
+			const body = await res.json();
+			console.log("status", res.status);
+			return [res.ok, body.message, body.method, body.token, res.headers.get("content-type")].join("|");
+		})()`, upstream.URL+"/greeting"),
+	}, &exec)
+	if exec.Status != "ok" {
+		t.Fatalf("expected fetch to succeed, got status=%q error=%q", exec.Status, exec.Error.Message)
+	}
+	if got := resultPreview(t, exec.Result); got != "true|hello|POST|t1|application/json" {
+		t.Fatalf("unexpected fetch result %q", got)
+	}
+
+	events := []struct {
+		Type    string          `json:"type"`
+		Payload json.RawMessage `json:"payload"`
+	}{}
+	getJSON(t, client, fmt.Sprintf("%s/api/v1/executions/%s/events?after_seq=0", server.URL, exec.ID), &events)
+	var netEvent struct {
+		Method string `json:"method"`
+		URL    string `json:"url"`
+		Status int    `json:"status"`
+		Bytes  int64  `json:"bytes"`
+	}
+	types := []string{}
+	for _, event := range events {
+		types = append(types, event.Type)
+		if event.Type == "net" {
+			if err := json.Unmarshal(event.Payload, &netEvent); err != nil {
+				t.Fatalf("unmarshal net event: %v", err)
+			}
+		}
+	}
+	if strings.Join(types, ",") != "input_echo,net,console,value" {
+		t.Fatalf("unexpected event sequence %v", types)
+	}
+	if netEvent.Method != "POST" || netEvent.Status != http.StatusOK || netEvent.URL != upstream.URL+"/greeting" || netEvent.Bytes == 0 {
+		t.Fatalf("unexpected net event %+v", netEvent)
+	}
+
+	rejected := map[string]string{
+		`fetch("http://example.invalid/")`:              "host not allowed",
+		fmt.Sprintf(`fetch(%q)`, upstream.URL+"/large"): "max_response_bytes",
+	}
+	for input, expected := range rejected {
+		exec := executionResponse{}
+		postJSON(t, client, server.URL+"/api/v1/executions/repl", map[string]interface{}{
+			"session_id": sessionID,
+			"input":      input,
+		}, &exec)
+		if exec.Status != "error" || !strings.Contains(exec.Error.Message, expected) {
+			t.Fatalf("expected %s to be rejected with %q, got status=%q error=%q", input, expected, exec.Status, exec.Error.Message)
+		}
+	}
+
+	plainTemplateID := createTemplateForTest(t, client, server.URL, "no-fetch-template")
+	plainSessionID := createSessionForTest(t, client, server.URL, plainTemplateID, worktree, "ws-fetch")
+	exec = executionResponse{}
+	postJSON(t, client, server.URL+"/api/v1/executions/repl", map[string]interface{}{
+		"session_id": plainSessionID,
+		"input":      `typeof fetch`,
+	}, &exec)
+	if got := resultPreview(t, exec.Result); got != "undefined" {
+		t.Fatalf("expected fetch to be absent without net capability, got %q", got)
+	}
+}
@@ -0,0 +1,163 @@
+package vmhttp_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dop251/goja"
+
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmmodules"
+	"github.com/go-go-golems/vm-system/pkg/vmstore"
+	vmhttp "github.com/go-go-golems/vm-system/pkg/vmtransport/http"
+)
+
+func TestHostModulesRegisteredByEmbedderAreSelectablePerTemplate(t *testing.T) {
+	registry, err := vmmodules.NewRegistry(
+		vmmodules.HostModule{
+			Name:        "greeter",
+			Description: "Greets from Go",
+			Functions:   []string{"hello"},
+			New: func(vmmodules.SessionInfo) (*vmmodules.HostInstance, error) {
+				return &vmmodules.HostInstance{
+					Loader: func(vm *goja.Runtime, module *goja.Object) {
+						exports := module.Get("exports").(*goja.Object)
+						_ = exports.Set("hello", func(name string) string { return "hello " + name })
+					},
+				}, nil
+			},
+		},
+		vmmodules.GlobalFunctions("clock", "Deterministic clock", map[string]interface{}{
+			"hostAnswer": func() int { return 42 },
+		}),
+		vmmodules.SessionObject("tenant", "Per-session tenant client", "tenant", func(session vmmodules.SessionInfo) (interface{}, error) {
+			return map[string]interface{}{"workspace": session.WorkspaceID}, nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("new module registry: %v", err)
+	}
+
+	store, err := vmstore.NewVMStore(filepath.Join(t.TempDir(), "vm-system.db"))
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	core := vmcontrol.NewCore(store, vmcontrol.WithModuleRegistry(registry))
+	server := httptest.NewServer(vmhttp.NewHandler(core))
+	defer server.Close()
+	client := server.Client()
+
+	catalog := []struct {
+		Name        string `json:"name"`
+		Kind        string `json:"kind"`
+		Description string `json:"description"`
+	}{}
+	getJSON(t, client, server.URL+"/api/v1/modules", &catalog)
+	hostDocs := map[string]string{}
+	for _, module := range catalog {
+		if module.Kind == "host" {
+			hostDocs[module.Name] = module.Description
+		}
+	}
+	if hostDocs["greeter"] != "Greets from Go" || hostDocs["tenant"] == "" || hostDocs["clock"] == "" {
+		t.Fatalf("expected host modules with docs in catalog, got %#v", catalog)
+	}
+
+	worktree := filepath.Join(t.TempDir(), "worktree")
+	mustMkdirAll(t, worktree)
+
+	templateID := createTemplateForTest(t, client, server.URL, "host-modules-template")
+	for _, name := range []string{"greeter", "clock", "tenant"} {
+		postJSON(t, client, fmt.Sprintf("%s/api/v1/templates/%s/modules", server.URL, templateID), map[string]interface{}{
+			"name": name,
+		}, &map[string]interface{}{})
+	}
+	doRequest(t, client, http.MethodPost, fmt.Sprintf("%s/api/v1/templates/%s/modules", server.URL, templateID), map[string]interface{}{
+		"name": "not-registered",
+	}, http.StatusUnprocessableEntity, map[string]string{"code": "MODULE_NOT_ALLOWED"})
+
+	sessionID := createSessionForTest(t, client, server.URL, templateID, worktree, "ws-tenant-a")
+
+	cases := map[string]string{
+		`require("greeter").hello("vm")`: "hello vm",
+		`hostAnswer()`:                   "42",
+		`tenant.workspace`:               "ws-tenant-a",
+		`require("tenant").workspace`:    "ws-tenant-a",
+	}
+	for input, expected := range cases {
+		exec := executionResponse{}
+		postJSON(t, client, server.URL+"/api/v1/executions/repl", map[string]interface{}{
+			"session_id": sessionID,
+			"input":      input,
+		}, &exec)
+		if exec.Status != "ok" {
+			t.Fatalf("expected %s to succeed, got status=%q error=%q", input, exec.Status, exec.Error.Message)
+		}
+		if got := resultPreview(t, exec.Result); got != expected {
+			t.Fatalf("expected %s preview %q, got %q", input, expected, got)
+		}
+	}
+
+	plainTemplateID := createTemplateForTest(t, client, server.URL, "host-modules-disabled-template")
+	plainSessionID := createSessionForTest(t, client, server.URL, plainTemplateID, worktree, "ws-tenant-b")
+	exec := executionResponse{}
+	postJSON(t, client, server.URL+"/api/v1/executions/repl", map[string]interface{}{
+		"session_id": plainSessionID,
+		"input":      `typeof tenant + ":" + typeof hostAnswer`,
+	}, &exec)
+	if got := resultPreview(t, exec.Result); got != "undefined:undefined" {
+		t.Fatalf("expected host globals to be absent from unconfigured template, got %q", got)
+	}
+
+	// A pool would build the runtime before any workspace claims it, so
+	// templates exposing host modules are built cold at session creation.
+	pooled := applyTemplateResponse{}
+	reqJSONStatus(t, client, http.MethodPost, server.URL+"/api/v1/templates:apply", map[string]interface{}{
+		"name":     "host-modules-pooled-template",
+		"modules":  []string{"tenant"},
+		"settings": map[string]interface{}{"pool": map[string]interface{}{"min_idle": 1}},
+	}, http.StatusCreated, &pooled)
+	if err := core.Sessions.WarmPools(context.Background()); err != nil {
+		t.Fatalf("warm pools: %v", err)
+	}
+	summary := poolSummary{}
+	getJSON(t, client, server.URL+"/api/v1/runtime/summary", &summary)
+	if len(summary.Pools) != 0 {
+		t.Fatalf("expected no pool for a template exposing host modules, got %+v", summary.Pools)
+	}
+	pooledSessionID := createSessionForTest(t, client, server.URL, pooled.Template.ID, worktree, "ws-tenant-pooled")
+	exec = executionResponse{}
+	postJSON(t, client, server.URL+"/api/v1/executions/repl", map[string]interface{}{
+		"session_id": pooledSessionID,
+		"input":      `tenant.workspace`,
+	}, &exec)
+	if got := resultPreview(t, exec.Result); got != "ws-tenant-pooled" {
+		t.Fatalf("expected the pooled template's host module bound to the session workspace, got %q", got)
+	}
+}
+
+func TestHostModuleRegistryRejectsShadowedNames(t *testing.T) {
+	noop := func(vmmodules.SessionInfo) (*vmmodules.HostInstance, error) { return &vmmodules.HostInstance{}, nil }
+
+	for _, name := range []string{"json", "fs", ""} {
+		_, err := vmmodules.NewRegistry(vmmodules.HostModule{Name: name, New: noop})
+		if err == nil {
+			t.Fatalf("expected host module name %q to be rejected", name)
+		}
+	}
+
+	_, err := vmmodules.NewRegistry(
+		vmmodules.HostModule{Name: "dup", New: noop},
+		vmmodules.HostModule{Name: "DUP", New: noop},
+	)
+	if err == nil || !strings.Contains(err.Error(), "already registered") {
+		t.Fatalf("expected duplicate host module registration to fail, got %v", err)
+	}
+}
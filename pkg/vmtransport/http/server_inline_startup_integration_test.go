@@ -0,0 +1,100 @@
+package vmhttp_test
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+func TestInlineStartupScriptsRunWithoutWorktreeFiles(t *testing.T) {
+	server, client := newIntegrationTestServer(t)
+	defer server.Close()
+
+	worktree := filepath.Join(t.TempDir(), "worktree")
+	mustMkdirAll(t, filepath.Join(worktree, "init"))
+	writeFile(t, filepath.Join(worktree, "init", "repo.js"), `globalThis.trace = (globalThis.trace || []).concat("repo");`)
+
+	templateID := createTemplateForTest(t, client, server.URL, "inline-startup-template")
+	templateURL := fmt.Sprintf("%s/api/v1/templates/%s", server.URL, templateID)
+
+	prelude := startupFileResponse{}
+	reqJSONStatus(t, client, http.MethodPost, templateURL+"/startup-files", map[string]interface{}{
+		"kind":        "inline",
+		"path":        "prelude/helpers.js",
+		"source":      `globalThis.trace = ["prelude"]; globalThis.greet = (name) => "hello " + name;`,
+		"order_index": 10,
+	}, http.StatusCreated, &prelude)
+	postJSON(t, client, templateURL+"/startup-files", map[string]interface{}{"path": "init/repo.js", "order_index": 20}, &map[string]interface{}{})
+
+	doRequest(t, client, http.MethodPost, templateURL+"/startup-files", map[string]interface{}{
+		"kind": "inline",
+		"path": "prelude/empty.js",
+	}, http.StatusUnprocessableEntity, map[string]string{"code": "INVALID_STARTUP_FILE"})
+	doRequest(t, client, http.MethodPost, templateURL+"/startup-files", map[string]interface{}{
+		"path":   "init/other.js",
+		"source": "1",
+	}, http.StatusUnprocessableEntity, map[string]string{"code": "INVALID_STARTUP_FILE"})
+
+	sessionID := createSessionForTest(t, client, server.URL, templateID, worktree, "ws-inline")
+	exec := executionResponse{}
+	postJSON(t, client, server.URL+"/api/v1/executions/repl", map[string]interface{}{
+		"session_id": sessionID,
+		"input":      `trace.join(",") + "|" + greet("vm")`,
+	}, &exec)
+	if got := resultPreview(t, exec.Result); got != "prelude,repo|hello vm" {
+		t.Fatalf("unexpected startup result %q", got)
+	}
+
+	// Editing the inline source affects new sessions and records a revision.
+	updated := startupFileResponse{}
+	reqJSONStatus(t, client, http.MethodPatch, fmt.Sprintf("%s/startup-files/%s", templateURL, prelude.ID), map[string]interface{}{
+		"source": `globalThis.trace = ["prelude-v2"]; globalThis.greet = (name) => "hi " + name;`,
+	}, http.StatusOK, &updated)
+	if updated.ID != prelude.ID {
+		t.Fatalf("expected startup file id to be preserved, got %q", updated.ID)
+	}
+
+	emptyWorktree := filepath.Join(t.TempDir(), "empty")
+	mustMkdirAll(t, emptyWorktree)
+	repoFile := struct {
+		StartupFiles []startupFileResponse `json:"startup_files"`
+	}{}
+	getJSON(t, client, templateURL, &repoFile)
+	for _, file := range repoFile.StartupFiles {
+		if file.Path == "init/repo.js" {
+			reqJSONStatus(t, client, http.MethodDelete, fmt.Sprintf("%s/startup-files/%s", templateURL, file.ID), nil, http.StatusOK, &map[string]interface{}{})
+		}
+	}
+	doRequest(t, client, http.MethodPatch, fmt.Sprintf("%s/startup-files/%s", templateURL, "missing"), map[string]interface{}{"order_index": 5}, http.StatusNotFound, map[string]string{"code": "STARTUP_FILE_NOT_FOUND"})
+
+	inlineOnlySessionID := createSessionForTest(t, client, server.URL, templateID, emptyWorktree, "ws-inline")
+	exec = executionResponse{}
+	postJSON(t, client, server.URL+"/api/v1/executions/repl", map[string]interface{}{
+		"session_id": inlineOnlySessionID,
+		"input":      `trace.join(",") + "|" + greet("vm")`,
+	}, &exec)
+	if got := resultPreview(t, exec.Result); got != "prelude-v2|hi vm" {
+		t.Fatalf("expected updated inline prelude in a worktree without startup files, got %q", got)
+	}
+
+	spec := struct {
+		StartupFiles []struct {
+			Kind   string `json:"kind"`
+			Path   string `json:"path"`
+			Source string `json:"source"`
+		} `json:"startup_files"`
+	}{}
+	getJSON(t, client, templateURL+"/export", &spec)
+	if len(spec.StartupFiles) != 1 || spec.StartupFiles[0].Kind != "inline" || spec.StartupFiles[0].Source == "" {
+		t.Fatalf("expected inline startup file in exported spec, got %+v", spec.StartupFiles)
+	}
+
+	revisions := []templateRevisionResponse{}
+	getJSON(t, client, templateURL+"/revisions", &revisions)
+	last := revisions[len(revisions)-1]
+	previous := revisions[len(revisions)-2]
+	if last.summary() != "remove startup_file init/repo.js" || previous.summary() != "update startup_file prelude/helpers.js" {
+		t.Fatalf("unexpected revision history tail %q, %q", previous.summary(), last.summary())
+	}
+}
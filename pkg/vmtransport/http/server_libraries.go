@@ -0,0 +1,76 @@
+package vmhttp
+
+import (
+	stdhttp "net/http"
+
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+)
+
+type registerLibraryRequest struct {
+	Name         string   `json:"name"`
+	Version      string   `json:"version"`
+	Global       string   `json:"global"`
+	Description  string   `json:"description"`
+	Content      string   `json:"content"`
+	Tarball      []byte   `json:"tarball"` // base64 in JSON
+	Entry        string   `json:"entry"`
+	Dependencies []string `json:"dependencies"`
+}
+
+func (s *Server) handleLibraryList(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	libraries, err := s.core.Libraries.List(r.Context())
+	if err != nil {
+		writeCoreError(w, err, nil)
+		return
+	}
+	writeJSON(w, stdhttp.StatusOK, libraries)
+}
+
+func (s *Server) handleLibraryRegister(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	var req registerLibraryRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, stdhttp.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	auditDetail(r, "name", req.Name)
+	auditDetail(r, "version", req.Version)
+
+	library, err := s.core.Libraries.Register(r.Context(), vmcontrol.RegisterLibraryInput{
+		Name:         req.Name,
+		Version:      req.Version,
+		Global:       req.Global,
+		Description:  req.Description,
+		Content:      req.Content,
+		Tarball:      req.Tarball,
+		Entry:        req.Entry,
+		Dependencies: req.Dependencies,
+	})
+	if err != nil {
+		writeCoreError(w, err, map[string]string{"name": req.Name, "version": req.Version})
+		return
+	}
+	auditResource(r, library.Ref, "")
+	writeJSON(w, stdhttp.StatusCreated, library)
+}
+
+func (s *Server) handleLibraryGet(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	ref := r.PathValue("library_ref")
+	library, err := s.core.Libraries.Get(r.Context(), ref)
+	if err != nil {
+		writeCoreError(w, err, map[string]string{"library_ref": ref})
+		return
+	}
+	writeJSON(w, stdhttp.StatusOK, library)
+}
+
+func (s *Server) handleLibraryDelete(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	ref := r.PathValue("library_ref")
+	if err := s.core.Libraries.Remove(r.Context(), ref); err != nil {
+		writeCoreError(w, err, map[string]string{"library_ref": ref})
+		return
+	}
+	writeJSON(w, stdhttp.StatusOK, map[string]string{
+		"status": "ok",
+		"ref":    ref,
+	})
+}
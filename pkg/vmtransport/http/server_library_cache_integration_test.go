@@ -0,0 +1,115 @@
+package vmhttp_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmstore"
+	vmhttp "github.com/go-go-golems/vm-system/pkg/vmtransport/http"
+)
+
+func TestLibraryCacheRootedAtDataDirAndVerified(t *testing.T) {
+	testRoot := t.TempDir()
+	dataDir := filepath.Join(testRoot, "data")
+	worktree := filepath.Join(testRoot, "worktree")
+	mustMkdirAll(t, worktree)
+
+	// A flat file from an older cache layout is adopted on first load.
+	cacheDir := filepath.Join(dataDir, "libraries")
+	mustMkdirAll(t, cacheDir)
+	writeFile(t, filepath.Join(cacheDir, "lodash-4.17.21.js"), lodashFixture())
+
+	// The daemon runs from a directory unrelated to its data dir.
+	chdirForTest(t, t.TempDir())
+
+	store, err := vmstore.NewVMStore(filepath.Join(testRoot, "vm-system.db"))
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	server := httptest.NewServer(vmhttp.NewHandler(vmcontrol.NewCore(store, vmcontrol.WithDataDir(dataDir))))
+	defer server.Close()
+	client := server.Client()
+
+	code := `var pinned = { answer: 42 };`
+	sum := fmt.Sprintf("%x", sha256.Sum256([]byte(code)))
+	registered := struct {
+		SHA256 string `json:"sha256"`
+	}{}
+	reqJSONStatus(t, client, http.MethodPost, server.URL+"/api/v1/libraries", map[string]interface{}{
+		"name":    "pinned",
+		"version": "1.0.0",
+		"global":  "pinned",
+		"content": code,
+	}, http.StatusCreated, &registered)
+	if registered.SHA256 != sum {
+		t.Fatalf("expected registered sha256 %s, got %s", sum, registered.SHA256)
+	}
+
+	templateID := createTemplateForTest(t, client, server.URL, "pinned-template")
+	for _, ref := range []string{"lodash-4.17.21", "pinned-1.0.0"} {
+		postJSON(t, client, fmt.Sprintf("%s/api/v1/templates/%s/libraries", server.URL, templateID), map[string]interface{}{
+			"name": ref,
+		}, &map[string]interface{}{})
+	}
+
+	sessionID := createSessionForTest(t, client, server.URL, templateID, worktree, "ws-pinned")
+	out := executionResponse{}
+	postJSON(t, client, server.URL+"/api/v1/executions/repl", map[string]interface{}{
+		"session_id": sessionID,
+		"input":      `_.chunk([1,2,3,4], 2).length + pinned.answer`,
+	}, &out)
+	if out.Status != "ok" || resultPreview(t, out.Result) != "44" {
+		t.Fatalf("expected libraries to load from data dir, got status=%q error=%q", out.Status, out.Error.Message)
+	}
+
+	session := struct {
+		RuntimeMeta struct {
+			Libraries []struct {
+				Ref    string `json:"ref"`
+				SHA256 string `json:"sha256"`
+			} `json:"libraries"`
+		} `json:"runtime_meta"`
+	}{}
+	getJSON(t, client, server.URL+"/api/v1/sessions/"+sessionID, &session)
+	loaded := map[string]string{}
+	for _, lib := range session.RuntimeMeta.Libraries {
+		loaded[lib.Ref] = lib.SHA256
+	}
+	if loaded["pinned-1.0.0"] != sum {
+		t.Fatalf("expected runtime meta to record pinned sha256 %s, got %#v", sum, loaded)
+	}
+	if loaded["lodash-4.17.21"] != fmt.Sprintf("%x", sha256.Sum256([]byte(lodashFixture()))) {
+		t.Fatalf("expected runtime meta to record lodash sha256, got %#v", loaded)
+	}
+
+	// Tampering with cached code is caught when the next session loads it.
+	writeFile(t, filepath.Join(cacheDir, "sha256", sum+".js"), `var pinned = { answer: 0 };`)
+	doRequest(t, client, http.MethodPost, server.URL+"/api/v1/sessions", map[string]interface{}{
+		"template_id":     templateID,
+		"workspace_id":    "ws-tampered",
+		"base_commit_oid": "deadbeef",
+		"worktree_path":   worktree,
+	}, http.StatusInternalServerError, map[string]string{"code": "INTERNAL"})
+
+	sessions := []struct {
+		WorkspaceID string `json:"workspace_id"`
+		LastError   string `json:"last_error"`
+	}{}
+	getJSON(t, client, server.URL+"/api/v1/sessions", &sessions)
+	for _, s := range sessions {
+		if s.WorkspaceID == "ws-tampered" {
+			if !strings.Contains(s.LastError, "checksum mismatch") {
+				t.Fatalf("expected checksum mismatch on tampered library, got %q", s.LastError)
+			}
+			return
+		}
+	}
+	t.Fatalf("expected tampered session record to exist")
+}
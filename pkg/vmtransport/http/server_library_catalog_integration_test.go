@@ -0,0 +1,168 @@
+package vmhttp_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+type libraryResponse struct {
+	Ref     string            `json:"ref"`
+	ID      string            `json:"id"`
+	Version string            `json:"version"`
+	Type    string            `json:"type"`
+	Builtin bool              `json:"builtin"`
+	Config  map[string]string `json:"config"`
+}
+
+func TestLibraryCatalogRegisterUseAndRemove(t *testing.T) {
+	testRoot := t.TempDir()
+	chdirForTest(t, testRoot)
+
+	server, client := newIntegrationTestServer(t)
+	defer server.Close()
+
+	worktree := filepath.Join(testRoot, "worktree")
+	mustMkdirAll(t, worktree)
+
+	// Uploaded source.
+	uploaded := libraryResponse{}
+	reqJSONStatus(t, client, http.MethodPost, server.URL+"/api/v1/libraries", map[string]interface{}{
+		"name":    "greeter",
+		"version": "1.0.0",
+		"global":  "greeter",
+		"content": `var greeter = { hello: function (n) { return "hello " + n; } };`,
+	}, http.StatusCreated, &uploaded)
+	if uploaded.Ref != "greeter-1.0.0" || uploaded.Type != "upload" || uploaded.Builtin {
+		t.Fatalf("unexpected uploaded library: %+v", uploaded)
+	}
+
+	// The daemon never reads library code from its own filesystem.
+	hostPath := filepath.Join(testRoot, "adder.js")
+	writeFile(t, hostPath, `var adder = { add: function (a, b) { return a + b; } };`)
+	doRequest(t, client, http.MethodPost, server.URL+"/api/v1/libraries", map[string]interface{}{
+		"name":    "adder",
+		"version": "0.1.0",
+		"global":  "adder",
+		"path":    hostPath,
+	}, http.StatusBadRequest, map[string]string{"code": "INVALID_REQUEST"})
+	reqJSONStatus(t, client, http.MethodPost, server.URL+"/api/v1/libraries", map[string]interface{}{
+		"name":    "adder",
+		"version": "0.1.0",
+		"global":  "adder",
+		"content": `var adder = { add: function (a, b) { return a + b; } };`,
+	}, http.StatusCreated, nil)
+
+	// Offline npm tarball; name and version come from package.json.
+	packed := libraryResponse{}
+	reqJSONStatus(t, client, http.MethodPost, server.URL+"/api/v1/libraries", map[string]interface{}{
+		"global":  "tiny",
+		"tarball": npmTarballFixture(t),
+	}, http.StatusCreated, &packed)
+	if packed.Ref != "tiny-2.0.0" || packed.Type != "tarball" || packed.Config["entry"] != "dist/tiny.js" {
+		t.Fatalf("unexpected tarball library: %+v", packed)
+	}
+
+	doRequest(t, client, http.MethodPost, server.URL+"/api/v1/libraries", map[string]interface{}{
+		"name":    "greeter",
+		"version": "1.0.0",
+		"global":  "greeter",
+		"content": `var greeter = {};`,
+	}, http.StatusConflict, map[string]string{"code": "LIBRARY_EXISTS"})
+	doRequest(t, client, http.MethodPost, server.URL+"/api/v1/libraries", map[string]interface{}{
+		"name":    "broken",
+		"version": "1.0.0",
+		"global":  "broken",
+		"content": `var broken = {`,
+	}, http.StatusUnprocessableEntity, map[string]string{"code": "INVALID_LIBRARY"})
+	doRequest(t, client, http.MethodPost, server.URL+"/api/v1/libraries", map[string]interface{}{
+		"name":    "nosource",
+		"version": "1.0.0",
+		"global":  "nosource",
+	}, http.StatusUnprocessableEntity, map[string]string{"code": "INVALID_LIBRARY"})
+
+	var catalog []libraryResponse
+	getJSON(t, client, server.URL+"/api/v1/libraries", &catalog)
+	refs := map[string]bool{}
+	for _, lib := range catalog {
+		refs[lib.Ref] = lib.Builtin
+	}
+	if builtin, ok := refs["lodash-4.17.21"]; !ok || !builtin {
+		t.Fatalf("expected builtin lodash in catalog, got %#v", refs)
+	}
+	for _, ref := range []string{"greeter-1.0.0", "adder-0.1.0", "tiny-2.0.0"} {
+		if builtin, ok := refs[ref]; !ok || builtin {
+			t.Fatalf("expected registered %s in catalog, got %#v", ref, refs)
+		}
+	}
+
+	got := libraryResponse{}
+	getJSON(t, client, server.URL+"/api/v1/libraries/tiny-2.0.0", &got)
+	if got.ID != "tiny" || got.Version != "2.0.0" {
+		t.Fatalf("unexpected library lookup: %+v", got)
+	}
+	doRequest(t, client, http.MethodGet, server.URL+"/api/v1/libraries/missing-1.0.0", nil, http.StatusNotFound, map[string]string{"code": "LIBRARY_NOT_FOUND"})
+
+	templateID := createTemplateForTest(t, client, server.URL, "catalog-template")
+	doRequest(t, client, http.MethodPost, fmt.Sprintf("%s/api/v1/templates/%s/libraries", server.URL, templateID), map[string]interface{}{
+		"name": "unknown-1.0.0",
+	}, http.StatusUnprocessableEntity, map[string]string{"code": "INVALID_LIBRARY"})
+	for _, ref := range []string{"greeter-1.0.0", "adder-0.1.0", "tiny-2.0.0"} {
+		postJSON(t, client, fmt.Sprintf("%s/api/v1/templates/%s/libraries", server.URL, templateID), map[string]interface{}{
+			"name": ref,
+		}, &map[string]interface{}{})
+	}
+
+	sessionID := createSessionForTest(t, client, server.URL, templateID, worktree, "ws-catalog")
+	out := executionResponse{}
+	postJSON(t, client, server.URL+"/api/v1/executions/repl", map[string]interface{}{
+		"session_id": sessionID,
+		"input":      `greeter.hello("x") + " " + adder.add(1, 2) + " " + tiny.version`,
+	}, &out)
+	if out.Status != "ok" {
+		t.Fatalf("expected registered libraries to load, got status=%q error=%q", out.Status, out.Error.Message)
+	}
+	if preview := resultPreview(t, out.Result); preview != "hello x 3 2.0.0" {
+		t.Fatalf("unexpected result preview %q", preview)
+	}
+
+	doRequest(t, client, http.MethodDelete, server.URL+"/api/v1/libraries/greeter-1.0.0", nil, http.StatusConflict, map[string]string{"code": "LIBRARY_IN_USE"})
+	doRequest(t, client, http.MethodDelete, server.URL+"/api/v1/libraries/lodash-4.17.21", nil, http.StatusUnprocessableEntity, map[string]string{"code": "INVALID_LIBRARY"})
+
+	doRequest(t, client, http.MethodDelete, fmt.Sprintf("%s/api/v1/templates/%s/libraries/%s", server.URL, templateID, "greeter-1.0.0"), nil, http.StatusOK, nil)
+	doRequest(t, client, http.MethodDelete, server.URL+"/api/v1/libraries/greeter-1.0.0", nil, http.StatusOK, nil)
+	doRequest(t, client, http.MethodGet, server.URL+"/api/v1/libraries/greeter-1.0.0", nil, http.StatusNotFound, map[string]string{"code": "LIBRARY_NOT_FOUND"})
+}
+
+func npmTarballFixture(t *testing.T) []byte {
+	t.Helper()
+
+	files := []struct{ name, content string }{
+		{"package/package.json", `{"name":"tiny","version":"2.0.0","main":"index.js","browser":"dist/tiny.js"}`},
+		{"package/index.js", `module.exports = {};`},
+		{"package/dist/tiny.js", `var tiny = { version: "2.0.0" };`},
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0o644, Size: int64(len(f.content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("write tar header: %v", err)
+		}
+		if _, err := tw.Write([]byte(f.content)); err != nil {
+			t.Fatalf("write tar content: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("close tar: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("close gzip: %v", err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,102 @@
+package vmhttp_test
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+func TestLibraryVersionsAndDependencyOrder(t *testing.T) {
+	testRoot := t.TempDir()
+	chdirForTest(t, testRoot)
+
+	server, client := newIntegrationTestServer(t)
+	defer server.Close()
+
+	worktree := filepath.Join(testRoot, "worktree")
+	mustMkdirAll(t, worktree)
+
+	register := func(body map[string]interface{}) libraryResponse {
+		t.Helper()
+		out := libraryResponse{}
+		reqJSONStatus(t, client, http.MethodPost, server.URL+"/api/v1/libraries", body, http.StatusCreated, &out)
+		return out
+	}
+	register(map[string]interface{}{
+		"name": "clock", "version": "1.0.0", "global": "clock",
+		"content": `var clock = { version: "1", plugins: [] };`,
+	})
+	// The plugin touches clock at load time, so it only works after it.
+	plugin := struct {
+		Dependencies []string `json:"dependencies"`
+	}{}
+	reqJSONStatus(t, client, http.MethodPost, server.URL+"/api/v1/libraries", map[string]interface{}{
+		"name": "clock-utc", "version": "1.0.0", "global": "clockUtc",
+		"content":      `clock.plugins.push("utc"); var clockUtc = { base: clock.version };`,
+		"dependencies": []string{"clock"},
+	}, http.StatusCreated, &plugin)
+	if len(plugin.Dependencies) != 1 || plugin.Dependencies[0] != "clock@1.0.0" {
+		t.Fatalf("expected dependency pinned to clock@1.0.0, got %#v", plugin.Dependencies)
+	}
+	register(map[string]interface{}{
+		"name": "clock", "version": "2.0.0", "global": "clock",
+		"content": `var clock = { version: "2", plugins: [] };`,
+	})
+	doRequest(t, client, http.MethodPost, server.URL+"/api/v1/libraries", map[string]interface{}{
+		"name": "orphan", "version": "1.0.0", "global": "orphan",
+		"content":      `var orphan = {};`,
+		"dependencies": []string{"missing@1.0.0"},
+	}, http.StatusUnprocessableEntity, map[string]string{"code": "INVALID_LIBRARY"})
+
+	templateID := createTemplateForTest(t, client, server.URL, "clock-template")
+	librariesURL := fmt.Sprintf("%s/api/v1/templates/%s/libraries", server.URL, templateID)
+
+	// A bare name is ambiguous once several versions exist.
+	doRequest(t, client, http.MethodPost, librariesURL, map[string]interface{}{"name": "clock"}, http.StatusUnprocessableEntity, map[string]string{"code": "INVALID_LIBRARY"})
+
+	postJSON(t, client, librariesURL, map[string]interface{}{"name": "clock-utc"}, &map[string]interface{}{})
+	var libraries []string
+	getJSON(t, client, librariesURL, &libraries)
+	if len(libraries) != 1 || libraries[0] != "clock-utc@1.0.0" {
+		t.Fatalf("expected bare single-version name pinned, got %#v", libraries)
+	}
+
+	// clock@2.0.0 defines the same global as the plugin's clock@1.0.0.
+	doRequest(t, client, http.MethodPost, librariesURL, map[string]interface{}{"name": "clock@2.0.0"}, http.StatusUnprocessableEntity, map[string]string{"code": "INVALID_LIBRARY"})
+	doRequest(t, client, http.MethodPost, server.URL+"/api/v1/templates:apply", map[string]interface{}{
+		"name":      "clock-template",
+		"libraries": []string{"clock-utc@1.0.0", "clock@2.0.0"},
+	}, http.StatusUnprocessableEntity, map[string]string{"code": "INVALID_TEMPLATE_SPEC"})
+
+	sessionID := createSessionForTest(t, client, server.URL, templateID, worktree, "ws-clock")
+	out := executionResponse{}
+	postJSON(t, client, server.URL+"/api/v1/executions/repl", map[string]interface{}{
+		"session_id": sessionID,
+		"input":      `clock.version + ":" + clock.plugins.join(",") + ":" + clockUtc.base`,
+	}, &out)
+	if out.Status != "ok" || resultPreview(t, out.Result) != "1:utc:1" {
+		t.Fatalf("expected dependency to load first, got status=%q error=%q", out.Status, out.Error.Message)
+	}
+
+	// Another template can use the newer version side by side.
+	otherID := createTemplateForTest(t, client, server.URL, "clock-v2-template")
+	postJSON(t, client, fmt.Sprintf("%s/api/v1/templates/%s/libraries", server.URL, otherID), map[string]interface{}{"name": "clock@2.0.0"}, &map[string]interface{}{})
+	otherSession := createSessionForTest(t, client, server.URL, otherID, worktree, "ws-clock-v2")
+	out = executionResponse{}
+	postJSON(t, client, server.URL+"/api/v1/executions/repl", map[string]interface{}{
+		"session_id": otherSession,
+		"input":      `clock.version`,
+	}, &out)
+	if out.Status != "ok" || resultPreview(t, out.Result) != "2" {
+		t.Fatalf("expected clock 2 in second template, got status=%q preview=%q", out.Status, resultPreview(t, out.Result))
+	}
+
+	doRequest(t, client, http.MethodDelete, server.URL+"/api/v1/libraries/clock-1.0.0", nil, http.StatusConflict, map[string]string{"code": "LIBRARY_IN_USE"})
+	doRequest(t, client, http.MethodDelete, librariesURL+"/clock-utc-1.0.0", nil, http.StatusOK, nil)
+	libraries = nil
+	getJSON(t, client, librariesURL, &libraries)
+	if len(libraries) != 0 {
+		t.Fatalf("expected ref spelling to remove clock-utc@1.0.0, got %#v", libraries)
+	}
+}
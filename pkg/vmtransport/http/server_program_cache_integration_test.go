@@ -0,0 +1,60 @@
+package vmhttp_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+type programCacheSummary struct {
+	ProgramCache struct {
+		Entries int    `json:"entries"`
+		Hits    uint64 `json:"hits"`
+		Misses  uint64 `json:"misses"`
+	} `json:"program_cache"`
+}
+
+func TestProgramCacheSharesCompiledCodeAcrossSessions(t *testing.T) {
+	server, client := newIntegrationTestServer(t)
+	defer server.Close()
+
+	worktree := filepath.Join(t.TempDir(), "worktree")
+	mustMkdirAll(t, filepath.Join(worktree, "init"))
+	writeFile(t, filepath.Join(worktree, "init", "prelude.js"), `globalThis.base = 40;`)
+	writeFile(t, filepath.Join(worktree, "calc.js"), `base + 2`)
+
+	templateID := createTemplateForTest(t, client, server.URL, "program-cache-template")
+	postJSON(t, client, fmt.Sprintf("%s/api/v1/templates/%s/startup-files", server.URL, templateID), map[string]interface{}{
+		"path":        "init/prelude.js",
+		"order_index": 10,
+	}, &map[string]interface{}{})
+
+	before := programCacheSummary{}
+	getJSON(t, client, server.URL+"/api/v1/runtime/summary", &before)
+
+	for i := 0; i < 2; i++ {
+		sessionID := createSessionForTest(t, client, server.URL, templateID, worktree, "ws-program-cache")
+		exec := executionResponse{}
+		postJSON(t, client, server.URL+"/api/v1/executions/run-file", map[string]interface{}{
+			"session_id": sessionID,
+			"path":       "calc.js",
+		}, &exec)
+		if got := resultPreview(t, exec.Result); got != "42" {
+			t.Fatalf("session %d: expected run-file result 42, got %q", i, got)
+		}
+	}
+
+	after := programCacheSummary{}
+	getJSON(t, client, server.URL+"/api/v1/runtime/summary", &after)
+	// The first session compiles the startup file and run-file; the second
+	// reuses both programs.
+	if misses := after.ProgramCache.Misses - before.ProgramCache.Misses; misses != 2 {
+		t.Fatalf("expected 2 program cache misses, got %d", misses)
+	}
+	if hits := after.ProgramCache.Hits - before.ProgramCache.Hits; hits != 2 {
+		t.Fatalf("expected 2 program cache hits, got %d", hits)
+	}
+	if after.ProgramCache.Entries != before.ProgramCache.Entries+2 {
+		t.Fatalf("expected 2 new cache entries, got %+v", after.ProgramCache)
+	}
+}
@@ -0,0 +1,79 @@
+package vmhttp_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmsession"
+	"github.com/go-go-golems/vm-system/pkg/vmstore"
+	vmhttp "github.com/go-go-golems/vm-system/pkg/vmtransport/http"
+)
+
+func TestSessionQuotasRejectCreatesBeyondLimits(t *testing.T) {
+	store, err := vmstore.NewVMStore(filepath.Join(t.TempDir(), "vm-system.db"))
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	core := vmcontrol.NewCore(store,
+		vmcontrol.WithSessionQuotas(vmsession.Quotas{MaxSessions: 3, MaxPerTemplate: 2, MaxPerWorkspace: 1}),
+		vmcontrol.WithMaxConcurrentExecutions(4),
+	)
+	server := httptest.NewServer(vmhttp.NewHandler(core))
+	defer server.Close()
+	client := server.Client()
+
+	worktree := filepath.Join(t.TempDir(), "worktree")
+	mustMkdirAll(t, worktree)
+	first := createTemplateForTest(t, client, server.URL, "quota-first")
+	second := createTemplateForTest(t, client, server.URL, "quota-second")
+
+	rejectSession := func(templateID, workspaceID, code string) {
+		t.Helper()
+		doRequest(t, client, http.MethodPost, server.URL+"/api/v1/sessions", map[string]interface{}{
+			"template_id":     templateID,
+			"workspace_id":    workspaceID,
+			"base_commit_oid": "deadbeef",
+			"worktree_path":   worktree,
+		}, http.StatusTooManyRequests, map[string]string{"code": code})
+	}
+
+	closable := createSessionForTest(t, client, server.URL, first, worktree, "ws-1")
+	rejectSession(first, "ws-1", "WORKSPACE_SESSION_QUOTA_EXCEEDED")
+	createSessionForTest(t, client, server.URL, first, worktree, "ws-2")
+	rejectSession(first, "ws-3", "TEMPLATE_SESSION_QUOTA_EXCEEDED")
+	createSessionForTest(t, client, server.URL, second, worktree, "ws-3")
+	rejectSession(second, "ws-4", "SESSION_QUOTA_EXCEEDED")
+
+	summary := struct {
+		SessionQuota struct {
+			MaxSessions int            `json:"max_sessions"`
+			Sessions    int            `json:"sessions"`
+			ByTemplate  map[string]int `json:"by_template"`
+			ByWorkspace map[string]int `json:"by_workspace"`
+		} `json:"session_quota"`
+		Executions struct {
+			MaxConcurrent int `json:"max_concurrent"`
+			Running       int `json:"running"`
+		} `json:"executions"`
+	}{}
+	getJSON(t, client, server.URL+"/api/v1/runtime/summary", &summary)
+	if summary.SessionQuota.MaxSessions != 3 || summary.SessionQuota.Sessions != 3 {
+		t.Fatalf("expected 3 of 3 sessions in use, got %+v", summary.SessionQuota)
+	}
+	if summary.SessionQuota.ByTemplate[first] != 2 || summary.SessionQuota.ByTemplate[second] != 1 || summary.SessionQuota.ByWorkspace["ws-1"] != 1 {
+		t.Fatalf("unexpected per-template/workspace usage %+v", summary.SessionQuota)
+	}
+	if summary.Executions.MaxConcurrent != 4 || summary.Executions.Running != 0 {
+		t.Fatalf("unexpected execution usage %+v", summary.Executions)
+	}
+
+	// Closing a session frees its slot in every quota.
+	reqJSONStatus(t, client, http.MethodPost, fmt.Sprintf("%s/api/v1/sessions/%s/close", server.URL, closable), nil, http.StatusOK, &map[string]interface{}{})
+	createSessionForTest(t, client, server.URL, second, worktree, "ws-1")
+	rejectSession(second, "ws-5", "SESSION_QUOTA_EXCEEDED")
+}
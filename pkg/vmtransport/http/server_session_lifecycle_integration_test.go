@@ -0,0 +1,133 @@
+package vmhttp_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmstore"
+	vmhttp "github.com/go-go-golems/vm-system/pkg/vmtransport/http"
+)
+
+type sessionLifecycleResponse struct {
+	Status         string     `json:"status"`
+	LastActivityAt time.Time  `json:"last_activity_at"`
+	LastError      string     `json:"last_error"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	ExpiresInMs    *int64     `json:"expires_in_ms"`
+}
+
+func TestSessionLifecycleExpiresIdleAndOldSessions(t *testing.T) {
+	store, err := vmstore.NewVMStore(filepath.Join(t.TempDir(), "vm-system.db"))
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	core := vmcontrol.NewCore(store)
+	server := httptest.NewServer(vmhttp.NewHandler(core))
+	defer server.Close()
+	client := server.Client()
+
+	worktree := filepath.Join(t.TempDir(), "worktree")
+	mustMkdirAll(t, worktree)
+
+	invalid := map[string]interface{}{
+		"name":     "bad-lifecycle-template",
+		"settings": map[string]interface{}{"lifecycle": map[string]interface{}{"idle_timeout": "soon"}},
+	}
+	doRequest(t, client, http.MethodPost, server.URL+"/api/v1/templates:apply", invalid, http.StatusUnprocessableEntity, map[string]string{"code": "INVALID_TEMPLATE_SPEC"})
+
+	apply := func(name string, lifecycle map[string]interface{}) string {
+		created := applyTemplateResponse{}
+		reqJSONStatus(t, client, http.MethodPost, server.URL+"/api/v1/templates:apply", map[string]interface{}{
+			"name":     name,
+			"settings": map[string]interface{}{"lifecycle": lifecycle},
+		}, http.StatusCreated, &created)
+		return created.Template.ID
+	}
+	idleTemplateID := apply("idle-template", map[string]interface{}{"idle_timeout": "10m"})
+	lifetimeTemplateID := apply("lifetime-template", map[string]interface{}{"idle_timeout": "2h", "max_lifetime": "1h"})
+	plainTemplateID := createTemplateForTest(t, client, server.URL, "plain-template")
+
+	idleSessionID := createSessionForTest(t, client, server.URL, idleTemplateID, worktree, "ws-idle")
+	lifetimeSessionID := createSessionForTest(t, client, server.URL, lifetimeTemplateID, worktree, "ws-lifetime")
+	plainSessionID := createSessionForTest(t, client, server.URL, plainTemplateID, worktree, "ws-plain")
+
+	getSession := func(sessionID string) sessionLifecycleResponse {
+		out := sessionLifecycleResponse{}
+		getJSON(t, client, fmt.Sprintf("%s/api/v1/sessions/%s", server.URL, sessionID), &out)
+		return out
+	}
+
+	idle := getSession(idleSessionID)
+	if idle.ExpiresAt == nil || idle.ExpiresInMs == nil {
+		t.Fatalf("expected time-to-expiry for session with idle_timeout, got %+v", idle)
+	}
+	if *idle.ExpiresInMs <= 0 || *idle.ExpiresInMs > (10*time.Minute).Milliseconds() {
+		t.Fatalf("expected expiry within the idle timeout, got %dms", *idle.ExpiresInMs)
+	}
+	if idle.LastActivityAt.IsZero() {
+		t.Fatalf("expected last_activity_at to be set at creation")
+	}
+	if plain := getSession(plainSessionID); plain.ExpiresAt != nil || plain.ExpiresInMs != nil {
+		t.Fatalf("expected no expiry without lifecycle settings, got %+v", plain)
+	}
+
+	// Activity pushes back the idle expiry.
+	before := *idle.ExpiresAt
+	time.Sleep(20 * time.Millisecond)
+	postJSON(t, client, server.URL+"/api/v1/executions/repl", map[string]interface{}{
+		"session_id": idleSessionID,
+		"input":      "1 + 1",
+	}, &executionResponse{})
+	idle = getSession(idleSessionID)
+	if !idle.ExpiresAt.After(before) {
+		t.Fatalf("expected execution to push back idle expiry past %s, got %s", before, idle.ExpiresAt)
+	}
+
+	// 30 minutes on, the 10m idle timeout has passed.
+	expired, err := core.Sessions.Expire(context.Background(), time.Now().Add(30*time.Minute))
+	if err != nil {
+		t.Fatalf("expire sessions: %v", err)
+	}
+	if len(expired) != 1 || expired[0] != idleSessionID {
+		t.Fatalf("expected only the idle session to expire, got %v", expired)
+	}
+	closed := getSession(idleSessionID)
+	if closed.Status != "expired" || closed.LastError != "expired: idle for 10m0s" {
+		t.Fatalf("expected session expired by idle timeout, got %+v", closed)
+	}
+	if closed.ExpiresAt != nil {
+		t.Fatalf("expected no time-to-expiry on a closed session, got %+v", closed)
+	}
+	doRequest(t, client, http.MethodPost, server.URL+"/api/v1/executions/repl", map[string]interface{}{
+		"session_id": idleSessionID,
+		"input":      "1",
+	}, http.StatusNotFound, map[string]string{"code": "SESSION_NOT_FOUND"})
+
+	// max_lifetime applies however active the session is.
+	expired, err = core.Sessions.Expire(context.Background(), time.Now().Add(61*time.Minute))
+	if err != nil {
+		t.Fatalf("expire sessions: %v", err)
+	}
+	if len(expired) != 1 || expired[0] != lifetimeSessionID {
+		t.Fatalf("expected only the lifetime session to expire, got %v", expired)
+	}
+	if session := getSession(lifetimeSessionID); session.Status != "expired" || session.LastError != "expired: reached max lifetime of 1h0m0s" {
+		t.Fatalf("expected session expired by max lifetime, got %+v", session)
+	}
+	if plain := getSession(plainSessionID); plain.Status != "ready" {
+		t.Fatalf("expected session without lifecycle settings to stay ready, got %q", plain.Status)
+	}
+
+	expiredList := []sessionLifecycleResponse{}
+	getJSON(t, client, server.URL+"/api/v1/sessions?status=expired", &expiredList)
+	if len(expiredList) != 2 {
+		t.Fatalf("expected two sessions listed as expired, got %d", len(expiredList))
+	}
+}
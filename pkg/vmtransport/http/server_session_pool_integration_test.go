@@ -0,0 +1,144 @@
+package vmhttp_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmstore"
+	vmhttp "github.com/go-go-golems/vm-system/pkg/vmtransport/http"
+)
+
+type poolSummary struct {
+	Pools []struct {
+		TemplateID string `json:"template_id"`
+		MinIdle    int    `json:"min_idle"`
+		Idle       int    `json:"idle"`
+		Claims     uint64 `json:"claims"`
+		Misses     uint64 `json:"misses"`
+		Failures   uint64 `json:"failures"`
+	} `json:"pools"`
+}
+
+func TestSessionPoolHandsOutWarmRuntimesBoundAtClaim(t *testing.T) {
+	store, err := vmstore.NewVMStore(filepath.Join(t.TempDir(), "vm-system.db"))
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	core := vmcontrol.NewCore(store)
+	server := httptest.NewServer(vmhttp.NewHandler(core))
+	defer server.Close()
+	client := server.Client()
+
+	worktree := filepath.Join(t.TempDir(), "worktree")
+	mustMkdirAll(t, filepath.Join(worktree, "init"))
+	writeFile(t, filepath.Join(worktree, "init", "repo.js"), `globalThis.trace = trace.concat("repo");`)
+
+	spec := map[string]interface{}{
+		"name": "pooled-template",
+		"settings": map[string]interface{}{
+			"pool": map[string]interface{}{"min_idle": 1, "max": 3},
+		},
+		"startup_files": []map[string]interface{}{
+			{"kind": "inline", "path": "prelude.js", "source": `globalThis.trace = ["prelude"];`, "order_index": 10},
+			{"path": "init/repo.js", "order_index": 20},
+		},
+	}
+	created := applyTemplateResponse{}
+	reqJSONStatus(t, client, http.MethodPost, server.URL+"/api/v1/templates:apply", spec, http.StatusCreated, &created)
+	templateID := created.Template.ID
+
+	invalid := map[string]interface{}{
+		"name":     "bad-pool-template",
+		"settings": map[string]interface{}{"pool": map[string]interface{}{"min_idle": 4, "max": 2}},
+	}
+	doRequest(t, client, http.MethodPost, server.URL+"/api/v1/templates:apply", invalid, http.StatusUnprocessableEntity, map[string]string{"code": "INVALID_TEMPLATE_SPEC"})
+
+	if err := core.Sessions.WarmPools(context.Background()); err != nil {
+		t.Fatalf("warm pools: %v", err)
+	}
+	summary := poolSummary{}
+	getJSON(t, client, server.URL+"/api/v1/runtime/summary", &summary)
+	if len(summary.Pools) != 1 || summary.Pools[0].TemplateID != templateID || summary.Pools[0].Idle != 1 {
+		t.Fatalf("expected one warm runtime for the pooled template, got %+v", summary.Pools)
+	}
+
+	sessionID := createSessionForTest(t, client, server.URL, templateID, worktree, "ws-pool")
+	session := struct {
+		WorkspaceID  string `json:"workspace_id"`
+		WorktreePath string `json:"worktree_path"`
+		Status       string `json:"status"`
+	}{}
+	getJSON(t, client, fmt.Sprintf("%s/api/v1/sessions/%s", server.URL, sessionID), &session)
+	if session.WorkspaceID != "ws-pool" || session.WorktreePath != worktree || session.Status != "ready" {
+		t.Fatalf("expected claimed session bound to the request, got %+v", session)
+	}
+
+	exec := executionResponse{}
+	postJSON(t, client, server.URL+"/api/v1/executions/repl", map[string]interface{}{
+		"session_id": sessionID,
+		"input":      `trace.join(",")`,
+	}, &exec)
+	if got := resultPreview(t, exec.Result); got != "prelude,repo" {
+		t.Fatalf("expected inline prelude from the pool and repo startup at claim, got %q", got)
+	}
+
+	// The claim triggers a background refill back to min_idle.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		summary = poolSummary{}
+		getJSON(t, client, server.URL+"/api/v1/runtime/summary", &summary)
+		if len(summary.Pools) == 1 && summary.Pools[0].Idle == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected pool to refill, got %+v", summary.Pools)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if summary.Pools[0].Claims != 1 || summary.Pools[0].Misses != 0 || summary.Pools[0].Failures != 0 {
+		t.Fatalf("unexpected pool counters %+v", summary.Pools[0])
+	}
+
+	// Editing the template makes warm runtimes stale: the next session is
+	// built cold from the new revision.
+	reqJSONStatus(t, client, http.MethodPatch, fmt.Sprintf("%s/api/v1/templates/%s/startup-files/%s", server.URL, templateID, startupFileIDForTest(t, client, server.URL, templateID, "prelude.js")), map[string]interface{}{
+		"source": `globalThis.trace = ["prelude-v2"];`,
+	}, http.StatusOK, &map[string]interface{}{})
+	staleSessionID := createSessionForTest(t, client, server.URL, templateID, worktree, "ws-pool")
+	exec = executionResponse{}
+	postJSON(t, client, server.URL+"/api/v1/executions/repl", map[string]interface{}{
+		"session_id": staleSessionID,
+		"input":      `trace.join(",")`,
+	}, &exec)
+	if got := resultPreview(t, exec.Result); got != "prelude-v2,repo" {
+		t.Fatalf("expected session from the updated template, got %q", got)
+	}
+	summary = poolSummary{}
+	getJSON(t, client, server.URL+"/api/v1/runtime/summary", &summary)
+	if summary.Pools[0].Misses != 1 {
+		t.Fatalf("expected stale warm runtime to count as a miss, got %+v", summary.Pools[0])
+	}
+}
+
+func startupFileIDForTest(t *testing.T, client *http.Client, serverURL, templateID, path string) string {
+	t.Helper()
+
+	detail := struct {
+		StartupFiles []startupFileResponse `json:"startup_files"`
+	}{}
+	getJSON(t, client, fmt.Sprintf("%s/api/v1/templates/%s", serverURL, templateID), &detail)
+	for _, file := range detail.StartupFiles {
+		if file.Path == path {
+			return file.ID
+		}
+	}
+	t.Fatalf("startup file %s not found", path)
+	return ""
+}
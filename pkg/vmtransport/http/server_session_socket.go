@@ -0,0 +1,213 @@
+package vmhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stdhttp "net/http"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmexec"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+const (
+	// maxSocketMessage bounds one client message on a session socket.
+	maxSocketMessage = 1 << 20
+	// socketWriteTimeout bounds how long a slow client may hold up the
+	// execution whose output it is being sent.
+	socketWriteTimeout = 10 * time.Second
+)
+
+// socketRequest is a message a client sends on a session socket: "repl"
+// with input, or "cancel" with an execution_id. Ref is the client's own
+// label, echoed on every reply to the message.
+type socketRequest struct {
+	Type        string `json:"type"`
+	Ref         string `json:"ref,omitempty"`
+	Input       string `json:"input,omitempty"`
+	ExecutionID string `json:"execution_id,omitempty"`
+}
+
+type socketError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// socketMessage is a message the server pushes on a session socket.
+type socketMessage struct {
+	Type      string                   `json:"type"`
+	Ref       string                   `json:"ref,omitempty"`
+	Session   *vmmodels.VMSession      `json:"session,omitempty"`
+	Execution *vmmodels.Execution      `json:"execution,omitempty"`
+	Event     *vmmodels.ExecutionEvent `json:"event,omitempty"`
+	Error     *socketError             `json:"error,omitempty"`
+}
+
+// handleSessionSocket upgrades to a WebSocket carrying REPL inputs for one
+// session. Each input runs through the same ExecuteREPL path as POST
+// /api/v1/executions/repl; its record and events are pushed as they are
+// persisted.
+func (s *Server) handleSessionSocket(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	sessionID, ok := parseSessionIDOrWriteValidationError(w, r.PathValue("session_id"))
+	if !ok {
+		return
+	}
+	details := map[string]string{"session_id": sessionID.String()}
+	session, err := s.core.Sessions.Get(r.Context(), sessionID.String())
+	if err != nil {
+		writeCoreError(w, err, details)
+		return
+	}
+	if session.Status != string(vmmodels.SessionReady) {
+		writeCoreError(w, vmmodels.ErrSessionNotReady, details)
+		return
+	}
+
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		// Accept has already written the error response.
+		return
+	}
+	conn.SetReadLimit(maxSocketMessage)
+	queued := make(chan struct{})
+	close(queued)
+	socket := &sessionSocket{
+		server:    s,
+		r:         r,
+		conn:      conn,
+		sessionID: sessionID.String(),
+		queued:    queued,
+	}
+	socket.send(socketMessage{Type: "session", Session: session})
+	socket.serve()
+	_ = conn.Close(websocket.StatusNormalClosure, "")
+}
+
+// sessionSocket is one client connection to a session socket.
+type sessionSocket struct {
+	server    *Server
+	r         *stdhttp.Request
+	conn      *websocket.Conn
+	sessionID string
+
+	writeMu    sync.Mutex
+	executions sync.WaitGroup
+	// queued is closed once the previous input has an execution record, so
+	// inputs join the session queue in the order they were sent.
+	queued <-chan struct{}
+}
+
+// serve reads client messages until the connection closes, then waits for
+// the executions it started. Executions outlive the client that sent them.
+func (c *sessionSocket) serve() {
+	defer c.executions.Wait()
+	for {
+		_, data, err := c.conn.Read(c.r.Context())
+		if err != nil {
+			return
+		}
+		var req socketRequest
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&req); err != nil {
+			c.sendError("", "INVALID_REQUEST", err.Error())
+			continue
+		}
+
+		switch req.Type {
+		case "repl":
+			if req.Input == "" {
+				c.sendError(req.Ref, "VALIDATION_ERROR", "input is required")
+				continue
+			}
+			c.repl(req)
+		case "cancel":
+			c.cancel(req)
+		default:
+			c.sendError(req.Ref, "VALIDATION_ERROR", "type must be repl or cancel")
+		}
+	}
+}
+
+func (c *sessionSocket) repl(req socketRequest) {
+	previous := c.queued
+	queued := make(chan struct{})
+	c.queued = queued
+	var once sync.Once
+	markQueued := func() { once.Do(func() { close(queued) }) }
+
+	c.executions.Add(1)
+	go func() {
+		defer c.executions.Done()
+		defer markQueued()
+		<-previous
+
+		note := &auditNote{details: map[string]interface{}{
+			"session_id":   c.sessionID,
+			"input_sha256": auditHash(req.Input),
+		}}
+		exec, err := c.server.core.Executions.ExecuteREPL(c.r.Context(), vmcontrol.ExecuteREPLInput{
+			SessionID: c.sessionID,
+			Input:     req.Input,
+			Observer: vmexec.Observer{
+				Started: func(exec *vmmodels.Execution) {
+					markQueued()
+					c.send(socketMessage{Type: "execution.started", Ref: req.Ref, Execution: exec})
+				},
+				Event: func(event *vmmodels.ExecutionEvent) {
+					c.send(socketMessage{Type: "execution.event", Ref: req.Ref, Event: event})
+				},
+			},
+		})
+		if err != nil {
+			status, code, message := ErrorStatus(err)
+			c.server.recordAudit(c.r, "execution.repl", note, status, code)
+			c.sendError(req.Ref, code, message)
+			return
+		}
+		note.resourceID, note.workspaceID = exec.ID, exec.WorkspaceID
+		c.server.recordAudit(c.r, "execution.repl", note, stdhttp.StatusCreated, "")
+		c.send(socketMessage{Type: "execution.finished", Ref: req.Ref, Execution: exec})
+	}()
+}
+
+// cancel cancels a queued execution. Its own execution.finished message,
+// with status cancelled, confirms it.
+func (c *sessionSocket) cancel(req socketRequest) {
+	executionID, err := vmmodels.ParseExecutionID(req.ExecutionID)
+	if err != nil {
+		c.sendError(req.Ref, "VALIDATION_ERROR", "execution_id must be a valid UUID")
+		return
+	}
+	note := &auditNote{resourceID: executionID.String(), details: map[string]interface{}{}}
+	exec, err := c.server.core.Executions.Cancel(c.r.Context(), executionID.String())
+	if err != nil {
+		status, code, message := ErrorStatus(err)
+		c.server.recordAudit(c.r, "execution.cancel", note, status, code)
+		c.sendError(req.Ref, code, message)
+		return
+	}
+	note.workspaceID = exec.WorkspaceID
+	c.server.recordAudit(c.r, "execution.cancel", note, stdhttp.StatusOK, "")
+}
+
+// send pushes msg to the client. Write failures are dropped: a client that
+// went away or stalled past socketWriteTimeout has its connection closed and
+// misses the rest of the output, which stays in the store.
+func (c *sessionSocket) send(msg socketMessage) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	ctx, cancel := context.WithTimeout(c.r.Context(), socketWriteTimeout)
+	defer cancel()
+	_ = wsjson.Write(ctx, c.conn, msg)
+}
+
+func (c *sessionSocket) sendError(ref, code, message string) {
+	c.send(socketMessage{Type: "error", Ref: ref, Error: &socketError{Code: code, Message: message}})
+}
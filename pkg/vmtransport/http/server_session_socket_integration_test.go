@@ -0,0 +1,168 @@
+package vmhttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+	"github.com/go-go-golems/vm-system/pkg/vmstore"
+	vmhttp "github.com/go-go-golems/vm-system/pkg/vmtransport/http"
+)
+
+type socketMessage struct {
+	Type    string `json:"type"`
+	Ref     string `json:"ref"`
+	Session *struct {
+		ID string `json:"id"`
+	} `json:"session"`
+	Execution *struct {
+		ID     string          `json:"id"`
+		Status string          `json:"status"`
+		Result json.RawMessage `json:"result"`
+	} `json:"execution"`
+	Event *struct {
+		ExecutionID string `json:"execution_id"`
+		Seq         int    `json:"seq"`
+		Type        string `json:"type"`
+	} `json:"event"`
+	Error *struct {
+		Code string `json:"code"`
+	} `json:"error"`
+}
+
+func TestSessionSocketStreamsREPLExecutions(t *testing.T) {
+	store, err := vmstore.NewVMStore(filepath.Join(t.TempDir(), "vm-system.db"))
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	core := vmcontrol.NewCore(store)
+	server := httptest.NewServer(vmhttp.NewHandler(core))
+	defer server.Close()
+	client := server.Client()
+
+	worktree := filepath.Join(t.TempDir(), "worktree")
+	mustMkdirAll(t, worktree)
+	templateID := createTemplateForTest(t, client, server.URL, "socket-template")
+	sessionID := createSessionForTest(t, client, server.URL, templateID, worktree, "ws-socket")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	_, resp, err := websocket.Dial(ctx, wsURL+"/api/v1/sessions/00000000-0000-0000-0000-000000000000/ws", nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown session, got %v", err)
+	}
+
+	conn, _, err := websocket.Dial(ctx, fmt.Sprintf("%s/api/v1/sessions/%s/ws", wsURL, sessionID), nil)
+	if err != nil {
+		t.Fatalf("dial session socket: %v", err)
+	}
+	defer func() { _ = conn.CloseNow() }()
+
+	read := func() socketMessage {
+		t.Helper()
+		var msg socketMessage
+		if err := wsjson.Read(ctx, conn, &msg); err != nil {
+			t.Fatalf("read socket message: %v", err)
+		}
+		return msg
+	}
+	write := func(msg interface{}) {
+		t.Helper()
+		if err := wsjson.Write(ctx, conn, msg); err != nil {
+			t.Fatalf("write socket message: %v", err)
+		}
+	}
+
+	if hello := read(); hello.Type != "session" || hello.Session == nil || hello.Session.ID != sessionID {
+		t.Fatalf("expected session message first, got %+v", hello)
+	}
+
+	// Inputs sent back to back run in order; each reports its record, its
+	// events and its settled record.
+	write(map[string]string{"type": "repl", "ref": "a", "input": `globalThis.n = 40; console.log("hi"); n`})
+	write(map[string]string{"type": "repl", "ref": "b", "input": "n + 2"})
+	seen := map[string][]string{}
+	finished := map[string]string{}
+	var order []string
+	for len(finished) < 2 {
+		msg := read()
+		switch msg.Type {
+		case "execution.started":
+			order = append(order, msg.Ref)
+		case "execution.event":
+			if len(seen[msg.Ref]) == 0 && msg.Event.Seq != 1 {
+				t.Fatalf("expected events from seq 1, got %+v", msg.Event)
+			}
+			seen[msg.Ref] = append(seen[msg.Ref], msg.Event.Type)
+		case "execution.finished":
+			if msg.Execution.Status != "ok" {
+				t.Fatalf("expected %s to finish ok, got %+v", msg.Ref, msg.Execution)
+			}
+			finished[msg.Ref] = msg.Execution.ID
+		default:
+			t.Fatalf("unexpected socket message %+v", msg)
+		}
+	}
+	if strings.Join(order, ",") != "a,b" {
+		t.Fatalf("expected executions to start in send order, got %v", order)
+	}
+	if got := strings.Join(seen["a"], ","); got != "input_echo,console,value" {
+		t.Fatalf("unexpected events for a: %s", got)
+	}
+	if got := strings.Join(seen["b"], ","); got != "input_echo,value" {
+		t.Fatalf("unexpected events for b: %s", got)
+	}
+
+	// The executions are persisted like POST /executions/repl.
+	persisted := executionResponse{}
+	getJSON(t, client, server.URL+"/api/v1/executions/"+finished["b"], &persisted)
+	if persisted.Status != "ok" || !strings.Contains(string(persisted.Result), `"preview":"42"`) {
+		t.Fatalf("unexpected persisted execution %+v", persisted)
+	}
+	events := []map[string]interface{}{}
+	getJSON(t, client, server.URL+"/api/v1/executions/"+finished["a"]+"/events", &events)
+	if len(events) != 3 {
+		t.Fatalf("expected 3 persisted events, got %d", len(events))
+	}
+
+	// Bad messages are answered with an error and leave the socket open.
+	write(map[string]string{"type": "repl", "ref": "c"})
+	if msg := read(); msg.Type != "error" || msg.Ref != "c" || msg.Error.Code != "VALIDATION_ERROR" {
+		t.Fatalf("expected VALIDATION_ERROR for empty input, got %+v", msg)
+	}
+	write(map[string]string{"type": "repl", "input": "1", "extra": "x"})
+	if msg := read(); msg.Type != "error" || msg.Error.Code != "INVALID_REQUEST" {
+		t.Fatalf("expected INVALID_REQUEST for unknown fields, got %+v", msg)
+	}
+	write(map[string]string{"type": "cancel", "ref": "d", "execution_id": finished["a"]})
+	if msg := read(); msg.Type != "error" || msg.Ref != "d" || msg.Error.Code != "EXECUTION_NOT_CANCELLABLE" {
+		t.Fatalf("expected EXECUTION_NOT_CANCELLABLE for a finished execution, got %+v", msg)
+	}
+
+	audit, err := core.Audit.List(context.Background(), vmmodels.AuditFilter{Action: "execution.repl"})
+	if err != nil {
+		t.Fatalf("list audit events: %v", err)
+	}
+	if len(audit) != 2 {
+		t.Fatalf("expected one audit event per socket input, got %d", len(audit))
+	}
+	for _, event := range audit {
+		if event.ResourceID != finished["a"] && event.ResourceID != finished["b"] {
+			t.Fatalf("unexpected audited execution %s", event.ResourceID)
+		}
+	}
+}
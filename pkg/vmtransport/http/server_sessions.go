@@ -20,7 +20,9 @@ func (s *Server) handleSessionCreate(w stdhttp.ResponseWriter, r *stdhttp.Reques
 		writeError(w, stdhttp.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
 		return
 	}
-	if req.TemplateID == "" || req.WorkspaceID == "" || req.BaseCommitOID == "" || req.WorktreePath == "" {
+	// A workspace-bound token implies its workspace.
+	_, confined := vmcontrol.WorkspaceFromContext(r.Context())
+	if req.TemplateID == "" || (req.WorkspaceID == "" && !confined) || req.BaseCommitOID == "" || req.WorktreePath == "" {
 		writeError(w, stdhttp.StatusBadRequest, "VALIDATION_ERROR", "template_id, workspace_id, base_commit_oid, and worktree_path are required", nil)
 		return
 	}
@@ -29,6 +31,7 @@ func (s *Server) handleSessionCreate(w stdhttp.ResponseWriter, r *stdhttp.Reques
 		writeError(w, stdhttp.StatusBadRequest, "VALIDATION_ERROR", "template_id must be a valid UUID", nil)
 		return
 	}
+	auditDetail(r, "template_id", templateID.String())
 
 	session, err := s.core.Sessions.Create(r.Context(), vmcontrol.CreateSessionInput{
 		TemplateID:    templateID.String(),
@@ -40,12 +43,13 @@ func (s *Server) handleSessionCreate(w stdhttp.ResponseWriter, r *stdhttp.Reques
 		writeCoreError(w, err, map[string]string{"template_id": templateID.String()})
 		return
 	}
+	auditResource(r, session.ID, session.WorkspaceID)
 	writeJSON(w, stdhttp.StatusCreated, session)
 }
 
 func (s *Server) handleSessionList(w stdhttp.ResponseWriter, r *stdhttp.Request) {
-	status := r.URL.Query().Get("status")
-	sessions, err := s.core.Sessions.List(r.Context(), status)
+	query := r.URL.Query()
+	sessions, err := s.core.Sessions.List(r.Context(), query.Get("status"), query.Get("workspace_id"))
 	if err != nil {
 		writeCoreError(w, err, nil)
 		return
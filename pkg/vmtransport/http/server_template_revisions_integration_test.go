@@ -0,0 +1,102 @@
+package vmhttp_test
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type templateRevisionResponse struct {
+	TemplateID string `json:"template_id"`
+	Revision   int    `json:"revision"`
+	Spec       struct {
+		Modules []string `json:"modules"`
+	} `json:"spec"`
+	Changes []struct {
+		Action   string `json:"action"`
+		Resource string `json:"resource"`
+		Name     string `json:"name"`
+	} `json:"changes"`
+}
+
+func (r templateRevisionResponse) summary() string {
+	parts := make([]string, 0, len(r.Changes))
+	for _, change := range r.Changes {
+		parts = append(parts, change.Action+" "+change.Resource+" "+change.Name)
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestTemplateRevisionsRecordChangesAndPinSessions(t *testing.T) {
+	server, client := newIntegrationTestServer(t)
+	defer server.Close()
+
+	worktree := filepath.Join(t.TempDir(), "worktree")
+	mustMkdirAll(t, worktree)
+
+	templateID := createTemplateForTest(t, client, server.URL, "revision-template")
+	templateURL := fmt.Sprintf("%s/api/v1/templates/%s", server.URL, templateID)
+
+	firstSessionID := createSessionForTest(t, client, server.URL, templateID, worktree, "ws-revisions")
+
+	postJSON(t, client, templateURL+"/modules", map[string]interface{}{"name": "fs"}, &map[string]interface{}{})
+	// Re-adding an existing module changes nothing and must not record a revision.
+	postJSON(t, client, templateURL+"/modules", map[string]interface{}{"name": "fs"}, &map[string]interface{}{})
+	postJSON(t, client, templateURL+"/capabilities", map[string]interface{}{
+		"kind":    "net",
+		"name":    "fetch",
+		"enabled": true,
+		"config":  map[string]interface{}{"allow_hosts": []string{"api.example.com"}},
+	}, &map[string]interface{}{})
+
+	secondSessionID := createSessionForTest(t, client, server.URL, templateID, worktree, "ws-revisions")
+
+	postJSON(t, client, templateURL+"/startup-files", map[string]interface{}{"path": "init/setup.js", "order_index": 10}, &map[string]interface{}{})
+
+	template := struct {
+		Template struct {
+			Revision int `json:"revision"`
+		} `json:"template"`
+	}{}
+	getJSON(t, client, templateURL, &template)
+	if template.Template.Revision != 4 {
+		t.Fatalf("expected template at revision 4, got %d", template.Template.Revision)
+	}
+
+	for sessionID, expected := range map[string]int{firstSessionID: 1, secondSessionID: 3} {
+		session := struct {
+			TemplateRevision int `json:"template_revision"`
+		}{}
+		getJSON(t, client, fmt.Sprintf("%s/api/v1/sessions/%s", server.URL, sessionID), &session)
+		if session.TemplateRevision != expected {
+			t.Fatalf("expected session %s pinned to revision %d, got %d", sessionID, expected, session.TemplateRevision)
+		}
+	}
+
+	revisions := []templateRevisionResponse{}
+	getJSON(t, client, templateURL+"/revisions", &revisions)
+	summaries := make([]string, 0, len(revisions))
+	for _, revision := range revisions {
+		summaries = append(summaries, fmt.Sprintf("%d:%s", revision.Revision, revision.summary()))
+	}
+	expected := []string{
+		"1:create template revision-template",
+		"2:add module fs",
+		"3:add capability net:fetch",
+		"4:add startup_file init/setup.js",
+	}
+	if strings.Join(summaries, "|") != strings.Join(expected, "|") {
+		t.Fatalf("unexpected revision history %v", summaries)
+	}
+
+	revision := templateRevisionResponse{}
+	getJSON(t, client, templateURL+"/revisions/2", &revision)
+	if revision.TemplateID != templateID || len(revision.Spec.Modules) != 1 || revision.Spec.Modules[0] != "fs" || revision.summary() != "add module fs" {
+		t.Fatalf("unexpected revision 2 %+v", revision)
+	}
+
+	doRequest(t, client, http.MethodGet, templateURL+"/revisions/9", nil, http.StatusNotFound, map[string]string{"code": "TEMPLATE_REVISION_NOT_FOUND"})
+	doRequest(t, client, http.MethodGet, templateURL+"/revisions/zero", nil, http.StatusBadRequest, map[string]string{"code": "VALIDATION_ERROR"})
+}
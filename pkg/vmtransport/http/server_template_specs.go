@@ -0,0 +1,93 @@
+package vmhttp
+
+import (
+	stdhttp "net/http"
+	"strconv"
+
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+)
+
+func (s *Server) handleTemplateExport(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	templateID, ok := parseTemplateIDOrWriteValidationError(w, r.PathValue("template_id"))
+	if !ok {
+		return
+	}
+	spec, err := s.core.Templates.Export(r.Context(), templateID.String())
+	if err != nil {
+		writeCoreError(w, err, map[string]string{"template_id": templateID.String()})
+		return
+	}
+	writeJSON(w, stdhttp.StatusOK, spec)
+}
+
+func (s *Server) handleTemplateApply(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	dryRun := false
+	if raw := r.URL.Query().Get("dry_run"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			writeError(w, stdhttp.StatusBadRequest, "VALIDATION_ERROR", "dry_run must be a boolean", map[string]string{"dry_run": raw})
+			return
+		}
+		dryRun = parsed
+	}
+
+	var spec vmmodels.TemplateSpec
+	if err := decodeJSON(r, &spec); err != nil {
+		writeError(w, stdhttp.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	if spec.Name == "" {
+		writeError(w, stdhttp.StatusBadRequest, "VALIDATION_ERROR", "name is required", nil)
+		return
+	}
+
+	auditDetail(r, "name", spec.Name)
+	auditDetail(r, "dry_run", dryRun)
+
+	result, err := s.core.Templates.Apply(r.Context(), &spec, dryRun)
+	if err != nil {
+		writeCoreError(w, err, map[string]string{"name": spec.Name})
+		return
+	}
+	if result.Template != nil {
+		auditResource(r, result.Template.ID, result.Template.WorkspaceID)
+	}
+	auditDetail(r, "changes", result.Changes)
+	status := stdhttp.StatusOK
+	if result.Created && !result.DryRun {
+		status = stdhttp.StatusCreated
+	}
+	writeJSON(w, status, result)
+}
+
+func (s *Server) handleTemplateListRevisions(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	templateID, ok := parseTemplateIDOrWriteValidationError(w, r.PathValue("template_id"))
+	if !ok {
+		return
+	}
+	revisions, err := s.core.Templates.ListRevisions(r.Context(), templateID.String())
+	if err != nil {
+		writeCoreError(w, err, map[string]string{"template_id": templateID.String()})
+		return
+	}
+	writeJSON(w, stdhttp.StatusOK, revisions)
+}
+
+func (s *Server) handleTemplateGetRevision(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	templateID, ok := parseTemplateIDOrWriteValidationError(w, r.PathValue("template_id"))
+	if !ok {
+		return
+	}
+	rawRevision := r.PathValue("revision")
+	revision, err := strconv.Atoi(rawRevision)
+	if err != nil || revision < 1 {
+		writeError(w, stdhttp.StatusBadRequest, "VALIDATION_ERROR", "revision must be a positive integer", map[string]string{"revision": rawRevision})
+		return
+	}
+	result, err := s.core.Templates.GetRevision(r.Context(), templateID.String(), revision)
+	if err != nil {
+		writeCoreError(w, err, map[string]string{"template_id": templateID.String(), "revision": rawRevision})
+		return
+	}
+	writeJSON(w, stdhttp.StatusOK, result)
+}
@@ -0,0 +1,147 @@
+package vmhttp_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type applyTemplateResponse struct {
+	Template *struct {
+		ID             string   `json:"id"`
+		ExposedModules []string `json:"exposed_modules"`
+	} `json:"template"`
+	Created bool `json:"created"`
+	DryRun  bool `json:"dry_run"`
+	Changes []struct {
+		Action   string `json:"action"`
+		Resource string `json:"resource"`
+		Name     string `json:"name"`
+	} `json:"changes"`
+}
+
+func (r applyTemplateResponse) summary() string {
+	parts := make([]string, 0, len(r.Changes))
+	for _, change := range r.Changes {
+		parts = append(parts, change.Action+" "+change.Resource+" "+change.Name)
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestTemplateApplyCreatesUpdatesAndExportsSpec(t *testing.T) {
+	server, client := newIntegrationTestServer(t)
+	defer server.Close()
+
+	spec := map[string]interface{}{
+		"name":   "spec-template",
+		"engine": "goja",
+		"settings": map[string]interface{}{
+			"limits": map[string]interface{}{"cpu_ms": 1000, "wall_ms": 2000, "mem_mb": 64, "max_events": 100, "max_output_kb": 64},
+		},
+		"modules":   []string{"fs"},
+		"libraries": []string{"lodash-4.17.21"},
+		"capabilities": []map[string]interface{}{
+			{"kind": "net", "name": "fetch", "config": map[string]interface{}{"allow_hosts": []string{"api.example.com"}}},
+		},
+		"startup_files": []map[string]interface{}{
+			{"path": "init/a.js", "order_index": 10},
+			{"path": "init/b.js", "order_index": 20},
+		},
+	}
+
+	created := applyTemplateResponse{}
+	reqJSONStatus(t, client, http.MethodPost, server.URL+"/api/v1/templates:apply", spec, http.StatusCreated, &created)
+	if !created.Created || created.Template == nil || !strings.HasPrefix(created.summary(), "create template spec-template,add module fs,add library lodash-4.17.21,update settings limits") {
+		t.Fatalf("unexpected create result %q", created.summary())
+	}
+	templateID := created.Template.ID
+
+	reapplied := applyTemplateResponse{}
+	reqJSONStatus(t, client, http.MethodPost, server.URL+"/api/v1/templates:apply", spec, http.StatusOK, &reapplied)
+	if reapplied.Created || len(reapplied.Changes) != 0 || reapplied.Template.ID != templateID {
+		t.Fatalf("expected idempotent re-apply, got %q", reapplied.summary())
+	}
+
+	exported := map[string]interface{}{}
+	getJSON(t, client, fmt.Sprintf("%s/api/v1/templates/%s/export", server.URL, templateID), &exported)
+	if exported["name"] != "spec-template" || fmt.Sprint(exported["modules"]) != "[fs]" {
+		t.Fatalf("unexpected exported spec %#v", exported)
+	}
+	limits := exported["settings"].(map[string]interface{})["limits"].(map[string]interface{})
+	if limits["cpu_ms"] != float64(1000) {
+		t.Fatalf("expected exported limits to round-trip, got %#v", limits)
+	}
+	capabilities := exported["capabilities"].([]interface{})
+	if len(capabilities) != 1 || capabilities[0].(map[string]interface{})["enabled"] != true {
+		t.Fatalf("expected exported fetch capability to default to enabled, got %#v", capabilities)
+	}
+
+	// Re-applying the exported spec must not produce changes either.
+	roundTrip := applyTemplateResponse{}
+	reqJSONStatus(t, client, http.MethodPost, server.URL+"/api/v1/templates:apply", exported, http.StatusOK, &roundTrip)
+	if len(roundTrip.Changes) != 0 {
+		t.Fatalf("expected exported spec to apply cleanly, got %s", roundTrip.summary())
+	}
+
+	spec["modules"] = []string{}
+	spec["capabilities"] = []map[string]interface{}{
+		{"kind": "net", "name": "fetch", "config": map[string]interface{}{"allow_hosts": []string{"api.example.com", "cdn.example.com"}}},
+	}
+	spec["startup_files"] = []map[string]interface{}{
+		{"path": "init/a.js", "order_index": 30},
+		{"path": "init/c.js", "order_index": 40},
+	}
+	expected := "remove module fs,update capability net:fetch,update startup_file init/a.js,add startup_file init/c.js,remove startup_file init/b.js"
+
+	dryRun := applyTemplateResponse{}
+	reqJSONStatus(t, client, http.MethodPost, server.URL+"/api/v1/templates:apply?dry_run=true", spec, http.StatusOK, &dryRun)
+	if !dryRun.DryRun || dryRun.summary() != expected {
+		t.Fatalf("unexpected dry-run diff %q", dryRun.summary())
+	}
+	modules := []string{}
+	getJSON(t, client, fmt.Sprintf("%s/api/v1/templates/%s/modules", server.URL, templateID), &modules)
+	if len(modules) != 1 {
+		t.Fatalf("expected dry run to leave modules untouched, got %v", modules)
+	}
+
+	applied := applyTemplateResponse{}
+	reqJSONStatus(t, client, http.MethodPost, server.URL+"/api/v1/templates:apply", spec, http.StatusOK, &applied)
+	if applied.summary() != expected || len(applied.Template.ExposedModules) != 0 {
+		t.Fatalf("unexpected apply result %q %+v", applied.summary(), applied.Template)
+	}
+	startup := []struct {
+		Path       string `json:"path"`
+		OrderIndex int    `json:"order_index"`
+	}{}
+	getJSON(t, client, fmt.Sprintf("%s/api/v1/templates/%s/startup-files", server.URL, templateID), &startup)
+	if len(startup) != 2 || startup[0].Path != "init/a.js" || startup[0].OrderIndex != 30 || startup[1].Path != "init/c.js" {
+		t.Fatalf("unexpected startup files after apply %+v", startup)
+	}
+}
+
+func TestTemplateApplyRejectsInvalidSpecs(t *testing.T) {
+	server, client := newIntegrationTestServer(t)
+	defer server.Close()
+
+	cases := []struct {
+		spec   map[string]interface{}
+		status int
+		code   string
+	}{
+		{map[string]interface{}{"engine": "goja"}, http.StatusBadRequest, "VALIDATION_ERROR"},
+		{map[string]interface{}{"name": "x", "unknown": true}, http.StatusBadRequest, "INVALID_REQUEST"},
+		{map[string]interface{}{"name": "x", "modules": []string{"json"}}, http.StatusUnprocessableEntity, "MODULE_NOT_ALLOWED"},
+		{map[string]interface{}{"name": "x", "startup_files": []map[string]interface{}{{"path": "../escape.js"}}}, http.StatusUnprocessableEntity, "INVALID_TEMPLATE_SPEC"},
+		{map[string]interface{}{"name": "x", "capabilities": []map[string]interface{}{{"kind": "net", "name": "socket"}}}, http.StatusUnprocessableEntity, "INVALID_CAPABILITY"},
+	}
+	for _, tc := range cases {
+		doRequest(t, client, http.MethodPost, server.URL+"/api/v1/templates:apply", tc.spec, tc.status, map[string]string{"code": tc.code})
+	}
+
+	templates := []map[string]interface{}{}
+	getJSON(t, client, server.URL+"/api/v1/templates", &templates)
+	if len(templates) != 0 {
+		t.Fatalf("expected invalid specs to create nothing, got %d templates", len(templates))
+	}
+}
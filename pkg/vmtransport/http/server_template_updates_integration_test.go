@@ -0,0 +1,121 @@
+package vmhttp_test
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type templateSummaryResponse struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	IsActive bool   `json:"is_active"`
+}
+
+type startupFileResponse struct {
+	ID         string `json:"id"`
+	Path       string `json:"path"`
+	OrderIndex int    `json:"order_index"`
+}
+
+func TestTemplateUpdateCloneAndChildResourceDeletion(t *testing.T) {
+	server, client := newIntegrationTestServer(t)
+	defer server.Close()
+
+	worktree := filepath.Join(t.TempDir(), "worktree")
+	mustMkdirAll(t, filepath.Join(worktree, "init"))
+	writeFile(t, filepath.Join(worktree, "init", "a.js"), `globalThis.order = (globalThis.order || "") + "a";`)
+	writeFile(t, filepath.Join(worktree, "init", "b.js"), `globalThis.order = (globalThis.order || "") + "b";`)
+
+	templateID := createTemplateForTest(t, client, server.URL, "update-template")
+	createTemplateForTest(t, client, server.URL, "taken-name")
+	templateURL := fmt.Sprintf("%s/api/v1/templates/%s", server.URL, templateID)
+
+	renamed := templateSummaryResponse{}
+	reqJSONStatus(t, client, http.MethodPatch, templateURL, map[string]interface{}{"name": "renamed-template"}, http.StatusOK, &renamed)
+	if renamed.Name != "renamed-template" || !renamed.IsActive {
+		t.Fatalf("unexpected renamed template %+v", renamed)
+	}
+	doRequest(t, client, http.MethodPatch, templateURL, map[string]interface{}{"name": "taken-name"}, http.StatusConflict, map[string]string{"code": "TEMPLATE_NAME_CONFLICT"})
+	doRequest(t, client, http.MethodPatch, templateURL, map[string]interface{}{}, http.StatusBadRequest, map[string]string{"code": "VALIDATION_ERROR"})
+
+	capability := struct {
+		ID string `json:"id"`
+	}{}
+	postJSON(t, client, templateURL+"/capabilities", map[string]interface{}{"kind": "module", "name": "console", "enabled": true}, &capability)
+	fileA := startupFileResponse{}
+	postJSON(t, client, templateURL+"/startup-files", map[string]interface{}{"path": "init/a.js", "order_index": 10}, &fileA)
+	fileB := startupFileResponse{}
+	postJSON(t, client, templateURL+"/startup-files", map[string]interface{}{"path": "init/b.js", "order_index": 20}, &fileB)
+
+	reordered := []startupFileResponse{}
+	reqJSONStatus(t, client, http.MethodPost, templateURL+"/startup-files:reorder", map[string]interface{}{
+		"startup_file_ids": []string{fileB.ID, fileA.ID},
+	}, http.StatusOK, &reordered)
+	if len(reordered) != 2 || reordered[0].Path != "init/b.js" || reordered[0].OrderIndex != 10 || reordered[1].OrderIndex != 20 {
+		t.Fatalf("unexpected reordered startup files %+v", reordered)
+	}
+	doRequest(t, client, http.MethodPost, templateURL+"/startup-files:reorder", map[string]interface{}{
+		"startup_file_ids": []string{fileA.ID},
+	}, http.StatusUnprocessableEntity, map[string]string{"code": "INVALID_STARTUP_ORDER"})
+
+	sessionID := createSessionForTest(t, client, server.URL, templateID, worktree, "ws-update")
+	exec := executionResponse{}
+	postJSON(t, client, server.URL+"/api/v1/executions/repl", map[string]interface{}{"session_id": sessionID, "input": "globalThis.order"}, &exec)
+	if got := resultPreview(t, exec.Result); got != "ba" {
+		t.Fatalf("expected startup files to run in reordered sequence, got %q", got)
+	}
+
+	clone := templateSummaryResponse{}
+	reqJSONStatus(t, client, http.MethodPost, templateURL+"/clone", map[string]interface{}{}, http.StatusCreated, &clone)
+	if clone.ID == templateID || clone.Name != "renamed-template-copy" {
+		t.Fatalf("unexpected clone %+v", clone)
+	}
+	cloneDetail := struct {
+		Capabilities []struct {
+			ID string `json:"id"`
+		} `json:"capabilities"`
+		StartupFiles []startupFileResponse `json:"startup_files"`
+	}{}
+	getJSON(t, client, fmt.Sprintf("%s/api/v1/templates/%s", server.URL, clone.ID), &cloneDetail)
+	if len(cloneDetail.Capabilities) != 1 || cloneDetail.Capabilities[0].ID == capability.ID || len(cloneDetail.StartupFiles) != 2 || cloneDetail.StartupFiles[0].Path != "init/b.js" {
+		t.Fatalf("unexpected clone detail %+v", cloneDetail)
+	}
+	doRequest(t, client, http.MethodPost, templateURL+"/clone", map[string]interface{}{"name": "taken-name"}, http.StatusConflict, map[string]string{"code": "TEMPLATE_NAME_CONFLICT"})
+
+	reqJSONStatus(t, client, http.MethodDelete, fmt.Sprintf("%s/capabilities/%s", templateURL, capability.ID), nil, http.StatusOK, &map[string]interface{}{})
+	doRequest(t, client, http.MethodDelete, fmt.Sprintf("%s/capabilities/%s", templateURL, capability.ID), nil, http.StatusNotFound, map[string]string{"code": "CAPABILITY_NOT_FOUND"})
+	reqJSONStatus(t, client, http.MethodDelete, fmt.Sprintf("%s/startup-files/%s", templateURL, fileA.ID), nil, http.StatusOK, &map[string]interface{}{})
+	// Child resources of another template are not reachable through this one.
+	doRequest(t, client, http.MethodDelete, fmt.Sprintf("%s/startup-files/%s", templateURL, cloneDetail.StartupFiles[0].ID), nil, http.StatusNotFound, map[string]string{"code": "STARTUP_FILE_NOT_FOUND"})
+
+	detail := struct {
+		Capabilities []interface{}         `json:"capabilities"`
+		StartupFiles []startupFileResponse `json:"startup_files"`
+	}{}
+	getJSON(t, client, templateURL, &detail)
+	if len(detail.Capabilities) != 0 || len(detail.StartupFiles) != 1 || detail.StartupFiles[0].ID != fileB.ID {
+		t.Fatalf("unexpected template detail after deletes %+v", detail)
+	}
+
+	deactivated := templateSummaryResponse{}
+	reqJSONStatus(t, client, http.MethodPatch, templateURL, map[string]interface{}{"is_active": false}, http.StatusOK, &deactivated)
+	if deactivated.IsActive {
+		t.Fatalf("expected template to be inactive")
+	}
+	doRequest(t, client, http.MethodPost, server.URL+"/api/v1/sessions", map[string]interface{}{
+		"template_id":     templateID,
+		"workspace_id":    "ws-update",
+		"base_commit_oid": "deadbeef",
+		"worktree_path":   worktree,
+	}, http.StatusConflict, map[string]string{"code": "TEMPLATE_INACTIVE"})
+
+	// Existing sessions keep working.
+	exec = executionResponse{}
+	postJSON(t, client, server.URL+"/api/v1/executions/repl", map[string]interface{}{"session_id": sessionID, "input": "1 + 1"}, &exec)
+	if exec.Status != "ok" || !strings.Contains(resultPreview(t, exec.Result), "2") {
+		t.Fatalf("expected existing session to keep working, got status=%q", exec.Status)
+	}
+}
@@ -14,8 +14,9 @@ import (
 )
 
 type createTemplateRequest struct {
-	Name   string `json:"name"`
-	Engine string `json:"engine"`
+	Name        string `json:"name"`
+	Engine      string `json:"engine"`
+	WorkspaceID string `json:"workspace_id"`
 }
 
 func (s *Server) handleTemplateCreate(w stdhttp.ResponseWriter, r *stdhttp.Request) {
@@ -28,15 +29,18 @@ func (s *Server) handleTemplateCreate(w stdhttp.ResponseWriter, r *stdhttp.Reque
 		writeError(w, stdhttp.StatusBadRequest, "VALIDATION_ERROR", "name is required", nil)
 		return
 	}
+	auditDetail(r, "name", req.Name)
 
 	template, err := s.core.Templates.Create(r.Context(), vmcontrol.CreateTemplateInput{
-		Name:   req.Name,
-		Engine: req.Engine,
+		Name:        req.Name,
+		Engine:      req.Engine,
+		WorkspaceID: req.WorkspaceID,
 	})
 	if err != nil {
 		writeCoreError(w, err, nil)
 		return
 	}
+	auditResource(r, template.ID, template.WorkspaceID)
 	writeJSON(w, stdhttp.StatusCreated, template)
 }
 
@@ -109,6 +113,73 @@ func (s *Server) handleTemplateDelete(w stdhttp.ResponseWriter, r *stdhttp.Reque
 	})
 }
 
+type updateTemplateRequest struct {
+	Name     *string `json:"name"`
+	IsActive *bool   `json:"is_active"`
+}
+
+func (s *Server) handleTemplateUpdate(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	templateID, ok := parseTemplateIDOrWriteValidationError(w, r.PathValue("template_id"))
+	if !ok {
+		return
+	}
+
+	var req updateTemplateRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, stdhttp.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	if req.Name == nil && req.IsActive == nil {
+		writeError(w, stdhttp.StatusBadRequest, "VALIDATION_ERROR", "name or is_active is required", nil)
+		return
+	}
+	if req.Name != nil && strings.TrimSpace(*req.Name) == "" {
+		writeError(w, stdhttp.StatusBadRequest, "VALIDATION_ERROR", "name must not be empty", nil)
+		return
+	}
+	if req.Name != nil {
+		auditDetail(r, "name", *req.Name)
+	}
+	if req.IsActive != nil {
+		auditDetail(r, "is_active", *req.IsActive)
+	}
+
+	template, err := s.core.Templates.Update(r.Context(), templateID.String(), vmcontrol.UpdateTemplateInput{
+		Name:     req.Name,
+		IsActive: req.IsActive,
+	})
+	if err != nil {
+		writeCoreError(w, err, map[string]string{"template_id": templateID.String()})
+		return
+	}
+	writeJSON(w, stdhttp.StatusOK, template)
+}
+
+type cloneTemplateRequest struct {
+	Name string `json:"name"`
+}
+
+func (s *Server) handleTemplateClone(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	templateID, ok := parseTemplateIDOrWriteValidationError(w, r.PathValue("template_id"))
+	if !ok {
+		return
+	}
+
+	var req cloneTemplateRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, stdhttp.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+
+	template, err := s.core.Templates.Clone(r.Context(), templateID.String(), vmcontrol.CloneTemplateInput{Name: req.Name})
+	if err != nil {
+		writeCoreError(w, err, map[string]string{"template_id": templateID.String()})
+		return
+	}
+	auditDetail(r, "clone_id", template.ID)
+	writeJSON(w, stdhttp.StatusCreated, template)
+}
+
 type addCapabilityRequest struct {
 	Kind    string          `json:"kind"`
 	Name    string          `json:"name"`
@@ -134,6 +205,9 @@ func (s *Server) handleTemplateAddCapability(w stdhttp.ResponseWriter, r *stdhtt
 	if len(req.Config) == 0 {
 		req.Config = json.RawMessage("{}")
 	}
+	auditDetail(r, "kind", req.Kind)
+	auditDetail(r, "name", req.Name)
+	auditDetail(r, "enabled", req.Enabled)
 
 	cap := &vmmodels.VMCapability{
 		ID:      uuid.NewString(),
@@ -147,6 +221,7 @@ func (s *Server) handleTemplateAddCapability(w stdhttp.ResponseWriter, r *stdhtt
 		writeCoreError(w, err, map[string]string{"template_id": templateID.String()})
 		return
 	}
+	auditDetail(r, "capability_id", cap.ID)
 	writeJSON(w, stdhttp.StatusCreated, cap)
 }
 
@@ -163,6 +238,29 @@ func (s *Server) handleTemplateListCapabilities(w stdhttp.ResponseWriter, r *std
 	writeJSON(w, stdhttp.StatusOK, caps)
 }
 
+func (s *Server) handleTemplateDeleteCapability(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	templateID, ok := parseTemplateIDOrWriteValidationError(w, r.PathValue("template_id"))
+	if !ok {
+		return
+	}
+	capabilityID := r.PathValue("capability_id")
+	if capabilityID == "" {
+		writeError(w, stdhttp.StatusBadRequest, "VALIDATION_ERROR", "capability_id is required", nil)
+		return
+	}
+
+	details := map[string]string{"template_id": templateID.String(), "capability_id": capabilityID}
+	if err := s.core.Templates.DeleteCapability(r.Context(), templateID.String(), capabilityID); err != nil {
+		writeCoreError(w, err, details)
+		return
+	}
+	writeJSON(w, stdhttp.StatusOK, map[string]string{
+		"status":        "ok",
+		"template_id":   templateID.String(),
+		"capability_id": capabilityID,
+	})
+}
+
 type addTemplateModuleRequest struct {
 	Name string `json:"name"`
 }
@@ -195,6 +293,7 @@ func (s *Server) handleTemplateAddModule(w stdhttp.ResponseWriter, r *stdhttp.Re
 		writeError(w, stdhttp.StatusBadRequest, "VALIDATION_ERROR", "name is required", nil)
 		return
 	}
+	auditDetail(r, "module_name", req.Name)
 
 	if err := s.core.Templates.AddModule(r.Context(), templateID.String(), req.Name); err != nil {
 		writeCoreError(w, err, map[string]string{"template_id": templateID.String()})
@@ -261,6 +360,7 @@ func (s *Server) handleTemplateAddLibrary(w stdhttp.ResponseWriter, r *stdhttp.R
 		writeError(w, stdhttp.StatusBadRequest, "VALIDATION_ERROR", "name is required", nil)
 		return
 	}
+	auditDetail(r, "library_name", req.Name)
 
 	if err := s.core.Templates.AddLibrary(r.Context(), templateID.String(), req.Name); err != nil {
 		writeCoreError(w, err, map[string]string{"template_id": templateID.String()})
@@ -296,7 +396,9 @@ func (s *Server) handleTemplateRemoveLibrary(w stdhttp.ResponseWriter, r *stdhtt
 }
 
 type addStartupFileRequest struct {
+	Kind       string `json:"kind"`
 	Path       string `json:"path"`
+	Source     string `json:"source"`
 	OrderIndex int    `json:"order_index"`
 	Mode       string `json:"mode"`
 }
@@ -343,14 +445,18 @@ func (s *Server) handleTemplateAddStartupFile(w stdhttp.ResponseWriter, r *stdht
 	startup := &vmmodels.VMStartupFile{
 		ID:         uuid.NewString(),
 		VMID:       templateID.String(),
+		Kind:       req.Kind,
 		Path:       parsedPath.String(),
+		Source:     req.Source,
 		OrderIndex: req.OrderIndex,
 		Mode:       req.Mode,
 	}
+	auditDetail(r, "path", startup.Path)
 	if err := s.core.Templates.AddStartupFile(r.Context(), startup); err != nil {
 		writeCoreError(w, err, map[string]string{"template_id": templateID.String()})
 		return
 	}
+	auditDetail(r, "startup_file_id", startup.ID)
 
 	writeJSON(w, stdhttp.StatusCreated, startup)
 }
@@ -367,3 +473,90 @@ func (s *Server) handleTemplateListStartupFiles(w stdhttp.ResponseWriter, r *std
 	}
 	writeJSON(w, stdhttp.StatusOK, files)
 }
+
+func (s *Server) handleTemplateDeleteStartupFile(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	templateID, ok := parseTemplateIDOrWriteValidationError(w, r.PathValue("template_id"))
+	if !ok {
+		return
+	}
+	startupFileID := r.PathValue("startup_file_id")
+	if startupFileID == "" {
+		writeError(w, stdhttp.StatusBadRequest, "VALIDATION_ERROR", "startup_file_id is required", nil)
+		return
+	}
+
+	details := map[string]string{"template_id": templateID.String(), "startup_file_id": startupFileID}
+	if err := s.core.Templates.DeleteStartupFile(r.Context(), templateID.String(), startupFileID); err != nil {
+		writeCoreError(w, err, details)
+		return
+	}
+	writeJSON(w, stdhttp.StatusOK, map[string]string{
+		"status":          "ok",
+		"template_id":     templateID.String(),
+		"startup_file_id": startupFileID,
+	})
+}
+
+type updateStartupFileRequest struct {
+	Source     *string `json:"source"`
+	OrderIndex *int    `json:"order_index"`
+	Mode       *string `json:"mode"`
+}
+
+func (s *Server) handleTemplateUpdateStartupFile(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	templateID, ok := parseTemplateIDOrWriteValidationError(w, r.PathValue("template_id"))
+	if !ok {
+		return
+	}
+	startupFileID := r.PathValue("startup_file_id")
+
+	var req updateStartupFileRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, stdhttp.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	if req.Source == nil && req.OrderIndex == nil && req.Mode == nil {
+		writeError(w, stdhttp.StatusBadRequest, "VALIDATION_ERROR", "source, order_index, or mode is required", nil)
+		return
+	}
+
+	details := map[string]string{"template_id": templateID.String(), "startup_file_id": startupFileID}
+	file, err := s.core.Templates.UpdateStartupFile(r.Context(), templateID.String(), startupFileID, vmcontrol.UpdateStartupFileInput{
+		Source:     req.Source,
+		OrderIndex: req.OrderIndex,
+		Mode:       req.Mode,
+	})
+	if err != nil {
+		writeCoreError(w, err, details)
+		return
+	}
+	writeJSON(w, stdhttp.StatusOK, file)
+}
+
+type reorderStartupFilesRequest struct {
+	StartupFileIDs []string `json:"startup_file_ids"`
+}
+
+func (s *Server) handleTemplateReorderStartupFiles(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	templateID, ok := parseTemplateIDOrWriteValidationError(w, r.PathValue("template_id"))
+	if !ok {
+		return
+	}
+
+	var req reorderStartupFilesRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, stdhttp.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	if req.StartupFileIDs == nil {
+		writeError(w, stdhttp.StatusBadRequest, "VALIDATION_ERROR", "startup_file_ids is required", nil)
+		return
+	}
+
+	files, err := s.core.Templates.ReorderStartupFiles(r.Context(), templateID.String(), req.StartupFileIDs)
+	if err != nil {
+		writeCoreError(w, err, map[string]string{"template_id": templateID.String()})
+		return
+	}
+	writeJSON(w, stdhttp.StatusOK, files)
+}
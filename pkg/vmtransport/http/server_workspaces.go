@@ -0,0 +1,54 @@
+package vmhttp
+
+import (
+	stdhttp "net/http"
+
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+)
+
+type createWorkspaceRequest struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (s *Server) handleWorkspaceCreate(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	var req createWorkspaceRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, stdhttp.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	if req.ID == "" {
+		writeError(w, stdhttp.StatusBadRequest, "VALIDATION_ERROR", "id is required", nil)
+		return
+	}
+	auditResource(r, req.ID, req.ID)
+
+	workspace, err := s.core.Workspaces.Create(r.Context(), vmcontrol.CreateWorkspaceInput{
+		ID:   req.ID,
+		Name: req.Name,
+	})
+	if err != nil {
+		writeCoreError(w, err, map[string]string{"workspace_id": req.ID})
+		return
+	}
+	writeJSON(w, stdhttp.StatusCreated, workspace)
+}
+
+func (s *Server) handleWorkspaceList(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	workspaces, err := s.core.Workspaces.List(r.Context())
+	if err != nil {
+		writeCoreError(w, err, nil)
+		return
+	}
+	writeJSON(w, stdhttp.StatusOK, workspaces)
+}
+
+func (s *Server) handleWorkspaceGet(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	workspace, err := s.core.Workspaces.Get(r.Context(), workspaceID)
+	if err != nil {
+		writeCoreError(w, err, map[string]string{"workspace_id": workspaceID})
+		return
+	}
+	writeJSON(w, stdhttp.StatusOK, workspace)
+}
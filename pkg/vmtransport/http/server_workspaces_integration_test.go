@@ -0,0 +1,223 @@
+package vmhttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-go-golems/vm-system/pkg/vmclient"
+	"github.com/go-go-golems/vm-system/pkg/vmcontrol"
+	"github.com/go-go-golems/vm-system/pkg/vmmodels"
+	"github.com/go-go-golems/vm-system/pkg/vmstore"
+	vmhttp "github.com/go-go-golems/vm-system/pkg/vmtransport/http"
+)
+
+func TestWorkspaceTokensIsolateSessionsExecutionsAndTemplates(t *testing.T) {
+	store, err := vmstore.NewVMStore(filepath.Join(t.TempDir(), "vm-system.db"))
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	core := vmcontrol.NewCore(store)
+	server := httptest.NewServer(vmhttp.NewHandler(core, vmhttp.RequireAuth()))
+	defer server.Close()
+	ctx := context.Background()
+
+	allScopes := []string{vmmodels.ScopeTemplatesWrite, vmmodels.ScopeSessionsCreate, vmmodels.ScopeExecutionsRun}
+	issue := func(name, workspaceID string) *vmclient.Client {
+		created, err := core.Auth.Create(ctx, vmcontrol.CreateTokenInput{Name: name, Scopes: allScopes, WorkspaceID: workspaceID})
+		if err != nil {
+			t.Fatalf("create token %s: %v", name, err)
+		}
+		if created.Token.WorkspaceID != workspaceID {
+			t.Fatalf("expected token bound to %q, got %q", workspaceID, created.Token.WorkspaceID)
+		}
+		return vmclient.New(server.URL, server.Client(), vmclient.WithToken(created.Secret))
+	}
+	admin := issue("admin", "")
+	alice := issue("alice", "ws-a")
+	bob := issue("bob", "ws-b")
+
+	expectAPIError := func(err error, status int, code string) {
+		t.Helper()
+		var apiErr *vmclient.APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("expected API error %s, got %v", code, err)
+		}
+		if apiErr.StatusCode != status || apiErr.Code != code {
+			t.Fatalf("expected %d %s, got %d %s", status, code, apiErr.StatusCode, apiErr.Code)
+		}
+	}
+
+	// Tokens register the workspaces they are bound to.
+	workspaces, err := admin.ListWorkspaces(ctx)
+	if err != nil {
+		t.Fatalf("admin list workspaces: %v", err)
+	}
+	if len(workspaces) != 2 {
+		t.Fatalf("expected workspaces ws-a and ws-b, got %+v", workspaces)
+	}
+	if visible, err := alice.ListWorkspaces(ctx); err != nil || len(visible) != 1 || visible[0].ID != "ws-a" {
+		t.Fatalf("expected alice to see only ws-a, got %+v (%v)", visible, err)
+	}
+	_, err = alice.GetWorkspace(ctx, "ws-b")
+	expectAPIError(err, http.StatusNotFound, "WORKSPACE_NOT_FOUND")
+	_, err = alice.CreateWorkspace(ctx, vmclient.CreateWorkspaceRequest{ID: "ws-c"})
+	expectAPIError(err, http.StatusForbidden, "WORKSPACE_FORBIDDEN")
+	if _, err := admin.CreateWorkspace(ctx, vmclient.CreateWorkspaceRequest{ID: "ws-c", Name: "Team C"}); err != nil {
+		t.Fatalf("admin create workspace: %v", err)
+	}
+	_, err = admin.CreateWorkspace(ctx, vmclient.CreateWorkspaceRequest{ID: "ws-c"})
+	expectAPIError(err, http.StatusConflict, "WORKSPACE_EXISTS")
+
+	// Shared templates are readable everywhere but only unconfined callers
+	// change them; workspace templates are private to their workspace.
+	shared, err := admin.CreateTemplate(ctx, vmclient.CreateTemplateRequest{Name: "shared-template"})
+	if err != nil {
+		t.Fatalf("admin create shared template: %v", err)
+	}
+	private, err := alice.CreateTemplate(ctx, vmclient.CreateTemplateRequest{Name: "alice-template"})
+	if err != nil {
+		t.Fatalf("alice create template: %v", err)
+	}
+	if private.WorkspaceID != "ws-a" {
+		t.Fatalf("expected alice's template owned by ws-a, got %q", private.WorkspaceID)
+	}
+	if _, err := bob.GetTemplate(ctx, shared.ID); err != nil {
+		t.Fatalf("bob get shared template: %v", err)
+	}
+	rename := "renamed"
+	_, err = bob.UpdateTemplate(ctx, shared.ID, vmclient.UpdateTemplateRequest{Name: &rename})
+	expectAPIError(err, http.StatusForbidden, "WORKSPACE_FORBIDDEN")
+	_, err = bob.GetTemplate(ctx, private.ID)
+	expectAPIError(err, http.StatusNotFound, "TEMPLATE_NOT_FOUND")
+	if templates, err := bob.ListTemplates(ctx); err != nil || len(templates) != 1 || templates[0].ID != shared.ID {
+		t.Fatalf("expected bob to list only the shared template, got %+v (%v)", templates, err)
+	}
+
+	worktree := filepath.Join(t.TempDir(), "worktree")
+	mustMkdirAll(t, worktree)
+	sessionRequest := func(templateID, workspaceID string) vmclient.CreateSessionRequest {
+		return vmclient.CreateSessionRequest{
+			TemplateID:    templateID,
+			WorkspaceID:   workspaceID,
+			BaseCommitOID: "deadbeef",
+			WorktreePath:  worktree,
+		}
+	}
+
+	// A bound token's workspace is implied and cannot be overridden.
+	aliceSession, err := alice.CreateSession(ctx, sessionRequest(private.ID, ""))
+	if err != nil {
+		t.Fatalf("alice create session: %v", err)
+	}
+	if aliceSession.WorkspaceID != "ws-a" {
+		t.Fatalf("expected alice's session in ws-a, got %q", aliceSession.WorkspaceID)
+	}
+	_, err = alice.CreateSession(ctx, sessionRequest(shared.ID, "ws-b"))
+	expectAPIError(err, http.StatusForbidden, "WORKSPACE_FORBIDDEN")
+	_, err = bob.CreateSession(ctx, sessionRequest(private.ID, ""))
+	expectAPIError(err, http.StatusNotFound, "TEMPLATE_NOT_FOUND")
+	bobSession, err := bob.CreateSession(ctx, sessionRequest(shared.ID, ""))
+	if err != nil {
+		t.Fatalf("bob create session: %v", err)
+	}
+
+	execution, err := alice.ExecuteREPL(ctx, vmclient.ExecuteREPLRequest{SessionID: aliceSession.ID, Input: "40 + 2"})
+	if err != nil {
+		t.Fatalf("alice execute repl: %v", err)
+	}
+	if execution.WorkspaceID != "ws-a" {
+		t.Fatalf("expected execution owned by ws-a, got %q", execution.WorkspaceID)
+	}
+
+	// Other workspaces' sessions and executions do not exist for bob.
+	_, err = bob.GetSession(ctx, aliceSession.ID)
+	expectAPIError(err, http.StatusNotFound, "SESSION_NOT_FOUND")
+	_, err = bob.CloseSession(ctx, aliceSession.ID)
+	expectAPIError(err, http.StatusNotFound, "SESSION_NOT_FOUND")
+	_, err = bob.ExecuteREPL(ctx, vmclient.ExecuteREPLRequest{SessionID: aliceSession.ID, Input: "1"})
+	expectAPIError(err, http.StatusNotFound, "SESSION_NOT_FOUND")
+	_, err = bob.ListExecutions(ctx, aliceSession.ID, 10)
+	expectAPIError(err, http.StatusNotFound, "SESSION_NOT_FOUND")
+	_, err = bob.GetExecution(ctx, execution.ID)
+	expectAPIError(err, http.StatusNotFound, "EXECUTION_NOT_FOUND")
+	_, err = bob.GetExecutionEvents(ctx, execution.ID, 0)
+	expectAPIError(err, http.StatusNotFound, "EXECUTION_NOT_FOUND")
+
+	if sessions, err := bob.ListSessions(ctx, ""); err != nil || len(sessions) != 1 || sessions[0].ID != bobSession.ID {
+		t.Fatalf("expected bob to list only his session, got %+v (%v)", sessions, err)
+	}
+
+	// Unbound tokens see every workspace and can filter by one.
+	if sessions, err := admin.ListSessions(ctx, ""); err != nil || len(sessions) != 2 {
+		t.Fatalf("expected admin to list both sessions, got %+v (%v)", sessions, err)
+	}
+	if sessions, err := admin.ListWorkspaceSessions(ctx, "ws-a", ""); err != nil || len(sessions) != 1 || sessions[0].ID != aliceSession.ID {
+		t.Fatalf("expected ws-a filter to return alice's session, got %+v (%v)", sessions, err)
+	}
+	if _, err := admin.GetExecution(ctx, execution.ID); err != nil {
+		t.Fatalf("admin get execution: %v", err)
+	}
+
+	// The runtime summary shows confined tokens only their workspace's
+	// sessions, quota usage and pools.
+	pooled := func(client *vmclient.Client, name string) string {
+		applied, err := client.ApplyTemplate(ctx, &vmmodels.TemplateSpec{
+			Name:     name,
+			Settings: &vmmodels.TemplateSpecSettings{Pool: &vmmodels.PoolConfig{MinIdle: 1}},
+		}, false)
+		if err != nil {
+			t.Fatalf("apply %s: %v", name, err)
+		}
+		return applied.Template.ID
+	}
+	alicePool := pooled(alice, "alice-pool")
+	pooled(admin, "shared-pool")
+	if err := core.Sessions.WarmPools(ctx); err != nil {
+		t.Fatalf("warm pools: %v", err)
+	}
+	type runtimeSummary struct {
+		ActiveSessionIDs []string `json:"active_session_ids"`
+		Pools            []struct {
+			TemplateID string `json:"template_id"`
+		} `json:"pools"`
+		SessionQuota struct {
+			Sessions    int            `json:"sessions"`
+			ByTemplate  map[string]int `json:"by_template"`
+			ByWorkspace map[string]int `json:"by_workspace"`
+		} `json:"session_quota"`
+	}
+	summaryOf := func(client *vmclient.Client) runtimeSummary {
+		t.Helper()
+		raw, err := client.RuntimeSummary(ctx)
+		if err != nil {
+			t.Fatalf("runtime summary: %v", err)
+		}
+		data, _ := json.Marshal(raw)
+		summary := runtimeSummary{}
+		if err := json.Unmarshal(data, &summary); err != nil {
+			t.Fatalf("decode runtime summary: %v", err)
+		}
+		return summary
+	}
+	aliceSummary := summaryOf(alice)
+	if fmt.Sprint(aliceSummary.ActiveSessionIDs) != fmt.Sprint([]string{aliceSession.ID}) {
+		t.Fatalf("expected alice to see only her session, got %v", aliceSummary.ActiveSessionIDs)
+	}
+	if quota := aliceSummary.SessionQuota; quota.Sessions != 1 || fmt.Sprint(quota.ByWorkspace) != "map[ws-a:1]" || fmt.Sprint(quota.ByTemplate) != fmt.Sprintf("map[%s:1]", private.ID) {
+		t.Fatalf("expected alice's quota usage limited to ws-a, got %+v", quota)
+	}
+	if len(aliceSummary.Pools) != 1 || aliceSummary.Pools[0].TemplateID != alicePool {
+		t.Fatalf("expected alice to see only her template's pool, got %+v", aliceSummary.Pools)
+	}
+	adminSummary := summaryOf(admin)
+	if len(adminSummary.ActiveSessionIDs) != 2 || len(adminSummary.Pools) != 2 || len(adminSummary.SessionQuota.ByWorkspace) != 2 {
+		t.Fatalf("expected admin to see every session, pool and workspace, got %+v", adminSummary)
+	}
+}
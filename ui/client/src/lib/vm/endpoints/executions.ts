@@ -4,6 +4,8 @@ import type {
   RawExecutionEvent,
 } from '../../types';
 import { asArray, mapEvents, mapExecution } from '../../normalize';
+import { runREPLOverSocket } from '../sessionSocket';
+import type { ApiError } from '../transport';
 import type { VmEndpointBuilder } from './shared';
 
 export function buildExecutionEndpoints(builder: VmEndpointBuilder) {
@@ -40,24 +42,13 @@ export function buildExecutionEndpoints(builder: VmEndpointBuilder) {
     }),
 
     executeREPL: builder.mutation<Execution, { sessionId: string; input: string }>({
-      async queryFn({ sessionId, input }, _api, _extraOpts, baseQuery) {
-        const res = await baseQuery({
-          url: '/api/v1/executions/repl',
-          method: 'POST',
-          body: { session_id: sessionId, input },
-        });
-        if (res.error) return { error: res.error };
-
-        const raw = res.data as RawExecution;
-        const eventsRes = await baseQuery({
-          url: `/api/v1/executions/${raw.id}/events`,
-          query: { after_seq: 0 },
-        });
-        const events = eventsRes.error
-          ? []
-          : mapEvents(asArray(eventsRes.data as RawExecutionEvent[] | null));
-
-        return { data: mapExecution(raw, events) };
+      async queryFn({ sessionId, input }) {
+        try {
+          const { execution, events } = await runREPLOverSocket(sessionId, input);
+          return { data: mapExecution(execution, mapEvents(events)) };
+        } catch (err) {
+          return { error: err as ApiError };
+        }
       },
       invalidatesTags: (_result, _err, { sessionId }) => [
         { type: 'Execution', id: `LIST-${sessionId}` },
@@ -0,0 +1,91 @@
+import type { RawExecution, RawExecutionEvent } from '../types';
+import { getSocketURL, type ApiError } from './transport';
+
+interface SocketMessage {
+  type: 'session' | 'execution.started' | 'execution.event' | 'execution.finished' | 'error';
+  ref?: string;
+  execution?: RawExecution;
+  event?: RawExecutionEvent;
+  error?: { code: string; message: string };
+}
+
+export interface SocketREPLResult {
+  execution: RawExecution;
+  events: RawExecutionEvent[];
+}
+
+interface PendingREPL {
+  events: RawExecutionEvent[];
+  resolve: (result: SocketREPLResult) => void;
+  reject: (error: ApiError) => void;
+}
+
+// One WebSocket per session (GET /api/v1/sessions/{id}/ws), shared by every
+// REPL run submitted from the workbench. Events arrive as they are produced,
+// so a run no longer needs a follow-up events request.
+class SessionSocket {
+  private socket: WebSocket;
+  private opened: Promise<void>;
+  private pending = new Map<string, PendingREPL>();
+  private nextRef = 0;
+
+  constructor(sessionId: string, onClose: () => void) {
+    this.socket = new WebSocket(getSocketURL(`/api/v1/sessions/${sessionId}/ws`));
+    this.opened = new Promise((resolve, reject) => {
+      this.socket.addEventListener('open', () => resolve(), { once: true });
+      this.socket.addEventListener(
+        'error',
+        () => reject({ status: 0, message: 'Session socket failed to connect' } satisfies ApiError),
+        { once: true },
+      );
+    });
+    this.socket.addEventListener('message', (e) => {
+      this.handle(JSON.parse(String(e.data)) as SocketMessage);
+    });
+    this.socket.addEventListener('close', () => {
+      this.pending.forEach((p) => p.reject({ status: 0, message: 'Session socket closed' }));
+      this.pending.clear();
+      onClose();
+    });
+  }
+
+  async runREPL(input: string): Promise<SocketREPLResult> {
+    await this.opened;
+    const ref = String(++this.nextRef);
+    return new Promise((resolve, reject) => {
+      this.pending.set(ref, { events: [], resolve, reject });
+      this.socket.send(JSON.stringify({ type: 'repl', ref, input }));
+    });
+  }
+
+  private handle(msg: SocketMessage) {
+    const ref = msg.ref ?? '';
+    const pending = this.pending.get(ref);
+    if (!pending) return;
+
+    switch (msg.type) {
+      case 'execution.event':
+        if (msg.event) pending.events.push(msg.event);
+        break;
+      case 'execution.finished':
+        this.pending.delete(ref);
+        pending.resolve({ execution: msg.execution as RawExecution, events: pending.events });
+        break;
+      case 'error':
+        this.pending.delete(ref);
+        pending.reject({ status: 0, message: msg.error?.message ?? 'Execution failed' });
+        break;
+    }
+  }
+}
+
+const sockets = new Map<string, SessionSocket>();
+
+export function runREPLOverSocket(sessionId: string, input: string): Promise<SocketREPLResult> {
+  let socket = sockets.get(sessionId);
+  if (!socket) {
+    socket = new SessionSocket(sessionId, () => sockets.delete(sessionId));
+    sockets.set(sessionId, socket);
+  }
+  return socket.runREPL(input);
+}
@@ -34,6 +34,13 @@ function getURL(path: string, query?: Record<string, string | number | undefined
   return hasAbsoluteBase ? url.toString() : `${url.pathname}${url.search}`;
 }
 
+// getSocketURL returns the ws:// or wss:// URL of an API path.
+export function getSocketURL(path: string): string {
+  const url = new URL(getURL(path), window.location.origin);
+  url.protocol = url.protocol === 'https:' ? 'wss:' : 'ws:';
+  return url.toString();
+}
+
 export type VmBaseQuery = BaseQueryFn<ApiRequestArgs | string, unknown, ApiError>;
 
 export const vmBaseQuery: VmBaseQuery = async (args) => {
@@ -34,6 +34,11 @@ export default defineConfig(({ mode }) => {
         "/api/v1": {
           target: proxyTarget,
           changeOrigin: true,
+          ws: true,
+          // The daemon only accepts session sockets from its own origin.
+          configure: (proxy) => {
+            proxy.on("proxyReqWs", (proxyReq) => proxyReq.setHeader("origin", proxyTarget));
+          },
         },
       },
       fs: {